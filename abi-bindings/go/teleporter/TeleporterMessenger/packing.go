@@ -144,6 +144,102 @@ func PackMessageReceivedOutput(success bool) ([]byte, error) {
 	return abi.PackOutput("messageReceived", success)
 }
 
+// PackGetFeeInfo packs a GetFeeInfoInput to form a call to the getFeeInfo function
+func PackGetFeeInfo(messageID [32]byte) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+	return abi.Pack("getFeeInfo", messageID)
+}
+
+func PackGetFeeInfoOutput(feeTokenAddress common.Address, amount *big.Int) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.PackOutput("getFeeInfo", feeTokenAddress, amount)
+}
+
+// PackCheckRelayerRewardAmount packs a CheckRelayerRewardAmountInput to form a call to the checkRelayerRewardAmount
+// function
+func PackCheckRelayerRewardAmount(relayer common.Address, feeAsset common.Address) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+	return abi.Pack("checkRelayerRewardAmount", relayer, feeAsset)
+}
+
+func PackCheckRelayerRewardAmountOutput(amount *big.Int) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.PackOutput("checkRelayerRewardAmount", amount)
+}
+
+// PackRedeemRelayerRewards packs a RedeemRelayerRewardsInput to form a call to the redeemRelayerRewards function
+func PackRedeemRelayerRewards(feeAsset common.Address) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+	return abi.Pack("redeemRelayerRewards", feeAsset)
+}
+
+// PackSendSpecifiedReceipts packs input to form a call to the sendSpecifiedReceipts function
+func PackSendSpecifiedReceipts(
+	sourceBlockchainID ids.ID,
+	messageIDs [][32]byte,
+	feeInfo TeleporterFeeInfo,
+	allowedRelayerAddresses []common.Address,
+) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+	return abi.Pack("sendSpecifiedReceipts", sourceBlockchainID, messageIDs, feeInfo, allowedRelayerAddresses)
+}
+
+// PackGetReceiptQueueSize packs input to form a call to the getReceiptQueueSize function
+func PackGetReceiptQueueSize(sourceBlockchainID ids.ID) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+	return abi.Pack("getReceiptQueueSize", sourceBlockchainID)
+}
+
+func PackGetReceiptQueueSizeOutput(size *big.Int) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.PackOutput("getReceiptQueueSize", size)
+}
+
+// PackGetReceiptAtIndex packs input to form a call to the getReceiptAtIndex function
+func PackGetReceiptAtIndex(sourceBlockchainID ids.ID, index *big.Int) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+	return abi.Pack("getReceiptAtIndex", sourceBlockchainID, index)
+}
+
+func PackGetReceiptAtIndexOutput(receipt TeleporterMessageReceipt) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.PackOutput("getReceiptAtIndex", receipt)
+}
+
 // UnpackEvent unpacks the event data and topics into the provided interface
 func UnpackEvent(out interface{}, event string, topics []common.Hash, data []byte) error {
 	teleporterABI, err := TeleporterMessengerMetaData.GetAbi()
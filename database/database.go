@@ -18,18 +18,45 @@ var (
 	ErrKeyNotFound              = errors.New("key not found")
 	ErrRelayerIDNotFound        = errors.New("no database entry for relayer id")
 	ErrDatabaseMisconfiguration = errors.New("database misconfiguration")
+	ErrNewerCheckpoint          = errors.New("target database has a newer checkpoint")
+	ErrMigrationVerification    = errors.New("migrated value does not match the source database")
 )
 
 const (
 	LatestProcessedBlockKey DataKey = iota
+	DeadLetterQueueKey
+	MessageStatusKey
+	SpendBudgetsKey
+	RedeemedRewardsKey
+	ExecutionRetriesKey
 )
 
 type DataKey int
 
+// DataKeys are all of the keys stored for each relayerID
+var DataKeys = []DataKey{
+	LatestProcessedBlockKey,
+	DeadLetterQueueKey,
+	MessageStatusKey,
+	SpendBudgetsKey,
+	RedeemedRewardsKey,
+	ExecutionRetriesKey,
+}
+
 func (k DataKey) String() string {
 	switch k {
 	case LatestProcessedBlockKey:
 		return "latestProcessedBlock"
+	case DeadLetterQueueKey:
+		return "deadLetterQueue"
+	case MessageStatusKey:
+		return "messageStatus"
+	case SpendBudgetsKey:
+		return "spendBudgets"
+	case RedeemedRewardsKey:
+		return "redeemedRewards"
+	case ExecutionRetriesKey:
+		return "executionRetries"
 	}
 	return "unknown"
 }
@@ -42,18 +69,99 @@ type RelayerDatabase interface {
 	Close() error
 }
 
+// Write is a value to store for a relayerID
+type Write struct {
+	RelayerID common.Hash
+	Key       DataKey
+	Value     []byte
+}
+
+// BatchWriter is implemented by databases that can write multiple values atomically
+type BatchWriter interface {
+	PutBatch(writes []Write) error
+}
+
+// PutBatch writes [writes] to [db]. The writes are atomic if [db] implements BatchWriter, and are otherwise
+// applied in order, stopping at the first failure.
+func PutBatch(db RelayerDatabase, writes []Write) error {
+	if batchWriter, ok := db.(BatchWriter); ok {
+		return batchWriter.PutBatch(writes)
+	}
+	for _, write := range writes {
+		if err := db.Put(write.RelayerID, write.Key, write.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewDatabase creates the database configured by [cfg]. In dry-run mode, the state is stored in the
+// dry-run namespace, so that it is isolated from the state of relayers that send transactions.
 func NewDatabase(logger logging.Logger, cfg *config.Config) (RelayerDatabase, error) {
-	if cfg.RedisURL != "" {
-		db, err := NewRedisDatabase(logger, cfg.RedisURL, GetConfigRelayerIDs(cfg))
+	return NewDatabaseWithStorage(logger, cfg, cfg.StorageLocation, cfg.StorageType, cfg.RedisURL)
+}
+
+// NewDatabaseWithStorage creates a database for the relayer IDs configured by [cfg], which is stored in Redis
+// at [redisURL] if set, and otherwise in [storageLocation] with the format given by [storageType].
+func NewDatabaseWithStorage(
+	logger logging.Logger,
+	cfg *config.Config,
+	storageLocation string,
+	storageType string,
+	redisURL string,
+) (RelayerDatabase, error) {
+	relayerIDs := append(GetConfigRelayerIDs(cfg), GetConfigDestinationStateIDs(cfg)...)
+	if cfg.DryRun {
+		relayerIDs = namespacedRelayerIDs(cfg.DryRunNamespace, relayerIDs)
+	}
+	var db RelayerDatabase
+	if redisURL != "" {
+		redisDB, err := NewRedisDatabase(logger, cfg, redisURL, relayerIDs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create redis database: %w", err)
 		}
-		return db, nil
+		db = redisDB
+	} else if storageType == config.LevelDBStorageType {
+		levelDB, err := NewLevelDB(logger, storageLocation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create leveldb database: %w", err)
+		}
+		db = levelDB
 	} else {
-		db, err := NewJSONFileStorage(logger, cfg.StorageLocation, GetConfigRelayerIDs(cfg))
+		jsonDB, err := NewJSONFileStorage(logger, storageLocation, relayerIDs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create json database: %w", err)
 		}
-		return db, nil
+		db = jsonDB
+	}
+	if cfg.DryRun {
+		return newNamespacedDatabase(db, cfg.DryRunNamespace), nil
+	}
+	return db, nil
+}
+
+// AddRelayerIDs configures the database to store state for additional relayer IDs.
+// Databases that are not configured per relayer ID are unaffected.
+func AddRelayerIDs(db RelayerDatabase, relayerIDs []RelayerID) error {
+	switch db := db.(type) {
+	case *JSONFileStorage:
+		return db.AddRelayerIDs(relayerIDs)
+	case *RedisDatabase:
+		db.AddRelayerIDs(relayerIDs)
+	case *namespacedDatabase:
+		return AddRelayerIDs(db.db, namespacedRelayerIDs(db.namespace, relayerIDs))
+	}
+	return nil
+}
+
+// RefreshKeyExpiry resets the expiry of the keys of the configured relayer IDs in databases whose keys expire.
+// Other databases are unaffected.
+func RefreshKeyExpiry(db RelayerDatabase) error {
+	switch db := db.(type) {
+	case *RedisDatabase:
+		return db.RefreshExpiry()
+	case *namespacedDatabase:
+		return RefreshKeyExpiry(db.db)
 	}
+	return nil
 }
@@ -0,0 +1,157 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/libevm/common"
+	"github.com/pkg/errors"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter describes a Warp message that could not be delivered by an application relayer
+// after exhausting its retries.
+type DeadLetter struct {
+	RelayerID               common.Hash    `json:"relayer-id"`
+	WarpMessageID           ids.ID         `json:"warp-message-id"`
+	SourceBlockchainID      ids.ID         `json:"source-blockchain-id"`
+	DestinationBlockchainID ids.ID         `json:"destination-blockchain-id"`
+	SourceAddress           common.Address `json:"source-address"` // the protocol contract that sent the Warp message
+	Height                  uint64         `json:"height"`
+	UnsignedMessageBytes    []byte         `json:"unsigned-message-bytes"`
+	LastError               string         `json:"last-error"`
+	Attempts                int            `json:"attempts"`
+	FirstFailedAt           time.Time      `json:"first-failed-at"`
+	LastFailedAt            time.Time      `json:"last-failed-at"`
+}
+
+// DeadLetterQueue stores dead letters in the RelayerDatabase, under the DeadLetterQueueKey of the
+// application relayer that failed to deliver them. Each relayerID's queue is stored as a single value
+// so that it is supported by every RelayerDatabase implementation, and holds at most maxSize dead letters so
+// that the value, which is rewritten on every change, stays bounded.
+type DeadLetterQueue struct {
+	db      RelayerDatabase
+	maxSize uint64                  // 0 if the queue is unbounded
+	metrics *DeadLetterQueueMetrics // nil if dropped dead letters are not counted
+	// Serializes read-modify-write cycles against the database
+	lock sync.Mutex
+}
+
+// NewDeadLetterQueue creates a dead-letter queue that keeps at most [maxSize] dead letters per relayer ID,
+// dropping the oldest ones when it is full. A [maxSize] of 0 does not limit the queue.
+func NewDeadLetterQueue(db RelayerDatabase, maxSize uint64, metrics *DeadLetterQueueMetrics) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		db:      db,
+		maxSize: maxSize,
+		metrics: metrics,
+	}
+}
+
+// Add inserts the dead letter into its relayer's queue. If the Warp message is already in the queue,
+// the existing entry is updated with the latest error and the attempts are accumulated. If the queue
+// is full, the dead letters that were added first are dropped.
+func (q *DeadLetterQueue) Add(deadLetter DeadLetter) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	deadLetters, err := q.get(deadLetter.RelayerID)
+	if err != nil {
+		return err
+	}
+	for i, existing := range deadLetters {
+		if existing.WarpMessageID != deadLetter.WarpMessageID {
+			continue
+		}
+		deadLetter.Attempts += existing.Attempts
+		deadLetter.FirstFailedAt = existing.FirstFailedAt
+		deadLetters[i] = deadLetter
+		return q.put(deadLetter.RelayerID, deadLetters)
+	}
+	deadLetters = append(deadLetters, deadLetter)
+	var dropped []DeadLetter
+	if q.maxSize != 0 && uint64(len(deadLetters)) > q.maxSize {
+		numDropped := uint64(len(deadLetters)) - q.maxSize
+		dropped, deadLetters = deadLetters[:numDropped], deadLetters[numDropped:]
+	}
+	if err := q.put(deadLetter.RelayerID, deadLetters); err != nil {
+		return err
+	}
+	for _, droppedDeadLetter := range dropped {
+		q.metrics.incDroppedDeadLetterCount(droppedDeadLetter)
+	}
+	return nil
+}
+
+// List returns the dead letters for the relayerID, in the order they were first added.
+func (q *DeadLetterQueue) List(relayerID common.Hash) ([]DeadLetter, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.get(relayerID)
+}
+
+// Get returns the dead letter for the Warp message, or ErrDeadLetterNotFound if it is not in the queue.
+func (q *DeadLetterQueue) Get(relayerID common.Hash, warpMessageID ids.ID) (DeadLetter, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	deadLetters, err := q.get(relayerID)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+	for _, deadLetter := range deadLetters {
+		if deadLetter.WarpMessageID == warpMessageID {
+			return deadLetter, nil
+		}
+	}
+	return DeadLetter{}, ErrDeadLetterNotFound
+}
+
+// Remove deletes the dead letter for the Warp message, or returns ErrDeadLetterNotFound if it is not in the queue.
+func (q *DeadLetterQueue) Remove(relayerID common.Hash, warpMessageID ids.ID) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	deadLetters, err := q.get(relayerID)
+	if err != nil {
+		return err
+	}
+	for i, deadLetter := range deadLetters {
+		if deadLetter.WarpMessageID == warpMessageID {
+			return q.put(relayerID, append(deadLetters[:i], deadLetters[i+1:]...))
+		}
+	}
+	return ErrDeadLetterNotFound
+}
+
+// Helper to read the queue from the database. The caller is responsible for holding the lock.
+func (q *DeadLetterQueue) get(relayerID common.Hash) ([]DeadLetter, error) {
+	deadLettersBytes, err := q.db.Get(relayerID, DeadLetterQueueKey)
+	if IsKeyNotFoundError(err) {
+		return []DeadLetter{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var deadLetters []DeadLetter
+	if err := json.Unmarshal(deadLettersBytes, &deadLetters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead letters: %w", err)
+	}
+	return deadLetters, nil
+}
+
+// Helper to write the queue to the database. The caller is responsible for holding the lock.
+func (q *DeadLetterQueue) put(relayerID common.Hash, deadLetters []DeadLetter) error {
+	deadLettersBytes, err := json.Marshal(deadLetters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letters: %w", err)
+	}
+	return q.db.Put(relayerID, DeadLetterQueueKey, deadLettersBytes)
+}
@@ -0,0 +1,38 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type DeadLetterQueueMetrics struct {
+	droppedDeadLetterCount *prometheus.CounterVec
+}
+
+func NewDeadLetterQueueMetrics(registerer prometheus.Registerer) *DeadLetterQueueMetrics {
+	m := DeadLetterQueueMetrics{
+		droppedDeadLetterCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "dropped_dead_letter_count",
+				Help: "Number of dead letters dropped because their relayer's dead-letter queue was full",
+			},
+			[]string{"relayer_id", "destination_blockchain_id", "source_blockchain_id"},
+		),
+	}
+	registerer.MustRegister(m.droppedDeadLetterCount)
+
+	return &m
+}
+
+func (m *DeadLetterQueueMetrics) incDroppedDeadLetterCount(deadLetter DeadLetter) {
+	if m == nil {
+		return
+	}
+	m.droppedDeadLetterCount.WithLabelValues(
+		deadLetter.RelayerID.String(),
+		deadLetter.DestinationBlockchainID.String(),
+		deadLetter.SourceBlockchainID.String(),
+	).Inc()
+}
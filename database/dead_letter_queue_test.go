@@ -0,0 +1,111 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterQueue(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID(), ids.GenerateTestID()})
+	queue := NewDeadLetterQueue(setupJsonStorage(t, relayerIDs), 0, nil)
+
+	// An empty queue is not an error
+	deadLetters, err := queue.List(relayerIDs[0].ID)
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+
+	firstFailedAt := time.Unix(100, 0).UTC()
+	lastFailedAt := time.Unix(200, 0).UTC()
+	deadLetter := DeadLetter{
+		RelayerID:            relayerIDs[0].ID,
+		WarpMessageID:        ids.GenerateTestID(),
+		SourceBlockchainID:   relayerIDs[0].SourceBlockchainID,
+		UnsignedMessageBytes: []byte{1, 2, 3},
+		LastError:            "first error",
+		Attempts:             5,
+		FirstFailedAt:        firstFailedAt,
+		LastFailedAt:         firstFailedAt,
+	}
+	require.NoError(t, queue.Add(deadLetter))
+
+	// Adding the same message again accumulates the attempts and keeps the first failure time
+	deadLetter.LastError = "second error"
+	deadLetter.FirstFailedAt = lastFailedAt
+	deadLetter.LastFailedAt = lastFailedAt
+	require.NoError(t, queue.Add(deadLetter))
+
+	other := DeadLetter{
+		RelayerID:     relayerIDs[0].ID,
+		WarpMessageID: ids.GenerateTestID(),
+		Attempts:      1,
+	}
+	require.NoError(t, queue.Add(other))
+
+	deadLetters, err = queue.List(relayerIDs[0].ID)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 2)
+	require.Equal(t, deadLetter.WarpMessageID, deadLetters[0].WarpMessageID)
+	require.Equal(t, "second error", deadLetters[0].LastError)
+	require.Equal(t, 10, deadLetters[0].Attempts)
+	require.True(t, firstFailedAt.Equal(deadLetters[0].FirstFailedAt))
+	require.True(t, lastFailedAt.Equal(deadLetters[0].LastFailedAt))
+	require.Equal(t, []byte{1, 2, 3}, deadLetters[0].UnsignedMessageBytes)
+
+	// Queues are kept per relayerID
+	deadLetters, err = queue.List(relayerIDs[1].ID)
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+
+	stored, err := queue.Get(relayerIDs[0].ID, other.WarpMessageID)
+	require.NoError(t, err)
+	require.Equal(t, other.WarpMessageID, stored.WarpMessageID)
+
+	require.NoError(t, queue.Remove(relayerIDs[0].ID, deadLetter.WarpMessageID))
+	_, err = queue.Get(relayerIDs[0].ID, deadLetter.WarpMessageID)
+	require.True(t, errors.Is(err, ErrDeadLetterNotFound))
+	err = queue.Remove(relayerIDs[0].ID, deadLetter.WarpMessageID)
+	require.True(t, errors.Is(err, ErrDeadLetterNotFound))
+
+	deadLetters, err = queue.List(relayerIDs[0].ID)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	require.Equal(t, other.WarpMessageID, deadLetters[0].WarpMessageID)
+}
+
+func TestDeadLetterQueueMaxSize(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID()})
+	metrics := NewDeadLetterQueueMetrics(prometheus.NewRegistry())
+	queue := NewDeadLetterQueue(setupJsonStorage(t, relayerIDs), 2, metrics)
+
+	warpMessageIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID(), ids.GenerateTestID()}
+	for _, warpMessageID := range warpMessageIDs {
+		require.NoError(t, queue.Add(DeadLetter{RelayerID: relayerIDs[0].ID, WarpMessageID: warpMessageID}))
+	}
+	// Updating a queued dead letter does not drop any others
+	require.NoError(t, queue.Add(DeadLetter{RelayerID: relayerIDs[0].ID, WarpMessageID: warpMessageIDs[2]}))
+
+	// The oldest dead letter is dropped
+	deadLetters, err := queue.List(relayerIDs[0].ID)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 2)
+	require.Equal(t, warpMessageIDs[1], deadLetters[0].WarpMessageID)
+	require.Equal(t, warpMessageIDs[2], deadLetters[1].WarpMessageID)
+	require.Equal(
+		t,
+		float64(1),
+		testutil.ToFloat64(metrics.droppedDeadLetterCount.WithLabelValues(
+			relayerIDs[0].ID.String(),
+			ids.Empty.String(),
+			ids.Empty.String(),
+		)),
+	)
+}
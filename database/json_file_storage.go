@@ -26,11 +26,12 @@ type JSONFileStorage struct {
 	dir string
 
 	// Each network has its own mutex
-	// The RelayerIDs used to index the JSONFileStorage are created at initialization
-	// and are not modified afterwards, so we don't need to lock the map itself.
+	// The RelayerIDs used to index the JSONFileStorage are created at initialization,
+	// and are only added to when the relayer configuration is reloaded. lock guards the maps themselves.
 	mutexes      map[common.Hash]*sync.RWMutex
 	logger       logging.Logger
 	currentState map[common.Hash]chainState
+	lock         sync.RWMutex
 }
 
 // NewJSONFileStorage creates a new JSONFileStorage instance
@@ -42,30 +43,22 @@ func NewJSONFileStorage(logger logging.Logger, dir string, relayerIDs []RelayerI
 		currentState: make(map[common.Hash]chainState),
 	}
 
-	for _, relayerID := range relayerIDs {
-		key := relayerID.ID
-		storage.currentState[key] = make(chainState)
-		storage.mutexes[key] = &sync.RWMutex{}
-	}
-
 	_, err := os.Stat(dir)
 	if err == nil {
 		// Directory already exists.
 		// Read the existing storage.
-		for _, relayerID := range relayerIDs {
-			key := relayerID.ID
-			currentState, fileExists, err := storage.getCurrentState(key)
-			if err != nil {
-				return nil, err
-			}
-			if fileExists {
-				storage.currentState[key] = currentState
-			}
+		if err := storage.AddRelayerIDs(relayerIDs); err != nil {
+			return nil, err
 		}
-
 		return storage, nil
 	}
 
+	for _, relayerID := range relayerIDs {
+		key := relayerID.ID
+		storage.currentState[key] = make(chainState)
+		storage.mutexes[key] = &sync.RWMutex{}
+	}
+
 	// 0755: The owner can read, write, execute.
 	// Everyone else can read and execute but not modify the file.
 	err = os.MkdirAll(dir, 0755)
@@ -79,8 +72,35 @@ func NewJSONFileStorage(logger logging.Logger, dir string, relayerIDs []RelayerI
 	return storage, nil
 }
 
+// AddRelayerIDs configures the storage for additional relayer IDs, reading their existing state from disk.
+// Relayer IDs that are already configured are left unchanged.
+func (s *JSONFileStorage) AddRelayerIDs(relayerIDs []RelayerID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, relayerID := range relayerIDs {
+		key := relayerID.ID
+		if _, ok := s.mutexes[key]; ok {
+			continue
+		}
+		currentState, fileExists, err := s.getCurrentState(key)
+		if err != nil {
+			return err
+		}
+		if !fileExists {
+			currentState = make(chainState)
+		}
+		s.currentState[key] = currentState
+		s.mutexes[key] = &sync.RWMutex{}
+	}
+	return nil
+}
+
 // Get the latest chain state from the JSON database, and retrieve the value from the key
 func (s *JSONFileStorage) Get(relayerID common.Hash, dataKey DataKey) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	mutex, ok := s.mutexes[relayerID]
 	if !ok {
 		return nil, errors.Wrap(
@@ -131,6 +151,9 @@ func (s *JSONFileStorage) Put(relayerID common.Hash, dataKey DataKey, value []by
 		zap.Stringer("key", dataKey),
 		zap.String("value", string(value)),
 	)
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	mutex, ok := s.mutexes[relayerID]
 	if !ok {
 		return errors.Wrap(
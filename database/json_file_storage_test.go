@@ -4,6 +4,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -123,6 +124,28 @@ func TestConcurrentWriteReadMultipleChains(t *testing.T) {
 	}
 }
 
+// Test that relayer IDs added after initialization can be written to, and that existing state is read from disk.
+func TestAddRelayerIDs(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID(), ids.GenerateTestID()})
+	storageDir := t.TempDir()
+	jsonStorage, err := NewJSONFileStorage(logging.NoLog{}, storageDir, relayerIDs[:1])
+	assert.NoError(t, err)
+
+	err = jsonStorage.Put(relayerIDs[1].ID, LatestProcessedBlockKey, []byte("1"))
+	assert.True(t, errors.Is(err, ErrDatabaseMisconfiguration))
+
+	assert.NoError(t, AddRelayerIDs(jsonStorage, relayerIDs))
+	testWrite(jsonStorage, relayerIDs[1], 5)
+
+	// A new instance configured with only the first relayer ID reads the second relayer ID's state when it is added
+	reloaded, err := NewJSONFileStorage(logging.NoLog{}, storageDir, relayerIDs[:1])
+	assert.NoError(t, err)
+	assert.NoError(t, reloaded.AddRelayerIDs(relayerIDs[1:]))
+	latestProcessedBlockData, err := reloaded.Get(relayerIDs[1].ID, LatestProcessedBlockKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "5", string(latestProcessedBlockData))
+}
+
 func setupJsonStorage(t *testing.T, relayerIDs []RelayerID) *JSONFileStorage {
 	storageDir := t.TempDir()
 
@@ -0,0 +1,195 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var ErrLeaderLeaseLost = errors.New("leader lease lost")
+
+// Sets the lease to the instance if it is unheld or already held by the instance.
+// KEYS[1] is the lease name, ARGV[1] the instance ID, and ARGV[2] the lease duration in milliseconds.
+var acquireLeaseScript = redis.NewScript(`
+local holder = redis.call("GET", KEYS[1])
+if holder == false or holder == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// Deletes the lease if it is held by the instance.
+// KEYS[1] is the lease name and ARGV[1] the instance ID.
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LeaderLease elects a single leader among relayer instances that share a Redis database.
+// The leader holds a lease that expires unless it is renewed, so that a standby instance takes over
+// if the leader stops renewing it.
+type LeaderLease struct {
+	logger     logging.Logger
+	client     redis.UniversalClient
+	name       string
+	instanceID string
+	duration   time.Duration
+}
+
+// NewLeaderLease creates the lease configured by [cfg] in the Redis deployment at redis-url. The lease is stored
+// under the configured key prefix.
+func NewLeaderLease(logger logging.Logger, cfg *config.Config) (*LeaderLease, error) {
+	client, err := newRedisClient(cfg, cfg.RedisURL)
+	if err != nil {
+		logger.Error(
+			"Failed to create Redis client",
+			zap.String("mode", cfg.RedisMode),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	name := redisKey(cfg.RedisKeyPrefix, cfg.GetLeaderLeaseName())
+	instanceID, err := newInstanceID()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to generate instance ID: %w", err)
+	}
+	return &LeaderLease{
+		logger:     logger.With(zap.String("leaseName", name), zap.String("instanceID", instanceID)),
+		client:     client,
+		name:       name,
+		instanceID: instanceID,
+		duration:   cfg.GetLeaderLeaseDuration(),
+	}, nil
+}
+
+// newInstanceID returns an ID that identifies this relayer instance as the lease holder
+func newInstanceID() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return hostname + "-" + hex.EncodeToString(suffix), nil
+}
+
+// renewInterval is the interval at which the lease is acquired or renewed.
+// Renewing well within the lease duration tolerates a failed renewal.
+func (l *LeaderLease) renewInterval() time.Duration {
+	return l.duration / 3
+}
+
+// tryAcquire acquires or renews the lease, returning whether the instance holds it
+func (l *LeaderLease) tryAcquire(ctx context.Context) (bool, error) {
+	acquired, err := acquireLeaseScript.Run(
+		ctx,
+		l.client,
+		[]string{l.name},
+		l.instanceID,
+		l.duration.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+// Acquire acquires the lease without waiting, returning false if it is held by another instance
+func (l *LeaderLease) Acquire(ctx context.Context) (bool, error) {
+	acquired, err := l.tryAcquire(ctx)
+	if acquired {
+		l.logger.Info("Acquired leader lease")
+	}
+	return acquired, err
+}
+
+// WaitForLeadership blocks until the instance acquires the lease, or [ctx] is cancelled
+func (l *LeaderLease) WaitForLeadership(ctx context.Context) error {
+	l.logger.Info("Waiting to acquire leader lease")
+	ticker := time.NewTicker(l.renewInterval())
+	defer ticker.Stop()
+	for {
+		acquired, err := l.tryAcquire(ctx)
+		if err != nil {
+			l.logger.Warn("Failed to acquire leader lease", zap.Error(err))
+		}
+		if acquired {
+			l.logger.Info("Acquired leader lease")
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Hold renews the lease until [ctx] is cancelled. Returns ErrLeaderLeaseLost if another instance acquired the lease, or if the lease can
+// not be renewed before it expires.
+func (l *LeaderLease) Hold(ctx context.Context) error {
+	ticker := time.NewTicker(l.renewInterval())
+	defer ticker.Stop()
+	expiry := time.Now().Add(l.duration)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		renewCtx, cancel := context.WithTimeout(ctx, l.renewInterval())
+		renewedAt := time.Now()
+		renewed, err := l.tryAcquire(renewCtx)
+		cancel()
+		switch {
+		case err == nil && !renewed:
+			l.logger.Error("Leader lease is held by another instance")
+			return ErrLeaderLeaseLost
+		case err == nil:
+			expiry = renewedAt.Add(l.duration)
+		case ctx.Err() != nil:
+			return nil
+		case time.Now().Add(l.renewInterval()).After(expiry):
+			// Stop leading before the lease expires, rather than risk overlapping with a standby instance
+			l.logger.Error("Failed to renew leader lease before it expires", zap.Error(err))
+			return ErrLeaderLeaseLost
+		default:
+			l.logger.Warn("Failed to renew leader lease", zap.Error(err))
+		}
+	}
+}
+
+// Release releases the lease if it is held by the instance, so that a standby instance can take over
+// without waiting for it to expire. Should only be called once the instance has stopped relaying.
+func (l *LeaderLease) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.renewInterval())
+	defer cancel()
+	if err := releaseLeaseScript.Run(ctx, l.client, []string{l.name}, l.instanceID).Err(); err != nil {
+		l.logger.Warn("Failed to release leader lease", zap.Error(err))
+		return
+	}
+	l.logger.Info("Released leader lease")
+}
+
+func (l *LeaderLease) Close() error {
+	return l.client.Close()
+}
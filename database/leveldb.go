@@ -0,0 +1,91 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/libevm/common"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"go.uber.org/zap"
+)
+
+// Name of the directory within the storage location that holds the LevelDB database
+const levelDBDirectory = "leveldb"
+
+var (
+	_ RelayerDatabase = &LevelDB{}
+	_ BatchWriter     = &LevelDB{}
+)
+
+// LevelDB implements RelayerDatabase with an embedded LevelDB database. Writes are synced to disk before they
+// return, so that written state survives a crash.
+type LevelDB struct {
+	logger logging.Logger
+	db     *leveldb.DB
+}
+
+// NewLevelDB opens the LevelDB database in [dir], creating it if it does not exist. A database that was corrupted
+// is recovered before it is opened. Unlike JSONFileStorage, the database is not configured per relayerID.
+func NewLevelDB(logger logging.Logger, dir string) (*LevelDB, error) {
+	path := filepath.Join(filepath.Clean(dir), levelDBDirectory)
+	db, err := leveldb.OpenFile(path, nil)
+	if leveldbErrors.IsCorrupted(err) {
+		logger.Warn("Recovering corrupted LevelDB database", zap.String("path", path), zap.Error(err))
+		db, err = leveldb.RecoverFile(path, nil)
+	}
+	if err != nil {
+		logger.Error(
+			"Failed to open LevelDB database",
+			zap.String("path", path),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return &LevelDB{
+		logger: logger,
+		db:     db,
+	}, nil
+}
+
+func (l *LevelDB) Get(relayerID common.Hash, key DataKey) ([]byte, error) {
+	value, err := l.db.Get([]byte(constructCompositeKey(relayerID, key)), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (l *LevelDB) Put(relayerID common.Hash, key DataKey, value []byte) error {
+	return l.PutBatch([]Write{{RelayerID: relayerID, Key: key, Value: value}})
+}
+
+// PutBatch writes all of [writes] atomically
+func (l *LevelDB) PutBatch(writes []Write) error {
+	batch := new(leveldb.Batch)
+	for _, write := range writes {
+		batch.Put([]byte(constructCompositeKey(write.RelayerID, write.Key)), write.Value)
+	}
+	if err := l.db.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		l.logger.Error(
+			"Failed to write to LevelDB",
+			zap.Int("numWrites", len(writes)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to write batch: %w", err)
+	}
+	return nil
+}
+
+func (l *LevelDB) Close() error {
+	return l.db.Close()
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelDBGetPut(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID(), ids.GenerateTestID()})
+	db, err := NewLevelDB(logging.NoLog{}, t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Get(relayerIDs[0].ID, LatestProcessedBlockKey)
+	require.True(t, IsKeyNotFoundError(err))
+
+	require.NoError(t, db.Put(relayerIDs[0].ID, LatestProcessedBlockKey, []byte("100")))
+	value, err := db.Get(relayerIDs[0].ID, LatestProcessedBlockKey)
+	require.NoError(t, err)
+	require.Equal(t, []byte("100"), value)
+
+	// Values are stored per relayer ID and key
+	_, err = db.Get(relayerIDs[1].ID, LatestProcessedBlockKey)
+	require.True(t, IsKeyNotFoundError(err))
+	_, err = db.Get(relayerIDs[0].ID, DeadLetterQueueKey)
+	require.True(t, IsKeyNotFoundError(err))
+}
+
+func TestLevelDBPutBatch(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID(), ids.GenerateTestID()})
+	dir := t.TempDir()
+	db, err := NewLevelDB(logging.NoLog{}, dir)
+	require.NoError(t, err)
+
+	writes := []Write{
+		{RelayerID: relayerIDs[0].ID, Key: LatestProcessedBlockKey, Value: []byte("100")},
+		{RelayerID: relayerIDs[0].ID, Key: DeadLetterQueueKey, Value: []byte("[]")},
+		{RelayerID: relayerIDs[1].ID, Key: LatestProcessedBlockKey, Value: []byte("200")},
+	}
+	require.NoError(t, PutBatch(db, writes))
+	require.NoError(t, db.Close())
+
+	// The writes persist after the database is reopened
+	db, err = NewLevelDB(logging.NoLog{}, dir)
+	require.NoError(t, err)
+	defer db.Close()
+	for _, write := range writes {
+		value, err := db.Get(write.RelayerID, write.Key)
+		require.NoError(t, err)
+		require.Equal(t, write.Value, value)
+	}
+}
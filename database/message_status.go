@@ -0,0 +1,293 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/common/hexutil"
+	"go.uber.org/zap"
+)
+
+// The maximum number of message statuses retained for each relayerID. Once reached, the statuses
+// of the least recently observed messages are evicted.
+const DefaultMaxMessageStatuses = 1000
+
+var ErrMessageStatusNotFound = errors.New("message status not found")
+
+// MessageState is a stage in the lifecycle of a message relayed by an application relayer
+type MessageState string
+
+const (
+	// The message was received by the application relayer
+	MessageStateSeen MessageState = "seen"
+	// The message handler determined that the message should not be sent
+	MessageStateSkipped MessageState = "skipped"
+	// The aggregate signature for the message was constructed
+	MessageStateSignaturesAggregated MessageState = "signatures-aggregated"
+	// The signed message was handed to the destination client to be issued
+	MessageStateTxSent MessageState = "tx-sent"
+	// A successful receipt was received for the delivery transaction
+	MessageStateDelivered MessageState = "delivered"
+	// The message could not be delivered after exhausting all retries
+	MessageStateFailed MessageState = "failed"
+	// The delivery transaction was recorded instead of being sent, as the relayer is in dry-run mode
+	MessageStateDryRun MessageState = "dry-run"
+)
+
+type MessageStateTransition struct {
+	State     MessageState `json:"state"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// MessageStatus describes the latest known delivery state of a Warp message for a single application relayer.
+type MessageStatus struct {
+	RelayerID               common.Hash              `json:"relayer-id"`
+	WarpMessageID           ids.ID                   `json:"warp-message-id"`
+	ProtocolMessageID       *ids.ID                  `json:"protocol-message-id,omitempty"` // e.g. the Teleporter message ID
+	SourceBlockchainID      ids.ID                   `json:"source-blockchain-id"`
+	DestinationBlockchainID ids.ID                   `json:"destination-blockchain-id"`
+	State                   MessageState             `json:"state"`
+	TransactionHash         string                   `json:"transaction-hash,omitempty"`
+	Error                   string                   `json:"error,omitempty"`
+	Reason                  string                   `json:"reason,omitempty"` // why the message was skipped
+	History                 []MessageStateTransition `json:"history"`
+	DryRun                  *DryRunTransaction       `json:"dry-run,omitempty"`
+}
+
+// DryRunTransaction describes the delivery transaction that a relayer in dry-run mode would have sent
+type DryRunTransaction struct {
+	SignerAddress common.Address `json:"signer-address"`
+	ToAddress     common.Address `json:"to-address"`
+	GasLimit      uint64         `json:"gas-limit"`
+	CallData      hexutil.Bytes  `json:"call-data"`
+	GasFeeCap     *big.Int       `json:"gas-fee-cap"`
+	// The maximum fee of the transaction, in wei
+	EstimatedFee *big.Int `json:"estimated-fee"`
+}
+
+// MessageStatusStore records the delivery state of messages, indexed by Warp message ID and protocol message ID.
+// The statuses of each relayerID are held in memory, and are stored as a single value under the MessageStatusKey.
+// State transitions only update the statuses in memory: the statuses of the relayerIDs modified since the last
+// write are written to the database each time the write signal passed to Run is signaled.
+type MessageStatusStore struct {
+	logger      logging.Logger
+	db          RelayerDatabase
+	maxStatuses int
+
+	lock sync.RWMutex
+	// The statuses of each loaded relayerID, ordered by when the message was first recorded
+	statuses map[common.Hash][]*MessageStatus
+	// Lookups by Warp message ID and protocol message ID. If the same message is processed by multiple
+	// relayerIDs, the most recently updated status is returned.
+	byWarpMessageID     map[ids.ID]*MessageStatus
+	byProtocolMessageID map[ids.ID]*MessageStatus
+	// The relayerIDs whose statuses were modified since they were last written to the database
+	dirty set.Set[common.Hash]
+
+	// Closed once the statuses have been written after the write signal is closed
+	done chan struct{}
+}
+
+// NewMessageStatusStore creates a MessageStatusStore, loading the statuses for [relayerIDs] from the database.
+func NewMessageStatusStore(
+	logger logging.Logger,
+	db RelayerDatabase,
+	relayerIDs []RelayerID,
+	maxStatuses int,
+) (*MessageStatusStore, error) {
+	s := &MessageStatusStore{
+		logger:              logger,
+		db:                  db,
+		maxStatuses:         maxStatuses,
+		statuses:            make(map[common.Hash][]*MessageStatus),
+		byWarpMessageID:     make(map[ids.ID]*MessageStatus),
+		byProtocolMessageID: make(map[ids.ID]*MessageStatus),
+		dirty:               set.NewSet[common.Hash](len(relayerIDs)),
+		done:                make(chan struct{}),
+	}
+	if err := s.AddRelayerIDs(relayerIDs); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// AddRelayerIDs loads the statuses for [relayerIDs] from the database, so that the statuses of routes added
+// after the store was created can be looked up. RelayerIDs that are already loaded are skipped.
+func (s *MessageStatusStore) AddRelayerIDs(relayerIDs []RelayerID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, relayerID := range relayerIDs {
+		if _, ok := s.statuses[relayerID.ID]; ok {
+			continue
+		}
+		statusesBytes, err := s.db.Get(relayerID.ID, MessageStatusKey)
+		if IsKeyNotFoundError(err) {
+			s.statuses[relayerID.ID] = nil
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read message statuses for relayer %s: %w", relayerID.ID.Hex(), err)
+		}
+		var statuses []*MessageStatus
+		if err := json.Unmarshal(statusesBytes, &statuses); err != nil {
+			return fmt.Errorf("failed to unmarshal message statuses for relayer %s: %w", relayerID.ID.Hex(), err)
+		}
+		s.statuses[relayerID.ID] = statuses
+		for _, status := range statuses {
+			s.index(status)
+		}
+	}
+	return nil
+}
+
+// Run writes the modified statuses to the database each time [writeSignal] is signaled. Once [writeSignal] is
+// closed, the remaining modified statuses are written and the channel returned by Done is closed.
+func (s *MessageStatusStore) Run(writeSignal chan struct{}) {
+	go func() {
+		defer close(s.done)
+		for range writeSignal {
+			s.writeToDatabase()
+		}
+		s.writeToDatabase()
+	}()
+}
+
+// Done returns a channel that is closed once the store has stopped writing to the database
+func (s *MessageStatusStore) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *MessageStatusStore) writeToDatabase() {
+	if err := s.Flush(); err != nil {
+		s.logger.Error("Failed to write message statuses", zap.Error(err))
+	}
+}
+
+// Flush writes the statuses of the relayerIDs that were modified since they were last written to the database.
+// RelayerIDs whose statuses fail to be written are retried by the next call.
+func (s *MessageStatusStore) Flush() error {
+	s.lock.Lock()
+	writes := make([]Write, 0, s.dirty.Len())
+	for relayerID := range s.dirty {
+		statusesBytes, err := json.Marshal(s.statuses[relayerID])
+		if err != nil {
+			s.lock.Unlock()
+			return fmt.Errorf("failed to marshal message statuses: %w", err)
+		}
+		writes = append(writes, Write{RelayerID: relayerID, Key: MessageStatusKey, Value: statusesBytes})
+	}
+	s.dirty.Clear()
+	s.lock.Unlock()
+
+	if len(writes) == 0 {
+		return nil
+	}
+	if err := PutBatch(s.db, writes); err != nil {
+		s.lock.Lock()
+		for _, write := range writes {
+			s.dirty.Add(write.RelayerID)
+		}
+		s.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// SetState transitions the message described by [status] to [status.State], creating its entry if necessary.
+// The transaction hash, error and dry-run transaction are only overwritten if they are set in [status].
+// The statuses of the message's relayerID are written to the database on the next write signal.
+func (s *MessageStatusStore) SetState(status MessageStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	transition := MessageStateTransition{
+		State:     status.State,
+		Timestamp: time.Now().UTC(),
+	}
+
+	statuses := s.statuses[status.RelayerID]
+	idx := slices.IndexFunc(statuses, func(existing *MessageStatus) bool {
+		return existing.WarpMessageID == status.WarpMessageID
+	})
+	if idx == -1 {
+		status.History = []MessageStateTransition{transition}
+		statuses = append(statuses, &status)
+		// Evict the oldest statuses once the limit is reached
+		for len(statuses) > s.maxStatuses {
+			s.unindex(statuses[0])
+			statuses = statuses[1:]
+		}
+		s.index(&status)
+	} else {
+		existing := statuses[idx]
+		existing.State = status.State
+		if status.ProtocolMessageID != nil {
+			existing.ProtocolMessageID = status.ProtocolMessageID
+		}
+		if status.TransactionHash != "" {
+			existing.TransactionHash = status.TransactionHash
+		}
+		if status.Error != "" {
+			existing.Error = status.Error
+		}
+		if status.Reason != "" {
+			existing.Reason = status.Reason
+		}
+		if status.DryRun != nil {
+			existing.DryRun = status.DryRun
+		}
+		existing.History = append(existing.History, transition)
+		s.index(existing)
+	}
+	s.statuses[status.RelayerID] = statuses
+	s.dirty.Add(status.RelayerID)
+}
+
+// Get returns the status of the message with the given Warp message ID or protocol message ID,
+// or ErrMessageStatusNotFound if the message has not been recorded.
+func (s *MessageStatusStore) Get(messageID ids.ID) (MessageStatus, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	status, ok := s.byWarpMessageID[messageID]
+	if !ok {
+		status, ok = s.byProtocolMessageID[messageID]
+	}
+	if !ok {
+		return MessageStatus{}, ErrMessageStatusNotFound
+	}
+	statusCopy := *status
+	statusCopy.History = slices.Clone(status.History)
+	return statusCopy, nil
+}
+
+// Helpers to maintain the lookup indices. The caller is responsible for holding the lock.
+
+func (s *MessageStatusStore) index(status *MessageStatus) {
+	s.byWarpMessageID[status.WarpMessageID] = status
+	if status.ProtocolMessageID != nil {
+		s.byProtocolMessageID[*status.ProtocolMessageID] = status
+	}
+}
+
+func (s *MessageStatusStore) unindex(status *MessageStatus) {
+	if s.byWarpMessageID[status.WarpMessageID] == status {
+		delete(s.byWarpMessageID, status.WarpMessageID)
+	}
+	if status.ProtocolMessageID != nil && s.byProtocolMessageID[*status.ProtocolMessageID] == status {
+		delete(s.byProtocolMessageID, *status.ProtocolMessageID)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/libevm/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageStatusStore(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID()})
+	relayerID := relayerIDs[0]
+	storageDir := t.TempDir()
+	db, err := NewJSONFileStorage(logging.NoLog{}, storageDir, relayerIDs)
+	require.NoError(t, err)
+
+	store, err := NewMessageStatusStore(logging.NoLog{}, db, relayerIDs, 2)
+	require.NoError(t, err)
+
+	warpMessageID := ids.GenerateTestID()
+	teleporterMessageID := ids.GenerateTestID()
+	_, err = store.Get(warpMessageID)
+	require.True(t, errors.Is(err, ErrMessageStatusNotFound))
+
+	status := MessageStatus{
+		RelayerID:               relayerID.ID,
+		WarpMessageID:           warpMessageID,
+		ProtocolMessageID:       &teleporterMessageID,
+		SourceBlockchainID:      relayerID.SourceBlockchainID,
+		DestinationBlockchainID: relayerID.DestinationBlockchainID,
+		State:                   MessageStateSeen,
+	}
+	store.SetState(status)
+	status.State = MessageStateFailed
+	status.Error = "failed to send warp message"
+	store.SetState(status)
+	status.State = MessageStateDelivered
+	status.Error = ""
+	status.TransactionHash = "0x1234"
+	store.SetState(status)
+
+	// The status can be looked up by either message ID
+	for _, messageID := range []ids.ID{warpMessageID, teleporterMessageID} {
+		stored, err := store.Get(messageID)
+		require.NoError(t, err)
+		require.Equal(t, warpMessageID, stored.WarpMessageID)
+		require.Equal(t, MessageStateDelivered, stored.State)
+		require.Equal(t, "0x1234", stored.TransactionHash)
+		require.Equal(t, "failed to send warp message", stored.Error)
+		require.Len(t, stored.History, 3)
+		require.Equal(t, MessageStateSeen, stored.History[0].State)
+		require.Equal(t, MessageStateFailed, stored.History[1].State)
+		require.Equal(t, MessageStateDelivered, stored.History[2].State)
+	}
+
+	// Statuses are written to the database when the store is flushed
+	reloaded, err := NewMessageStatusStore(logging.NoLog{}, db, relayerIDs, 2)
+	require.NoError(t, err)
+	_, err = reloaded.Get(teleporterMessageID)
+	require.ErrorIs(t, err, ErrMessageStatusNotFound)
+
+	// Statuses are reloaded from the database
+	require.NoError(t, store.Flush())
+	reloaded, err = NewMessageStatusStore(logging.NoLog{}, db, relayerIDs, 2)
+	require.NoError(t, err)
+	stored, err := reloaded.Get(teleporterMessageID)
+	require.NoError(t, err)
+	require.Equal(t, MessageStateDelivered, stored.State)
+	require.Len(t, stored.History, 3)
+
+	// The oldest statuses are evicted once the limit is reached
+	for i := 0; i < 2; i++ {
+		store.SetState(MessageStatus{
+			RelayerID:     relayerID.ID,
+			WarpMessageID: ids.GenerateTestID(),
+			State:         MessageStateSeen,
+		})
+	}
+	_, err = store.Get(warpMessageID)
+	require.True(t, errors.Is(err, ErrMessageStatusNotFound))
+	_, err = store.Get(teleporterMessageID)
+	require.True(t, errors.Is(err, ErrMessageStatusNotFound))
+
+	// The transaction recorded in dry-run mode is kept with the status
+	dryRunMessageID := ids.GenerateTestID()
+	dryRun := &DryRunTransaction{
+		SignerAddress: common.HexToAddress("0x27aE10273D17Cd7e80de8580A51f476960626e5f"),
+		ToAddress:     common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf"),
+		GasLimit:      100_000,
+		CallData:      []byte{1, 2, 3},
+		GasFeeCap:     big.NewInt(10),
+		EstimatedFee:  big.NewInt(1_000_000),
+	}
+	store.SetState(MessageStatus{
+		RelayerID:     relayerID.ID,
+		WarpMessageID: dryRunMessageID,
+		State:         MessageStateSeen,
+	})
+	store.SetState(MessageStatus{
+		RelayerID:       relayerID.ID,
+		WarpMessageID:   dryRunMessageID,
+		State:           MessageStateDryRun,
+		TransactionHash: "0x5678",
+		DryRun:          dryRun,
+	})
+	require.NoError(t, store.Flush())
+	reloaded, err = NewMessageStatusStore(logging.NoLog{}, db, relayerIDs, 2)
+	require.NoError(t, err)
+	stored, err = reloaded.Get(dryRunMessageID)
+	require.NoError(t, err)
+	require.Equal(t, MessageStateDryRun, stored.State)
+	require.Equal(t, dryRun, stored.DryRun)
+}
+
+func TestMessageStatusStoreRun(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID(), ids.GenerateTestID()})
+	db, err := NewJSONFileStorage(logging.NoLog{}, t.TempDir(), relayerIDs)
+	require.NoError(t, err)
+
+	store, err := NewMessageStatusStore(logging.NoLog{}, db, relayerIDs[:1], DefaultMaxMessageStatuses)
+	require.NoError(t, err)
+	writeSignal := make(chan struct{})
+	store.Run(writeSignal)
+
+	// The modified statuses are written on each write signal
+	firstMessageID := ids.GenerateTestID()
+	store.SetState(MessageStatus{RelayerID: relayerIDs[0].ID, WarpMessageID: firstMessageID, State: MessageStateSeen})
+	writeSignal <- struct{}{}
+	// The statuses of relayerIDs added after the store was created are written when the write signal is closed
+	require.NoError(t, store.AddRelayerIDs(relayerIDs[1:]))
+	secondMessageID := ids.GenerateTestID()
+	store.SetState(MessageStatus{RelayerID: relayerIDs[1].ID, WarpMessageID: secondMessageID, State: MessageStateSeen})
+	close(writeSignal)
+	<-store.Done()
+
+	reloaded, err := NewMessageStatusStore(logging.NoLog{}, db, relayerIDs[:1], DefaultMaxMessageStatuses)
+	require.NoError(t, err)
+	_, err = reloaded.Get(firstMessageID)
+	require.NoError(t, err)
+	_, err = reloaded.Get(secondMessageID)
+	require.ErrorIs(t, err, ErrMessageStatusNotFound)
+
+	// The statuses of added relayerIDs are loaded
+	require.NoError(t, reloaded.AddRelayerIDs(relayerIDs[1:]))
+	_, err = reloaded.Get(secondMessageID)
+	require.NoError(t, err)
+}
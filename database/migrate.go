@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Migrate copies the values of every DataKey stored for each of [relayerIDs] from [source] to [target], and
+// verifies that the target holds the copied values. Values that are not stored in the source are left unchanged
+// in the target. Returns the number of values copied. The values are written atomically if the target
+// implements BatchWriter.
+// Migrate fails without writing any values if the target holds a later checkpoint than the source for any of
+// the relayer IDs, since overwriting it would cause the relayer to process blocks again.
+func Migrate(source RelayerDatabase, target RelayerDatabase, relayerIDs []RelayerID) (int, error) {
+	values := make(map[RelayerID]map[DataKey][]byte, len(relayerIDs))
+	for _, relayerID := range relayerIDs {
+		relayerValues := make(map[DataKey][]byte, len(DataKeys))
+		for _, key := range DataKeys {
+			value, err := source.Get(relayerID.ID, key)
+			if IsKeyNotFoundError(err) {
+				continue
+			}
+			if err != nil {
+				return 0, fmt.Errorf("failed to get %s of relayer id %s from source: %w", key, relayerID.ID, err)
+			}
+			relayerValues[key] = value
+		}
+		values[relayerID] = relayerValues
+
+		if _, ok := relayerValues[LatestProcessedBlockKey]; !ok {
+			continue
+		}
+		sourceHeight, err := GetLatestProcessedBlockHeight(source, relayerID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get checkpoint of relayer id %s from source: %w", relayerID.ID, err)
+		}
+		targetHeight, err := GetLatestProcessedBlockHeight(target, relayerID)
+		if IsKeyNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get checkpoint of relayer id %s from target: %w", relayerID.ID, err)
+		}
+		if targetHeight > sourceHeight {
+			return 0, fmt.Errorf(
+				"%w: relayer id %s has height %d in the target and %d in the source",
+				ErrNewerCheckpoint,
+				relayerID.ID,
+				targetHeight,
+				sourceHeight,
+			)
+		}
+	}
+
+	var writes []Write
+	for _, relayerID := range relayerIDs {
+		for key, value := range values[relayerID] {
+			writes = append(writes, Write{RelayerID: relayerID.ID, Key: key, Value: value})
+		}
+	}
+	if err := PutBatch(target, writes); err != nil {
+		return 0, fmt.Errorf("failed to put values in target: %w", err)
+	}
+	copied := len(writes)
+
+	for _, relayerID := range relayerIDs {
+		for key, value := range values[relayerID] {
+			migrated, err := target.Get(relayerID.ID, key)
+			if err != nil {
+				return copied, fmt.Errorf("failed to get %s of relayer id %s from target: %w", key, relayerID.ID, err)
+			}
+			if !bytes.Equal(value, migrated) {
+				return copied, fmt.Errorf("%w: %s of relayer id %s", ErrMigrationVerification, key, relayerID.ID)
+			}
+		}
+	}
+	return copied, nil
+}
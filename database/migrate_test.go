@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID(), ids.GenerateTestID(), ids.GenerateTestID()})
+	testCases := []struct {
+		name          string
+		targetHeights map[int]uint64
+		expectedError error
+	}{
+		{
+			name: "empty target",
+		},
+		{
+			name:          "older checkpoint in target",
+			targetHeights: map[int]uint64{0: 50},
+		},
+		{
+			name:          "newer checkpoint in target",
+			targetHeights: map[int]uint64{1: 50, 0: 150},
+			expectedError: ErrNewerCheckpoint,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			source, err := NewJSONFileStorage(logging.NoLog{}, t.TempDir(), relayerIDs)
+			require.NoError(t, err)
+			target, err := NewJSONFileStorage(logging.NoLog{}, t.TempDir(), relayerIDs)
+			require.NoError(t, err)
+
+			// The first relayer ID has a checkpoint and a dead letter, the second only a checkpoint, and the
+			// third no state
+			require.NoError(t, SetLatestProcessedBlockHeight(source, relayerIDs[0], 100))
+			require.NoError(t, NewDeadLetterQueue(source, 0, nil).Add(DeadLetter{
+				RelayerID:     relayerIDs[0].ID,
+				WarpMessageID: ids.GenerateTestID(),
+			}))
+			require.NoError(t, SetLatestProcessedBlockHeight(source, relayerIDs[1], 200))
+			for i, height := range testCase.targetHeights {
+				require.NoError(t, SetLatestProcessedBlockHeight(target, relayerIDs[i], height))
+			}
+
+			copied, err := Migrate(source, target, relayerIDs)
+			if testCase.expectedError != nil {
+				require.ErrorIs(t, err, testCase.expectedError)
+				require.Zero(t, copied)
+				// No values are written if the migration is refused
+				for i, height := range testCase.targetHeights {
+					targetHeight, err := GetLatestProcessedBlockHeight(target, relayerIDs[i])
+					require.NoError(t, err)
+					require.Equal(t, height, targetHeight)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 3, copied)
+
+			height, err := GetLatestProcessedBlockHeight(target, relayerIDs[0])
+			require.NoError(t, err)
+			require.Equal(t, uint64(100), height)
+			height, err = GetLatestProcessedBlockHeight(target, relayerIDs[1])
+			require.NoError(t, err)
+			require.Equal(t, uint64(200), height)
+			deadLetters, err := NewDeadLetterQueue(target, 0, nil).List(relayerIDs[0].ID)
+			require.NoError(t, err)
+			require.Len(t, deadLetters, 1)
+			_, err = GetLatestProcessedBlockHeight(target, relayerIDs[2])
+			require.True(t, IsKeyNotFoundError(err))
+		})
+	}
+}
@@ -83,3 +83,41 @@ func (mr *MockRelayerDatabaseMockRecorder) Put(relayerID, key, value any) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockRelayerDatabase)(nil).Put), relayerID, key, value)
 }
+
+// MockBatchWriter is a mock of BatchWriter interface.
+type MockBatchWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockBatchWriterMockRecorder is the mock recorder for MockBatchWriter.
+type MockBatchWriterMockRecorder struct {
+	mock *MockBatchWriter
+}
+
+// NewMockBatchWriter creates a new mock instance.
+func NewMockBatchWriter(ctrl *gomock.Controller) *MockBatchWriter {
+	mock := &MockBatchWriter{ctrl: ctrl}
+	mock.recorder = &MockBatchWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchWriter) EXPECT() *MockBatchWriterMockRecorder {
+	return m.recorder
+}
+
+// PutBatch mocks base method.
+func (m *MockBatchWriter) PutBatch(writes []database.Write) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutBatch", writes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBatch indicates an expected call of PutBatch.
+func (mr *MockBatchWriterMockRecorder) PutBatch(writes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBatch", reflect.TypeOf((*MockBatchWriter)(nil).PutBatch), writes)
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/crypto"
+)
+
+// namespacedDatabase isolates the state of relayers sharing a database by storing the state of each relayerID
+// under an ID derived from the relayerID and the namespace.
+type namespacedDatabase struct {
+	db        RelayerDatabase
+	namespace string
+}
+
+// newNamespacedDatabase wraps [db], which must already be configured for the namespaced relayer IDs.
+func newNamespacedDatabase(db RelayerDatabase, namespace string) *namespacedDatabase {
+	return &namespacedDatabase{
+		db:        db,
+		namespace: namespace,
+	}
+}
+
+func (d *namespacedDatabase) Get(relayerID common.Hash, key DataKey) ([]byte, error) {
+	return d.db.Get(namespacedID(d.namespace, relayerID), key)
+}
+
+func (d *namespacedDatabase) Put(relayerID common.Hash, key DataKey, value []byte) error {
+	return d.db.Put(namespacedID(d.namespace, relayerID), key, value)
+}
+
+func (d *namespacedDatabase) PutBatch(writes []Write) error {
+	namespaced := make([]Write, len(writes))
+	for i, write := range writes {
+		namespaced[i] = write
+		namespaced[i].RelayerID = namespacedID(d.namespace, write.RelayerID)
+	}
+	return PutBatch(d.db, namespaced)
+}
+
+func (d *namespacedDatabase) Close() error {
+	return d.db.Close()
+}
+
+func namespacedID(namespace string, relayerID common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte(namespace), relayerID.Bytes())
+}
+
+// namespacedRelayerIDs returns copies of [relayerIDs] whose IDs are derived from the namespace
+func namespacedRelayerIDs(namespace string, relayerIDs []RelayerID) []RelayerID {
+	namespaced := make([]RelayerID, len(relayerIDs))
+	for i, relayerID := range relayerIDs {
+		namespaced[i] = relayerID
+		namespaced[i].ID = namespacedID(namespace, relayerID.ID)
+	}
+	return namespaced
+}
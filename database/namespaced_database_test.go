@@ -0,0 +1,43 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespacedDatabase(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID(), ids.GenerateTestID()})
+	storageDir := t.TempDir()
+	jsonStorage, err := NewJSONFileStorage(
+		logging.NoLog{},
+		storageDir,
+		append(relayerIDs[:1:1], namespacedRelayerIDs("dry-run", relayerIDs[:1])...),
+	)
+	require.NoError(t, err)
+	db := newNamespacedDatabase(jsonStorage, "dry-run")
+
+	// State written in the namespace is isolated from the state of the same relayer ID outside of it
+	require.NoError(t, jsonStorage.Put(relayerIDs[0].ID, LatestProcessedBlockKey, []byte("1")))
+	_, err = db.Get(relayerIDs[0].ID, LatestProcessedBlockKey)
+	require.True(t, IsKeyNotFoundError(err))
+	require.NoError(t, db.Put(relayerIDs[0].ID, LatestProcessedBlockKey, []byte("2")))
+	value, err := db.Get(relayerIDs[0].ID, LatestProcessedBlockKey)
+	require.NoError(t, err)
+	require.Equal(t, "2", string(value))
+	value, err = jsonStorage.Get(relayerIDs[0].ID, LatestProcessedBlockKey)
+	require.NoError(t, err)
+	require.Equal(t, "1", string(value))
+
+	// Relayer IDs added to the namespaced database are added in the namespace
+	require.ErrorIs(t, db.Put(relayerIDs[1].ID, LatestProcessedBlockKey, []byte("3")), ErrDatabaseMisconfiguration)
+	require.NoError(t, AddRelayerIDs(db, relayerIDs[1:]))
+	require.NoError(t, db.Put(relayerIDs[1].ID, LatestProcessedBlockKey, []byte("3")))
+	err = jsonStorage.Put(relayerIDs[1].ID, LatestProcessedBlockKey, []byte("4"))
+	require.ErrorIs(t, err, ErrDatabaseMisconfiguration)
+}
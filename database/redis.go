@@ -5,10 +5,18 @@ package database
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/relayer/config"
 	"github.com/ryt-io/libevm/common"
+	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
@@ -16,34 +24,91 @@ import (
 var _ RelayerDatabase = &RedisDatabase{}
 
 type RedisDatabase struct {
-	logger logging.Logger
-	client *redis.Client
+	logger    logging.Logger
+	client    redis.UniversalClient
+	keyPrefix string
+	keyExpiry time.Duration
+
+	// The relayer IDs whose keys have their expiry refreshed by RefreshExpiry. lock guards the map.
+	relayerIDs map[common.Hash]struct{}
+	lock       sync.Mutex
 }
 
-func NewRedisDatabase(logger logging.Logger, redisURL string, relayerIDs []RelayerID) (*RedisDatabase, error) {
-	opts, err := redis.ParseURL(redisURL)
+// NewRedisDatabase connects to the Redis deployment at [redisURL], with the topology, TLS client certificate, key
+// prefix and key expiry configured by [cfg].
+func NewRedisDatabase(
+	logger logging.Logger,
+	cfg *config.Config,
+	redisURL string,
+	relayerIDs []RelayerID,
+) (*RedisDatabase, error) {
+	client, err := newRedisClient(cfg, redisURL)
 	if err != nil {
 		logger.Error(
-			"Failed to parse Redis URL",
-			zap.String("url", redisURL),
+			"Failed to create Redis client",
+			zap.String("mode", cfg.RedisMode),
 			zap.Error(err),
 		)
 		return nil, err
 	}
+	r := &RedisDatabase{
+		logger:     logger,
+		client:     client,
+		keyPrefix:  cfg.RedisKeyPrefix,
+		keyExpiry:  cfg.GetRedisKeyExpiry(),
+		relayerIDs: make(map[common.Hash]struct{}),
+	}
+	r.AddRelayerIDs(relayerIDs)
+	return r, nil
+}
+
+// AddRelayerIDs adds relayer IDs whose keys have their expiry refreshed by RefreshExpiry
+func (r *RedisDatabase) AddRelayerIDs(relayerIDs []RelayerID) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, relayerID := range relayerIDs {
+		r.relayerIDs[relayerID.ID] = struct{}{}
+	}
+}
+
+// expiringKeys returns the keys of the relayer IDs whose expiry is refreshed
+func (r *RedisDatabase) expiringKeys() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	keys := make([]string, 0, len(r.relayerIDs)*len(DataKeys))
+	for relayerID := range r.relayerIDs {
+		for _, key := range DataKeys {
+			keys = append(keys, redisKey(r.keyPrefix, constructCompositeKey(relayerID, key)))
+		}
+	}
+	return keys
+}
 
-	// Create a new Redis client.
-	// The server address, password, db index, and protocol version are extracted from the URL
-	// If not provided in the URL, request timeouts use the default value of 3 seconds
-	client := redis.NewClient(opts)
-	return &RedisDatabase{
-		logger: logger,
-		client: client,
-	}, nil
+// RefreshExpiry resets the expiry of all of the keys of the relayer IDs the database is configured with, whether
+// or not they were written since the last refresh. Keys that are rarely written, such as those of the dead-letter
+// queue, therefore expire together with the other keys of their relayer ID, once it is no longer configured.
+// Does nothing if keys do not expire.
+func (r *RedisDatabase) RefreshExpiry() error {
+	if r.keyExpiry == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	pipe := r.client.Pipeline()
+	for _, key := range r.expiringKeys() {
+		// Keys that do not exist are left unset
+		pipe.Expire(ctx, key, r.keyExpiry)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to refresh key expiry: %w", err)
+	}
+	return nil
 }
 
 func (r *RedisDatabase) Get(relayerID common.Hash, key DataKey) ([]byte, error) {
 	ctx := context.Background()
-	compositeKey := constructCompositeKey(relayerID, key)
+	compositeKey := redisKey(r.keyPrefix, constructCompositeKey(relayerID, key))
 	val, err := r.client.Get(ctx, compositeKey).Result()
 	if err != nil {
 		r.logger.Debug("Error retrieving key from Redis",
@@ -60,10 +125,11 @@ func (r *RedisDatabase) Get(relayerID common.Hash, key DataKey) ([]byte, error)
 
 func (r *RedisDatabase) Put(relayerID common.Hash, key DataKey, value []byte) error {
 	ctx := context.Background()
-	compositeKey := constructCompositeKey(relayerID, key)
+	compositeKey := redisKey(r.keyPrefix, constructCompositeKey(relayerID, key))
 
-	// Persistently store the value in Redis
-	err := r.client.Set(ctx, compositeKey, value, 0).Err()
+	// Store the value in Redis. If a key expiry is configured, each write resets it, and RefreshExpiry resets it
+	// periodically for the keys of the configured relayer IDs.
+	err := r.client.Set(ctx, compositeKey, value, r.keyExpiry).Err()
 	if err != nil {
 		r.logger.Error("Error storing key in Redis",
 			zap.String("key", compositeKey),
@@ -82,3 +148,81 @@ func constructCompositeKey(relayerID common.Hash, key DataKey) string {
 	const keyDelimiter = "-"
 	return strings.Join([]string{relayerID.Hex(), key.String()}, keyDelimiter)
 }
+
+// redisKey prefixes [key] with [prefix] if it is set, so that relayer deployments sharing a Redis database
+// do not overwrite each other's keys
+func redisKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + ":" + key
+}
+
+// newRedisClient creates a client for the Redis deployment at [redisURL] with the topology configured by [cfg].
+// The server addresses, password, db index, and protocol version are extracted from the URL. Sentinel and cluster
+// URLs list additional addresses with the addr query parameter, and sentinel URLs set the master_name parameter.
+// If not provided in the URL, request timeouts use the default value of 3 seconds.
+func newRedisClient(cfg *config.Config, redisURL string) (redis.UniversalClient, error) {
+	switch cfg.RedisMode {
+	case config.RedisSentinelMode:
+		opts, err := redis.ParseFailoverURL(redisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+		}
+		if opts.MasterName == "" {
+			return nil, errors.New("redis sentinel URL must set master_name")
+		}
+		if err := configureRedisTLS(opts.TLSConfig, cfg); err != nil {
+			return nil, err
+		}
+		return redis.NewFailoverClient(opts), nil
+	case config.RedisClusterMode:
+		opts, err := redis.ParseClusterURL(redisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+		}
+		if err := configureRedisTLS(opts.TLSConfig, cfg); err != nil {
+			return nil, err
+		}
+		return redis.NewClusterClient(opts), nil
+	default:
+		opts, err := redis.ParseURL(redisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+		}
+		if err := configureRedisTLS(opts.TLSConfig, cfg); err != nil {
+			return nil, err
+		}
+		return redis.NewClient(opts), nil
+	}
+}
+
+// configureRedisTLS adds the client certificate and CA certificates configured by [cfg] to [tlsConfig], which
+// is nil unless the Redis URL uses the rediss scheme.
+func configureRedisTLS(tlsConfig *tls.Config, cfg *config.Config) error {
+	if cfg.RedisTLSCertPath == "" && cfg.RedisTLSCACertPath == "" {
+		return nil
+	}
+	if tlsConfig == nil {
+		return errors.New("redis TLS certificates require a rediss:// URL")
+	}
+	if cfg.RedisTLSCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.RedisTLSCertPath, cfg.RedisTLSKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load redis client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.RedisTLSCACertPath != "" {
+		caCerts, err := os.ReadFile(cfg.RedisTLSCACertPath)
+		if err != nil {
+			return fmt.Errorf("failed to read redis CA certificates: %w", err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caCerts) {
+			return errors.New("no certificates found in redis-tls-ca-cert-path")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	return nil
+}
@@ -77,6 +77,22 @@ func GetConfigRelayerIDs(cfg *config.Config) []RelayerID {
 	return keys
 }
 
+// NewDestinationStateID returns the ID under which the state of a destination blockchain's client, such as its
+// spend budgets, is stored. It is distinct from the relayer ID of every route, since source blockchain IDs are
+// never empty.
+func NewDestinationStateID(destinationBlockchainID ids.ID) RelayerID {
+	return NewRelayerID(ids.Empty, destinationBlockchainID, AllAllowedAddress, AllAllowedAddress)
+}
+
+// Gets the destination state IDs of all of the destination blockchains in a given configuration.
+func GetConfigDestinationStateIDs(cfg *config.Config) []RelayerID {
+	keys := make([]RelayerID, 0, len(cfg.DestinationBlockchains))
+	for _, d := range cfg.DestinationBlockchains {
+		keys = append(keys, NewDestinationStateID(d.GetBlockchainID()))
+	}
+	return keys
+}
+
 // Calculates all of the possible relayer keys for a given source blockchain.
 func GetSourceBlockchainRelayerIDs(sourceBlockchain *config.SourceBlockchain) []RelayerID {
 	var ids []RelayerID
@@ -0,0 +1,148 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/libevm/common"
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownRelayerID = errors.New("relayer id is not configured")
+
+// RelayerState is the state stored in the RelayerDatabase for a relayerID, along with the route that the
+// relayerID is calculated from. Used to inspect, export and import the state of the relayer.
+type RelayerState struct {
+	RelayerID               common.Hash    `json:"relayer-id"`
+	SourceBlockchainID      ids.ID         `json:"source-blockchain-id"`
+	DestinationBlockchainID ids.ID         `json:"destination-blockchain-id"`
+	OriginSenderAddress     common.Address `json:"origin-sender-address"`
+	DestinationAddress      common.Address `json:"destination-address"`
+	// nil if the relayerID has no checkpoint
+	LatestProcessedBlock *uint64         `json:"latest-processed-block,omitempty"`
+	DeadLetters          []DeadLetter    `json:"dead-letters,omitempty"`
+	MessageStatuses      []MessageStatus `json:"message-statuses,omitempty"`
+}
+
+// GetRelayerState reads the state of [relayerID] from the database. Keys that are not stored are left empty.
+func GetRelayerState(db RelayerDatabase, relayerID RelayerID) (RelayerState, error) {
+	state := RelayerState{
+		RelayerID:               relayerID.ID,
+		SourceBlockchainID:      relayerID.SourceBlockchainID,
+		DestinationBlockchainID: relayerID.DestinationBlockchainID,
+		OriginSenderAddress:     relayerID.OriginSenderAddress,
+		DestinationAddress:      relayerID.DestinationAddress,
+	}
+
+	latestProcessedBlock, err := GetLatestProcessedBlockHeight(db, relayerID)
+	if err == nil {
+		state.LatestProcessedBlock = &latestProcessedBlock
+	} else if !IsKeyNotFoundError(err) {
+		return RelayerState{}, fmt.Errorf("failed to get %s: %w", LatestProcessedBlockKey, err)
+	}
+	if err := getJSON(db, relayerID.ID, DeadLetterQueueKey, &state.DeadLetters); err != nil {
+		return RelayerState{}, err
+	}
+	if err := getJSON(db, relayerID.ID, MessageStatusKey, &state.MessageStatuses); err != nil {
+		return RelayerState{}, err
+	}
+	return state, nil
+}
+
+// ExportState reads the state of each of [relayerIDs] from the database
+func ExportState(db RelayerDatabase, relayerIDs []RelayerID) ([]RelayerState, error) {
+	states := make([]RelayerState, 0, len(relayerIDs))
+	for _, relayerID := range relayerIDs {
+		state, err := GetRelayerState(db, relayerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export state of relayer id %s: %w", relayerID.ID, err)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// ImportState writes [states] to the database, overwriting the stored values of the keys that are set in each
+// state. Each state must belong to one of [relayerIDs], and match its route. The states are all validated before
+// any of them are written, and they are written atomically if the database implements BatchWriter.
+func ImportState(db RelayerDatabase, relayerIDs []RelayerID, states []RelayerState) error {
+	configured := make(map[common.Hash]RelayerID, len(relayerIDs))
+	for _, relayerID := range relayerIDs {
+		configured[relayerID.ID] = relayerID
+	}
+	for _, state := range states {
+		relayerID, ok := configured[state.RelayerID]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownRelayerID, state.RelayerID)
+		}
+		if relayerID.SourceBlockchainID != state.SourceBlockchainID ||
+			relayerID.DestinationBlockchainID != state.DestinationBlockchainID ||
+			relayerID.OriginSenderAddress != state.OriginSenderAddress ||
+			relayerID.DestinationAddress != state.DestinationAddress {
+			return fmt.Errorf("route of relayer id %s does not match the configured route", state.RelayerID)
+		}
+	}
+
+	var writes []Write
+	for _, state := range states {
+		if state.LatestProcessedBlock != nil {
+			writes = append(writes, Write{
+				RelayerID: state.RelayerID,
+				Key:       LatestProcessedBlockKey,
+				Value:     []byte(strconv.FormatUint(*state.LatestProcessedBlock, 10)),
+			})
+		}
+		if state.DeadLetters != nil {
+			write, err := jsonWrite(state.RelayerID, DeadLetterQueueKey, state.DeadLetters)
+			if err != nil {
+				return err
+			}
+			writes = append(writes, write)
+		}
+		if state.MessageStatuses != nil {
+			write, err := jsonWrite(state.RelayerID, MessageStatusKey, state.MessageStatuses)
+			if err != nil {
+				return err
+			}
+			writes = append(writes, write)
+		}
+	}
+	if err := PutBatch(db, writes); err != nil {
+		return fmt.Errorf("failed to import state: %w", err)
+	}
+	return nil
+}
+
+// Helper function to set the latest processed block height in the database.
+func SetLatestProcessedBlockHeight(db RelayerDatabase, relayerID RelayerID, height uint64) error {
+	return db.Put(relayerID.ID, LatestProcessedBlockKey, []byte(strconv.FormatUint(height, 10)))
+}
+
+// Helper to read a JSON encoded value. [v] is left unchanged if the key is not stored.
+func getJSON(db RelayerDatabase, relayerID common.Hash, key DataKey, v interface{}) error {
+	value, err := db.Get(relayerID, key)
+	if IsKeyNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	if err := json.Unmarshal(value, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+	return nil
+}
+
+// Helper to create a write of a JSON encoded value
+func jsonWrite(relayerID common.Hash, key DataKey, v interface{}) (Write, error) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return Write{}, fmt.Errorf("failed to marshal %s of relayer id %s: %w", key, relayerID, err)
+	}
+	return Write{RelayerID: relayerID, Key: key, Value: value}, nil
+}
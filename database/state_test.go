@@ -0,0 +1,110 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/libevm/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportState(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID(), ids.GenerateTestID()})
+	db, err := NewJSONFileStorage(logging.NoLog{}, t.TempDir(), relayerIDs)
+	require.NoError(t, err)
+
+	require.NoError(t, SetLatestProcessedBlockHeight(db, relayerIDs[0], 100))
+	deadLetter := DeadLetter{
+		RelayerID:     relayerIDs[0].ID,
+		WarpMessageID: ids.GenerateTestID(),
+		Height:        99,
+		Attempts:      1,
+	}
+	require.NoError(t, NewDeadLetterQueue(db, 0, nil).Add(deadLetter))
+
+	states, err := ExportState(db, relayerIDs)
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	require.Equal(t, relayerIDs[0].ID, states[0].RelayerID)
+	require.Equal(t, relayerIDs[0].SourceBlockchainID, states[0].SourceBlockchainID)
+	require.Equal(t, uint64(100), *states[0].LatestProcessedBlock)
+	require.Len(t, states[0].DeadLetters, 1)
+	require.Equal(t, deadLetter.WarpMessageID, states[0].DeadLetters[0].WarpMessageID)
+	// A relayer ID without a checkpoint is exported without one
+	require.Nil(t, states[1].LatestProcessedBlock)
+	require.Empty(t, states[1].DeadLetters)
+
+	// The exported state round trips through JSON, and is imported into another database
+	stateBytes, err := json.Marshal(states)
+	require.NoError(t, err)
+	var importedStates []RelayerState
+	require.NoError(t, json.Unmarshal(stateBytes, &importedStates))
+	importDB, err := NewJSONFileStorage(logging.NoLog{}, t.TempDir(), relayerIDs)
+	require.NoError(t, err)
+	require.NoError(t, ImportState(importDB, relayerIDs, importedStates))
+
+	reexportedStates, err := ExportState(importDB, relayerIDs)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), *reexportedStates[0].LatestProcessedBlock)
+	deadLetters, err := NewDeadLetterQueue(importDB, 0, nil).List(relayerIDs[0].ID)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	_, err = GetLatestProcessedBlockHeight(importDB, relayerIDs[1])
+	require.True(t, IsKeyNotFoundError(err))
+}
+
+func TestImportStateValidation(t *testing.T) {
+	relayerIDs := createRelayerIDs([]ids.ID{ids.GenerateTestID()})
+	height := uint64(10)
+	testCases := []struct {
+		name          string
+		state         RelayerState
+		expectedError error
+	}{
+		{
+			name: "unknown relayer id",
+			state: RelayerState{
+				RelayerID:            common.HexToHash("0x1234"),
+				LatestProcessedBlock: &height,
+			},
+			expectedError: ErrUnknownRelayerID,
+		},
+		{
+			name: "mismatched route",
+			state: RelayerState{
+				RelayerID:               relayerIDs[0].ID,
+				SourceBlockchainID:      relayerIDs[0].SourceBlockchainID,
+				DestinationBlockchainID: ids.GenerateTestID(),
+				LatestProcessedBlock:    &height,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			db, err := NewJSONFileStorage(logging.NoLog{}, t.TempDir(), relayerIDs)
+			require.NoError(t, err)
+
+			// A valid state is not written if any of the states are invalid
+			validState := RelayerState{
+				RelayerID:               relayerIDs[0].ID,
+				SourceBlockchainID:      relayerIDs[0].SourceBlockchainID,
+				DestinationBlockchainID: relayerIDs[0].DestinationBlockchainID,
+				OriginSenderAddress:     relayerIDs[0].OriginSenderAddress,
+				DestinationAddress:      relayerIDs[0].DestinationAddress,
+				LatestProcessedBlock:    &height,
+			}
+			err = ImportState(db, relayerIDs, []RelayerState{validState, testCase.state})
+			require.Error(t, err)
+			if testCase.expectedError != nil {
+				require.ErrorIs(t, err, testCase.expectedError)
+			}
+			_, err = GetLatestProcessedBlockHeight(db, relayerIDs[0])
+			require.True(t, IsKeyNotFoundError(err))
+		})
+	}
+}
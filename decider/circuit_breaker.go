@@ -0,0 +1,100 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var errCircuitOpen = errors.New("decider circuit breaker is open")
+
+// circuitBreaker stops queries to the decider after consecutive failures. Once the cooldown has passed, a single
+// query is allowed through: the breaker closes if it succeeds, and opens again for another cooldown if it fails.
+type circuitBreaker struct {
+	maxFailures uint64 // zero disables the breaker
+	cooldown    time.Duration
+	now         func() time.Time
+
+	lock     sync.Mutex
+	failures uint64
+	openedAt time.Time // zero while the breaker is closed
+	probing  bool
+}
+
+func newCircuitBreaker(maxFailures uint64, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		now:         time.Now,
+	}
+}
+
+// allow returns true if the decider should be queried
+func (b *circuitBreaker) allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *circuitBreaker) recordSuccess() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.probing = false
+}
+
+// recordFailure returns true if the failure opened the breaker
+func (b *circuitBreaker) recordFailure() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.failures++
+	if b.probing {
+		b.probing = false
+		b.openedAt = b.now()
+		return true
+	}
+	if b.maxFailures == 0 || !b.openedAt.IsZero() || b.failures < b.maxFailures {
+		return false
+	}
+	b.openedAt = b.now()
+	return true
+}
+
+// reopensAt returns when the next query is allowed through, if the breaker is open and its cooldown has not
+// passed
+func (b *circuitBreaker) reopensAt() (time.Time, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.openedAt.IsZero() {
+		return time.Time{}, false
+	}
+	reopensAt := b.openedAt.Add(b.cooldown)
+	return reopensAt, reopensAt.After(b.now())
+}
+
+// health returns an error while the breaker is open
+func (b *circuitBreaker) health() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	return fmt.Errorf("%w after %d consecutive failures", errCircuitOpen, b.failures)
+}
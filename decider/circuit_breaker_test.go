@@ -0,0 +1,63 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	// The breaker opens after the configured number of consecutive failures
+	require.True(t, breaker.allow())
+	require.False(t, breaker.recordFailure())
+	require.NoError(t, breaker.health())
+	require.True(t, breaker.allow())
+	require.True(t, breaker.recordFailure())
+	require.ErrorIs(t, breaker.health(), errCircuitOpen)
+	require.False(t, breaker.allow())
+	reopensAt, ok := breaker.reopensAt()
+	require.True(t, ok)
+	require.Equal(t, now.Add(time.Minute), reopensAt)
+
+	// A single probe is allowed once the cooldown has passed, and a failed probe opens the breaker again
+	now = now.Add(time.Minute)
+	require.True(t, breaker.allow())
+	require.False(t, breaker.allow())
+	require.True(t, breaker.recordFailure())
+	require.False(t, breaker.allow())
+
+	// A successful probe closes the breaker
+	now = now.Add(time.Minute)
+	require.True(t, breaker.allow())
+	_, ok = breaker.reopensAt()
+	require.False(t, ok)
+	breaker.recordSuccess()
+	require.NoError(t, breaker.health())
+	_, ok = breaker.reopensAt()
+	require.False(t, ok)
+	require.True(t, breaker.allow())
+	require.True(t, breaker.allow())
+
+	// Successes reset the consecutive failures
+	require.False(t, breaker.recordFailure())
+	breaker.recordSuccess()
+	require.False(t, breaker.recordFailure())
+	require.NoError(t, breaker.health())
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := newCircuitBreaker(0, time.Minute)
+	for range 10 {
+		require.True(t, breaker.allow())
+		require.False(t, breaker.recordFailure())
+	}
+	require.NoError(t, breaker.health())
+}
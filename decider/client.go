@@ -0,0 +1,258 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUnavailable is wrapped by the errors of deciders that fail closed, so that messages are not sent
+// without the decider's approval
+var ErrUnavailable = errors.New("decider is unavailable")
+
+// UnavailableError is returned by deciders that fail closed when a query fails. It wraps ErrUnavailable and
+// the error of the query.
+type UnavailableError struct {
+	// RetryAt is when the decider may next be queried: once the circuit breaker's cooldown has passed if it is
+	// open, and after the query timeout otherwise
+	RetryAt time.Time
+	Err     error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrUnavailable, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() []error {
+	return []error{ErrUnavailable, e.Err}
+}
+
+// The code of the errors of queries that are skipped while the circuit breaker is open
+const circuitOpenCode = "CircuitOpen"
+
+var _ pbDecider.DeciderServiceClient = &Client{}
+
+// Client queries the decider at decider-url. Each query times out after decider-timeout-seconds, and queries
+// are not sent while the circuit breaker is open.
+type Client struct {
+	logger     logging.Logger
+	connection *grpc.ClientConn
+	client     pbDecider.DeciderServiceClient
+	timeout    time.Duration
+	failClosed bool
+	breaker    *circuitBreaker
+	metrics    *Metrics
+}
+
+// NewClient connects to the decider configured by [cfg]. Deciders at http or https URLs are queried with JSON
+// over HTTP, and other deciders with gRPC. Returns nil if decider-url is not set.
+func NewClient(logger logging.Logger, cfg *config.Config, metrics *Metrics) (*Client, error) {
+	if len(cfg.DeciderURL) == 0 {
+		return nil, nil
+	}
+	if cfg.DeciderUsesHTTP() {
+		client, err := newHTTPClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newClient(logger, cfg, nil, client, metrics), nil
+	}
+	connection, err := dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(logger, cfg, connection, pbDecider.NewDeciderServiceClient(connection), metrics), nil
+}
+
+func newClient(
+	logger logging.Logger,
+	cfg *config.Config,
+	connection *grpc.ClientConn,
+	client pbDecider.DeciderServiceClient,
+	metrics *Metrics,
+) *Client {
+	return &Client{
+		logger:     logger.With(zap.String("deciderURL", cfg.DeciderURL)),
+		connection: connection,
+		client:     client,
+		timeout:    cfg.GetDeciderTimeout(),
+		failClosed: cfg.DeciderFailurePolicy == config.DeciderFailClosed,
+		breaker:    newCircuitBreaker(cfg.DeciderBreakerFailures, cfg.GetDeciderBreakerCooldown()),
+		metrics:    metrics,
+	}
+}
+
+func dial(cfg *config.Config) (*grpc.ClientConn, error) {
+	transportCredentials := insecure.NewCredentials()
+	if cfg.DeciderTLS {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}
+	if len(cfg.DeciderHeaders) != 0 {
+		opts = append(opts, grpc.WithPerRPCCredentials(headerCredentials(cfg.DeciderHeaders)))
+	}
+
+	connection, err := grpc.NewClient(cfg.DeciderURL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate grpc client: %w", err)
+	}
+	return connection, nil
+}
+
+// newTLSConfig returns the TLS configuration of the decider connection. The client certificate is only
+// presented if the decider requires mutual TLS.
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.DeciderTLSCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.DeciderTLSCertPath, cfg.DeciderTLSKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load decider client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.DeciderTLSCACertPath != "" {
+		caCerts, err := os.ReadFile(cfg.DeciderTLSCACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read decider CA certificates: %w", err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caCerts) {
+			return nil, errors.New("no certificates found in decider-tls-ca-cert-path")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	return tlsConfig, nil
+}
+
+// headerCredentials sends the decider-headers with each gRPC query, for example to authenticate the relayer.
+// The headers are only sent over TLS connections.
+type headerCredentials map[string]string
+
+func (h headerCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return h, nil
+}
+
+func (h headerCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+func (c *Client) ShouldSendMessage(
+	ctx context.Context,
+	in *pbDecider.ShouldSendMessageRequest,
+	opts ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageResponse, error) {
+	return query(
+		ctx,
+		c,
+		"ShouldSendMessage",
+		func(ctx context.Context) (*pbDecider.ShouldSendMessageResponse, error) {
+			return c.client.ShouldSendMessage(ctx, in, opts...)
+		},
+	)
+}
+
+func (c *Client) ShouldSendMessageV2(
+	ctx context.Context,
+	in *pbDecider.ShouldSendMessageV2Request,
+	opts ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageV2Response, error) {
+	return query(
+		ctx,
+		c,
+		"ShouldSendMessageV2",
+		func(ctx context.Context) (*pbDecider.ShouldSendMessageV2Response, error) {
+			return c.client.ShouldSendMessageV2(ctx, in, opts...)
+		},
+	)
+}
+
+// query calls the decider's [method] with [call], and records the result with the circuit breaker and metrics
+func query[T any](
+	ctx context.Context,
+	c *Client,
+	method string,
+	call func(context.Context) (T, error),
+) (T, error) {
+	var empty T
+	if !c.breaker.allow() {
+		c.incRequestErrorCount(method, circuitOpenCode)
+		return empty, c.failure(errCircuitOpen)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	start := time.Now()
+	response, err := call(ctx)
+	c.metrics.requestLatencyMS.WithLabelValues(method).Observe(float64(time.Since(start).Milliseconds()))
+	if err == nil {
+		c.breaker.recordSuccess()
+		return response, nil
+	}
+	code := status.Code(err)
+	if code == codes.Unimplemented {
+		// The decider is available, and the caller is expected to fall back to another method
+		c.breaker.recordSuccess()
+		return empty, err
+	}
+
+	c.incRequestErrorCount(method, code.String())
+	if c.breaker.recordFailure() {
+		c.logger.Warn(
+			"Opened decider circuit breaker",
+			zap.Duration("cooldown", c.breaker.cooldown),
+			zap.Error(err),
+		)
+	}
+	return empty, c.failure(err)
+}
+
+// failure wraps [err] in an UnavailableError if the decider fails closed
+func (c *Client) failure(err error) error {
+	if !c.failClosed {
+		return err
+	}
+	retryAt, ok := c.breaker.reopensAt()
+	if !ok {
+		retryAt = time.Now().Add(c.timeout)
+	}
+	return &UnavailableError{RetryAt: retryAt, Err: err}
+}
+
+// Health returns an error while the circuit breaker is open
+func (c *Client) Health() error {
+	return c.breaker.health()
+}
+
+func (c *Client) Close() error {
+	if c.connection == nil {
+		return nil
+	}
+	return c.connection.Close()
+}
+
+func (c *Client) incRequestErrorCount(method string, code string) {
+	c.metrics.requestErrorCount.
+		WithLabelValues(method, code).
+		Inc()
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testDeciderClient returns [err] from each query, and records the deadline of the queries
+type testDeciderClient struct {
+	err       error
+	calls     int
+	deadlines []time.Time
+}
+
+func (c *testDeciderClient) ShouldSendMessage(
+	ctx context.Context,
+	_ *pbDecider.ShouldSendMessageRequest,
+	_ ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageResponse, error) {
+	c.record(ctx)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &pbDecider.ShouldSendMessageResponse{ShouldSendMessage: true}, nil
+}
+
+func (c *testDeciderClient) ShouldSendMessageV2(
+	ctx context.Context,
+	_ *pbDecider.ShouldSendMessageV2Request,
+	_ ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageV2Response, error) {
+	c.record(ctx)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &pbDecider.ShouldSendMessageV2Response{ShouldSendMessage: true, GasLimitOverride: 1}, nil
+}
+
+func (c *testDeciderClient) record(ctx context.Context) {
+	c.calls++
+	deadline, _ := ctx.Deadline()
+	c.deadlines = append(c.deadlines, deadline)
+}
+
+func newTestClient(t *testing.T, failurePolicy string, inner *testDeciderClient) *Client {
+	cfg := &config.Config{
+		DeciderURL:                    "dns:///localhost:50051",
+		DeciderFailurePolicy:          failurePolicy,
+		DeciderTimeoutSeconds:         5,
+		DeciderBreakerFailures:        2,
+		DeciderBreakerCooldownSeconds: 60,
+	}
+	client := newClient(logging.NoLog{}, cfg, nil, inner, NewMetrics(prometheus.NewRegistry()))
+	require.NotNil(t, client)
+	return client
+}
+
+func TestClientQuery(t *testing.T) {
+	inner := &testDeciderClient{}
+	client := newTestClient(t, config.DeciderFailOpen, inner)
+
+	start := time.Now()
+	response, err := client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{})
+	require.NoError(t, err)
+	require.True(t, response.GetShouldSendMessage())
+	require.Equal(t, uint64(1), response.GetGasLimitOverride())
+
+	v1Response, err := client.ShouldSendMessage(context.Background(), &pbDecider.ShouldSendMessageRequest{})
+	require.NoError(t, err)
+	require.True(t, v1Response.GetShouldSendMessage())
+
+	// Each query times out after decider-timeout-seconds
+	require.Len(t, inner.deadlines, 2)
+	for _, deadline := range inner.deadlines {
+		require.WithinDuration(t, start.Add(5*time.Second), deadline, time.Second)
+	}
+	require.NoError(t, client.Health())
+}
+
+func TestClientFailurePolicy(t *testing.T) {
+	testCases := []struct {
+		name          string
+		failurePolicy string
+		failClosed    bool
+	}{
+		{
+			name:          "fail open",
+			failurePolicy: config.DeciderFailOpen,
+		},
+		{
+			name:          "fail closed",
+			failurePolicy: config.DeciderFailClosed,
+			failClosed:    true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			deciderErr := status.Error(codes.Unavailable, "connection refused")
+			inner := &testDeciderClient{err: deciderErr}
+			client := newTestClient(t, test.failurePolicy, inner)
+
+			start := time.Now()
+			_, err := client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{})
+			require.Equal(t, codes.Unavailable, status.Code(err))
+			require.Equal(t, test.failClosed, errors.Is(err, ErrUnavailable))
+			// Until the breaker opens, the decider may be queried again after the query timeout
+			var unavailable *UnavailableError
+			require.Equal(t, test.failClosed, errors.As(err, &unavailable))
+			if test.failClosed {
+				require.WithinDuration(t, start.Add(5*time.Second), unavailable.RetryAt, time.Second)
+			}
+
+			// The circuit breaker opens after the second failure, and the next query is not sent
+			_, err = client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{})
+			require.Error(t, err)
+			require.ErrorIs(t, client.Health(), errCircuitOpen)
+
+			_, err = client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{})
+			require.ErrorIs(t, err, errCircuitOpen)
+			require.Equal(t, test.failClosed, errors.Is(err, ErrUnavailable))
+			// While the breaker is open, the decider may be queried again once the cooldown has passed
+			require.Equal(t, test.failClosed, errors.As(err, &unavailable))
+			if test.failClosed {
+				require.WithinDuration(t, start.Add(time.Minute), unavailable.RetryAt, time.Second)
+			}
+			require.Equal(t, 2, inner.calls)
+
+			require.Equal(
+				t,
+				float64(2),
+				testutil.ToFloat64(
+					client.metrics.requestErrorCount.WithLabelValues("ShouldSendMessageV2", codes.Unavailable.String()),
+				),
+			)
+			require.Equal(
+				t,
+				float64(1),
+				testutil.ToFloat64(
+					client.metrics.requestErrorCount.WithLabelValues("ShouldSendMessageV2", circuitOpenCode),
+				),
+			)
+		})
+	}
+}
+
+func TestClientUnimplemented(t *testing.T) {
+	// Deciders that do not implement a method are available, so the breaker does not open
+	inner := &testDeciderClient{err: status.Error(codes.Unimplemented, "not implemented")}
+	client := newTestClient(t, config.DeciderFailClosed, inner)
+
+	for range 3 {
+		_, err := client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+		require.NotErrorIs(t, err, ErrUnavailable)
+	}
+	require.Equal(t, 3, inner.calls)
+	require.NoError(t, client.Health())
+}
+
+func TestNewClient(t *testing.T) {
+	client, err := NewClient(logging.NoLog{}, &config.Config{}, NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+	require.Nil(t, client)
+
+	_, err = NewClient(
+		logging.NoLog{},
+		&config.Config{
+			DeciderURL:           "dns:///localhost:50051",
+			DeciderTLS:           true,
+			DeciderTLSCACertPath: "missing-ca.crt",
+		},
+		NewMetrics(prometheus.NewRegistry()),
+	)
+	require.ErrorContains(t, err, "failed to read decider CA certificates")
+}
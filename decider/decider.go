@@ -0,0 +1,43 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
+	"github.com/ryt-io/icm-services/relayer/config"
+)
+
+var (
+	_ Decider = &Client{}
+	_ Decider = &RuleEngine{}
+)
+
+// Decider is queried by the message handlers to decide whether messages should be sent
+type Decider interface {
+	pbDecider.DeciderServiceClient
+
+	// Health returns an error if the decider can not currently be queried
+	Health() error
+	Close() error
+}
+
+// New creates the decider configured by [cfg]: the rule engine if decider rules are configured, or the client
+// of the decider at decider-url. Returns nil if neither is configured.
+func New(logger logging.Logger, cfg *config.Config, metrics *Metrics) (Decider, error) {
+	if cfg.HasDeciderRules() {
+		ruleEngine, err := NewRuleEngine(logger, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return ruleEngine, nil
+	}
+
+	client, err := NewClient(logger, cfg, metrics)
+	if err != nil || client == nil {
+		// Do not return a nil *Client, which is a non-nil Decider
+		return nil, err
+	}
+	return client, nil
+}
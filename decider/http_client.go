@@ -0,0 +1,201 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
+	"github.com/ryt-io/icm-services/relayer/config"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// The maximum number of cached decider responses
+	httpResponseCacheSize = 10_000
+	// The maximum size of a decider response body
+	maxHTTPResponseBytes = 1 << 20
+)
+
+var _ pbDecider.DeciderServiceClient = &httpClient{}
+
+// httpClient queries a decider that accepts the requests of the decider service as JSON over HTTP. Requests and
+// responses are encoded with the field names of proto/decider. Both methods post their request to decider-url:
+// the ShouldSendMessageV2 request includes all of the fields of the ShouldSendMessage request, so deciders that
+// only read the ShouldSendMessage fields can respond to both.
+type httpClient struct {
+	url      string
+	headers  map[string]string
+	client   *http.Client
+	cacheTTL time.Duration
+	cache    *lru.Cache[responseCacheKey, cachedResponse]
+	now      func() time.Time
+}
+
+// responseCacheKey identifies the decider's response to a query about a message
+type responseCacheKey struct {
+	method    string
+	messageID string
+}
+
+type cachedResponse struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+func newHTTPClient(cfg *config.Config) (*httpClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.DeciderTLS {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	cache, err := lru.New[responseCacheKey, cachedResponse](httpResponseCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &httpClient{
+		url:     cfg.DeciderURL,
+		headers: cfg.DeciderHeaders,
+		// The timeout of each query is applied to its context by the Client
+		client:   &http.Client{Transport: transport},
+		cacheTTL: cfg.GetDeciderCacheTTL(),
+		cache:    cache,
+		now:      time.Now,
+	}, nil
+}
+
+func (c *httpClient) ShouldSendMessage(
+	ctx context.Context,
+	in *pbDecider.ShouldSendMessageRequest,
+	_ ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageResponse, error) {
+	response := &pbDecider.ShouldSendMessageResponse{}
+	if err := c.post(ctx, "ShouldSendMessage", in.GetId(), in, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *httpClient) ShouldSendMessageV2(
+	ctx context.Context,
+	in *pbDecider.ShouldSendMessageV2Request,
+	_ ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageV2Response, error) {
+	response := &pbDecider.ShouldSendMessageV2Response{}
+	if err := c.post(ctx, "ShouldSendMessageV2", in.GetId(), in, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// post sends [request] to the decider and decodes its reply into [response], unless the decider's response
+// to the [method] query about [messageID] is cached. Errors are returned as gRPC status errors, so that they
+// are handled in the same way as the errors of the gRPC decider.
+func (c *httpClient) post(
+	ctx context.Context,
+	method string,
+	messageID []byte,
+	request proto.Message,
+	response proto.Message,
+) error {
+	key := responseCacheKey{method: method, messageID: string(messageID)}
+	if cached, ok := c.cache.Get(key); ok {
+		if c.now().Before(cached.expiresAt) {
+			return decodeResponse(cached.body, response)
+		}
+		c.cache.Remove(key)
+	}
+
+	requestBody, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(request)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to encode decider request: %v", err)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(requestBody))
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to create decider request: %v", err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	for name, value := range c.headers {
+		httpRequest.Header.Set(name, value)
+	}
+
+	httpResponse, err := c.client.Do(httpRequest)
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return status.Errorf(codes.Unavailable, "failed to query decider: %v", err)
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(httpResponse.Body, maxHTTPResponseBytes))
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to read decider response: %v", err)
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return status.Errorf(
+			httpStatusCode(httpResponse.StatusCode),
+			"decider returned status %d: %s",
+			httpResponse.StatusCode,
+			strings.TrimSpace(string(responseBody)),
+		)
+	}
+	if err := decodeResponse(responseBody, response); err != nil {
+		return err
+	}
+
+	if c.cacheTTL > 0 && len(messageID) != 0 {
+		c.cache.Add(key, cachedResponse{body: responseBody, expiresAt: c.now().Add(c.cacheTTL)})
+	}
+	return nil
+}
+
+func decodeResponse(body []byte, response proto.Message) error {
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, response); err != nil {
+		return status.Errorf(codes.Internal, "failed to decode decider response: %v", err)
+	}
+	return nil
+}
+
+// httpStatusCode returns the gRPC code of the errors of responses with [httpStatus].
+// Only 501 Not Implemented is reported as Unimplemented, so that a misconfigured decider-url is not mistaken
+// for a decider that does not implement a method.
+func httpStatusCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		if httpStatus >= http.StatusInternalServerError {
+			return codes.Internal
+		}
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestHTTPDecider returns a decider server that replies to each request with [statusCode] and [responseBody],
+// and the number of requests it received
+func newTestHTTPDecider(t *testing.T, statusCode int, responseBody string) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(responseBody))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHTTPClientRequest(t *testing.T) {
+	var (
+		requestBody map[string]any
+		headers     http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		headers = r.Header
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &requestBody))
+		_, _ = w.Write(
+			[]byte(`{"should_send_message": false, "reject_reason": "paused", "gas_limit_override": "500000"}`),
+		)
+	}))
+	defer server.Close()
+
+	client, err := newHTTPClient(&config.Config{
+		DeciderURL:     server.URL,
+		DeciderHeaders: map[string]string{"Authorization": "Bearer token"},
+	})
+	require.NoError(t, err)
+
+	messageID := ids.GenerateTestID()
+	sourceBlockchainID := ids.GenerateTestID()
+	response, err := client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{
+		NetworkId:        1,
+		SourceChainId:    sourceBlockchainID[:],
+		Id:               messageID[:],
+		Protocol:         config.TELEPORTER.String(),
+		RequiredGasLimit: 100_000,
+	})
+	require.NoError(t, err)
+	require.False(t, response.GetShouldSendMessage())
+	require.Equal(t, "paused", response.GetRejectReason())
+	require.Equal(t, uint64(500_000), response.GetGasLimitOverride())
+
+	// The request is encoded with the field names of the decider service
+	require.Equal(t, "application/json", headers.Get("Content-Type"))
+	require.Equal(t, "Bearer token", headers.Get("Authorization"))
+	require.InDelta(t, 1, requestBody["network_id"], 0)
+	require.NotEmpty(t, requestBody["source_chain_id"])
+	require.NotEmpty(t, requestBody["id"])
+	require.Equal(t, config.TELEPORTER.String(), requestBody["protocol"])
+	require.Equal(t, "100000", requestBody["required_gas_limit"])
+}
+
+func TestHTTPClientCache(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cacheTTL         uint64
+		expectedRequests int
+	}{
+		{
+			name:             "cache disabled",
+			expectedRequests: 2,
+		},
+		{
+			name:             "cache enabled",
+			cacheTTL:         60,
+			expectedRequests: 1,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			server, requests := newTestHTTPDecider(t, http.StatusOK, `{"should_send_message": true}`)
+			client, err := newHTTPClient(&config.Config{DeciderURL: server.URL, DeciderCacheTTLSeconds: test.cacheTTL})
+			require.NoError(t, err)
+			now := time.Now()
+			client.now = func() time.Time { return now }
+
+			messageID := ids.GenerateTestID()
+			request := &pbDecider.ShouldSendMessageRequest{Id: messageID[:]}
+			for range 2 {
+				response, err := client.ShouldSendMessage(context.Background(), request)
+				require.NoError(t, err)
+				require.True(t, response.GetShouldSendMessage())
+			}
+			require.Equal(t, test.expectedRequests, *requests)
+
+			// Responses are cached for each message and method, until they expire
+			otherMessageID := ids.GenerateTestID()
+			_, err = client.ShouldSendMessage(
+				context.Background(),
+				&pbDecider.ShouldSendMessageRequest{Id: otherMessageID[:]},
+			)
+			require.NoError(t, err)
+			_, err = client.ShouldSendMessageV2(
+				context.Background(),
+				&pbDecider.ShouldSendMessageV2Request{Id: messageID[:]},
+			)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedRequests+2, *requests)
+
+			now = now.Add(client.cacheTTL)
+			_, err = client.ShouldSendMessage(context.Background(), request)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedRequests+3, *requests)
+		})
+	}
+}
+
+func TestHTTPClientErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		statusCode   int
+		responseBody string
+		expectedCode codes.Code
+	}{
+		{
+			name:         "not implemented",
+			statusCode:   http.StatusNotImplemented,
+			expectedCode: codes.Unimplemented,
+		},
+		{
+			name:         "not found",
+			statusCode:   http.StatusNotFound,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "unavailable",
+			statusCode:   http.StatusServiceUnavailable,
+			expectedCode: codes.Unavailable,
+		},
+		{
+			name:         "internal error",
+			statusCode:   http.StatusInternalServerError,
+			expectedCode: codes.Internal,
+		},
+		{
+			name:         "invalid response",
+			statusCode:   http.StatusOK,
+			responseBody: "yes",
+			expectedCode: codes.Internal,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			server, _ := newTestHTTPDecider(t, test.statusCode, test.responseBody)
+			client, err := newHTTPClient(&config.Config{DeciderURL: server.URL})
+			require.NoError(t, err)
+
+			_, err = client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{})
+			require.Equal(t, test.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestHTTPDecider(t *testing.T) {
+	server, requests := newTestHTTPDecider(t, http.StatusInternalServerError, "")
+	client, err := NewClient(
+		logging.NoLog{},
+		&config.Config{
+			DeciderURL:             server.URL,
+			DeciderFailurePolicy:   config.DeciderFailClosed,
+			DeciderTimeoutSeconds:  5,
+			DeciderBreakerFailures: 1,
+		},
+		NewMetrics(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Failures of HTTP deciders are handled by the failure policy and circuit breaker
+	_, err = client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{})
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.ErrorIs(t, client.Health(), errCircuitOpen)
+	require.Equal(t, 1, *requests)
+}
+
+func TestHTTPDeciderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{DeciderURL: server.URL, DeciderTimeoutSeconds: 1}
+	httpClient, err := newHTTPClient(cfg)
+	require.NoError(t, err)
+	client := newClient(logging.NoLog{}, cfg, nil, httpClient, NewMetrics(prometheus.NewRegistry()))
+	client.timeout = 50 * time.Millisecond
+
+	_, err = client.ShouldSendMessageV2(context.Background(), &pbDecider.ShouldSendMessageV2Request{})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type Metrics struct {
+	requestLatencyMS  *prometheus.HistogramVec
+	requestErrorCount *prometheus.CounterVec
+}
+
+// NewMetrics creates the decider metrics, which are shared by the decider clients created when the
+// configuration is reloaded
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := Metrics{
+		requestLatencyMS: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "decider_request_latency_ms",
+				Help:    "Latency of querying the decider in milliseconds",
+				Buckets: prometheus.ExponentialBucketsRange(1, 30000, 12),
+			},
+			[]string{"method"},
+		),
+		requestErrorCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "decider_request_error_count",
+				Help: "Number of failed decider queries, including queries skipped while the circuit breaker is open",
+			},
+			[]string{"method", "code"},
+		),
+	}
+	registerer.MustRegister(m.requestLatencyMS)
+	registerer.MustRegister(m.requestErrorCount)
+
+	return &m
+}
@@ -0,0 +1,224 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	warpPayload "github.com/ryt-io/ryt-v2/vms/platformvm/warp/payload"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/libevm/common"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The minimum interval between checks for modifications of the rules file
+const rulesFileCheckInterval = 5 * time.Second
+
+var _ pbDecider.DeciderServiceClient = &RuleEngine{}
+
+// RuleEngine decides whether messages should be sent by evaluating the decider-rules, followed by the rules in
+// decider-rules-file, in order. The first rule that matches a message decides whether it is sent. Messages that
+// no rule matches are handled according to decider-rules-default-action. The rules file is reloaded when it is
+// modified.
+type RuleEngine struct {
+	logger       logging.Logger
+	rules        []*config.DeciderRule
+	defaultAllow bool
+	rulesFile    string
+	now          func() time.Time
+
+	// Guards the fields below
+	lock          sync.Mutex
+	fileRules     []*config.DeciderRule
+	fileModTime   time.Time
+	fileCheckedAt time.Time
+}
+
+// NewRuleEngine creates the rule engine configured by [cfg]. The rules in the rules file are validated, and the
+// rule engine is not created if they are invalid.
+func NewRuleEngine(logger logging.Logger, cfg *config.Config) (*RuleEngine, error) {
+	e := &RuleEngine{
+		logger:       logger,
+		rules:        cfg.DeciderRules,
+		defaultAllow: cfg.DeciderRulesDefaultAction != config.DeciderRuleDeny,
+		rulesFile:    cfg.DeciderRulesFile,
+		now:          time.Now,
+	}
+	if e.rulesFile == "" {
+		return e, nil
+	}
+
+	info, err := os.Stat(e.rulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decider-rules-file: %w", err)
+	}
+	e.fileRules, err = readRulesFile(e.rulesFile)
+	if err != nil {
+		return nil, err
+	}
+	e.fileModTime = info.ModTime()
+	e.fileCheckedAt = e.now()
+	return e, nil
+}
+
+// readRulesFile reads the JSON array of rules in [path], and validates them
+func readRulesFile(path string) ([]*config.DeciderRule, error) {
+	rulesBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decider-rules-file: %w", err)
+	}
+	var rules []*config.DeciderRule
+	if err := json.Unmarshal(rulesBytes, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse decider-rules-file: %w", err)
+	}
+	for i, rule := range rules {
+		if rule == nil {
+			return nil, fmt.Errorf("decider rule %d in decider-rules-file is empty", i)
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("failed to validate decider rule %d in decider-rules-file: %w", i, err)
+		}
+	}
+	return rules, nil
+}
+
+// getFileRules returns the rules in the rules file, after reloading the file if it was modified.
+// If the modified file can not be read or is invalid, the previous rules are kept.
+func (e *RuleEngine) getFileRules() []*config.DeciderRule {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.rulesFile == "" || e.now().Sub(e.fileCheckedAt) < rulesFileCheckInterval {
+		return e.fileRules
+	}
+	e.fileCheckedAt = e.now()
+
+	info, err := os.Stat(e.rulesFile)
+	if err != nil {
+		e.logger.Warn("Failed to check decider rules file", zap.String("path", e.rulesFile), zap.Error(err))
+		return e.fileRules
+	}
+	if info.ModTime().Equal(e.fileModTime) {
+		return e.fileRules
+	}
+	// Invalid files are only reported once per modification
+	e.fileModTime = info.ModTime()
+	rules, err := readRulesFile(e.rulesFile)
+	if err != nil {
+		e.logger.Error(
+			"Failed to reload decider rules file, keeping the previous rules",
+			zap.String("path", e.rulesFile),
+			zap.Error(err),
+		)
+		return e.fileRules
+	}
+	e.fileRules = rules
+	e.logger.Info("Reloaded decider rules file", zap.String("path", e.rulesFile), zap.Int("rules", len(rules)))
+	return e.fileRules
+}
+
+// ShouldSendMessage is not implemented, since its request does not include the fields that rules match
+func (e *RuleEngine) ShouldSendMessage(
+	context.Context,
+	*pbDecider.ShouldSendMessageRequest,
+	...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "decider rules require ShouldSendMessageV2")
+}
+
+func (e *RuleEngine) ShouldSendMessageV2(
+	_ context.Context,
+	in *pbDecider.ShouldSendMessageV2Request,
+	_ ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageV2Response, error) {
+	message, err := newRuleMessage(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	for i, rule := range e.rules {
+		if rule.Matches(message) {
+			return ruleResponse(rule, fmt.Sprintf("decider rule %d", i)), nil
+		}
+	}
+	for i, rule := range e.getFileRules() {
+		if rule.Matches(message) {
+			return ruleResponse(rule, fmt.Sprintf("decider rule %d in decider-rules-file", i)), nil
+		}
+	}
+	if e.defaultAllow {
+		return &pbDecider.ShouldSendMessageV2Response{ShouldSendMessage: true}, nil
+	}
+	return &pbDecider.ShouldSendMessageV2Response{RejectReason: "no decider rule matched"}, nil
+}
+
+// ruleResponse returns the decision of [rule], which is named [name] if it does not have a reason
+func ruleResponse(rule *config.DeciderRule, name string) *pbDecider.ShouldSendMessageV2Response {
+	if rule.Action == config.DeciderRuleAllow {
+		return &pbDecider.ShouldSendMessageV2Response{ShouldSendMessage: true}
+	}
+	reason := rule.Reason
+	if reason == "" {
+		reason = "denied by " + name
+	}
+	return &pbDecider.ShouldSendMessageV2Response{RejectReason: reason}
+}
+
+// newRuleMessage returns the fields of the request that rules are evaluated against. The allowed relayers are
+// parsed from the payload of Teleporter messages.
+func newRuleMessage(in *pbDecider.ShouldSendMessageV2Request) (*config.DeciderRuleMessage, error) {
+	sourceBlockchainID, err := ids.ToID(in.GetSourceChainId())
+	if err != nil {
+		return nil, fmt.Errorf("invalid source chain ID: %w", err)
+	}
+	destinationBlockchainID, err := ids.ToID(in.GetDestinationChainId())
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination chain ID: %w", err)
+	}
+	message := &config.DeciderRuleMessage{
+		SourceBlockchainID:      sourceBlockchainID,
+		DestinationBlockchainID: destinationBlockchainID,
+		SenderAddress:           common.BytesToAddress(in.GetRoutingInfo().GetSenderAddress()),
+		DestinationAddress:      common.BytesToAddress(in.GetRoutingInfo().GetDestinationAddress()),
+		RequiredGasLimit:        in.GetRequiredGasLimit(),
+		FeeTokenAddress:         common.BytesToAddress(in.GetFeeInfo().GetFeeTokenAddress()),
+		FeeAmount:               new(big.Int).SetBytes(in.GetFeeInfo().GetAmount()),
+	}
+
+	if in.GetProtocol() == config.TELEPORTER.String() {
+		addressedPayload, err := warpPayload.ParseAddressedCall(in.GetPayload())
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing addressed payload: %w", err)
+		}
+		var teleporterMessage teleportermessenger.TeleporterMessage
+		if err := teleporterMessage.Unpack(addressedPayload.Payload); err != nil {
+			return nil, fmt.Errorf("failed unpacking teleporter message: %w", err)
+		}
+		message.AllowedRelayerAddresses = teleporterMessage.AllowedRelayerAddresses
+	}
+	return message, nil
+}
+
+// Health always returns nil, since the rules are evaluated in process. Rules files that fail to be
+// reloaded are logged, and the previous rules are kept.
+func (e *RuleEngine) Health() error {
+	return nil
+}
+
+func (e *RuleEngine) Close() error {
+	return nil
+}
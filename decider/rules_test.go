@@ -0,0 +1,317 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package decider
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	warpPayload "github.com/ryt-io/ryt-v2/vms/platformvm/warp/payload"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/libevm/common"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	testSourceBlockchainID      = ids.GenerateTestID()
+	testDestinationBlockchainID = ids.GenerateTestID()
+	testProtocolAddress         = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	testSenderAddress           = common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	testDestinationAddress      = common.HexToAddress("0x89abcdef0123456789abcdef0123456789abcdef")
+	testRelayerAddress          = common.HexToAddress("0xabcdef0123456789abcdef0123456789abcdef01")
+	testFeeTokenAddress         = common.HexToAddress("0x1111111111111111111111111111111111111111")
+)
+
+// newTestRequest returns the request of a Teleporter message from testSenderAddress to testDestinationAddress
+func newTestRequest(
+	t *testing.T,
+	requiredGasLimit uint64,
+	feeAmount int64,
+	allowedRelayerAddresses []common.Address,
+) *pbDecider.ShouldSendMessageV2Request {
+	teleporterMessage := teleportermessenger.TeleporterMessage{
+		MessageNonce:            big.NewInt(1),
+		OriginSenderAddress:     testSenderAddress,
+		DestinationBlockchainID: testDestinationBlockchainID,
+		DestinationAddress:      testDestinationAddress,
+		RequiredGasLimit:        new(big.Int).SetUint64(requiredGasLimit),
+		AllowedRelayerAddresses: allowedRelayerAddresses,
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{1, 2, 3, 4},
+	}
+	teleporterMessageBytes, err := teleporterMessage.Pack()
+	require.NoError(t, err)
+	addressedCall, err := warpPayload.NewAddressedCall(testProtocolAddress.Bytes(), teleporterMessageBytes)
+	require.NoError(t, err)
+
+	return &pbDecider.ShouldSendMessageV2Request{
+		SourceChainId:      testSourceBlockchainID[:],
+		Payload:            addressedCall.Bytes(),
+		Protocol:           config.TELEPORTER.String(),
+		DestinationChainId: testDestinationBlockchainID[:],
+		RoutingInfo: &pbDecider.MessageRoutingInfo{
+			SourceChainId:      testSourceBlockchainID[:],
+			SenderAddress:      testSenderAddress.Bytes(),
+			DestinationChainId: testDestinationBlockchainID[:],
+			DestinationAddress: testDestinationAddress.Bytes(),
+		},
+		RequiredGasLimit: requiredGasLimit,
+		FeeInfo: &pbDecider.FeeInfo{
+			FeeTokenAddress: testFeeTokenAddress.Bytes(),
+			Amount:          big.NewInt(feeAmount).Bytes(),
+		},
+	}
+}
+
+func newTestRuleEngine(t *testing.T, cfg *config.Config) *RuleEngine {
+	for _, rule := range cfg.DeciderRules {
+		require.NoError(t, rule.Validate())
+	}
+	ruleEngine, err := NewRuleEngine(logging.NoLog{}, cfg)
+	require.NoError(t, err)
+	return ruleEngine
+}
+
+func TestRuleEngine(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		rules                   []*config.DeciderRule
+		defaultAction           string
+		requiredGasLimit        uint64
+		feeAmount               int64
+		allowedRelayerAddresses []common.Address
+		expectedShouldSend      bool
+		expectedRejectReason    string
+	}{
+		{
+			name:               "no rules",
+			requiredGasLimit:   100_000,
+			expectedShouldSend: true,
+		},
+		{
+			name:                 "no rules with default deny",
+			defaultAction:        config.DeciderRuleDeny,
+			requiredGasLimit:     100_000,
+			expectedRejectReason: "no decider rule matched",
+		},
+		{
+			name: "allowed route and sender",
+			rules: []*config.DeciderRule{
+				{
+					Action:                   config.DeciderRuleAllow,
+					SourceBlockchainIDs:      []string{testSourceBlockchainID.String()},
+					DestinationBlockchainIDs: []string{testDestinationBlockchainID.String()},
+					SenderAddresses:          []string{testSenderAddress.Hex()},
+					DestinationAddresses:     []string{testDestinationAddress.Hex()},
+				},
+			},
+			defaultAction:      config.DeciderRuleDeny,
+			requiredGasLimit:   100_000,
+			expectedShouldSend: true,
+		},
+		{
+			name: "other sender",
+			rules: []*config.DeciderRule{
+				{
+					Action:          config.DeciderRuleAllow,
+					SenderAddresses: []string{testDestinationAddress.Hex()},
+				},
+			},
+			defaultAction:        config.DeciderRuleDeny,
+			requiredGasLimit:     100_000,
+			expectedRejectReason: "no decider rule matched",
+		},
+		{
+			name: "denied without reason",
+			rules: []*config.DeciderRule{
+				{
+					Action:              config.DeciderRuleDeny,
+					Reason:              "required gas limit is too high",
+					MaxRequiredGasLimit: 100_000,
+				},
+				{
+					Action:              config.DeciderRuleDeny,
+					MinRequiredGasLimit: 100_001,
+				},
+			},
+			requiredGasLimit:     200_000,
+			expectedRejectReason: "denied by decider rule 1",
+		},
+		{
+			name: "first matching rule decides",
+			rules: []*config.DeciderRule{
+				{
+					Action:              config.DeciderRuleDeny,
+					Reason:              "required gas limit is too high",
+					MinRequiredGasLimit: 100_001,
+				},
+				{
+					Action: config.DeciderRuleAllow,
+				},
+			},
+			defaultAction:        config.DeciderRuleDeny,
+			requiredGasLimit:     200_000,
+			expectedRejectReason: "required gas limit is too high",
+		},
+		{
+			name: "fee below minimum",
+			rules: []*config.DeciderRule{
+				{
+					Action:            config.DeciderRuleAllow,
+					FeeTokenAddresses: []string{testFeeTokenAddress.Hex()},
+					MinFeeAmount:      "1000",
+				},
+			},
+			defaultAction:        config.DeciderRuleDeny,
+			requiredGasLimit:     100_000,
+			feeAmount:            999,
+			expectedRejectReason: "no decider rule matched",
+		},
+		{
+			name: "fee at minimum",
+			rules: []*config.DeciderRule{
+				{
+					Action:            config.DeciderRuleAllow,
+					FeeTokenAddresses: []string{testFeeTokenAddress.Hex()},
+					MinFeeAmount:      "1000",
+				},
+			},
+			defaultAction:      config.DeciderRuleDeny,
+			requiredGasLimit:   100_000,
+			feeAmount:          1000,
+			expectedShouldSend: true,
+		},
+		{
+			name: "relayer not allowed",
+			rules: []*config.DeciderRule{
+				{
+					Action:                  config.DeciderRuleAllow,
+					AllowedRelayerAddresses: []string{testRelayerAddress.Hex()},
+				},
+			},
+			defaultAction:           config.DeciderRuleDeny,
+			requiredGasLimit:        100_000,
+			allowedRelayerAddresses: []common.Address{testSenderAddress},
+			expectedRejectReason:    "no decider rule matched",
+		},
+		{
+			name: "relayer allowed",
+			rules: []*config.DeciderRule{
+				{
+					Action:                  config.DeciderRuleAllow,
+					AllowedRelayerAddresses: []string{testRelayerAddress.Hex()},
+				},
+			},
+			defaultAction:           config.DeciderRuleDeny,
+			requiredGasLimit:        100_000,
+			allowedRelayerAddresses: []common.Address{testSenderAddress, testRelayerAddress},
+			expectedShouldSend:      true,
+		},
+		{
+			name: "any relayer allowed",
+			rules: []*config.DeciderRule{
+				{
+					Action:                  config.DeciderRuleAllow,
+					AllowedRelayerAddresses: []string{testRelayerAddress.Hex()},
+				},
+			},
+			defaultAction:      config.DeciderRuleDeny,
+			requiredGasLimit:   100_000,
+			expectedShouldSend: true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ruleEngine := newTestRuleEngine(t, &config.Config{
+				DeciderRules:              test.rules,
+				DeciderRulesDefaultAction: test.defaultAction,
+			})
+
+			response, err := ruleEngine.ShouldSendMessageV2(
+				context.Background(),
+				newTestRequest(t, test.requiredGasLimit, test.feeAmount, test.allowedRelayerAddresses),
+			)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedShouldSend, response.GetShouldSendMessage())
+			require.Equal(t, test.expectedRejectReason, response.GetRejectReason())
+		})
+	}
+}
+
+func TestRuleEngineShouldSendMessageUnimplemented(t *testing.T) {
+	ruleEngine := newTestRuleEngine(t, &config.Config{})
+	_, err := ruleEngine.ShouldSendMessage(context.Background(), &pbDecider.ShouldSendMessageRequest{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestRuleEngineRulesFile(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	writeRules := func(rules string, modTime time.Time) {
+		require.NoError(t, os.WriteFile(rulesFile, []byte(rules), 0o600))
+		require.NoError(t, os.Chtimes(rulesFile, modTime, modTime))
+	}
+	writeRules(`[{"action": "deny", "reason": "paused"}]`, time.Unix(1_000, 0))
+
+	// The rules in the configuration are evaluated before the rules file
+	ruleEngine := newTestRuleEngine(t, &config.Config{
+		DeciderRules: []*config.DeciderRule{
+			{
+				Action:          config.DeciderRuleAllow,
+				SenderAddresses: []string{testDestinationAddress.Hex()},
+			},
+		},
+		DeciderRulesFile: rulesFile,
+	})
+	now := time.Now()
+	ruleEngine.now = func() time.Time { return now }
+	request := newTestRequest(t, 100_000, 0, nil)
+
+	response, err := ruleEngine.ShouldSendMessageV2(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, "paused", response.GetRejectReason())
+
+	// The modified file is not reloaded until the check interval has passed
+	writeRules(`[{"action": "allow"}]`, time.Unix(2_000, 0))
+	response, err = ruleEngine.ShouldSendMessageV2(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, response.GetShouldSendMessage())
+
+	now = now.Add(rulesFileCheckInterval)
+	response, err = ruleEngine.ShouldSendMessageV2(context.Background(), request)
+	require.NoError(t, err)
+	require.True(t, response.GetShouldSendMessage())
+
+	// Invalid files are ignored, and the previous rules are kept
+	writeRules(`[{"action": "skip"}]`, time.Unix(3_000, 0))
+	now = now.Add(rulesFileCheckInterval)
+	response, err = ruleEngine.ShouldSendMessageV2(context.Background(), request)
+	require.NoError(t, err)
+	require.True(t, response.GetShouldSendMessage())
+}
+
+func TestNewRuleEngineInvalidRulesFile(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(rulesFile, []byte(`[{"action": "deny", "sender-addresses": ["0x12"]}]`), 0o600))
+
+	_, err := NewRuleEngine(logging.NoLog{}, &config.Config{DeciderRulesFile: rulesFile})
+	require.EqualError(
+		t,
+		err,
+		"failed to validate decider rule 0 in decider-rules-file: invalid sender-addresses: invalid address 0x12",
+	)
+
+	missingRulesFile := filepath.Join(t.TempDir(), "missing.json")
+	_, err = NewRuleEngine(logging.NoLog{}, &config.Config{DeciderRulesFile: missingRulesFile})
+	require.ErrorContains(t, err, "failed to read decider-rules-file")
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/libevm/common"
+)
+
+// EventType is a step in the lifecycle of a relayed message
+type EventType string
+
+const (
+	// The relayer started processing the message
+	MessageObserved EventType = "message-observed"
+	// The message is not delivered, for the reason given by the event
+	MessageSkipped EventType = "message-skipped"
+	// The signatures of the message were aggregated
+	MessageSigned EventType = "message-signed"
+	// The transaction delivering the message is being sent to the destination blockchain
+	TxSent EventType = "tx-sent"
+	// The message was delivered by the transaction given by the event
+	MessageDelivered EventType = "message-delivered"
+	// The message could not be delivered after all retries, for the error given by the event
+	MessageFailed EventType = "message-failed"
+)
+
+var eventTypes = set.Of(MessageObserved, MessageSkipped, MessageSigned, TxSent, MessageDelivered, MessageFailed)
+
+// Event is emitted to sinks as a message moves through the relayer
+type Event struct {
+	Type                    EventType   `json:"type"`
+	Timestamp               time.Time   `json:"timestamp"`
+	RelayerID               common.Hash `json:"relayer-id"`
+	WarpMessageID           ids.ID      `json:"warp-message-id"`
+	ProtocolMessageID       *ids.ID     `json:"protocol-message-id,omitempty"` // e.g. the Teleporter message ID
+	SourceBlockchainID      ids.ID      `json:"source-blockchain-id"`
+	DestinationBlockchainID ids.ID      `json:"destination-blockchain-id"`
+	TransactionHash         string      `json:"transaction-hash,omitempty"`
+	Reason                  string      `json:"reason,omitempty"`
+	Error                   string      `json:"error,omitempty"`
+}
+
+// Sink receives the events of relayed messages. Emit must be safe to call concurrently, and should not block
+// message processing, so failures to deliver an event are logged rather than returned.
+type Sink interface {
+	Emit(event Event)
+	Close() error
+}
+
+var _ Sink = &Sinks{}
+
+// Sinks emits each event to the configured sinks that include the event's route and type
+type Sinks struct {
+	sinks []*routedSink
+}
+
+type routedSink struct {
+	cfg        *config.EventSink
+	eventTypes set.Set[EventType]
+	sink       Sink
+}
+
+// NewSinks creates the sinks configured by [cfgs], which are expected to have been validated
+func NewSinks(logger logging.Logger, cfgs []*config.EventSink, metrics *Metrics) (*Sinks, error) {
+	s := &Sinks{}
+	for i, cfg := range cfgs {
+		routed, err := newRoutedSink(logger, cfg, metrics)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to create event sink %d: %w", i, err)
+		}
+		s.sinks = append(s.sinks, routed)
+	}
+	return s, nil
+}
+
+func newRoutedSink(logger logging.Logger, cfg *config.EventSink, metrics *Metrics) (*routedSink, error) {
+	routed := &routedSink{
+		cfg:        cfg,
+		eventTypes: set.NewSet[EventType](len(cfg.EventTypes)),
+	}
+	for _, eventType := range cfg.EventTypes {
+		if !eventTypes.Contains(EventType(eventType)) {
+			return nil, fmt.Errorf("invalid event type %q", eventType)
+		}
+		routed.eventTypes.Add(EventType(eventType))
+	}
+
+	var err error
+	switch cfg.Type {
+	case config.WebhookEventSinkType:
+		routed.sink = NewWebhookSink(logger, cfg, metrics)
+	case config.FileEventSinkType:
+		routed.sink, err = NewFileSink(logger, cfg.Path)
+	default:
+		err = fmt.Errorf("invalid event sink type %q", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return routed, nil
+}
+
+func (s *Sinks) Emit(event Event) {
+	for _, routed := range s.sinks {
+		if !routed.cfg.IncludesRoute(event.SourceBlockchainID, event.DestinationBlockchainID) {
+			continue
+		}
+		if routed.eventTypes.Len() != 0 && !routed.eventTypes.Contains(event.Type) {
+			continue
+		}
+		routed.sink.Emit(event)
+	}
+}
+
+func (s *Sinks) Close() error {
+	var errs []error
+	for _, routed := range s.sinks {
+		errs = append(errs, routed.sink.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	lock   sync.Mutex
+	events []Event
+}
+
+func (s *recordingSink) Emit(event Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *recordingSink) Close() error {
+	return nil
+}
+
+func TestSinksEmitEventTypes(t *testing.T) {
+	allEvents := &recordingSink{}
+	deliveries := &recordingSink{}
+	sinks := &Sinks{
+		sinks: []*routedSink{
+			{cfg: &config.EventSink{}, eventTypes: set.NewSet[EventType](0), sink: allEvents},
+			{cfg: &config.EventSink{}, eventTypes: set.Of(MessageDelivered), sink: deliveries},
+		},
+	}
+
+	warpMessageID := ids.GenerateTestID()
+	for _, eventType := range []EventType{MessageObserved, MessageSigned, TxSent, MessageDelivered} {
+		sinks.Emit(Event{Type: eventType, WarpMessageID: warpMessageID})
+	}
+	require.Len(t, allEvents.events, 4)
+	require.Equal(t, []Event{{Type: MessageDelivered, WarpMessageID: warpMessageID}}, deliveries.events)
+}
+
+func TestNewSinksInvalidEventType(t *testing.T) {
+	_, err := NewSinks(logging.NoLog{}, []*config.EventSink{
+		{
+			Type:       config.FileEventSinkType,
+			Path:       t.TempDir() + "/events.jsonl",
+			EventTypes: []string{"message-relayed"},
+		},
+	}, NewMetrics(prometheus.NewRegistry()))
+	require.ErrorContains(t, err, `invalid event type "message-relayed"`)
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"go.uber.org/zap"
+)
+
+var _ Sink = &FileSink{}
+
+// FileSink appends each event to a file as a line of JSON
+type FileSink struct {
+	logger logging.Logger
+	lock   sync.Mutex
+	file   *os.File
+}
+
+// NewFileSink opens the file at [path] for appending, creating it and its directory if they do not exist
+func NewFileSink(logger logging.Logger, path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{
+		logger: logger.With(zap.String("eventSinkPath", path)),
+		file:   file,
+	}, nil
+}
+
+func (s *FileSink) Emit(event Event) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("Failed to marshal event", zap.Error(err))
+		return
+	}
+	line = append(line, '\n')
+
+	// Each line is written with a single call, so that events are not interleaved
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, err := s.file.Write(line); err != nil {
+		s.logger.Error(
+			"Failed to write event",
+			zap.String("eventType", string(event.Type)),
+			zap.Stringer("warpMessageID", event.WarpMessageID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *FileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
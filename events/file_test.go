@@ -0,0 +1,45 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "events.jsonl")
+	expected := []Event{
+		{Type: MessageObserved, WarpMessageID: ids.GenerateTestID()},
+		{Type: MessageSkipped, WarpMessageID: ids.GenerateTestID(), Reason: "message already delivered"},
+	}
+
+	// Events are appended to the events written before the file was reopened
+	for _, event := range expected {
+		sink, err := NewFileSink(logging.NoLog{}, path)
+		require.NoError(t, err)
+		sink.Emit(event)
+		require.NoError(t, sink.Close())
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var written []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		written = append(written, event)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, expected, written)
+}
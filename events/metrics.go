@@ -0,0 +1,38 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Reasons for which a webhook sink drops events
+	queueFullDropReason = "queue-full"
+	shutdownDropReason  = "shutdown"
+	failedDropReason    = "post-failed"
+)
+
+type Metrics struct {
+	droppedEventCount *prometheus.CounterVec
+}
+
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := Metrics{
+		droppedEventCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "dropped_event_count",
+				Help: "Number of events dropped by webhook sinks without being posted",
+			},
+			[]string{"event_type", "reason"},
+		),
+	}
+	registerer.MustRegister(m.droppedEventCount)
+
+	return &m
+}
+
+func (m *Metrics) addDroppedEvents(eventType EventType, reason string, count int) {
+	m.droppedEventCount.WithLabelValues(string(eventType), reason).Add(float64(count))
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"go.uber.org/zap"
+)
+
+const (
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body, keyed by the sink's HMAC secret
+	SignatureHeader = "X-ICM-Relayer-Signature"
+
+	initialRetryDelay = time.Second
+	maxRetryDelay     = 30 * time.Second
+)
+
+var _ Sink = &WebhookSink{}
+
+// WebhookSink posts each event as JSON to a URL. Events are queued and posted in the order they are emitted, so
+// that a slow or unavailable endpoint does not delay message processing. Events are dropped if the queue is full,
+// if posting them fails after all retries, or if they are still queued when the sink is closed. Dropped events are
+// logged and counted in the dropped_event_count metric.
+type WebhookSink struct {
+	logger     logging.Logger
+	metrics    *Metrics
+	client     *http.Client
+	url        string
+	hmacSecret []byte
+	maxRetries uint64
+	queue      chan Event
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewWebhookSink starts posting the emitted events to the webhook configured by [cfg]
+func NewWebhookSink(logger logging.Logger, cfg *config.EventSink, metrics *Metrics) *WebhookSink {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &WebhookSink{
+		logger:     logger.With(zap.String("eventSinkURL", cfg.URL)),
+		metrics:    metrics,
+		client:     &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		url:        cfg.URL,
+		hmacSecret: []byte(cfg.HMACSecret),
+		maxRetries: cfg.MaxRetries,
+		queue:      make(chan Event, cfg.QueueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+func (s *WebhookSink) Emit(event Event) {
+	select {
+	case <-s.ctx.Done():
+		s.metrics.addDroppedEvents(event.Type, shutdownDropReason, 1)
+	case s.queue <- event:
+	default:
+		s.logger.Warn(
+			"Dropping event because the webhook queue is full",
+			zap.String("eventType", string(event.Type)),
+			zap.Stringer("warpMessageID", event.WarpMessageID),
+		)
+		s.metrics.addDroppedEvents(event.Type, queueFullDropReason, 1)
+	}
+}
+
+// Close stops posting events. Events that are still queued are dropped.
+func (s *WebhookSink) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	dropped := make(map[EventType]int)
+	for numDropped := len(s.queue); numDropped > 0; numDropped-- {
+		dropped[(<-s.queue).Type]++
+	}
+	for eventType, numDropped := range dropped {
+		s.logger.Warn(
+			"Dropped queued events on shutdown",
+			zap.String("eventType", string(eventType)),
+			zap.Int("numEvents", numDropped),
+		)
+		s.metrics.addDroppedEvents(eventType, shutdownDropReason, numDropped)
+	}
+	return nil
+}
+
+func (s *WebhookSink) run() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case event := <-s.queue:
+			s.post(event)
+		}
+	}
+}
+
+// post posts [event], retrying with an exponential backoff if the request fails or the endpoint responds with
+// a server error
+func (s *WebhookSink) post(event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("Failed to marshal event", zap.Error(err))
+		return
+	}
+	logger := s.logger.With(
+		zap.String("eventType", string(event.Type)),
+		zap.Stringer("warpMessageID", event.WarpMessageID),
+	)
+
+	delay := initialRetryDelay
+	for attempt := uint64(0); ; attempt++ {
+		retry, err := s.send(body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == s.maxRetries {
+			logger.Error("Failed to post event", zap.Uint64("attempts", attempt+1), zap.Error(err))
+			s.metrics.addDroppedEvents(event.Type, failedDropReason, 1)
+			return
+		}
+		logger.Warn("Failed to post event, retrying", zap.Duration("retryIn", delay), zap.Error(err))
+		select {
+		case <-s.ctx.Done():
+			s.metrics.addDroppedEvents(event.Type, shutdownDropReason, 1)
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+// send posts [body] once, and returns whether a failed request should be retried
+func (s *WebhookSink) send(body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.hmacSecret) != 0 {
+		req.Header.Set(SignatureHeader, Sign(s.hmacSecret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of [body] keyed by [secret], which webhook receivers compare to the
+// SignatureHeader of the request to authenticate it
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink(t *testing.T) {
+	testCases := []struct {
+		name             string
+		statusCodes      []int
+		expectedRequests int
+		expectedDropped  float64
+	}{
+		{
+			name:             "success",
+			statusCodes:      []int{http.StatusOK},
+			expectedRequests: 1,
+		},
+		{
+			name:             "retried server error",
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedRequests: 2,
+		},
+		{
+			name:             "client error is not retried",
+			statusCodes:      []int{http.StatusBadRequest},
+			expectedRequests: 1,
+			expectedDropped:  1,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			secret := "secret"
+			event := Event{Type: MessageDelivered, WarpMessageID: ids.GenerateTestID(), TransactionHash: "0x01"}
+			requests := make(chan Event, len(testCase.statusCodes))
+			var numRequests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, Sign([]byte(secret), body), r.Header.Get(SignatureHeader))
+
+				var received Event
+				require.NoError(t, json.Unmarshal(body, &received))
+				attempt := int(numRequests.Add(1)) - 1
+				w.WriteHeader(testCase.statusCodes[min(attempt, len(testCase.statusCodes)-1)])
+				requests <- received
+			}))
+			defer server.Close()
+
+			metrics := NewMetrics(prometheus.NewRegistry())
+			sink := NewWebhookSink(logging.NoLog{}, &config.EventSink{
+				Type:           config.WebhookEventSinkType,
+				URL:            server.URL,
+				HMACSecret:     secret,
+				MaxRetries:     3,
+				TimeoutSeconds: 1,
+				QueueSize:      1,
+			}, metrics)
+			sink.Emit(event)
+			for i := 0; i < testCase.expectedRequests; i++ {
+				select {
+				case received := <-requests:
+					require.Equal(t, event, received)
+				case <-time.After(5 * time.Second):
+					require.FailNow(t, "timed out waiting for webhook request")
+				}
+			}
+			require.NoError(t, sink.Close())
+			require.Equal(t, int32(testCase.expectedRequests), numRequests.Load())
+			require.Equal(
+				t,
+				testCase.expectedDropped,
+				testutil.ToFloat64(metrics.droppedEventCount.WithLabelValues(string(event.Type), failedDropReason)),
+			)
+		})
+	}
+}
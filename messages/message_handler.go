@@ -6,20 +6,42 @@
 package messages
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/logging"
 	"github.com/ryt-io/ryt-v2/vms/platformvm/warp"
 	"github.com/ryt-io/icm-services/vms"
+	"github.com/ryt-io/libevm/accounts/abi/bind"
 	"github.com/ryt-io/libevm/common"
 )
 
+// ErrMessageUndeliverable is wrapped by the errors of MessageHandlers that determined that the message can not
+// currently be delivered, so that retrying the delivery would fail in the same way.
+var ErrMessageUndeliverable = errors.New("message can not be delivered")
+
+// MessageDeferredError is returned by MessageHandlers for messages that should be sent, but not before RetryAt.
+// The message is processed again once RetryAt has passed.
+type MessageDeferredError struct {
+	RetryAt time.Time
+	Reason  string
+}
+
+func (e *MessageDeferredError) Error() string {
+	return fmt.Sprintf("message deferred until %s: %s", e.RetryAt.UTC().Format(time.RFC3339), e.Reason)
+}
+
 // MessageManager is specific to each message protocol. The interface handles choosing which messages to send
 // for each message protocol, and performs the sending to the destination chain.
 type MessageHandlerFactory interface {
-	// Create a message handler to relay the Warp message
+	// Create a message handler to relay the Warp message. [sourceClient] reads the state of the
+	// message's source blockchain.
 	NewMessageHandler(
 		logger logging.Logger,
 		unsignedMessage *warp.UnsignedMessage,
+		sourceClient bind.ContractCaller,
 		destinationClient vms.DestinationClient,
 	) (MessageHandler, error)
 
@@ -44,7 +66,9 @@ type MessageHandler interface {
 	// SendMessage sends the signed message to the destination chain. The payload parsed according to
 	// the VM rules is also passed in, since MessageManager does not assume any particular VM
 	// returns the transaction hash if the transaction is successful.
-	SendMessage(signedMessage *warp.Message) (common.Hash, error)
+	// [onTxSent], if non-nil, is called with the hash of the transaction once it is sent, before its
+	// receipt is awaited, and must not block.
+	SendMessage(signedMessage *warp.Message, onTxSent func(txHash common.Hash)) (common.Hash, error)
 
 	// LoggerWithContext returns a logger with the message context
 	LoggerWithContext(logging.Logger) logging.Logger
@@ -52,3 +76,29 @@ type MessageHandler interface {
 	// GetUnsignedMessage returns the unsigned message
 	GetUnsignedMessage() *warp.UnsignedMessage
 }
+
+// ProtocolMessageIDHandler is optionally implemented by MessageHandlers for message protocols that assign
+// their own identifier to each message, such as the Teleporter message ID.
+type ProtocolMessageIDHandler interface {
+	// GetProtocolMessageID returns the message protocol's identifier for the message
+	GetProtocolMessageID() ids.ID
+}
+
+// SkipReasonHandler is optionally implemented by MessageHandlers that report why a message should not be sent
+type SkipReasonHandler interface {
+	// GetSkipReason returns the reason that the last call to ShouldSendMessage returned false. The reason is one
+	// of a fixed set of values, so that it can be used as a metric label.
+	GetSkipReason() string
+
+	// GetSkipDetail returns the details of why the last call to ShouldSendMessage returned false, such as the
+	// reason given by a decider, or an empty string if there are none. The details are free-form.
+	GetSkipDetail() string
+}
+
+// PriorityHandler is optionally implemented by MessageHandlers for message protocols that assign a priority to
+// each message, such as the priority returned by a decider
+type PriorityHandler interface {
+	// GetPriority returns the priority assigned by the last call to ShouldSendMessage that returned true.
+	// Negative priorities are low, zero is normal, and positive priorities are high.
+	GetPriority() int32
+}
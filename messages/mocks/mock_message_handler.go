@@ -12,10 +12,12 @@ package mocks
 import (
 	reflect "reflect"
 
+	ids "github.com/ryt-io/ryt-v2/ids"
 	logging "github.com/ryt-io/ryt-v2/utils/logging"
 	warp "github.com/ryt-io/ryt-v2/vms/platformvm/warp"
 	messages "github.com/ryt-io/icm-services/messages"
 	vms "github.com/ryt-io/icm-services/vms"
+	bind "github.com/ryt-io/libevm/accounts/abi/bind"
 	common "github.com/ryt-io/libevm/common"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -60,18 +62,18 @@ func (mr *MockMessageHandlerFactoryMockRecorder) GetMessageRoutingInfo(unsignedM
 }
 
 // NewMessageHandler mocks base method.
-func (m *MockMessageHandlerFactory) NewMessageHandler(logger logging.Logger, unsignedMessage *warp.UnsignedMessage, destinationClient vms.DestinationClient) (messages.MessageHandler, error) {
+func (m *MockMessageHandlerFactory) NewMessageHandler(logger logging.Logger, unsignedMessage *warp.UnsignedMessage, sourceClient bind.ContractCaller, destinationClient vms.DestinationClient) (messages.MessageHandler, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewMessageHandler", logger, unsignedMessage, destinationClient)
+	ret := m.ctrl.Call(m, "NewMessageHandler", logger, unsignedMessage, sourceClient, destinationClient)
 	ret0, _ := ret[0].(messages.MessageHandler)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NewMessageHandler indicates an expected call of NewMessageHandler.
-func (mr *MockMessageHandlerFactoryMockRecorder) NewMessageHandler(logger, unsignedMessage, destinationClient any) *gomock.Call {
+func (mr *MockMessageHandlerFactoryMockRecorder) NewMessageHandler(logger, unsignedMessage, sourceClient, destinationClient any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewMessageHandler", reflect.TypeOf((*MockMessageHandlerFactory)(nil).NewMessageHandler), logger, unsignedMessage, destinationClient)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewMessageHandler", reflect.TypeOf((*MockMessageHandlerFactory)(nil).NewMessageHandler), logger, unsignedMessage, sourceClient, destinationClient)
 }
 
 // MockMessageHandler is a mock of MessageHandler interface.
@@ -127,18 +129,18 @@ func (mr *MockMessageHandlerMockRecorder) LoggerWithContext(arg0 any) *gomock.Ca
 }
 
 // SendMessage mocks base method.
-func (m *MockMessageHandler) SendMessage(signedMessage *warp.Message) (common.Hash, error) {
+func (m *MockMessageHandler) SendMessage(signedMessage *warp.Message, onTxSent func(common.Hash)) (common.Hash, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendMessage", signedMessage)
+	ret := m.ctrl.Call(m, "SendMessage", signedMessage, onTxSent)
 	ret0, _ := ret[0].(common.Hash)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SendMessage indicates an expected call of SendMessage.
-func (mr *MockMessageHandlerMockRecorder) SendMessage(signedMessage any) *gomock.Call {
+func (mr *MockMessageHandlerMockRecorder) SendMessage(signedMessage, onTxSent any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockMessageHandler)(nil).SendMessage), signedMessage)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockMessageHandler)(nil).SendMessage), signedMessage, onTxSent)
 }
 
 // ShouldSendMessage mocks base method.
@@ -155,3 +157,131 @@ func (mr *MockMessageHandlerMockRecorder) ShouldSendMessage() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldSendMessage", reflect.TypeOf((*MockMessageHandler)(nil).ShouldSendMessage))
 }
+
+// MockProtocolMessageIDHandler is a mock of ProtocolMessageIDHandler interface.
+type MockProtocolMessageIDHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockProtocolMessageIDHandlerMockRecorder
+	isgomock struct{}
+}
+
+// MockProtocolMessageIDHandlerMockRecorder is the mock recorder for MockProtocolMessageIDHandler.
+type MockProtocolMessageIDHandlerMockRecorder struct {
+	mock *MockProtocolMessageIDHandler
+}
+
+// NewMockProtocolMessageIDHandler creates a new mock instance.
+func NewMockProtocolMessageIDHandler(ctrl *gomock.Controller) *MockProtocolMessageIDHandler {
+	mock := &MockProtocolMessageIDHandler{ctrl: ctrl}
+	mock.recorder = &MockProtocolMessageIDHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProtocolMessageIDHandler) EXPECT() *MockProtocolMessageIDHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetProtocolMessageID mocks base method.
+func (m *MockProtocolMessageIDHandler) GetProtocolMessageID() ids.ID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProtocolMessageID")
+	ret0, _ := ret[0].(ids.ID)
+	return ret0
+}
+
+// GetProtocolMessageID indicates an expected call of GetProtocolMessageID.
+func (mr *MockProtocolMessageIDHandlerMockRecorder) GetProtocolMessageID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProtocolMessageID", reflect.TypeOf((*MockProtocolMessageIDHandler)(nil).GetProtocolMessageID))
+}
+
+// MockSkipReasonHandler is a mock of SkipReasonHandler interface.
+type MockSkipReasonHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSkipReasonHandlerMockRecorder
+	isgomock struct{}
+}
+
+// MockSkipReasonHandlerMockRecorder is the mock recorder for MockSkipReasonHandler.
+type MockSkipReasonHandlerMockRecorder struct {
+	mock *MockSkipReasonHandler
+}
+
+// NewMockSkipReasonHandler creates a new mock instance.
+func NewMockSkipReasonHandler(ctrl *gomock.Controller) *MockSkipReasonHandler {
+	mock := &MockSkipReasonHandler{ctrl: ctrl}
+	mock.recorder = &MockSkipReasonHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSkipReasonHandler) EXPECT() *MockSkipReasonHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetSkipDetail mocks base method.
+func (m *MockSkipReasonHandler) GetSkipDetail() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSkipDetail")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetSkipDetail indicates an expected call of GetSkipDetail.
+func (mr *MockSkipReasonHandlerMockRecorder) GetSkipDetail() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSkipDetail", reflect.TypeOf((*MockSkipReasonHandler)(nil).GetSkipDetail))
+}
+
+// GetSkipReason mocks base method.
+func (m *MockSkipReasonHandler) GetSkipReason() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSkipReason")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetSkipReason indicates an expected call of GetSkipReason.
+func (mr *MockSkipReasonHandlerMockRecorder) GetSkipReason() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSkipReason", reflect.TypeOf((*MockSkipReasonHandler)(nil).GetSkipReason))
+}
+
+// MockPriorityHandler is a mock of PriorityHandler interface.
+type MockPriorityHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockPriorityHandlerMockRecorder
+	isgomock struct{}
+}
+
+// MockPriorityHandlerMockRecorder is the mock recorder for MockPriorityHandler.
+type MockPriorityHandlerMockRecorder struct {
+	mock *MockPriorityHandler
+}
+
+// NewMockPriorityHandler creates a new mock instance.
+func NewMockPriorityHandler(ctrl *gomock.Controller) *MockPriorityHandler {
+	mock := &MockPriorityHandler{ctrl: ctrl}
+	mock.recorder = &MockPriorityHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPriorityHandler) EXPECT() *MockPriorityHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetPriority mocks base method.
+func (m *MockPriorityHandler) GetPriority() int32 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriority")
+	ret0, _ := ret[0].(int32)
+	return ret0
+}
+
+// GetPriority indicates an expected call of GetPriority.
+func (mr *MockPriorityHandlerMockRecorder) GetPriority() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriority", reflect.TypeOf((*MockPriorityHandler)(nil).GetPriority))
+}
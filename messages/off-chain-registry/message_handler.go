@@ -37,6 +37,7 @@ type messageHandler struct {
 	destinationClient vms.DestinationClient
 	registryAddress   common.Address
 	logFields         []zap.Field
+	skipReason        string
 }
 
 func NewMessageHandlerFactory(
@@ -63,6 +64,7 @@ func NewMessageHandlerFactory(
 func (f *factory) NewMessageHandler(
 	logger logging.Logger,
 	unsignedMessage *warp.UnsignedMessage,
+	_ bind.ContractCaller,
 	destinationClient vms.DestinationClient,
 ) (messages.MessageHandler, error) {
 	logFields := []zap.Field{
@@ -103,6 +105,7 @@ func (m *messageHandler) GetUnsignedMessage() *warp.UnsignedMessage {
 // in the TeleporterRegistry contract. This is because a single contract address can be registered
 // to multiple versions, but each version may only map to a single contract address.
 func (m *messageHandler) ShouldSendMessage() (bool, error) {
+	m.skipReason = ""
 	addressedPayload, err := warpPayload.ParseAddressedCall(m.unsignedMessage.Payload)
 	if err != nil {
 		m.logger.Error(
@@ -127,6 +130,7 @@ func (m *messageHandler) ShouldSendMessage() (bool, error) {
 			zap.Stringer("destination", destination),
 			zap.Stringer("configuredRegistry", m.registryAddress),
 		)
+		m.skipReason = "message is not intended for the configured registry"
 		return false, nil
 	}
 
@@ -157,10 +161,22 @@ func (m *messageHandler) ShouldSendMessage() (bool, error) {
 		zap.Stringer("version", entry.Version),
 		zap.Stringer("registeredAddress", address),
 	)
+	m.skipReason = "version is already registered"
 	return false, nil
 }
 
-func (m *messageHandler) SendMessage(signedMessage *warp.Message) (common.Hash, error) {
+func (m *messageHandler) GetSkipReason() string {
+	return m.skipReason
+}
+
+func (*messageHandler) GetSkipDetail() string {
+	return ""
+}
+
+func (m *messageHandler) SendMessage(
+	signedMessage *warp.Message,
+	onTxSent func(txHash common.Hash),
+) (common.Hash, error) {
 	// Construct the transaction call data to call the TeleporterRegistry contract.
 	// Only one off-chain registry Warp message is sent at a time, so we hardcode the index to 0 in the call.
 	callData, err := teleporterregistry.PackAddProtocolVersion(0)
@@ -175,6 +191,7 @@ func (m *messageHandler) SendMessage(signedMessage *warp.Message) (common.Hash,
 		m.registryAddress.Hex(),
 		addProtocolVersionGasLimit,
 		callData,
+		onTxSent,
 	)
 	if err != nil {
 		m.logger.Error(
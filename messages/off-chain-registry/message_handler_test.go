@@ -162,7 +162,7 @@ func TestShouldSendMessage(t *testing.T) {
 					test.destinationBlockchainID,
 				)
 			}
-			messageHandler, err := factory.NewMessageHandler(logging.NoLog{}, unsignedMessage, mockClient)
+			messageHandler, err := factory.NewMessageHandler(logging.NoLog{}, unsignedMessage, nil, mockClient)
 			require.NoError(t, err)
 			result, err := messageHandler.ShouldSendMessage()
 			if test.expectedError {
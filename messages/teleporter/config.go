@@ -4,13 +4,43 @@
 package teleporter
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 
+	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/libevm/common"
 )
 
 type Config struct {
-	RewardAddress string `json:"reward-address"`
+	RewardAddress string       `json:"reward-address"`
+	FeePolicies   []*FeePolicy `json:"fee-policies,omitempty"`
+	// If true, the delivery of each message is simulated before it is sent
+	PreflightSimulation bool `json:"preflight-simulation,omitempty"`
+}
+
+// FeePolicy defines the fees that messages to a destination blockchain must pay to be delivered
+type FeePolicy struct {
+	// If empty, the policy applies to all destination blockchains without a policy of their own
+	DestinationBlockchainID string              `json:"destination-blockchain-id"`
+	AcceptedFeeTokens       []*AcceptedFeeToken `json:"accepted-fee-tokens"`
+
+	// Parsed from the settings by ConfigFromMap
+	destinationBlockchainID ids.ID
+}
+
+// AcceptedFeeToken defines the minimum fee that messages paying fees in a token must pay
+type AcceptedFeeToken struct {
+	Address string `json:"address"`
+	// Minimum fee amount in the token's smallest denomination, as a decimal string
+	MinAmount string `json:"min-amount"`
+	// If non-zero, the fee amount must be at least this multiple of the estimated cost in the destination
+	// blockchain's native token of delivering the message
+	MinFeeToGasCostRatio float64 `json:"min-fee-to-gas-cost-ratio"`
+
+	address   common.Address
+	minAmount *big.Int
 }
 
 func ConfigFromMap(m map[string]any) (*Config, error) {
@@ -23,7 +53,136 @@ func ConfigFromMap(m map[string]any) (*Config, error) {
 		return nil, fmt.Errorf("invalid reward address for EVM source subnet: %s", rewardAddress)
 	}
 
+	var feePolicies []*FeePolicy
+	if rawFeePolicies, ok := m["fee-policies"]; ok {
+		// The settings are decoded as generic JSON values, so the nested fee policies are re-encoded
+		// to decode them into their types
+		feePoliciesBytes, err := json.Marshal(rawFeePolicies)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee-policies: %w", err)
+		}
+		if err := json.Unmarshal(feePoliciesBytes, &feePolicies); err != nil {
+			return nil, fmt.Errorf("invalid fee-policies: %w", err)
+		}
+		if err := validateFeePolicies(feePolicies); err != nil {
+			return nil, fmt.Errorf("invalid fee-policies: %w", err)
+		}
+	}
+
+	var preflightSimulation bool
+	if rawPreflightSimulation, ok := m["preflight-simulation"]; ok {
+		preflightSimulation, ok = rawPreflightSimulation.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid preflight-simulation: %v", rawPreflightSimulation)
+		}
+	}
+
 	return &Config{
-		RewardAddress: rewardAddress,
+		RewardAddress:       rewardAddress,
+		FeePolicies:         feePolicies,
+		PreflightSimulation: preflightSimulation,
 	}, nil
 }
+
+func validateFeePolicies(feePolicies []*FeePolicy) error {
+	destinationBlockchainIDs := make(map[ids.ID]struct{}, len(feePolicies))
+	for _, feePolicy := range feePolicies {
+		if feePolicy == nil {
+			return errors.New("fee policy is empty")
+		}
+		if err := feePolicy.validate(); err != nil {
+			return err
+		}
+		if _, ok := destinationBlockchainIDs[feePolicy.destinationBlockchainID]; ok {
+			return fmt.Errorf(
+				"duplicate fee policy for destination blockchain %s",
+				feePolicy.DestinationBlockchainID,
+			)
+		}
+		destinationBlockchainIDs[feePolicy.destinationBlockchainID] = struct{}{}
+	}
+	return nil
+}
+
+func (p *FeePolicy) validate() error {
+	if p.DestinationBlockchainID != "" {
+		blockchainID, err := ids.FromString(p.DestinationBlockchainID)
+		if err != nil {
+			return fmt.Errorf("invalid destination-blockchain-id in fee policy: %w", err)
+		}
+		p.destinationBlockchainID = blockchainID
+	}
+	if len(p.AcceptedFeeTokens) == 0 {
+		return errors.New("fee policy must accept at least one fee token")
+	}
+	tokenAddresses := make(map[common.Address]struct{}, len(p.AcceptedFeeTokens))
+	for _, token := range p.AcceptedFeeTokens {
+		if token == nil {
+			return errors.New("accepted fee token is empty")
+		}
+		if err := token.validate(); err != nil {
+			return err
+		}
+		if _, ok := tokenAddresses[token.address]; ok {
+			return fmt.Errorf("duplicate accepted fee token %s", token.Address)
+		}
+		tokenAddresses[token.address] = struct{}{}
+	}
+	return nil
+}
+
+func (t *AcceptedFeeToken) validate() error {
+	if !common.IsHexAddress(t.Address) {
+		return fmt.Errorf("invalid accepted fee token address: %s", t.Address)
+	}
+	t.address = common.HexToAddress(t.Address)
+
+	t.minAmount = big.NewInt(0)
+	if t.MinAmount != "" {
+		minAmount, ok := new(big.Int).SetString(t.MinAmount, 10)
+		if !ok || minAmount.Sign() < 0 {
+			return fmt.Errorf("invalid min-amount for fee token %s: %s", t.Address, t.MinAmount)
+		}
+		t.minAmount = minAmount
+	}
+	if t.MinFeeToGasCostRatio < 0 {
+		return fmt.Errorf(
+			"invalid min-fee-to-gas-cost-ratio for fee token %s: %f",
+			t.Address,
+			t.MinFeeToGasCostRatio,
+		)
+	}
+	return nil
+}
+
+// GetFeePolicy returns the fee policy of messages to [destinationBlockchainID], or nil if their fees are not checked
+func (c *Config) GetFeePolicy(destinationBlockchainID ids.ID) *FeePolicy {
+	var defaultPolicy *FeePolicy
+	for _, feePolicy := range c.FeePolicies {
+		if feePolicy.destinationBlockchainID == destinationBlockchainID {
+			return feePolicy
+		}
+		if feePolicy.destinationBlockchainID == ids.Empty {
+			defaultPolicy = feePolicy
+		}
+	}
+	return defaultPolicy
+}
+
+// GetAcceptedFeeToken returns the accepted fee token at [address], or nil if fees paid in the token are not accepted
+func (p *FeePolicy) GetAcceptedFeeToken(address common.Address) *AcceptedFeeToken {
+	for _, token := range p.AcceptedFeeTokens {
+		if token.address == address {
+			return token
+		}
+	}
+	return nil
+}
+
+func (t *AcceptedFeeToken) GetAddress() common.Address {
+	return t.address
+}
+
+func (t *AcceptedFeeToken) GetMinAmount() *big.Int {
+	return new(big.Int).Set(t.minAmount)
+}
@@ -17,6 +17,7 @@ func TestConfigValidate(t *testing.T) {
 	}
 
 	validAddress := "0x27aE10273D17Cd7e80de8580A51f476960626e5f"
+	validBlockchainID := "S4mMqUXe7vHsGiRAma6bv3CKnyaLssyAxmQ2KvFpX1KEvfFCD"
 
 	testCases := []test{
 		{
@@ -40,6 +41,116 @@ func TestConfigValidate(t *testing.T) {
 			},
 			isError: true,
 		},
+		{
+			name: "valid fee policies",
+			settings: map[string]any{
+				"reward-address": validAddress,
+				"fee-policies": []any{
+					map[string]any{
+						"accepted-fee-tokens": []any{
+							map[string]any{"address": validAddress, "min-amount": "1000"},
+						},
+					},
+					map[string]any{
+						"destination-blockchain-id": validBlockchainID,
+						"accepted-fee-tokens": []any{
+							map[string]any{"address": validAddress, "min-fee-to-gas-cost-ratio": 1.5},
+						},
+					},
+				},
+			},
+			isError: false,
+		},
+		{
+			name: "invalid fee policy destination",
+			settings: map[string]any{
+				"reward-address": validAddress,
+				"fee-policies": []any{
+					map[string]any{
+						"destination-blockchain-id": "invalid",
+						"accepted-fee-tokens": []any{
+							map[string]any{"address": validAddress},
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		{
+			name: "duplicate fee policy destination",
+			settings: map[string]any{
+				"reward-address": validAddress,
+				"fee-policies": []any{
+					map[string]any{
+						"accepted-fee-tokens": []any{
+							map[string]any{"address": validAddress},
+						},
+					},
+					map[string]any{
+						"accepted-fee-tokens": []any{
+							map[string]any{"address": validAddress},
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		{
+			name: "no accepted fee tokens",
+			settings: map[string]any{
+				"reward-address": validAddress,
+				"fee-policies": []any{
+					map[string]any{
+						"destination-blockchain-id": validBlockchainID,
+					},
+				},
+			},
+			isError: true,
+		},
+		{
+			name: "invalid fee token min amount",
+			settings: map[string]any{
+				"reward-address": validAddress,
+				"fee-policies": []any{
+					map[string]any{
+						"accepted-fee-tokens": []any{
+							map[string]any{"address": validAddress, "min-amount": "-1"},
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		{
+			name: "invalid fee to gas cost ratio",
+			settings: map[string]any{
+				"reward-address": validAddress,
+				"fee-policies": []any{
+					map[string]any{
+						"accepted-fee-tokens": []any{
+							map[string]any{"address": validAddress, "min-fee-to-gas-cost-ratio": -1},
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		{
+			name: "preflight simulation",
+			settings: map[string]any{
+				"reward-address":       validAddress,
+				"preflight-simulation": true,
+			},
+			isError: false,
+		},
+		{
+			name: "invalid preflight simulation",
+			settings: map[string]any{
+				"reward-address":       validAddress,
+				"preflight-simulation": "yes",
+			},
+			isError: true,
+		},
 	}
 
 	for _, test := range testCases {
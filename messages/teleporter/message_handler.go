@@ -5,8 +5,11 @@ package teleporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/ryt-io/ryt-v2/ids"
@@ -18,21 +21,32 @@ import (
 	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
 	gasUtils "github.com/ryt-io/icm-services/icm-contracts/utils/gas-utils"
 	teleporterUtils "github.com/ryt-io/icm-services/icm-contracts/utils/teleporter-utils"
+	"github.com/ryt-io/icm-services/decider"
 	"github.com/ryt-io/icm-services/messages"
 	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
 	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/icm-services/utils"
 	"github.com/ryt-io/icm-services/vms"
+	"github.com/ryt-io/icm-services/vms/evm"
 	"github.com/ryt-io/libevm/accounts/abi/bind"
 	"github.com/ryt-io/libevm/common"
 	"github.com/ryt-io/libevm/core/types"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// The longest that a message is deferred for when the decider delays it. If the decider delays a message for
+// longer, the decider is queried again once this has passed.
+const maxDeciderDelay = time.Hour
+
 type factory struct {
 	messageConfig   *Config
 	protocolAddress common.Address
 	deciderClient   pbDecider.DeciderServiceClient
+	// set once the decider is known to not implement ShouldSendMessageV2
+	deciderV2Unimplemented *atomic.Bool
 }
 
 type messageHandler struct {
@@ -40,14 +54,25 @@ type messageHandler struct {
 	teleporterMessage   *teleportermessenger.TeleporterMessage
 	unsignedMessage     *warp.UnsignedMessage
 	deciderClient       pbDecider.DeciderServiceClient
+	sourceClient        bind.ContractCaller
 	destinationClient   vms.DestinationClient
 	teleporterMessageID ids.ID
 	messageConfig       *Config
 	protocolAddress     common.Address
 	logFields           []zap.Field
+	skipReason          string
+	skipDetail          string
+	gasLimitOverride    uint64
+	priority            int32
+
+	deciderV2Unimplemented *atomic.Bool
+
+	// fee paid for the message, which is queried from the source blockchain once per call to ShouldSendMessage
+	feeTokenAddress common.Address
+	feeAmount       *big.Int
 }
 
-// define an "empty" decider client to use when a connection isn't provided:
+// define an "empty" decider client to use when a decider isn't provided:
 type emptyDeciderClient struct{}
 
 func (s *emptyDeciderClient) ShouldSendMessage(
@@ -58,33 +83,43 @@ func (s *emptyDeciderClient) ShouldSendMessage(
 	return &pbDecider.ShouldSendMessageResponse{ShouldSendMessage: true}, nil
 }
 
+func (s *emptyDeciderClient) ShouldSendMessageV2(
+	_ context.Context,
+	_ *pbDecider.ShouldSendMessageV2Request,
+	_ ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageV2Response, error) {
+	return &pbDecider.ShouldSendMessageV2Response{ShouldSendMessage: true}, nil
+}
+
+// NewMessageHandlerFactory creates the factory of Teleporter message handlers. Messages are only sent if
+// [deciderClient] approves them, unless it is nil.
 func NewMessageHandlerFactory(
 	messageProtocolAddress common.Address,
 	messageProtocolConfig config.MessageProtocolConfig,
-	deciderClientConn *grpc.ClientConn,
+	deciderClient pbDecider.DeciderServiceClient,
 ) (messages.MessageHandlerFactory, error) {
 	messageConfig, err := ConfigFromMap(messageProtocolConfig.Settings)
 	if err != nil {
 		return nil, fmt.Errorf("invalid teleporter config: %w", err)
 	}
 
-	var deciderClient pbDecider.DeciderServiceClient
-	if deciderClientConn == nil {
+	if deciderClient == nil {
 		deciderClient = &emptyDeciderClient{}
-	} else {
-		deciderClient = pbDecider.NewDeciderServiceClient(deciderClientConn)
 	}
 
 	return &factory{
 		messageConfig:   messageConfig,
 		protocolAddress: messageProtocolAddress,
 		deciderClient:   deciderClient,
+
+		deciderV2Unimplemented: &atomic.Bool{},
 	}, nil
 }
 
 func (f *factory) NewMessageHandler(
 	logger logging.Logger,
 	unsignedMessage *warp.UnsignedMessage,
+	sourceClient bind.ContractCaller,
 	destinationClient vms.DestinationClient,
 ) (messages.MessageHandler, error) {
 	teleporterMessage, err := f.parseTeleporterMessage(unsignedMessage)
@@ -122,11 +157,14 @@ func (f *factory) NewMessageHandler(
 
 		unsignedMessage:     unsignedMessage,
 		deciderClient:       f.deciderClient,
+		sourceClient:        sourceClient,
 		destinationClient:   destinationClient,
 		teleporterMessageID: teleporterMessageID,
 		messageConfig:       f.messageConfig,
 		protocolAddress:     f.protocolAddress,
 
+		deciderV2Unimplemented: f.deciderV2Unimplemented,
+
 		logFields: logFields,
 	}, nil
 }
@@ -166,8 +204,31 @@ func (m *messageHandler) GetUnsignedMessage() *warp.UnsignedMessage {
 	return m.unsignedMessage
 }
 
+// GetProtocolMessageID returns the Teleporter message ID
+func (m *messageHandler) GetProtocolMessageID() ids.ID {
+	return m.teleporterMessageID
+}
+
+func (m *messageHandler) GetSkipReason() string {
+	return m.skipReason
+}
+
+func (m *messageHandler) GetSkipDetail() string {
+	return m.skipDetail
+}
+
+// GetPriority returns the priority that the decider assigned to the message
+func (m *messageHandler) GetPriority() int32 {
+	return m.priority
+}
+
 // ShouldSendMessage returns true if the message should be sent to the destination chain
 func (m *messageHandler) ShouldSendMessage() (bool, error) {
+	m.skipReason = ""
+	m.skipDetail = ""
+	m.gasLimitOverride = 0
+	m.priority = 0
+	m.feeAmount = nil
 	requiredGasLimit := m.teleporterMessage.RequiredGasLimit.Uint64()
 	destBlockGasLimit := m.destinationClient.BlockGasLimit()
 	// Check if the specified gas limit is below the maximum threshold
@@ -177,12 +238,14 @@ func (m *messageHandler) ShouldSendMessage() (bool, error) {
 			zap.Uint64("requiredGasLimit", m.teleporterMessage.RequiredGasLimit.Uint64()),
 			zap.Uint64("blockGasLimit", destBlockGasLimit),
 		)
+		m.skipReason = "required gas limit exceeds the block gas limit"
 		return false, nil
 	}
 
 	// Check if the relayer is allowed to deliver this message
 	if !containsAllowedRelayer(m.teleporterMessage.AllowedRelayerAddresses, m.destinationClient.SenderAddresses()) {
 		m.logger.Info("Relayer EOA not allowed to deliver this message.")
+		m.skipReason = "relayer is not an allowed relayer"
 		return false, nil
 	}
 
@@ -198,29 +261,218 @@ func (m *messageHandler) ShouldSendMessage() (bool, error) {
 	}
 	if delivered {
 		m.logger.Info("Message already delivered to destination.")
+		m.skipReason = "message already delivered"
 		return false, nil
 	}
 
+	// Check if the fee paid for the message satisfies the fee policy of its destination
+	if feePolicy := m.messageConfig.GetFeePolicy(m.destinationClient.DestinationBlockchainID()); feePolicy != nil {
+		paysFee, err := m.paysRequiredFee(feePolicy)
+		if err != nil {
+			m.logger.Error("Failed to check the fee paid for the message.", zap.Error(err))
+			return false, err
+		}
+		if !paysFee {
+			m.skipReason = "fee does not satisfy the fee policy"
+			return false, nil
+		}
+	}
+
 	// Dispatch to the external decider service. If the service is unavailable or returns
-	// an error, then use the decision that has already been made, i.e. return true
+	// an error, then use the decision that has already been made, i.e. return true, unless
+	// the decider fails closed, in which case the message is deferred until the decider may
+	// be queried again
 	decision, err := m.getShouldSendMessageFromDecider()
+	var unavailable *decider.UnavailableError
+	if errors.As(err, &unavailable) {
+		m.logger.Warn(
+			"Decider is unavailable",
+			zap.Time("retryAt", unavailable.RetryAt),
+			zap.Error(err),
+		)
+		return false, &messages.MessageDeferredError{
+			RetryAt: unavailable.RetryAt,
+			Reason:  "decider is unavailable",
+		}
+	}
 	if err != nil {
 		m.logger.Warn("Error delegating to decider")
 		return true, nil
 	}
-	if !decision {
-		m.logger.Info("Decider rejected message")
+	if !decision.GetShouldSendMessage() {
+		m.logger.Info("Decider rejected message", zap.String("rejectReason", decision.GetRejectReason()))
+		m.skipReason = "decider rejected message"
+		m.skipDetail = decision.GetRejectReason()
+		return false, nil
+	}
+	if err := m.applyDeciderDecision(decision, destBlockGasLimit); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// applyDeciderDecision applies the delivery overrides returned by the decider for a message that should be sent.
+// If the decider set a time to delay the message until, a messages.MessageDeferredError is returned, so that the
+// message is processed again at that time, or after maxDeciderDelay if that is sooner.
+func (m *messageHandler) applyDeciderDecision(
+	decision *pbDecider.ShouldSendMessageV2Response,
+	destBlockGasLimit uint64,
+) error {
+	m.priority = decision.GetPriority()
+	if decision.GetGasLimitOverride() == 0 && decision.GetDelayUntil() == 0 && decision.GetPriority() == 0 {
+		return nil
+	}
+	m.logger.Info(
+		"Decider approved message",
+		zap.Uint64("gasLimitOverride", decision.GetGasLimitOverride()),
+		zap.Int64("delayUntil", decision.GetDelayUntil()),
+		zap.Int32("priority", decision.GetPriority()),
+	)
+
+	if gasLimit := decision.GetGasLimitOverride(); gasLimit > destBlockGasLimit {
+		m.logger.Warn(
+			"Ignoring decider gas limit override that exceeds the block gas limit",
+			zap.Uint64("gasLimitOverride", gasLimit),
+			zap.Uint64("blockGasLimit", destBlockGasLimit),
+		)
+	} else {
+		m.gasLimitOverride = gasLimit
+	}
+
+	if delay := time.Until(time.Unix(decision.GetDelayUntil(), 0)); decision.GetDelayUntil() != 0 && delay > 0 {
+		if delay > maxDeciderDelay {
+			m.logger.Info(
+				"Capping decider delay",
+				zap.Duration("delay", delay),
+				zap.Duration("maxDelay", maxDeciderDelay),
+			)
+			delay = maxDeciderDelay
+		}
+		return &messages.MessageDeferredError{
+			RetryAt: time.Now().Add(delay),
+			Reason:  "delayed by decider",
+		}
+	}
+	return nil
+}
+
+// paysRequiredFee returns true if the fee paid for the message on the source blockchain is in one of the
+// [feePolicy]'s accepted fee tokens, and meets the token's minimum amount and fee-to-gas-cost ratio.
+func (m *messageHandler) paysRequiredFee(feePolicy *FeePolicy) (bool, error) {
+	callCtx, callCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	defer callCtxCancel()
+	feeTokenAddress, feeAmount, err := m.getFeeInfo(callCtx)
+	if err != nil {
+		return false, err
+	}
+	log := m.logger.With(
+		zap.Stringer("feeTokenAddress", feeTokenAddress),
+		zap.Stringer("feeAmount", feeAmount),
+	)
+
+	feeToken := feePolicy.GetAcceptedFeeToken(feeTokenAddress)
+	if feeToken == nil {
+		log.Info("Message fee is not paid in an accepted fee token.")
+		return false, nil
+	}
+	minAmount := feeToken.GetMinAmount()
+	if feeAmount.Cmp(minAmount) < 0 {
+		log.Info("Message fee is below the minimum amount.", zap.Stringer("minAmount", minAmount))
+		return false, nil
+	}
+	if feeToken.MinFeeToGasCostRatio == 0 {
+		return true, nil
+	}
+
+	gasCost, err := m.estimateGasCost(callCtx)
+	if err != nil {
+		return false, fmt.Errorf("failed to estimate gas cost: %w", err)
+	}
+	minFee := new(big.Float).Mul(new(big.Float).SetInt(gasCost), big.NewFloat(feeToken.MinFeeToGasCostRatio))
+	if new(big.Float).SetInt(feeAmount).Cmp(minFee) < 0 {
+		log.Info(
+			"Message fee is below the minimum fee-to-gas-cost ratio.",
+			zap.Stringer("estimatedGasCost", gasCost),
+			zap.Float64("minFeeToGasCostRatio", feeToken.MinFeeToGasCostRatio),
+		)
+		return false, nil
+	}
+	return true, nil
+}
+
+// getFeeInfo returns the address of the fee token and the amount of the fee paid for the message on the source
+// blockchain. The fee is only queried once per call to ShouldSendMessage, since it is used both by the fee policy
+// and by the decider.
+func (m *messageHandler) getFeeInfo(ctx context.Context) (common.Address, *big.Int, error) {
+	if m.feeAmount != nil {
+		return m.feeTokenAddress, m.feeAmount, nil
+	}
+	sourceMessenger, err := teleportermessenger.NewTeleporterMessengerCaller(m.protocolAddress, m.sourceClient)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to get source teleporter messenger contract: %w", err)
+	}
+	feeTokenAddress, feeAmount, err := sourceMessenger.GetFeeInfo(
+		&bind.CallOpts{Context: ctx},
+		m.teleporterMessageID,
+	)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to get fee info: %w", err)
 	}
-	return decision, nil
+	m.feeTokenAddress, m.feeAmount = feeTokenAddress, feeAmount
+	return feeTokenAddress, feeAmount, nil
+}
+
+// estimateGasCost returns the estimated cost of delivering the message at the destination blockchain's current
+// gas price. The gas used to verify the signatures is not included, since the signers are not yet known.
+func (m *messageHandler) estimateGasCost(ctx context.Context) (*big.Int, error) {
+	// The predicate contains the signature, which is a fixed size apart from the signers' bit set
+	signedMessage, err := warp.NewMessage(m.unsignedMessage, &warp.BitSetSignature{})
+	if err != nil {
+		return nil, err
+	}
+	gasLimit, err := gasUtils.CalculateReceiveMessageGasLimit(
+		0,
+		m.teleporterMessage.RequiredGasLimit,
+		len(predicate.New(signedMessage.Bytes())),
+		len(signedMessage.Payload),
+		len(m.teleporterMessage.Receipts),
+	)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := m.destinationClient.Client().SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice), nil
 }
 
 // Queries the decider service to determine whether this message should be
-// sent. If the decider client is nil, returns true.
-func (m *messageHandler) getShouldSendMessageFromDecider() (bool, error) {
+// sent. If the decider client is nil, returns true. Deciders that do not implement
+// ShouldSendMessageV2 are queried with ShouldSendMessage, and their response only
+// sets whether the message should be sent.
+func (m *messageHandler) getShouldSendMessageFromDecider() (*pbDecider.ShouldSendMessageV2Response, error) {
+	// The request is not built if there is no decider, since it queries the fee paid for the message
+	if _, ok := m.deciderClient.(*emptyDeciderClient); ok {
+		return &pbDecider.ShouldSendMessageV2Response{ShouldSendMessage: true}, nil
+	}
 	warpMsgID := m.unsignedMessage.ID()
 
-	ctx, cancelCtx := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancelCtx()
+	// The decider client applies the configured timeout to each query
+	ctx := context.Background()
+	if !m.deciderV2Unimplemented.Load() {
+		response, err := m.deciderClient.ShouldSendMessageV2(ctx, m.newShouldSendMessageV2Request())
+		if status.Code(err) != codes.Unimplemented {
+			if err != nil {
+				m.logger.Error("Error response from decider.", zap.Error(err))
+				return nil, err
+			}
+			return response, nil
+		}
+		m.logger.Info("Decider does not implement ShouldSendMessageV2, falling back to ShouldSendMessage")
+		m.deciderV2Unimplemented.Store(true)
+	}
+
 	response, err := m.deciderClient.ShouldSendMessage(
 		ctx,
 		&pbDecider.ShouldSendMessageRequest{
@@ -233,16 +485,56 @@ func (m *messageHandler) getShouldSendMessageFromDecider() (bool, error) {
 	)
 	if err != nil {
 		m.logger.Error("Error response from decider.", zap.Error(err))
-		return false, err
+		return nil, err
+	}
+
+	return &pbDecider.ShouldSendMessageV2Response{ShouldSendMessage: response.ShouldSendMessage}, nil
+}
+
+// newShouldSendMessageV2Request returns the decider request for the message. If the fee paid for the message
+// can not be queried, the request is sent without it.
+func (m *messageHandler) newShouldSendMessageV2Request() *pbDecider.ShouldSendMessageV2Request {
+	warpMsgID := m.unsignedMessage.ID()
+	destinationBlockchainID := m.destinationClient.DestinationBlockchainID()
+	request := &pbDecider.ShouldSendMessageV2Request{
+		NetworkId:           m.unsignedMessage.NetworkID,
+		SourceChainId:       m.unsignedMessage.SourceChainID[:],
+		Payload:             m.unsignedMessage.Payload,
+		BytesRepresentation: m.unsignedMessage.Bytes(),
+		Id:                  warpMsgID[:],
+		Protocol:            config.TELEPORTER.String(),
+		DestinationChainId:  destinationBlockchainID[:],
+		RoutingInfo: &pbDecider.MessageRoutingInfo{
+			SourceChainId:      m.unsignedMessage.SourceChainID[:],
+			SenderAddress:      m.teleporterMessage.OriginSenderAddress.Bytes(),
+			DestinationChainId: m.teleporterMessage.DestinationBlockchainID[:],
+			DestinationAddress: m.teleporterMessage.DestinationAddress.Bytes(),
+		},
+		ProtocolMessageId: m.teleporterMessageID[:],
+		RequiredGasLimit:  m.teleporterMessage.RequiredGasLimit.Uint64(),
 	}
 
-	return response.ShouldSendMessage, nil
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	defer cancel()
+	feeTokenAddress, feeAmount, err := m.getFeeInfo(ctx)
+	if err != nil {
+		m.logger.Warn("Failed to get fee info for decider request", zap.Error(err))
+		return request
+	}
+	request.FeeInfo = &pbDecider.FeeInfo{
+		FeeTokenAddress: feeTokenAddress.Bytes(),
+		Amount:          feeAmount.Bytes(),
+	}
+	return request
 }
 
 // SendMessage extracts the gasLimit and packs the call data to call the receiveCrossChainMessage
 // method of the Teleporter contract, and dispatches transaction construction and broadcast to the
 // destination client.
-func (m *messageHandler) SendMessage(signedMessage *warp.Message) (common.Hash, error) {
+func (m *messageHandler) SendMessage(
+	signedMessage *warp.Message,
+	onTxSent func(txHash common.Hash),
+) (common.Hash, error) {
 	m.logger.Info("Sending message to destination chain")
 	numSigners, err := signedMessage.Signature.NumSigners()
 	if err != nil {
@@ -261,6 +553,14 @@ func (m *messageHandler) SendMessage(signedMessage *warp.Message) (common.Hash,
 		m.logger.Error("Failed to calculate gas limit for receiveCrossChainMessage call")
 		return common.Hash{}, err
 	}
+	if m.gasLimitOverride != 0 {
+		m.logger.Info(
+			"Using gas limit override from decider",
+			zap.Uint64("gasLimit", m.gasLimitOverride),
+			zap.Uint64("calculatedGasLimit", gasLimit),
+		)
+		gasLimit = m.gasLimitOverride
+	}
 	// Construct the transaction call data to call the receive cross chain message method of the receiver precompile.
 	callData, err := teleportermessenger.PackReceiveCrossChainMessage(
 		0,
@@ -271,12 +571,20 @@ func (m *messageHandler) SendMessage(signedMessage *warp.Message) (common.Hash,
 		return common.Hash{}, err
 	}
 
+	if m.messageConfig.PreflightSimulation {
+		send, err := m.simulateDelivery(signedMessage, gasLimit, callData)
+		if err != nil || !send {
+			return common.Hash{}, err
+		}
+	}
+
 	receipt, err := m.destinationClient.SendTx(
 		signedMessage,
 		set.Of(m.teleporterMessage.AllowedRelayerAddresses...),
 		m.protocolAddress.Hex(),
 		gasLimit,
 		callData,
+		onTxSent,
 	)
 	if err != nil {
 		m.logger.Error("Failed to send tx.", zap.Error(err))
@@ -309,6 +617,48 @@ func (m *messageHandler) SendMessage(signedMessage *warp.Message) (common.Hash,
 	return txHash, nil
 }
 
+// simulateDelivery simulates the receiveCrossChainMessage transaction before it is signed, and returns whether it
+// should be sent. Messages that were already delivered are not sent, and an error wrapping
+// messages.ErrMessageUndeliverable is returned for messages whose delivery would revert.
+func (m *messageHandler) simulateDelivery(
+	signedMessage *warp.Message,
+	gasLimit uint64,
+	callData []byte,
+) (bool, error) {
+	err := m.destinationClient.SimulateTx(
+		signedMessage,
+		set.Of(m.teleporterMessage.AllowedRelayerAddresses...),
+		m.protocolAddress.Hex(),
+		gasLimit,
+		callData,
+	)
+	if err == nil {
+		m.logger.Debug("Simulated delivery succeeded")
+		return true, nil
+	}
+	if !errors.Is(err, evm.ErrSimulationReverted) {
+		m.logger.Error("Failed to simulate delivery", zap.Error(err))
+		return false, err
+	}
+
+	// Check if the message has already been delivered to the destination chain
+	delivered, deliveredErr := m.getTeleporterMessenger().MessageReceived(&bind.CallOpts{}, m.teleporterMessageID)
+	if deliveredErr != nil {
+		m.logger.Error(
+			"Failed to check if message has been delivered to destination chain.",
+			zap.Error(deliveredErr),
+		)
+		return false, fmt.Errorf("failed to check if message has been delivered: %w", deliveredErr)
+	}
+	if delivered {
+		m.logger.Info("Simulated delivery reverted: message already delivered to destination.")
+		return false, nil
+	}
+
+	m.logger.Warn("Simulated delivery reverted", zap.Error(err))
+	return false, fmt.Errorf("%w: %w", messages.ErrMessageUndeliverable, err)
+}
+
 func (m *messageHandler) LoggerWithContext(logger logging.Logger) logging.Logger {
 	return logger.With(m.logFields...)
 }
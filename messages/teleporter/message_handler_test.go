@@ -4,16 +4,26 @@
 package teleporter
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
 	"github.com/ryt-io/ryt-v2/vms/platformvm/warp"
 	warpPayload "github.com/ryt-io/ryt-v2/vms/platformvm/warp/payload"
 	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
 	teleporterUtils "github.com/ryt-io/icm-services/icm-contracts/utils/teleporter-utils"
+	"github.com/ryt-io/icm-services/decider"
+	"github.com/ryt-io/icm-services/messages"
+	pbDecider "github.com/ryt-io/icm-services/proto/pb/decider"
 	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/icm-services/vms/evm"
 	mock_evm "github.com/ryt-io/icm-services/vms/evm/mocks"
 	mock_vms "github.com/ryt-io/icm-services/vms/mocks"
 	ethereum "github.com/ava-labs/libevm"
@@ -22,6 +32,9 @@ import (
 	"github.com/ryt-io/libevm/core/types"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CallContractChecker struct {
@@ -147,6 +160,7 @@ func TestShouldSendMessage(t *testing.T) {
 		messageReceivedCall     *CallContractChecker
 		expectedParseError      bool
 		expectedResult          bool
+		expectedSkipReason      string
 	}{
 		{
 			name:                    "valid message",
@@ -174,6 +188,7 @@ func TestShouldSendMessage(t *testing.T) {
 			senderAddressesResult:   []common.Address{common.Address{}},
 			senderAddressesTimes:    1,
 			warpUnsignedMessage:     warpUnsignedMessage,
+			expectedSkipReason:      "relayer is not an allowed relayer",
 		},
 		{
 			name:                    "not allowed",
@@ -183,6 +198,7 @@ func TestShouldSendMessage(t *testing.T) {
 			senderAddressesTimes:    1,
 			clientTimes:             0,
 			expectedResult:          false,
+			expectedSkipReason:      "relayer is not an allowed relayer",
 		},
 		{
 			name:                    "message already delivered",
@@ -196,13 +212,15 @@ func TestShouldSendMessage(t *testing.T) {
 				expectedResult: messageDelivered,
 				times:          1,
 			},
-			expectedResult: false,
+			expectedResult:     false,
+			expectedSkipReason: "message already delivered",
 		},
 		{
 			name:                    "gas limit exceeded",
 			destinationBlockchainID: destinationBlockchainID,
 			warpUnsignedMessage:     gasLimitExceededWarpUnsignedMessage,
 			expectedResult:          false,
+			expectedSkipReason:      "required gas limit exceeds the block gas limit",
 		},
 	}
 	for _, test := range testCases {
@@ -222,6 +240,7 @@ func TestShouldSendMessage(t *testing.T) {
 			messageHandler, err := factory.NewMessageHandler(
 				logging.NoLog{},
 				test.warpUnsignedMessage,
+				nil,
 				mockClient,
 			)
 			if test.expectedParseError {
@@ -257,6 +276,7 @@ func TestShouldSendMessage(t *testing.T) {
 			result, err := messageHandler.ShouldSendMessage()
 			require.NoError(t, err)
 			require.Equal(t, test.expectedResult, result)
+			require.Equal(t, test.expectedSkipReason, messageHandler.(messages.SkipReasonHandler).GetSkipReason())
 		})
 	}
 }
@@ -321,6 +341,7 @@ func TestSendMessageAlreadyDelivered(t *testing.T) {
 	messageHandler, err := factory.NewMessageHandler(
 		logging.NoLog{},
 		warpUnsignedMessage,
+		nil,
 		mockClient,
 	)
 	require.NoError(t, err)
@@ -332,7 +353,7 @@ func TestSendMessageAlreadyDelivered(t *testing.T) {
 		Times(1)
 
 	mockClient.EXPECT().
-		SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(
 			&types.Receipt{
 				Status: types.ReceiptStatusFailed,
@@ -346,6 +367,586 @@ func TestSendMessageAlreadyDelivered(t *testing.T) {
 		Times(1)
 
 	// Call the method under test
-	_, err = messageHandler.SendMessage(signedMessage)
+	_, err = messageHandler.SendMessage(signedMessage, nil)
 	require.NoError(t, err)
 }
+
+func TestSendMessagePreflightSimulation(t *testing.T) {
+	testCases := []struct {
+		name              string
+		simulationErr     error
+		delivered         *bool
+		expectSend        bool
+		expectErr         bool
+		expectUndelivered bool
+	}{
+		{
+			name:       "simulation succeeded",
+			expectSend: true,
+		},
+		{
+			name:          "already delivered",
+			simulationErr: fmt.Errorf("%w: message already delivered", evm.ErrSimulationReverted),
+			delivered:     boolPtr(true),
+		},
+		{
+			name:              "delivery would revert",
+			simulationErr:     fmt.Errorf("%w: invalid warp message", evm.ErrSimulationReverted),
+			delivered:         boolPtr(false),
+			expectErr:         true,
+			expectUndelivered: true,
+		},
+		{
+			name:          "simulation failed",
+			simulationErr: errors.New("connection refused"),
+			expectErr:     true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			validMessageBytes, err := validTeleporterMessage.Pack()
+			require.NoError(t, err)
+			validAddressedCall, err := warpPayload.NewAddressedCall(
+				messageProtocolAddress.Bytes(),
+				validMessageBytes,
+			)
+			require.NoError(t, err)
+			sourceBlockchainID := ids.Empty
+			warpUnsignedMessage, err := warp.NewUnsignedMessage(
+				0,
+				sourceBlockchainID,
+				validAddressedCall.Bytes(),
+			)
+			require.NoError(t, err)
+			signedMessage, err := warp.NewMessage(
+				warpUnsignedMessage,
+				&warp.BitSetSignature{},
+			)
+			require.NoError(t, err)
+
+			mockClient := mock_vms.NewMockDestinationClient(ctrl)
+			simulationConfig := config.MessageProtocolConfig{
+				MessageFormat: config.TELEPORTER.String(),
+				Settings: map[string]interface{}{
+					"reward-address":       "0x27aE10273D17Cd7e80de8580A51f476960626e5f",
+					"preflight-simulation": true,
+				},
+			}
+			factory, err := NewMessageHandlerFactory(
+				messageProtocolAddress,
+				simulationConfig,
+				nil,
+			)
+			require.NoError(t, err)
+			mockClient.EXPECT().DestinationBlockchainID().Return(destinationBlockchainID).AnyTimes()
+			messageHandler, err := factory.NewMessageHandler(
+				logging.NoLog{},
+				warpUnsignedMessage,
+				nil,
+				mockClient,
+			)
+			require.NoError(t, err)
+
+			mockClient.EXPECT().
+				SimulateTx(
+					signedMessage,
+					set.Of(validRelayerAddress),
+					messageProtocolAddress.Hex(),
+					gomock.Any(),
+					gomock.Any(),
+				).
+				Return(test.simulationErr).
+				Times(1)
+
+			if test.delivered != nil {
+				messageID, err := teleporterUtils.CalculateMessageID(
+					messageProtocolAddress,
+					sourceBlockchainID,
+					destinationBlockchainID,
+					validTeleporterMessage.MessageNonce,
+				)
+				require.NoError(t, err)
+				messageReceivedCallData, err := teleportermessenger.PackMessageReceived(messageID)
+				require.NoError(t, err)
+				messageReceivedResult, err := teleportermessenger.PackMessageReceivedOutput(*test.delivered)
+				require.NoError(t, err)
+				mockEthClient := mock_evm.NewMockClient(ctrl)
+				mockClient.EXPECT().
+					Client().
+					Return(mockEthClient).
+					Times(1)
+				mockEthClient.EXPECT().
+					CallContract(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+						To:   &messageProtocolAddress,
+						Data: messageReceivedCallData,
+					}), gomock.Any()).
+					Return(messageReceivedResult, nil).
+					Times(1)
+			}
+
+			sends := 0
+			if test.expectSend {
+				sends = 1
+			}
+			txHash := common.HexToHash("0x01")
+			mockClient.EXPECT().
+				SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(
+					&types.Receipt{
+						TxHash: txHash,
+						Status: types.ReceiptStatusSuccessful,
+					},
+					nil,
+				).Times(sends)
+
+			result, err := messageHandler.SendMessage(signedMessage, nil)
+			if test.expectErr {
+				require.Error(t, err)
+				require.Equal(t, test.expectUndelivered, errors.Is(err, messages.ErrMessageUndeliverable))
+				return
+			}
+			require.NoError(t, err)
+			if test.expectSend {
+				require.Equal(t, txHash, result)
+			} else {
+				require.Equal(t, common.Hash{}, result)
+			}
+		})
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestShouldSendMessageFeePolicy(t *testing.T) {
+	validMessageBytes, err := validTeleporterMessage.Pack()
+	require.NoError(t, err)
+	validAddressedCall, err := warpPayload.NewAddressedCall(
+		messageProtocolAddress.Bytes(),
+		validMessageBytes,
+	)
+	require.NoError(t, err)
+	sourceBlockchainID := ids.Empty
+	warpUnsignedMessage, err := warp.NewUnsignedMessage(
+		0,
+		sourceBlockchainID,
+		validAddressedCall.Bytes(),
+	)
+	require.NoError(t, err)
+
+	messageID, err := teleporterUtils.CalculateMessageID(
+		messageProtocolAddress,
+		sourceBlockchainID,
+		destinationBlockchainID,
+		validTeleporterMessage.MessageNonce,
+	)
+	require.NoError(t, err)
+	messageReceivedInput, err := teleportermessenger.PackMessageReceived(messageID)
+	require.NoError(t, err)
+	messageNotDelivered, err := teleportermessenger.PackMessageReceivedOutput(false)
+	require.NoError(t, err)
+	getFeeInfoInput, err := teleportermessenger.PackGetFeeInfo(messageID)
+	require.NoError(t, err)
+
+	acceptedFeeToken := common.HexToAddress("0xabcdef0123456789abcdef0123456789abcdef01")
+	otherFeeToken := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	// The estimated gas cost is the estimated gas limit, since the gas price is 1
+	largeFee, _ := new(big.Int).SetString("1000000000000000000", 10)
+
+	testCases := []struct {
+		name                    string
+		feePolicy               map[string]any
+		feeTokenAddress         common.Address
+		feeAmount               *big.Int
+		expectedGetFeeInfoCalls int
+		expectedGasPriceCalls   int
+		expectedResult          bool
+	}{
+		{
+			name: "no policy for destination",
+			feePolicy: map[string]any{
+				"destination-blockchain-id": ids.GenerateTestID().String(),
+				"accepted-fee-tokens": []any{
+					map[string]any{"address": acceptedFeeToken.Hex(), "min-amount": "100"},
+				},
+			},
+			expectedResult: true,
+		},
+		{
+			name: "fee token not accepted",
+			feePolicy: map[string]any{
+				"accepted-fee-tokens": []any{
+					map[string]any{"address": acceptedFeeToken.Hex(), "min-amount": "100"},
+				},
+			},
+			feeTokenAddress:         otherFeeToken,
+			feeAmount:               big.NewInt(100),
+			expectedGetFeeInfoCalls: 1,
+			expectedResult:          false,
+		},
+		{
+			name: "fee below minimum amount",
+			feePolicy: map[string]any{
+				"destination-blockchain-id": destinationBlockchainIDString,
+				"accepted-fee-tokens": []any{
+					map[string]any{"address": acceptedFeeToken.Hex(), "min-amount": "100"},
+				},
+			},
+			feeTokenAddress:         acceptedFeeToken,
+			feeAmount:               big.NewInt(99),
+			expectedGetFeeInfoCalls: 1,
+			expectedResult:          false,
+		},
+		{
+			name: "fee meets minimum amount",
+			feePolicy: map[string]any{
+				"accepted-fee-tokens": []any{
+					map[string]any{"address": acceptedFeeToken.Hex(), "min-amount": "100"},
+				},
+			},
+			feeTokenAddress:         acceptedFeeToken,
+			feeAmount:               big.NewInt(100),
+			expectedGetFeeInfoCalls: 1,
+			expectedResult:          true,
+		},
+		{
+			name: "fee below gas cost ratio",
+			feePolicy: map[string]any{
+				"accepted-fee-tokens": []any{
+					map[string]any{"address": acceptedFeeToken.Hex(), "min-fee-to-gas-cost-ratio": 1.5},
+				},
+			},
+			feeTokenAddress:         acceptedFeeToken,
+			feeAmount:               big.NewInt(1000),
+			expectedGetFeeInfoCalls: 1,
+			expectedGasPriceCalls:   1,
+			expectedResult:          false,
+		},
+		{
+			name: "fee meets gas cost ratio",
+			feePolicy: map[string]any{
+				"accepted-fee-tokens": []any{
+					map[string]any{"address": acceptedFeeToken.Hex(), "min-fee-to-gas-cost-ratio": 1.5},
+				},
+			},
+			feeTokenAddress:         acceptedFeeToken,
+			feeAmount:               largeFee,
+			expectedGetFeeInfoCalls: 1,
+			expectedGasPriceCalls:   1,
+			expectedResult:          true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockClient := mock_vms.NewMockDestinationClient(ctrl)
+			mockSourceClient := mock_evm.NewMockClient(ctrl)
+			mockDestinationEthClient := mock_evm.NewMockClient(ctrl)
+
+			factory, err := NewMessageHandlerFactory(
+				messageProtocolAddress,
+				config.MessageProtocolConfig{
+					MessageFormat: config.TELEPORTER.String(),
+					Settings: map[string]any{
+						"reward-address": "0x27aE10273D17Cd7e80de8580A51f476960626e5f",
+						"fee-policies":   []any{test.feePolicy},
+					},
+				},
+				nil,
+			)
+			require.NoError(t, err)
+			mockClient.EXPECT().DestinationBlockchainID().Return(destinationBlockchainID).AnyTimes()
+			messageHandler, err := factory.NewMessageHandler(
+				logging.NoLog{},
+				warpUnsignedMessage,
+				mockSourceClient,
+				mockClient,
+			)
+			require.NoError(t, err)
+
+			mockClient.EXPECT().BlockGasLimit().Return(uint64(10_000_000)).AnyTimes()
+			mockClient.EXPECT().SenderAddresses().Return([]common.Address{validRelayerAddress}).AnyTimes()
+			mockClient.EXPECT().Client().Return(mockDestinationEthClient).AnyTimes()
+			mockDestinationEthClient.EXPECT().
+				CallContract(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+					To:   &messageProtocolAddress,
+					Data: messageReceivedInput,
+				}), gomock.Any()).
+				Return(messageNotDelivered, nil).
+				Times(1)
+			mockDestinationEthClient.EXPECT().
+				SuggestGasPrice(gomock.Any()).
+				Return(big.NewInt(1), nil).
+				Times(test.expectedGasPriceCalls)
+			if test.expectedGetFeeInfoCalls > 0 {
+				getFeeInfoResult, err := teleportermessenger.PackGetFeeInfoOutput(test.feeTokenAddress, test.feeAmount)
+				require.NoError(t, err)
+				mockSourceClient.EXPECT().
+					CallContract(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+						To:   &messageProtocolAddress,
+						Data: getFeeInfoInput,
+					}), gomock.Any()).
+					Return(getFeeInfoResult, nil).
+					Times(test.expectedGetFeeInfoCalls)
+			}
+
+			result, err := messageHandler.ShouldSendMessage()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedResult, result)
+		})
+	}
+}
+
+type testDeciderClient struct {
+	v2Response *pbDecider.ShouldSendMessageV2Response
+	v2Err      error
+	v1Response *pbDecider.ShouldSendMessageResponse
+	v2Requests []*pbDecider.ShouldSendMessageV2Request
+	v1Calls    int
+}
+
+func (c *testDeciderClient) ShouldSendMessage(
+	_ context.Context,
+	_ *pbDecider.ShouldSendMessageRequest,
+	_ ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageResponse, error) {
+	c.v1Calls++
+	return c.v1Response, nil
+}
+
+func (c *testDeciderClient) ShouldSendMessageV2(
+	_ context.Context,
+	request *pbDecider.ShouldSendMessageV2Request,
+	_ ...grpc.CallOption,
+) (*pbDecider.ShouldSendMessageV2Response, error) {
+	c.v2Requests = append(c.v2Requests, request)
+	return c.v2Response, c.v2Err
+}
+
+func TestShouldSendMessageDecider(t *testing.T) {
+	validMessageBytes, err := validTeleporterMessage.Pack()
+	require.NoError(t, err)
+	validAddressedCall, err := warpPayload.NewAddressedCall(
+		messageProtocolAddress.Bytes(),
+		validMessageBytes,
+	)
+	require.NoError(t, err)
+	sourceBlockchainID := ids.Empty
+	warpUnsignedMessage, err := warp.NewUnsignedMessage(
+		0,
+		sourceBlockchainID,
+		validAddressedCall.Bytes(),
+	)
+	require.NoError(t, err)
+
+	messageID, err := teleporterUtils.CalculateMessageID(
+		messageProtocolAddress,
+		sourceBlockchainID,
+		destinationBlockchainID,
+		validTeleporterMessage.MessageNonce,
+	)
+	require.NoError(t, err)
+	messageReceivedInput, err := teleportermessenger.PackMessageReceived(messageID)
+	require.NoError(t, err)
+	messageNotDelivered, err := teleportermessenger.PackMessageReceivedOutput(false)
+	require.NoError(t, err)
+	getFeeInfoInput, err := teleportermessenger.PackGetFeeInfo(messageID)
+	require.NoError(t, err)
+	feeTokenAddress := common.HexToAddress("0xabcdef0123456789abcdef0123456789abcdef01")
+	getFeeInfoResult, err := teleportermessenger.PackGetFeeInfoOutput(feeTokenAddress, big.NewInt(1000))
+	require.NoError(t, err)
+
+	const blockGasLimit = 10_000_000
+	testCases := []struct {
+		name                     string
+		decider                  *testDeciderClient
+		expectedResult           bool
+		expectedSkipReason       string
+		expectedSkipDetail       string
+		expectedGasLimitOverride uint64
+		expectedPriority         int32
+		expectedV1Calls          int
+		expectedRetryAfter       time.Duration
+	}{
+		{
+			name: "approved",
+			decider: &testDeciderClient{
+				v2Response: &pbDecider.ShouldSendMessageV2Response{ShouldSendMessage: true},
+			},
+			expectedResult: true,
+		},
+		{
+			name: "rejected with reason",
+			decider: &testDeciderClient{
+				v2Response: &pbDecider.ShouldSendMessageV2Response{RejectReason: "sender is blocked"},
+			},
+			expectedSkipReason: "decider rejected message",
+			expectedSkipDetail: "sender is blocked",
+		},
+		{
+			name: "gas limit override",
+			decider: &testDeciderClient{
+				v2Response: &pbDecider.ShouldSendMessageV2Response{
+					ShouldSendMessage: true,
+					GasLimitOverride:  500_000,
+				},
+			},
+			expectedResult:           true,
+			expectedGasLimitOverride: 500_000,
+		},
+		{
+			name: "priority",
+			decider: &testDeciderClient{
+				v2Response: &pbDecider.ShouldSendMessageV2Response{
+					ShouldSendMessage: true,
+					Priority:          -1,
+				},
+			},
+			expectedResult:   true,
+			expectedPriority: -1,
+		},
+		{
+			name: "gas limit override exceeds block gas limit",
+			decider: &testDeciderClient{
+				v2Response: &pbDecider.ShouldSendMessageV2Response{
+					ShouldSendMessage: true,
+					GasLimitOverride:  blockGasLimit + 1,
+				},
+			},
+			expectedResult: true,
+		},
+		{
+			name: "delay until",
+			decider: &testDeciderClient{
+				v2Response: &pbDecider.ShouldSendMessageV2Response{
+					ShouldSendMessage: true,
+					DelayUntil:        time.Now().Add(time.Minute).Unix(),
+				},
+			},
+			expectedRetryAfter: time.Minute,
+		},
+		{
+			name: "delay until is capped",
+			decider: &testDeciderClient{
+				v2Response: &pbDecider.ShouldSendMessageV2Response{
+					ShouldSendMessage: true,
+					DelayUntil:        time.Now().Add(24 * time.Hour).Unix(),
+				},
+			},
+			expectedRetryAfter: maxDeciderDelay,
+		},
+		{
+			name: "delay until in the past",
+			decider: &testDeciderClient{
+				v2Response: &pbDecider.ShouldSendMessageV2Response{
+					ShouldSendMessage: true,
+					DelayUntil:        time.Now().Add(-time.Minute).Unix(),
+				},
+			},
+			expectedResult: true,
+		},
+		{
+			name: "v2 unimplemented",
+			decider: &testDeciderClient{
+				v2Err:      status.Error(codes.Unimplemented, "method ShouldSendMessageV2 not implemented"),
+				v1Response: &pbDecider.ShouldSendMessageResponse{ShouldSendMessage: false},
+			},
+			expectedSkipReason: "decider rejected message",
+			expectedV1Calls:    1,
+		},
+		{
+			name: "decider unavailable",
+			decider: &testDeciderClient{
+				v2Err: status.Error(codes.Unavailable, "connection refused"),
+			},
+			expectedResult: true,
+		},
+		{
+			name: "decider unavailable and failing closed",
+			decider: &testDeciderClient{
+				v2Err: &decider.UnavailableError{
+					RetryAt: time.Now().Add(30 * time.Second),
+					Err:     errors.New("connection refused"),
+				},
+			},
+			expectedRetryAfter: 30 * time.Second,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockClient := mock_vms.NewMockDestinationClient(ctrl)
+			mockSourceClient := mock_evm.NewMockClient(ctrl)
+			mockDestinationEthClient := mock_evm.NewMockClient(ctrl)
+
+			messageConfig, err := ConfigFromMap(messageProtocolConfig.Settings)
+			require.NoError(t, err)
+			f := &factory{
+				messageConfig:          messageConfig,
+				protocolAddress:        messageProtocolAddress,
+				deciderClient:          test.decider,
+				deciderV2Unimplemented: &atomic.Bool{},
+			}
+			mockClient.EXPECT().DestinationBlockchainID().Return(destinationBlockchainID).AnyTimes()
+			handler, err := f.NewMessageHandler(
+				logging.NoLog{},
+				warpUnsignedMessage,
+				mockSourceClient,
+				mockClient,
+			)
+			require.NoError(t, err)
+
+			mockClient.EXPECT().BlockGasLimit().Return(uint64(blockGasLimit)).AnyTimes()
+			mockClient.EXPECT().SenderAddresses().Return([]common.Address{validRelayerAddress}).AnyTimes()
+			mockClient.EXPECT().Client().Return(mockDestinationEthClient).AnyTimes()
+			mockDestinationEthClient.EXPECT().
+				CallContract(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+					To:   &messageProtocolAddress,
+					Data: messageReceivedInput,
+				}), gomock.Any()).
+				Return(messageNotDelivered, nil).
+				Times(1)
+			mockSourceClient.EXPECT().
+				CallContract(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+					To:   &messageProtocolAddress,
+					Data: getFeeInfoInput,
+				}), gomock.Any()).
+				Return(getFeeInfoResult, nil).
+				Times(1)
+
+			start := time.Now()
+			result, err := handler.ShouldSendMessage()
+			if test.expectedRetryAfter != 0 {
+				var deferred *messages.MessageDeferredError
+				require.ErrorAs(t, err, &deferred)
+				require.WithinDuration(t, start.Add(test.expectedRetryAfter), deferred.RetryAt, 2*time.Second)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.expectedResult, result)
+			require.Equal(t, test.expectedSkipReason, handler.(messages.SkipReasonHandler).GetSkipReason())
+			require.Equal(t, test.expectedSkipDetail, handler.(messages.SkipReasonHandler).GetSkipDetail())
+			require.Equal(t, test.expectedGasLimitOverride, handler.(*messageHandler).gasLimitOverride)
+			require.Equal(t, test.expectedPriority, handler.(messages.PriorityHandler).GetPriority())
+			require.Equal(t, test.expectedV1Calls, test.decider.v1Calls)
+			require.Equal(t, test.expectedV1Calls != 0, f.deciderV2Unimplemented.Load())
+
+			require.Len(t, test.decider.v2Requests, 1)
+			request := test.decider.v2Requests[0]
+			require.Equal(t, config.TELEPORTER.String(), request.GetProtocol())
+			require.Equal(t, destinationBlockchainID[:], request.GetDestinationChainId())
+			require.Equal(t, messageID[:], request.GetProtocolMessageId())
+			require.Equal(t, validTeleporterMessage.RequiredGasLimit.Uint64(), request.GetRequiredGasLimit())
+			require.Equal(
+				t,
+				validTeleporterMessage.OriginSenderAddress.Bytes(),
+				request.GetRoutingInfo().GetSenderAddress(),
+			)
+			require.Equal(t, feeTokenAddress.Bytes(), request.GetFeeInfo().GetFeeTokenAddress())
+			require.Equal(t, big.NewInt(1000).Bytes(), request.GetFeeInfo().GetAmount())
+		})
+	}
+}
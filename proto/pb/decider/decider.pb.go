@@ -141,6 +141,337 @@ func (x *ShouldSendMessageResponse) GetShouldSendMessage() bool {
 	return false
 }
 
+// MessageRoutingInfo is the routing info parsed from the message by its message protocol
+type MessageRoutingInfo struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	SourceChainId      []byte                 `protobuf:"bytes,1,opt,name=source_chain_id,json=sourceChainId,proto3" json:"source_chain_id,omitempty"`
+	SenderAddress      []byte                 `protobuf:"bytes,2,opt,name=sender_address,json=senderAddress,proto3" json:"sender_address,omitempty"`
+	DestinationChainId []byte                 `protobuf:"bytes,3,opt,name=destination_chain_id,json=destinationChainId,proto3" json:"destination_chain_id,omitempty"`
+	DestinationAddress []byte                 `protobuf:"bytes,4,opt,name=destination_address,json=destinationAddress,proto3" json:"destination_address,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MessageRoutingInfo) Reset() {
+	*x = MessageRoutingInfo{}
+	mi := &file_decider_decider_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageRoutingInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageRoutingInfo) ProtoMessage() {}
+
+func (x *MessageRoutingInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_decider_decider_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageRoutingInfo.ProtoReflect.Descriptor instead.
+func (*MessageRoutingInfo) Descriptor() ([]byte, []int) {
+	return file_decider_decider_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MessageRoutingInfo) GetSourceChainId() []byte {
+	if x != nil {
+		return x.SourceChainId
+	}
+	return nil
+}
+
+func (x *MessageRoutingInfo) GetSenderAddress() []byte {
+	if x != nil {
+		return x.SenderAddress
+	}
+	return nil
+}
+
+func (x *MessageRoutingInfo) GetDestinationChainId() []byte {
+	if x != nil {
+		return x.DestinationChainId
+	}
+	return nil
+}
+
+func (x *MessageRoutingInfo) GetDestinationAddress() []byte {
+	if x != nil {
+		return x.DestinationAddress
+	}
+	return nil
+}
+
+// FeeInfo is the fee paid to the relayer for delivering the message
+type FeeInfo struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FeeTokenAddress []byte                 `protobuf:"bytes,1,opt,name=fee_token_address,json=feeTokenAddress,proto3" json:"fee_token_address,omitempty"`
+	// Big-endian encoded amount of the fee token
+	Amount        []byte `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeeInfo) Reset() {
+	*x = FeeInfo{}
+	mi := &file_decider_decider_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeeInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeeInfo) ProtoMessage() {}
+
+func (x *FeeInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_decider_decider_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeeInfo.ProtoReflect.Descriptor instead.
+func (*FeeInfo) Descriptor() ([]byte, []int) {
+	return file_decider_decider_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FeeInfo) GetFeeTokenAddress() []byte {
+	if x != nil {
+		return x.FeeTokenAddress
+	}
+	return nil
+}
+
+func (x *FeeInfo) GetAmount() []byte {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+type ShouldSendMessageV2Request struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	NetworkId           uint32                 `protobuf:"varint,1,opt,name=network_id,json=networkId,proto3" json:"network_id,omitempty"`
+	SourceChainId       []byte                 `protobuf:"bytes,2,opt,name=source_chain_id,json=sourceChainId,proto3" json:"source_chain_id,omitempty"`
+	Payload             []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	BytesRepresentation []byte                 `protobuf:"bytes,4,opt,name=bytes_representation,json=bytesRepresentation,proto3" json:"bytes_representation,omitempty"`
+	Id                  []byte                 `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	// Name of the message protocol, e.g. "teleporter"
+	Protocol           string              `protobuf:"bytes,6,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	DestinationChainId []byte              `protobuf:"bytes,7,opt,name=destination_chain_id,json=destinationChainId,proto3" json:"destination_chain_id,omitempty"`
+	RoutingInfo        *MessageRoutingInfo `protobuf:"bytes,8,opt,name=routing_info,json=routingInfo,proto3" json:"routing_info,omitempty"`
+	// Message ID assigned by the message protocol, e.g. the Teleporter message ID
+	ProtocolMessageId []byte `protobuf:"bytes,9,opt,name=protocol_message_id,json=protocolMessageId,proto3" json:"protocol_message_id,omitempty"`
+	RequiredGasLimit  uint64 `protobuf:"varint,10,opt,name=required_gas_limit,json=requiredGasLimit,proto3" json:"required_gas_limit,omitempty"`
+	// Not set if the message protocol does not pay fees
+	FeeInfo       *FeeInfo `protobuf:"bytes,11,opt,name=fee_info,json=feeInfo,proto3" json:"fee_info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShouldSendMessageV2Request) Reset() {
+	*x = ShouldSendMessageV2Request{}
+	mi := &file_decider_decider_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShouldSendMessageV2Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShouldSendMessageV2Request) ProtoMessage() {}
+
+func (x *ShouldSendMessageV2Request) ProtoReflect() protoreflect.Message {
+	mi := &file_decider_decider_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShouldSendMessageV2Request.ProtoReflect.Descriptor instead.
+func (*ShouldSendMessageV2Request) Descriptor() ([]byte, []int) {
+	return file_decider_decider_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ShouldSendMessageV2Request) GetNetworkId() uint32 {
+	if x != nil {
+		return x.NetworkId
+	}
+	return 0
+}
+
+func (x *ShouldSendMessageV2Request) GetSourceChainId() []byte {
+	if x != nil {
+		return x.SourceChainId
+	}
+	return nil
+}
+
+func (x *ShouldSendMessageV2Request) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ShouldSendMessageV2Request) GetBytesRepresentation() []byte {
+	if x != nil {
+		return x.BytesRepresentation
+	}
+	return nil
+}
+
+func (x *ShouldSendMessageV2Request) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *ShouldSendMessageV2Request) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ShouldSendMessageV2Request) GetDestinationChainId() []byte {
+	if x != nil {
+		return x.DestinationChainId
+	}
+	return nil
+}
+
+func (x *ShouldSendMessageV2Request) GetRoutingInfo() *MessageRoutingInfo {
+	if x != nil {
+		return x.RoutingInfo
+	}
+	return nil
+}
+
+func (x *ShouldSendMessageV2Request) GetProtocolMessageId() []byte {
+	if x != nil {
+		return x.ProtocolMessageId
+	}
+	return nil
+}
+
+func (x *ShouldSendMessageV2Request) GetRequiredGasLimit() uint64 {
+	if x != nil {
+		return x.RequiredGasLimit
+	}
+	return 0
+}
+
+func (x *ShouldSendMessageV2Request) GetFeeInfo() *FeeInfo {
+	if x != nil {
+		return x.FeeInfo
+	}
+	return nil
+}
+
+type ShouldSendMessageV2Response struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ShouldSendMessage bool                   `protobuf:"varint,1,opt,name=should_send_message,json=shouldSendMessage,proto3" json:"should_send_message,omitempty"`
+	// Logged and counted by the relayer if the message is not sent
+	RejectReason string `protobuf:"bytes,2,opt,name=reject_reason,json=rejectReason,proto3" json:"reject_reason,omitempty"`
+	// If set, the message is not sent before this Unix timestamp in seconds
+	DelayUntil int64 `protobuf:"varint,3,opt,name=delay_until,json=delayUntil,proto3" json:"delay_until,omitempty"`
+	// If set, the gas limit of the transaction delivering the message
+	GasLimitOverride uint64 `protobuf:"varint,4,opt,name=gas_limit_override,json=gasLimitOverride,proto3" json:"gas_limit_override,omitempty"`
+	// Priority of the message, which is logged with the message, and counted by the relayer in buckets:
+	// negative priorities are low, zero is normal, and positive priorities are high
+	Priority      int32 `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShouldSendMessageV2Response) Reset() {
+	*x = ShouldSendMessageV2Response{}
+	mi := &file_decider_decider_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShouldSendMessageV2Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShouldSendMessageV2Response) ProtoMessage() {}
+
+func (x *ShouldSendMessageV2Response) ProtoReflect() protoreflect.Message {
+	mi := &file_decider_decider_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShouldSendMessageV2Response.ProtoReflect.Descriptor instead.
+func (*ShouldSendMessageV2Response) Descriptor() ([]byte, []int) {
+	return file_decider_decider_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ShouldSendMessageV2Response) GetShouldSendMessage() bool {
+	if x != nil {
+		return x.ShouldSendMessage
+	}
+	return false
+}
+
+func (x *ShouldSendMessageV2Response) GetRejectReason() string {
+	if x != nil {
+		return x.RejectReason
+	}
+	return ""
+}
+
+func (x *ShouldSendMessageV2Response) GetDelayUntil() int64 {
+	if x != nil {
+		return x.DelayUntil
+	}
+	return 0
+}
+
+func (x *ShouldSendMessageV2Response) GetGasLimitOverride() uint64 {
+	if x != nil {
+		return x.GasLimitOverride
+	}
+	return 0
+}
+
+func (x *ShouldSendMessageV2Response) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
 var File_decider_decider_proto protoreflect.FileDescriptor
 
 const file_decider_decider_proto_rawDesc = "" +
@@ -154,9 +485,39 @@ const file_decider_decider_proto_rawDesc = "" +
 	"\x14bytes_representation\x18\x04 \x01(\fR\x13bytesRepresentation\x12\x0e\n" +
 	"\x02id\x18\x05 \x01(\fR\x02id\"K\n" +
 	"\x19ShouldSendMessageResponse\x12.\n" +
-	"\x13should_send_message\x18\x01 \x01(\bR\x11shouldSendMessage2l\n" +
+	"\x13should_send_message\x18\x01 \x01(\bR\x11shouldSendMessage\"\xc6\x01\n" +
+	"\x12MessageRoutingInfo\x12&\n" +
+	"\x0fsource_chain_id\x18\x01 \x01(\fR\rsourceChainId\x12%\n" +
+	"\x0esender_address\x18\x02 \x01(\fR\rsenderAddress\x120\n" +
+	"\x14destination_chain_id\x18\x03 \x01(\fR\x12destinationChainId\x12/\n" +
+	"\x13destination_address\x18\x04 \x01(\fR\x12destinationAddress\"M\n" +
+	"\aFeeInfo\x12*\n" +
+	"\x11fee_token_address\x18\x01 \x01(\fR\x0ffeeTokenAddress\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\fR\x06amount\"\xd9\x03\n" +
+	"\x1aShouldSendMessageV2Request\x12\x1d\n" +
+	"\n" +
+	"network_id\x18\x01 \x01(\rR\tnetworkId\x12&\n" +
+	"\x0fsource_chain_id\x18\x02 \x01(\fR\rsourceChainId\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\x121\n" +
+	"\x14bytes_representation\x18\x04 \x01(\fR\x13bytesRepresentation\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\fR\x02id\x12\x1a\n" +
+	"\bprotocol\x18\x06 \x01(\tR\bprotocol\x120\n" +
+	"\x14destination_chain_id\x18\a \x01(\fR\x12destinationChainId\x12>\n" +
+	"\frouting_info\x18\b \x01(\v2\x1b.decider.MessageRoutingInfoR\vroutingInfo\x12.\n" +
+	"\x13protocol_message_id\x18\t \x01(\fR\x11protocolMessageId\x12,\n" +
+	"\x12required_gas_limit\x18\n" +
+	" \x01(\x04R\x10requiredGasLimit\x12+\n" +
+	"\bfee_info\x18\v \x01(\v2\x10.decider.FeeInfoR\afeeInfo\"\xdd\x01\n" +
+	"\x1bShouldSendMessageV2Response\x12.\n" +
+	"\x13should_send_message\x18\x01 \x01(\bR\x11shouldSendMessage\x12#\n" +
+	"\rreject_reason\x18\x02 \x01(\tR\frejectReason\x12\x1f\n" +
+	"\vdelay_until\x18\x03 \x01(\x03R\n" +
+	"delayUntil\x12,\n" +
+	"\x12gas_limit_override\x18\x04 \x01(\x04R\x10gasLimitOverride\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\x05R\bpriority2\xce\x01\n" +
 	"\x0eDeciderService\x12Z\n" +
-	"\x11ShouldSendMessage\x12!.decider.ShouldSendMessageRequest\x1a\".decider.ShouldSendMessageResponseB3Z1github.com/ryt-io/icm-services/proto/pb/deciderb\x06proto3"
+	"\x11ShouldSendMessage\x12!.decider.ShouldSendMessageRequest\x1a\".decider.ShouldSendMessageResponse\x12`\n" +
+	"\x13ShouldSendMessageV2\x12#.decider.ShouldSendMessageV2Request\x1a$.decider.ShouldSendMessageV2ResponseB1Z/github.com/ryt-io/icm-services/proto/pb/deciderb\x06proto3"
 
 var (
 	file_decider_decider_proto_rawDescOnce sync.Once
@@ -170,19 +531,27 @@ func file_decider_decider_proto_rawDescGZIP() []byte {
 	return file_decider_decider_proto_rawDescData
 }
 
-var file_decider_decider_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_decider_decider_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_decider_decider_proto_goTypes = []any{
-	(*ShouldSendMessageRequest)(nil),  // 0: decider.ShouldSendMessageRequest
-	(*ShouldSendMessageResponse)(nil), // 1: decider.ShouldSendMessageResponse
+	(*ShouldSendMessageRequest)(nil),    // 0: decider.ShouldSendMessageRequest
+	(*ShouldSendMessageResponse)(nil),   // 1: decider.ShouldSendMessageResponse
+	(*MessageRoutingInfo)(nil),          // 2: decider.MessageRoutingInfo
+	(*FeeInfo)(nil),                     // 3: decider.FeeInfo
+	(*ShouldSendMessageV2Request)(nil),  // 4: decider.ShouldSendMessageV2Request
+	(*ShouldSendMessageV2Response)(nil), // 5: decider.ShouldSendMessageV2Response
 }
 var file_decider_decider_proto_depIdxs = []int32{
-	0, // 0: decider.DeciderService.ShouldSendMessage:input_type -> decider.ShouldSendMessageRequest
-	1, // 1: decider.DeciderService.ShouldSendMessage:output_type -> decider.ShouldSendMessageResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: decider.ShouldSendMessageV2Request.routing_info:type_name -> decider.MessageRoutingInfo
+	3, // 1: decider.ShouldSendMessageV2Request.fee_info:type_name -> decider.FeeInfo
+	0, // 2: decider.DeciderService.ShouldSendMessage:input_type -> decider.ShouldSendMessageRequest
+	4, // 3: decider.DeciderService.ShouldSendMessageV2:input_type -> decider.ShouldSendMessageV2Request
+	1, // 4: decider.DeciderService.ShouldSendMessage:output_type -> decider.ShouldSendMessageResponse
+	5, // 5: decider.DeciderService.ShouldSendMessageV2:output_type -> decider.ShouldSendMessageV2Response
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_decider_decider_proto_init() }
@@ -196,7 +565,7 @@ func file_decider_decider_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_decider_decider_proto_rawDesc), len(file_decider_decider_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,8 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	DeciderService_ShouldSendMessage_FullMethodName = "/decider.DeciderService/ShouldSendMessage"
+	DeciderService_ShouldSendMessage_FullMethodName   = "/decider.DeciderService/ShouldSendMessage"
+	DeciderService_ShouldSendMessageV2_FullMethodName = "/decider.DeciderService/ShouldSendMessageV2"
 )
 
 // DeciderServiceClient is the client API for DeciderService service.
@@ -27,6 +28,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type DeciderServiceClient interface {
 	ShouldSendMessage(ctx context.Context, in *ShouldSendMessageRequest, opts ...grpc.CallOption) (*ShouldSendMessageResponse, error)
+	ShouldSendMessageV2(ctx context.Context, in *ShouldSendMessageV2Request, opts ...grpc.CallOption) (*ShouldSendMessageV2Response, error)
 }
 
 type deciderServiceClient struct {
@@ -46,11 +48,21 @@ func (c *deciderServiceClient) ShouldSendMessage(ctx context.Context, in *Should
 	return out, nil
 }
 
+func (c *deciderServiceClient) ShouldSendMessageV2(ctx context.Context, in *ShouldSendMessageV2Request, opts ...grpc.CallOption) (*ShouldSendMessageV2Response, error) {
+	out := new(ShouldSendMessageV2Response)
+	err := c.cc.Invoke(ctx, DeciderService_ShouldSendMessageV2_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DeciderServiceServer is the server API for DeciderService service.
 // All implementations must embed UnimplementedDeciderServiceServer
 // for forward compatibility
 type DeciderServiceServer interface {
 	ShouldSendMessage(context.Context, *ShouldSendMessageRequest) (*ShouldSendMessageResponse, error)
+	ShouldSendMessageV2(context.Context, *ShouldSendMessageV2Request) (*ShouldSendMessageV2Response, error)
 	mustEmbedUnimplementedDeciderServiceServer()
 }
 
@@ -61,6 +73,9 @@ type UnimplementedDeciderServiceServer struct {
 func (UnimplementedDeciderServiceServer) ShouldSendMessage(context.Context, *ShouldSendMessageRequest) (*ShouldSendMessageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ShouldSendMessage not implemented")
 }
+func (UnimplementedDeciderServiceServer) ShouldSendMessageV2(context.Context, *ShouldSendMessageV2Request) (*ShouldSendMessageV2Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShouldSendMessageV2 not implemented")
+}
 func (UnimplementedDeciderServiceServer) mustEmbedUnimplementedDeciderServiceServer() {}
 
 // UnsafeDeciderServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -92,6 +107,24 @@ func _DeciderService_ShouldSendMessage_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DeciderService_ShouldSendMessageV2_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShouldSendMessageV2Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeciderServiceServer).ShouldSendMessageV2(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeciderService_ShouldSendMessageV2_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeciderServiceServer).ShouldSendMessageV2(ctx, req.(*ShouldSendMessageV2Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DeciderService_ServiceDesc is the grpc.ServiceDesc for DeciderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -103,6 +136,10 @@ var DeciderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ShouldSendMessage",
 			Handler:    _DeciderService_ShouldSendMessage_Handler,
 		},
+		{
+			MethodName: "ShouldSendMessageV2",
+			Handler:    _DeciderService_ShouldSendMessageV2_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "decider/decider.proto",
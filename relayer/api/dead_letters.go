@@ -0,0 +1,157 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/relayer"
+	"github.com/ryt-io/icm-services/utils"
+	"github.com/ryt-io/libevm/common"
+	"go.uber.org/zap"
+)
+
+const (
+	DeadLettersAPIPath        = RelayAPIPath + "/dead-letters"
+	DeadLettersRetryAPIPath   = DeadLettersAPIPath + "/retry"
+	DeadLettersDiscardAPIPath = DeadLettersAPIPath + "/discard"
+)
+
+// Identifies a single message in the dead-letter queue
+type DeadLetterRequest struct {
+	// Required. "0x" prefixed hex-encoded relayer ID of the application relayer that failed to relay the message
+	RelayerID string `json:"relayer-id"`
+	// Required. cb58-encoded or "0x" prefixed hex-encoded warp message ID
+	MessageID string `json:"message-id"`
+}
+
+func HandleDeadLetters(logger logging.Logger, messageCoordinator *relayer.MessageCoordinator) {
+	http.Handle(DeadLettersAPIPath, listDeadLettersAPIHandler(logger, messageCoordinator))
+	http.Handle(DeadLettersRetryAPIPath, retryDeadLetterAPIHandler(logger, messageCoordinator))
+	http.Handle(DeadLettersDiscardAPIPath, discardDeadLetterAPIHandler(logger, messageCoordinator))
+}
+
+func listDeadLettersAPIHandler(logger logging.Logger, messageCoordinator *relayer.MessageCoordinator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadLetters, err := messageCoordinator.DeadLetters()
+		if err != nil {
+			logger.Error("Error listing dead letters", zap.Error(err))
+			http.Error(w, "error listing dead letters: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp, err := json.Marshal(deadLetters)
+		if err != nil {
+			logger.Error("Error marshalling response", zap.Error(err))
+			http.Error(w, "error marshalling response: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		_, err = w.Write(resp)
+		if err != nil {
+			logger.Error("Error writing response", zap.Error(err))
+		}
+	})
+}
+
+func retryDeadLetterAPIHandler(logger logging.Logger, messageCoordinator *relayer.MessageCoordinator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		relayerID, messageID, ok := decodeDeadLetterRequest(logger, w, r)
+		if !ok {
+			return
+		}
+
+		logger.Info(
+			"Retrying dead letter",
+			zap.Stringer("relayerID", relayerID),
+			zap.Stringer("messageID", messageID),
+		)
+		txHash, err := messageCoordinator.RetryDeadLetter(relayerID, messageID)
+		if errors.Is(err, database.ErrDeadLetterNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Error retrying dead letter", zap.Error(err))
+			http.Error(w, "error processing message: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp, err := json.Marshal(
+			RelayMessageResponse{
+				TransactionHash: txHash.Hex(),
+			},
+		)
+		if err != nil {
+			logger.Error("Error marshalling response", zap.Error(err))
+			http.Error(w, "error marshalling response: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		_, err = w.Write(resp)
+		if err != nil {
+			logger.Error("Error writing response", zap.Error(err))
+		}
+	})
+}
+
+func discardDeadLetterAPIHandler(logger logging.Logger, messageCoordinator *relayer.MessageCoordinator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		relayerID, messageID, ok := decodeDeadLetterRequest(logger, w, r)
+		if !ok {
+			return
+		}
+
+		logger.Info(
+			"Discarding dead letter",
+			zap.Stringer("relayerID", relayerID),
+			zap.Stringer("messageID", messageID),
+		)
+		err := messageCoordinator.DiscardDeadLetter(relayerID, messageID)
+		if errors.Is(err, database.ErrDeadLetterNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Error discarding dead letter", zap.Error(err))
+			http.Error(w, "error discarding dead letter: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Decodes and validates a DeadLetterRequest. Writes an error response and returns false if the request is invalid.
+func decodeDeadLetterRequest(
+	logger logging.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+) (common.Hash, ids.ID, bool) {
+	var req DeadLetterRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Warn("Could not decode request body")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return common.Hash{}, ids.ID{}, false
+	}
+
+	relayerIDBytes, err := utils.HexOrCB58ToID(req.RelayerID)
+	if err != nil {
+		logger.Warn("Invalid relayerID", zap.String("relayerID", req.RelayerID))
+		http.Error(w, "invalid relayerID: "+err.Error(), http.StatusBadRequest)
+		return common.Hash{}, ids.ID{}, false
+	}
+	messageID, err := utils.HexOrCB58ToID(req.MessageID)
+	if err != nil {
+		logger.Warn("Invalid messageID", zap.String("messageID", req.MessageID))
+		http.Error(w, "invalid messageID: "+err.Error(), http.StatusBadRequest)
+		return common.Hash{}, ids.ID{}, false
+	}
+	return common.Hash(relayerIDBytes), messageID, true
+}
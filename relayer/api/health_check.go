@@ -16,16 +16,23 @@ const HealthAPIPath = "/health"
 
 func HandleHealthCheck(
 	logger logging.Logger,
-	relayerHealth map[ids.ID]*atomic.Bool,
+	relayerHealth func() map[ids.ID]*atomic.Bool,
 	networkHealth func(context.Context) error,
+	destinationHealth func(context.Context) error,
+	deciderHealth func(context.Context) error,
 ) {
-	http.Handle(HealthAPIPath, healthCheckHandler(logger, relayerHealth, networkHealth))
+	http.Handle(
+		HealthAPIPath,
+		healthCheckHandler(logger, relayerHealth, networkHealth, destinationHealth, deciderHealth),
+	)
 }
 
 func healthCheckHandler(
 	logger logging.Logger,
-	relayerHealth map[ids.ID]*atomic.Bool,
+	relayerHealth func() map[ids.ID]*atomic.Bool,
 	networkHealth func(context.Context) error,
+	destinationHealth func(context.Context) error,
+	deciderHealth func(context.Context) error,
 ) http.Handler {
 	return health.NewHandler(health.NewChecker(
 		health.WithCheck(health.Check{
@@ -33,7 +40,7 @@ func healthCheckHandler(
 			Check: func(context.Context) error {
 				// Store the IDs as the cb58 encoding
 				var unhealthyRelayers []string
-				for id, health := range relayerHealth {
+				for id, health := range relayerHealth() {
 					if !health.Load() {
 						logger.Error("Unhealthy relayer", zap.Stringer("relayerID", id))
 						unhealthyRelayers = append(unhealthyRelayers, id.String())
@@ -50,5 +57,13 @@ func healthCheckHandler(
 			Name:  "network-all",
 			Check: networkHealth,
 		}),
+		health.WithCheck(health.Check{
+			Name:  "destinations-all",
+			Check: destinationHealth,
+		}),
+		health.WithCheck(health.Check{
+			Name:  "decider",
+			Check: deciderHealth,
+		}),
 	))
 }
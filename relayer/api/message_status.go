@@ -0,0 +1,61 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	RelayStatusAPIPath = RelayAPIPath + "/status"
+
+	// Query parameter containing the cb58-encoded or "0x" prefixed hex-encoded Warp or protocol message ID
+	messageIDQueryParam = "message-id"
+)
+
+func HandleMessageStatus(logger logging.Logger, messageStatusStore *database.MessageStatusStore) {
+	http.Handle(RelayStatusAPIPath, messageStatusAPIHandler(logger, messageStatusStore))
+}
+
+func messageStatusAPIHandler(logger logging.Logger, messageStatusStore *database.MessageStatusStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		messageIDString := r.URL.Query().Get(messageIDQueryParam)
+		messageID, err := utils.HexOrCB58ToID(messageIDString)
+		if err != nil {
+			logger.Warn("Invalid messageID", zap.String("messageID", messageIDString))
+			http.Error(w, "invalid messageID: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		status, err := messageStatusStore.Get(messageID)
+		if errors.Is(err, database.ErrMessageStatusNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Error getting message status", zap.Error(err))
+			http.Error(w, "error getting message status: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp, err := json.Marshal(status)
+		if err != nil {
+			logger.Error("Error marshalling response", zap.Error(err))
+			http.Error(w, "error marshalling response: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		_, err = w.Write(resp)
+		if err != nil {
+			logger.Error("Error writing response", zap.Error(err))
+		}
+	})
+}
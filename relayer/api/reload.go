@@ -0,0 +1,35 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"go.uber.org/zap"
+)
+
+const ReloadAPIPath = "/admin/reload"
+
+// HandleReload registers a handler that reloads the relayer configuration from the configuration file.
+func HandleReload(logger logging.Logger, reload func() error) {
+	http.Handle(ReloadAPIPath, reloadAPIHandler(logger, reload))
+}
+
+func reloadAPIHandler(logger logging.Logger, reload func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		logger.Info("Reloading configuration via API")
+		if err := reload(); err != nil {
+			logger.Error("Error reloading configuration", zap.Error(err))
+			http.Error(w, "error reloading configuration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -5,20 +5,26 @@ package relayer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/constants"
 	"github.com/ryt-io/ryt-v2/utils/logging"
 	avalancheWarp "github.com/ryt-io/ryt-v2/vms/platformvm/warp"
+	warpPayload "github.com/ryt-io/ryt-v2/vms/platformvm/warp/payload"
 	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/events"
 	"github.com/ryt-io/icm-services/messages"
 	"github.com/ryt-io/icm-services/peers"
 	"github.com/ryt-io/icm-services/relayer/config"
 	"github.com/ryt-io/icm-services/signature-aggregator/aggregator"
 	"github.com/ryt-io/icm-services/utils"
 	"github.com/ryt-io/icm-services/vms"
+	"github.com/ryt-io/icm-services/vms/evm"
 	"github.com/ryt-io/libevm/common"
 	"github.com/ryt-io/libevm/common/hexutil"
 	"github.com/ryt-io/libevm/rpc"
@@ -34,8 +40,15 @@ const (
 	// quorum. This allows for small weight changes in between the time the signature is constructed and the time
 	// it is verified to not cause the verification to fail.
 	defaultQuorumPercentageBuffer = uint64(3)
+
+	// The skip reason of messages for which the message handler did not send a transaction
+	notSentSkipReason = "transaction not sent"
 )
 
+// errApplicationRelayerStopped is returned for messages that were waiting for a spend limit to reset when the
+// application relayer was stopped
+var errApplicationRelayerStopped = errors.New("application relayer stopped")
+
 // CheckpointManager stores committed heights in the database
 type CheckpointManager interface {
 	// Run starts a go routine that periodically stores the last committed height in the Database
@@ -63,6 +76,15 @@ type ApplicationRelayer struct {
 	sourceWarpSignatureClient *rpc.Client // nil if configured to fetch signatures via AppRequest
 	signatureAggregator       *aggregator.SignatureAggregator
 	processMessageSemaphore   chan struct{}
+	deadLetterQueue           *database.DeadLetterQueue // nil if the dead-letter queue is disabled
+	messageStatusStore        *database.MessageStatusStore
+	eventSink                 events.Sink // nil if no event sinks are configured
+	// Tracks the messages being processed, so that the destination client is not replaced while in use.
+	// Incremented by the MessageCoordinator while it routes to the application relayer.
+	inFlight sync.WaitGroup
+	// Closed by stop, to stop waiting for spend limits to reset
+	stopped  chan struct{}
+	stopOnce sync.Once
 }
 
 func NewApplicationRelayer(
@@ -76,6 +98,9 @@ func NewApplicationRelayer(
 	cfg *config.Config,
 	signatureAggregator *aggregator.SignatureAggregator,
 	processMessageSemaphore chan struct{},
+	deadLetterQueue *database.DeadLetterQueue,
+	messageStatusStore *database.MessageStatusStore,
+	eventSink events.Sink,
 ) (*ApplicationRelayer, error) {
 	warpConfig, err := cfg.GetWarpConfig(relayerID.DestinationBlockchainID)
 	if err != nil {
@@ -130,13 +155,27 @@ func NewApplicationRelayer(
 		sourceWarpSignatureClient: warpClient,
 		signatureAggregator:       signatureAggregator,
 		processMessageSemaphore:   processMessageSemaphore,
+		deadLetterQueue:           deadLetterQueue,
+		messageStatusStore:        messageStatusStore,
+		eventSink:                 eventSink,
+		stopped:                   make(chan struct{}),
 	}
 
 	return &ar, nil
 }
 
+// stop stops waiting for spend limits to reset. The messages that were waiting are neither relayed nor
+// checkpointed, so they are processed again when their heights are.
+func (r *ApplicationRelayer) stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopped)
+	})
+}
+
 // Process [msgs] at height [height] by relaying each message to the destination chain.
 // Checkpoints the height with the checkpoint manager when all messages are relayed.
+// If the dead-letter queue is enabled, messages that fail to be relayed are added to it
+// and the height is still checkpointed.
 // ProcessHeight is expected to be called for every block greater than or equal to the
 // [startingHeight] provided in the constructor.
 func (r *ApplicationRelayer) ProcessHeight(
@@ -159,19 +198,87 @@ func (r *ApplicationRelayer) ProcessHeight(
 			defer func() {
 				<-r.processMessageSemaphore
 			}()
-			_, err := r.ProcessMessage(handler)
+			_, err := r.processMessageWhenAllowed(handler)
+			if errors.Is(err, errApplicationRelayerStopped) {
+				return err
+			}
+			if err != nil && r.deadLetterQueue != nil {
+				return r.addDeadLetter(height, handler, err)
+			}
+			if errors.Is(err, messages.ErrMessageUndeliverable) {
+				// Undeliverable messages are skipped if they can not be dead-lettered
+				handler.LoggerWithContext(r.logger).Warn("Skipping undeliverable message", zap.Error(err))
+				return nil
+			}
 			return err
 		})
 	}
 	if err := eg.Wait(); err != nil {
+		if errors.Is(err, errApplicationRelayerStopped) {
+			logger.Info("Stopped processing block")
+			return
+		}
 		logger.Error("Failed to process block", zap.Error(err))
-		errChan <- err
+		// The listener exits on the first error it receives, so the error is dropped if the channel is full
+		select {
+		case errChan <- err:
+		default:
+		}
 		return
 	}
 	r.checkpointManager.StageCommittedHeight(height)
 	logger.Verbo("Processed block")
 }
 
+// processMessageWhenAllowed relays a message like ProcessMessage, but if a spend limit of the destination
+// is exhausted, or the message handler deferred the message, it waits until the limit resets or the message
+// may be sent, and tries again. The process message semaphore, which must be held by the caller, is released
+// while waiting, so that messages of other routes are not blocked.
+func (r *ApplicationRelayer) processMessageWhenAllowed(handler messages.MessageHandler) (common.Hash, error) {
+	logger := handler.LoggerWithContext(r.logger)
+	r.observeMessage(handler)
+	for {
+		txHash, err := r.processMessageWithRetries(handler)
+		var (
+			exhausted *evm.SpendLimitExhaustedError
+			deferred  *messages.MessageDeferredError
+			retryAt   time.Time
+		)
+		switch {
+		case errors.As(err, &exhausted):
+			logger.Warn(
+				"Spend limit reached, pausing message until it resets",
+				zap.Stringer("period", exhausted.Period),
+				zap.Stringer("maxSpend", exhausted.MaxSpend),
+				zap.Time("resetAt", exhausted.ResetAt),
+			)
+			retryAt = exhausted.ResetAt
+		case errors.As(err, &deferred):
+			logger.Info(
+				"Deferring message",
+				zap.String("reason", deferred.Reason),
+				zap.Time("retryAt", deferred.RetryAt),
+			)
+			retryAt = deferred.RetryAt
+		default:
+			return txHash, err
+		}
+		<-r.processMessageSemaphore
+		timer := time.NewTimer(time.Until(retryAt))
+		select {
+		case <-timer.C:
+		case <-r.stopped:
+			timer.Stop()
+		}
+		r.processMessageSemaphore <- struct{}{}
+		select {
+		case <-r.stopped:
+			return common.Hash{}, errApplicationRelayerStopped
+		default:
+		}
+	}
+}
+
 // Relays a message to the destination chain. Does not checkpoint the height.
 // returns the transaction hash if the message is successfully relayed.
 func (r *ApplicationRelayer) processMessage(
@@ -180,12 +287,27 @@ func (r *ApplicationRelayer) processMessage(
 ) (common.Hash, error) {
 	logger.Info("Relaying message")
 	shouldSend, err := handler.ShouldSendMessage()
+	var deferred *messages.MessageDeferredError
+	if errors.As(err, &deferred) {
+		// The message has not failed, and is processed again once it may be sent
+		return common.Hash{}, err
+	}
 	if err != nil {
 		r.incFailedRelayMessageCount("failed to check if message should be sent")
 		return common.Hash{}, fmt.Errorf("failed to check if message should be sent: %w", err)
 	}
 	if !shouldSend {
-		logger.Info("Message should not be sent")
+		var skipReason, skipDetail string
+		if skipReasonHandler, ok := handler.(messages.SkipReasonHandler); ok {
+			skipReason = skipReasonHandler.GetSkipReason()
+			skipDetail = skipReasonHandler.GetSkipDetail()
+		}
+		logger.Info(
+			"Message should not be sent",
+			zap.String("skipReason", skipReason),
+			zap.String("skipDetail", skipDetail),
+		)
+		r.skipMessage(handler, skipReason, skipDetail)
 		return common.Hash{}, nil
 	}
 	unsignedMessage := handler.GetUnsignedMessage()
@@ -236,22 +358,63 @@ func (r *ApplicationRelayer) processMessage(
 
 	// create signed message latency (ms)
 	r.setCreateSignedMessageLatencyMS(float64(time.Since(startCreateSignedMessageTime).Milliseconds()))
+	r.setMessageState(handler, database.MessageStateSignaturesAggregated, common.Hash{}, nil)
+	r.emitEvent(r.newEvent(handler, events.MessageSigned, common.Hash{}, nil))
 
-	txHash, err := handler.SendMessage(signedMessage)
+	txHash, err := handler.SendMessage(signedMessage, func(txHash common.Hash) {
+		r.setMessageState(handler, database.MessageStateTxSent, txHash, nil)
+		r.emitEvent(r.newEvent(handler, events.TxSent, txHash, nil))
+	})
 	if err != nil {
 		r.incFailedRelayMessageCount("failed to send warp message")
 		return common.Hash{}, fmt.Errorf("failed to send warp message: %w", err)
 	}
+	if txHash == (common.Hash{}) {
+		// The message handler decided not to send a transaction, for example because the preflight simulation
+		// found that the message was already delivered
+		logger.Info("Message was not sent")
+		r.skipMessage(handler, notSentSkipReason, "")
+		return common.Hash{}, nil
+	}
+	if dryRunTx, ok := r.dryRunTransaction(txHash); ok {
+		logger.Info(
+			"Recorded delivery transaction instead of sending it in dry-run mode",
+			zap.Stringer("txID", txHash),
+		)
+		status := r.newMessageStatus(handler, database.MessageStateDryRun, txHash, nil)
+		status.DryRun = dryRunTx
+		r.storeMessageStatus(status)
+		// No delivered event is emitted, since the transaction was not sent
+		r.incSuccessfulRelayMessageCount()
+		r.incRelayedMessagePriorityCount(handler)
+		return txHash, nil
+	}
 	logger.Info(
 		"Finished relaying message to destination chain",
 		zap.Stringer("txID", txHash),
 	)
+	r.setMessageState(handler, database.MessageStateDelivered, txHash, nil)
+	r.emitEvent(r.newEvent(handler, events.MessageDelivered, txHash, nil))
 	r.incSuccessfulRelayMessageCount()
+	r.incRelayedMessagePriorityCount(handler)
 
 	return txHash, nil
 }
 
 func (r *ApplicationRelayer) ProcessMessage(handler messages.MessageHandler) (common.Hash, error) {
+	r.observeMessage(handler)
+	return r.processMessageWithRetries(handler)
+}
+
+// observeMessage records that the message was seen, and emits the observed event. Called once per message
+// handler, however many times the message is processed.
+func (r *ApplicationRelayer) observeMessage(handler messages.MessageHandler) {
+	r.setMessageState(handler, database.MessageStateSeen, common.Hash{}, nil)
+	r.emitEvent(r.newEvent(handler, events.MessageObserved, common.Hash{}, nil))
+}
+
+// processMessageWithRetries relays a message like ProcessMessage, without recording that it was seen
+func (r *ApplicationRelayer) processMessageWithRetries(handler messages.MessageHandler) (common.Hash, error) {
 	logger := handler.LoggerWithContext(r.logger)
 	var err error
 	// Retry processing the message if it fails to account for cases where the signature is successfully aggregated
@@ -273,11 +436,189 @@ func (r *ApplicationRelayer) ProcessMessage(handler messages.MessageHandler) (co
 			zap.Int64("latencyMS", time.Since(startProcessMessageTime).Milliseconds()),
 			zap.Error(err),
 		)
+		if errors.Is(err, messages.ErrMessageUndeliverable) {
+			// Retrying would fail in the same way
+			break
+		}
+		var (
+			exhausted *evm.SpendLimitExhaustedError
+			deferred  *messages.MessageDeferredError
+		)
+		if errors.As(err, &exhausted) || errors.As(err, &deferred) {
+			// The message has not failed, and can be relayed once the spend limit resets or it is no longer deferred
+			return common.Hash{}, err
+		}
 	}
 	r.logger.Error("failed to process message after max retries", zap.Error(err))
+	r.setMessageState(handler, database.MessageStateFailed, common.Hash{}, err)
+	r.emitEvent(r.newEvent(handler, events.MessageFailed, common.Hash{}, err))
 	return common.Hash{}, err
 }
 
+// setMessageState records the message's delivery state in the message status store
+func (r *ApplicationRelayer) setMessageState(
+	handler messages.MessageHandler,
+	state database.MessageState,
+	txHash common.Hash,
+	processErr error,
+) {
+	if r.messageStatusStore == nil {
+		return
+	}
+	r.storeMessageStatus(r.newMessageStatus(handler, state, txHash, processErr))
+}
+
+// skipMessage records that the message of [handler] was skipped. The metric is labeled with [skipReason] only,
+// while the status and event also include [skipDetail].
+func (r *ApplicationRelayer) skipMessage(handler messages.MessageHandler, skipReason string, skipDetail string) {
+	reason := skipReason
+	if skipDetail != "" {
+		reason += ": " + skipDetail
+	}
+	status := r.newMessageStatus(handler, database.MessageStateSkipped, common.Hash{}, nil)
+	status.Reason = reason
+	r.storeMessageStatus(status)
+	r.incSkippedMessageCount(skipReason)
+	event := r.newEvent(handler, events.MessageSkipped, common.Hash{}, nil)
+	event.Reason = reason
+	r.emitEvent(event)
+}
+
+func (r *ApplicationRelayer) newMessageStatus(
+	handler messages.MessageHandler,
+	state database.MessageState,
+	txHash common.Hash,
+	processErr error,
+) database.MessageStatus {
+	unsignedMessage := handler.GetUnsignedMessage()
+	status := database.MessageStatus{
+		RelayerID:               r.relayerID.ID,
+		WarpMessageID:           unsignedMessage.ID(),
+		SourceBlockchainID:      unsignedMessage.SourceChainID,
+		DestinationBlockchainID: r.relayerID.DestinationBlockchainID,
+		State:                   state,
+	}
+	if protocolHandler, ok := handler.(messages.ProtocolMessageIDHandler); ok {
+		protocolMessageID := protocolHandler.GetProtocolMessageID()
+		status.ProtocolMessageID = &protocolMessageID
+	}
+	if txHash != (common.Hash{}) {
+		status.TransactionHash = txHash.Hex()
+	}
+	if processErr != nil {
+		status.Error = processErr.Error()
+	}
+	return status
+}
+
+func (r *ApplicationRelayer) storeMessageStatus(status database.MessageStatus) {
+	if r.messageStatusStore == nil {
+		return
+	}
+	r.messageStatusStore.SetState(status)
+}
+
+func (r *ApplicationRelayer) newEvent(
+	handler messages.MessageHandler,
+	eventType events.EventType,
+	txHash common.Hash,
+	processErr error,
+) events.Event {
+	unsignedMessage := handler.GetUnsignedMessage()
+	event := events.Event{
+		Type:                    eventType,
+		Timestamp:               time.Now().UTC(),
+		RelayerID:               r.relayerID.ID,
+		WarpMessageID:           unsignedMessage.ID(),
+		SourceBlockchainID:      r.relayerID.SourceBlockchainID,
+		DestinationBlockchainID: r.relayerID.DestinationBlockchainID,
+	}
+	if protocolHandler, ok := handler.(messages.ProtocolMessageIDHandler); ok {
+		protocolMessageID := protocolHandler.GetProtocolMessageID()
+		event.ProtocolMessageID = &protocolMessageID
+	}
+	if txHash != (common.Hash{}) {
+		event.TransactionHash = txHash.Hex()
+	}
+	if processErr != nil {
+		event.Error = processErr.Error()
+	}
+	return event
+}
+
+// emitEvent emits [event] to the configured event sinks
+func (r *ApplicationRelayer) emitEvent(event events.Event) {
+	if r.eventSink == nil {
+		return
+	}
+	r.eventSink.Emit(event)
+}
+
+// dryRunTransaction returns the transaction with [txHash] that the destination client recorded instead of
+// sending it, if it is in dry-run mode
+func (r *ApplicationRelayer) dryRunTransaction(txHash common.Hash) (*database.DryRunTransaction, bool) {
+	recorder, ok := r.destinationClient.(vms.DryRunRecorder)
+	if !ok {
+		return nil, false
+	}
+	tx, signerAddress, ok := recorder.DryRunTransaction(txHash)
+	if !ok {
+		return nil, false
+	}
+	return &database.DryRunTransaction{
+		SignerAddress: signerAddress,
+		ToAddress:     *tx.To(),
+		GasLimit:      tx.Gas(),
+		CallData:      tx.Data(),
+		GasFeeCap:     tx.GasFeeCap(),
+		EstimatedFee:  new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap()),
+	}, true
+}
+
+// addDeadLetter persists a message that exhausted its retries to the dead-letter queue.
+// The processing error is only returned if the message could not be persisted.
+func (r *ApplicationRelayer) addDeadLetter(
+	height uint64,
+	handler messages.MessageHandler,
+	processErr error,
+) error {
+	logger := handler.LoggerWithContext(r.logger)
+	unsignedMessage := handler.GetUnsignedMessage()
+
+	// The protocol address is the sender of the addressed call, and is needed to route the message on retry.
+	addressedPayload, err := warpPayload.ParseAddressedCall(unsignedMessage.Payload)
+	if err != nil {
+		logger.Error("Failed to parse addressed payload for dead letter", zap.Error(err))
+		return processErr
+	}
+
+	now := time.Now().UTC()
+	err = r.deadLetterQueue.Add(database.DeadLetter{
+		RelayerID:               r.relayerID.ID,
+		WarpMessageID:           unsignedMessage.ID(),
+		SourceBlockchainID:      unsignedMessage.SourceChainID,
+		DestinationBlockchainID: r.relayerID.DestinationBlockchainID,
+		SourceAddress:           common.BytesToAddress(addressedPayload.SourceAddress),
+		Height:                  height,
+		UnsignedMessageBytes:    unsignedMessage.Bytes(),
+		LastError:               processErr.Error(),
+		Attempts:                maxRetryCount,
+		FirstFailedAt:           now,
+		LastFailedAt:            now,
+	})
+	if err != nil {
+		logger.Error("Failed to add message to dead-letter queue", zap.Error(err))
+		return processErr
+	}
+	logger.Warn(
+		"Added message to dead-letter queue",
+		zap.Uint64("height", height),
+		zap.Error(processErr),
+	)
+	r.incDeadLetterMessageCount()
+	return nil
+}
+
 // createSignedMessage fetches the signed Warp message from the source chain via RPC.
 // Each VM may implement their own RPC method to construct the aggregate signature, which
 // will need to be accounted for here.
@@ -362,6 +703,53 @@ func (r *ApplicationRelayer) incFetchSignatureRPCCount() {
 		).Inc()
 }
 
+func (r *ApplicationRelayer) incDeadLetterMessageCount() {
+	r.metrics.deadLetterMessageCount.
+		WithLabelValues(
+			r.relayerID.DestinationBlockchainID.String(),
+			r.sourceBlockchain.GetBlockchainID().String(),
+			r.sourceBlockchain.GetSubnetID().String(),
+		).Inc()
+}
+
+func (r *ApplicationRelayer) incSkippedMessageCount(skipReason string) {
+	r.metrics.skippedMessageCount.
+		WithLabelValues(
+			r.relayerID.DestinationBlockchainID.String(),
+			r.sourceBlockchain.GetBlockchainID().String(),
+			r.sourceBlockchain.GetSubnetID().String(),
+			skipReason,
+		).Inc()
+}
+
+// incRelayedMessagePriorityCount counts a relayed message by the bucket of the priority assigned to it,
+// if its message protocol assigns priorities
+func (r *ApplicationRelayer) incRelayedMessagePriorityCount(handler messages.MessageHandler) {
+	priorityHandler, ok := handler.(messages.PriorityHandler)
+	if !ok {
+		return
+	}
+	r.metrics.relayedMessagePriorityCount.
+		WithLabelValues(
+			r.relayerID.DestinationBlockchainID.String(),
+			r.sourceBlockchain.GetBlockchainID().String(),
+			r.sourceBlockchain.GetSubnetID().String(),
+			priorityBucket(priorityHandler.GetPriority()),
+		).Inc()
+}
+
+// priorityBucket returns the metric label of [priority], so that the label has a fixed set of values
+func priorityBucket(priority int32) string {
+	switch {
+	case priority < 0:
+		return "low"
+	case priority > 0:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
 func (r *ApplicationRelayer) incFetchSignatureAppRequestCount() {
 	r.metrics.fetchSignatureAppRequestCount.
 		WithLabelValues(
@@ -13,6 +13,9 @@ type ApplicationRelayerMetrics struct {
 	failedRelayMessageCount       *prometheus.CounterVec
 	fetchSignatureAppRequestCount *prometheus.CounterVec
 	fetchSignatureRPCCount        *prometheus.CounterVec
+	deadLetterMessageCount        *prometheus.CounterVec
+	skippedMessageCount           *prometheus.CounterVec
+	relayedMessagePriorityCount   *prometheus.CounterVec
 }
 
 func NewApplicationRelayerMetrics(registerer prometheus.Registerer) *ApplicationRelayerMetrics {
@@ -52,6 +55,27 @@ func NewApplicationRelayerMetrics(registerer prometheus.Registerer) *Application
 			},
 			[]string{"destination_chain_id", "source_chain_id", "source_subnet_id"},
 		),
+		deadLetterMessageCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "dead_letter_message_count",
+				Help: "Number of messages added to the dead-letter queue after exhausting retries",
+			},
+			[]string{"destination_chain_id", "source_chain_id", "source_subnet_id"},
+		),
+		skippedMessageCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "skipped_message_count",
+				Help: "Number of messages that were not relayed because they should not be sent",
+			},
+			[]string{"destination_chain_id", "source_chain_id", "source_subnet_id", "skip_reason"},
+		),
+		relayedMessagePriorityCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "relayed_message_priority_count",
+				Help: "Number of messages that relayed successfully, by the priority assigned to them",
+			},
+			[]string{"destination_chain_id", "source_chain_id", "source_subnet_id", "priority"},
+		),
 	}
 
 	registerer.MustRegister(m.successfulRelayMessageCount)
@@ -59,6 +83,9 @@ func NewApplicationRelayerMetrics(registerer prometheus.Registerer) *Application
 	registerer.MustRegister(m.failedRelayMessageCount)
 	registerer.MustRegister(m.fetchSignatureAppRequestCount)
 	registerer.MustRegister(m.fetchSignatureRPCCount)
+	registerer.MustRegister(m.deadLetterMessageCount)
+	registerer.MustRegister(m.skippedMessageCount)
+	registerer.MustRegister(m.relayedMessagePriorityCount)
 
 	return &m
 }
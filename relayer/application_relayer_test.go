@@ -0,0 +1,311 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package relayer
+
+import (
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/vms/platformvm/warp"
+	warpPayload "github.com/ryt-io/ryt-v2/vms/platformvm/warp/payload"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/decider"
+	"github.com/ryt-io/icm-services/events"
+	"github.com/ryt-io/icm-services/messages"
+	"github.com/ryt-io/icm-services/messages/teleporter"
+	"github.com/ryt-io/icm-services/relayer/config"
+	mock_evm "github.com/ryt-io/icm-services/vms/evm/mocks"
+	mock_vms "github.com/ryt-io/icm-services/vms/mocks"
+	"github.com/ryt-io/libevm/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// testEventSink records the events emitted to it
+type testEventSink struct {
+	lock   sync.Mutex
+	events []events.Event
+}
+
+func (s *testEventSink) Emit(event events.Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (*testEventSink) Close() error {
+	return nil
+}
+
+func (s *testEventSink) eventTypes() []events.EventType {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	eventTypes := make([]events.EventType, len(s.events))
+	for i, event := range s.events {
+		eventTypes[i] = event.Type
+	}
+	return eventTypes
+}
+
+// testMessageHandler returns the results of [shouldSend] from successive calls to ShouldSendMessage
+type testMessageHandler struct {
+	unsignedMessage *warp.UnsignedMessage
+	shouldSend      []func() (bool, error)
+	calls           int
+}
+
+func (h *testMessageHandler) ShouldSendMessage() (bool, error) {
+	result := h.shouldSend[h.calls]
+	h.calls++
+	return result()
+}
+
+func (*testMessageHandler) SendMessage(*warp.Message, func(common.Hash)) (common.Hash, error) {
+	return common.Hash{}, nil
+}
+
+func (*testMessageHandler) LoggerWithContext(logger logging.Logger) logging.Logger {
+	return logger
+}
+
+func (h *testMessageHandler) GetUnsignedMessage() *warp.UnsignedMessage {
+	return h.unsignedMessage
+}
+
+// testSkippingMessageHandler is a testMessageHandler that reports why its message should not be sent
+type testSkippingMessageHandler struct {
+	*testMessageHandler
+	skipReason string
+	skipDetail string
+}
+
+func (h *testSkippingMessageHandler) GetSkipReason() string {
+	return h.skipReason
+}
+
+func (h *testSkippingMessageHandler) GetSkipDetail() string {
+	return h.skipDetail
+}
+
+func newTestApplicationRelayer(eventSink events.Sink) *ApplicationRelayer {
+	return &ApplicationRelayer{
+		logger:  logging.NoLog{},
+		metrics: NewApplicationRelayerMetrics(prometheus.NewRegistry()),
+		relayerID: database.NewRelayerID(
+			ids.GenerateTestID(),
+			ids.GenerateTestID(),
+			database.AllAllowedAddress,
+			database.AllAllowedAddress,
+		),
+		processMessageSemaphore: make(chan struct{}, 1),
+		eventSink:               eventSink,
+		stopped:                 make(chan struct{}),
+	}
+}
+
+func newTestUnsignedMessage(t *testing.T) *warp.UnsignedMessage {
+	unsignedMessage, err := warp.NewUnsignedMessage(0, ids.GenerateTestID(), []byte{1, 2, 3})
+	require.NoError(t, err)
+	return unsignedMessage
+}
+
+func TestProcessMessageWhenAllowedObservesOnce(t *testing.T) {
+	eventSink := &testEventSink{}
+	appRelayer := newTestApplicationRelayer(eventSink)
+	deferred := func() (bool, error) {
+		return false, &messages.MessageDeferredError{RetryAt: time.Now(), Reason: "test"}
+	}
+	handler := &testMessageHandler{
+		unsignedMessage: newTestUnsignedMessage(t),
+		shouldSend: []func() (bool, error){
+			deferred,
+			deferred,
+			func() (bool, error) { return false, nil },
+		},
+	}
+
+	appRelayer.processMessageSemaphore <- struct{}{}
+	txHash, err := appRelayer.processMessageWhenAllowed(handler)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, txHash)
+	require.Equal(t, 3, handler.calls)
+	require.Equal(t, []events.EventType{events.MessageObserved, events.MessageSkipped}, eventSink.eventTypes())
+}
+
+func TestPriorityBucket(t *testing.T) {
+	testCases := []struct {
+		priority       int32
+		expectedBucket string
+	}{
+		{priority: -5, expectedBucket: "low"},
+		{priority: 0, expectedBucket: "normal"},
+		{priority: 3, expectedBucket: "high"},
+	}
+	for _, test := range testCases {
+		require.Equal(t, test.expectedBucket, priorityBucket(test.priority))
+	}
+}
+
+func TestProcessMessageSkipDetail(t *testing.T) {
+	eventSink := &testEventSink{}
+	appRelayer := newTestApplicationRelayer(eventSink)
+	db, err := database.NewJSONFileStorage(logging.NoLog{}, t.TempDir(), []database.RelayerID{appRelayer.relayerID})
+	require.NoError(t, err)
+	appRelayer.messageStatusStore, err = database.NewMessageStatusStore(
+		logging.NoLog{},
+		db,
+		[]database.RelayerID{appRelayer.relayerID},
+		1,
+	)
+	require.NoError(t, err)
+	handler := &testSkippingMessageHandler{
+		testMessageHandler: &testMessageHandler{
+			unsignedMessage: newTestUnsignedMessage(t),
+			shouldSend:      []func() (bool, error){func() (bool, error) { return false, nil }},
+		},
+		skipReason: "decider rejected message",
+		skipDetail: "sender is blocked",
+	}
+
+	txHash, err := appRelayer.ProcessMessage(handler)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, txHash)
+
+	// Only the fixed skip reason is used as the metric label
+	require.Equal(t, 1, testutil.CollectAndCount(appRelayer.metrics.skippedMessageCount))
+	require.Equal(t, float64(1), testutil.ToFloat64(appRelayer.metrics.skippedMessageCount.WithLabelValues(
+		appRelayer.relayerID.DestinationBlockchainID.String(),
+		appRelayer.sourceBlockchain.GetBlockchainID().String(),
+		appRelayer.sourceBlockchain.GetSubnetID().String(),
+		"decider rejected message",
+	)))
+
+	status, err := appRelayer.messageStatusStore.Get(handler.unsignedMessage.ID())
+	require.NoError(t, err)
+	require.Equal(t, database.MessageStateSkipped, status.State)
+	require.Equal(t, "decider rejected message: sender is blocked", status.Reason)
+
+	eventSink.lock.Lock()
+	defer eventSink.lock.Unlock()
+	require.Len(t, eventSink.events, 2)
+	require.Equal(t, events.MessageSkipped, eventSink.events[1].Type)
+	require.Equal(t, "decider rejected message: sender is blocked", eventSink.events[1].Reason)
+}
+
+// newTestTeleporterMessageHandler returns a handler of a Teleporter message that has not been delivered, and
+// whose delivery is decided by [deciderClient]
+func newTestTeleporterMessageHandler(t *testing.T, deciderClient *decider.Client) messages.MessageHandler {
+	protocolAddress := common.HexToAddress("0xd81545385803bCD83bd59f58Ba2d2c0562387F83")
+	destinationBlockchainID := ids.GenerateTestID()
+	teleporterMessage := teleportermessenger.TeleporterMessage{
+		MessageNonce:            big.NewInt(1),
+		OriginSenderAddress:     common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		DestinationBlockchainID: destinationBlockchainID,
+		DestinationAddress:      common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		RequiredGasLimit:        big.NewInt(2),
+		Message:                 []byte{1, 2, 3, 4},
+	}
+	messageBytes, err := teleporterMessage.Pack()
+	require.NoError(t, err)
+	addressedCall, err := warpPayload.NewAddressedCall(protocolAddress.Bytes(), messageBytes)
+	require.NoError(t, err)
+	unsignedMessage, err := warp.NewUnsignedMessage(0, ids.GenerateTestID(), addressedCall.Bytes())
+	require.NoError(t, err)
+
+	factory, err := teleporter.NewMessageHandlerFactory(
+		protocolAddress,
+		config.MessageProtocolConfig{
+			MessageFormat: config.TELEPORTER.String(),
+			Settings: map[string]interface{}{
+				"reward-address": "0x27aE10273D17Cd7e80de8580A51f476960626e5f",
+			},
+		},
+		deciderClient,
+	)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	destinationClient := mock_vms.NewMockDestinationClient(ctrl)
+	destinationEthClient := mock_evm.NewMockClient(ctrl)
+	sourceClient := mock_evm.NewMockClient(ctrl)
+	messageNotDelivered, err := teleportermessenger.PackMessageReceivedOutput(false)
+	require.NoError(t, err)
+	feeInfo, err := teleportermessenger.PackGetFeeInfoOutput(common.Address{}, big.NewInt(0))
+	require.NoError(t, err)
+	destinationClient.EXPECT().DestinationBlockchainID().Return(destinationBlockchainID).AnyTimes()
+	destinationClient.EXPECT().BlockGasLimit().Return(uint64(10_000_000)).AnyTimes()
+	destinationClient.EXPECT().SenderAddresses().Return([]common.Address{{}}).AnyTimes()
+	destinationClient.EXPECT().Client().Return(destinationEthClient).AnyTimes()
+	destinationEthClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(messageNotDelivered, nil).
+		AnyTimes()
+	sourceClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(feeInfo, nil).
+		AnyTimes()
+
+	handler, err := factory.NewMessageHandler(logging.NoLog{}, unsignedMessage, sourceClient, destinationClient)
+	require.NoError(t, err)
+	return handler
+}
+
+func TestProcessMessageWhenAllowedDefersWhileDeciderUnavailable(t *testing.T) {
+	// The decider can not be connected to, since nothing listens on its address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deciderURL := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	deciderClient, err := decider.NewClient(
+		logging.NoLog{},
+		&config.Config{
+			DeciderURL:                    deciderURL,
+			DeciderFailurePolicy:          config.DeciderFailClosed,
+			DeciderTimeoutSeconds:         5,
+			DeciderBreakerFailures:        1,
+			DeciderBreakerCooldownSeconds: 60,
+		},
+		decider.NewMetrics(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+	defer deciderClient.Close()
+
+	eventSink := &testEventSink{}
+	appRelayer := newTestApplicationRelayer(eventSink)
+	handler := newTestTeleporterMessageHandler(t, deciderClient)
+
+	appRelayer.processMessageSemaphore <- struct{}{}
+	processErr := make(chan error, 1)
+	go func() {
+		_, err := appRelayer.processMessageWhenAllowed(handler)
+		processErr <- err
+	}()
+
+	// The failed query opens the circuit breaker, and the message waits for it to reopen without holding the
+	// semaphore, instead of failing
+	require.Eventually(t, func() bool {
+		select {
+		case appRelayer.processMessageSemaphore <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Error(t, deciderClient.Health())
+	<-appRelayer.processMessageSemaphore
+
+	close(appRelayer.stopped)
+	require.ErrorIs(t, <-processErr, errApplicationRelayerStopped)
+	require.Equal(t, []events.EventType{events.MessageObserved}, eventSink.eventTypes())
+	require.Zero(t, testutil.CollectAndCount(appRelayer.metrics.failedRelayMessageCount))
+}
@@ -0,0 +1,204 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/relayer/config"
+	relayerTypes "github.com/ryt-io/icm-services/types"
+	"go.uber.org/zap"
+)
+
+// Interval at which the progress of a backfill is logged
+const backfillProgressInterval = 10 * time.Second
+
+// BlockProcessor dispatches the Warp messages of a block to the application relayers of the source blockchain.
+// Implemented by MessageCoordinator.
+type BlockProcessor interface {
+	ProcessBlock(icmBlockInfo *relayerTypes.WarpBlockInfo, blockchainID ids.ID, errChan chan error)
+}
+
+// BackfillReport summarizes the blocks replayed by a backfill
+type BackfillReport struct {
+	ProcessedBlocks uint64
+	WarpMessages    uint64
+	// Heights at which at least one application relayer failed to relay the block's messages
+	FailedHeights []uint64
+}
+
+// Backfill replays the Warp messages of a block range through the application relayers, without checkpointing
+// the processed heights. Each application relayer used by the backfill must be created with a checkpoint manager
+// returned by CheckpointManager, so that the backfill can track which application relayers have processed each
+// block.
+type Backfill struct {
+	logger logging.Logger
+	cfg    *config.BackfillConfig
+
+	lock sync.Mutex
+	// The number of checkpoint managers created, which is the number of application relayers that process each block
+	numRelayers int
+	// The results of each block in flight, one per application relayer
+	results map[uint64]chan error
+	report  BackfillReport
+}
+
+func NewBackfill(logger logging.Logger, cfg *config.BackfillConfig) *Backfill {
+	return &Backfill{
+		logger:  logger,
+		cfg:     cfg,
+		results: make(map[uint64]chan error),
+	}
+}
+
+// CheckpointManager returns a checkpoint manager for an application relayer used by the backfill.
+// The checkpoint manager does not store the committed heights.
+func (b *Backfill) CheckpointManager() CheckpointManager {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.numRelayers++
+	return &backfillCheckpointManager{backfill: b}
+}
+
+// Run replays the blocks received from [blocks] with [blockProcessor], processing up to
+// MaxConcurrentBlocks blocks at a time. Blocks must be received in order, up to the backfill's ToHeight.
+// Blocks that fail to be relayed are recorded in the returned report. An error is returned if the blocks
+// can not be received, or if [ctx] is canceled.
+func (b *Backfill) Run(
+	ctx context.Context,
+	blockProcessor BlockProcessor,
+	blocks <-chan *relayerTypes.WarpBlockInfo,
+	errChan <-chan error,
+) (*BackfillReport, error) {
+	b.lock.Lock()
+	numRelayers := b.numRelayers
+	b.lock.Unlock()
+
+	numBlocks := b.cfg.ToHeight - b.cfg.FromHeight + 1
+	b.logger.Info(
+		"Starting backfill",
+		zap.Stringer("sourceBlockchainID", b.cfg.SourceBlockchainID),
+		zap.Uint64("fromBlockHeight", b.cfg.FromHeight),
+		zap.Uint64("toBlockHeight", b.cfg.ToHeight),
+		zap.Int("numRelayers", numRelayers),
+	)
+
+	progressTicker := time.NewTicker(backfillProgressInterval)
+	defer progressTicker.Stop()
+
+	var wg sync.WaitGroup
+	// Wait for the blocks in flight on every return path, since they reference the backfill's state
+	defer wg.Wait()
+	semaphore := make(chan struct{}, b.cfg.MaxConcurrentBlocks)
+	for received := uint64(0); received < numBlocks; {
+		select {
+		case block := <-blocks:
+			received++
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			results := make(chan error, numRelayers)
+			b.lock.Lock()
+			b.results[block.BlockNumber] = results
+			b.lock.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				b.processBlock(blockProcessor, block, results, numRelayers)
+			}()
+		case err := <-errChan:
+			return nil, fmt.Errorf("failed to receive blocks: %w", err)
+		case <-progressTicker.C:
+			b.logProgress(numBlocks)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	wg.Wait()
+	b.logProgress(numBlocks)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	report := b.report
+	report.FailedHeights = append([]uint64(nil), b.report.FailedHeights...)
+	return &report, nil
+}
+
+// processBlock dispatches [block] to the application relayers, and waits for each of them to either checkpoint the
+// height or fail
+func (b *Backfill) processBlock(
+	blockProcessor BlockProcessor,
+	block *relayerTypes.WarpBlockInfo,
+	results chan error,
+	numRelayers int,
+) {
+	blockProcessor.ProcessBlock(block, b.cfg.SourceBlockchainID, results)
+
+	var errs []error
+	for range numRelayers {
+		if err := <-results; err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.results, block.BlockNumber)
+	b.report.ProcessedBlocks++
+	b.report.WarpMessages += uint64(len(block.Messages))
+	if len(errs) > 0 {
+		b.logger.Error(
+			"Failed to relay block",
+			zap.Uint64("height", block.BlockNumber),
+			zap.Error(errors.Join(errs...)),
+		)
+		b.report.FailedHeights = append(b.report.FailedHeights, block.BlockNumber)
+	}
+}
+
+func (b *Backfill) logProgress(numBlocks uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.logger.Info(
+		"Backfill progress",
+		zap.Uint64("processedBlocks", b.report.ProcessedBlocks),
+		zap.Uint64("totalBlocks", numBlocks),
+		zap.Uint64("warpMessages", b.report.WarpMessages),
+		zap.Int("failedBlocks", len(b.report.FailedHeights)),
+	)
+}
+
+// stageCommittedHeight records that an application relayer processed the block at [height]
+func (b *Backfill) stageCommittedHeight(height uint64) {
+	b.lock.Lock()
+	results, ok := b.results[height]
+	b.lock.Unlock()
+	if !ok {
+		b.logger.Warn("Received committed height outside of the backfill", zap.Uint64("height", height))
+		return
+	}
+	results <- nil
+}
+
+// backfillCheckpointManager reports the heights processed by an application relayer to the backfill,
+// instead of storing them
+type backfillCheckpointManager struct {
+	backfill *Backfill
+}
+
+func (cm *backfillCheckpointManager) Run() {}
+
+func (cm *backfillCheckpointManager) StageCommittedHeight(height uint64) {
+	cm.backfill.stageCommittedHeight(height)
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package relayer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/relayer/config"
+	relayerTypes "github.com/ryt-io/icm-services/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testBlockProcessor checkpoints each block with every checkpoint manager, except for the failing ones at the
+// failing heights
+type testBlockProcessor struct {
+	checkpointManagers []CheckpointManager
+	failingManagers    int
+	failingHeights     map[uint64]bool
+}
+
+func (p *testBlockProcessor) ProcessBlock(
+	icmBlockInfo *relayerTypes.WarpBlockInfo,
+	_ ids.ID,
+	errChan chan error,
+) {
+	for i, checkpointManager := range p.checkpointManagers {
+		if i < p.failingManagers && p.failingHeights[icmBlockInfo.BlockNumber] {
+			go func() { errChan <- errors.New("failed to relay message") }()
+			continue
+		}
+		go checkpointManager.StageCommittedHeight(icmBlockInfo.BlockNumber)
+	}
+}
+
+func TestBackfillRun(t *testing.T) {
+	testCases := []struct {
+		name            string
+		failingManagers int
+		failingHeights  map[uint64]bool
+		expectedFailed  []uint64
+	}{
+		{
+			name: "all blocks relayed",
+		},
+		{
+			name:            "one relayer fails",
+			failingManagers: 1,
+			failingHeights:  map[uint64]bool{12: true},
+			expectedFailed:  []uint64{12},
+		},
+		{
+			name:            "all relayers fail",
+			failingManagers: 3,
+			failingHeights:  map[uint64]bool{10: true, 14: true},
+			expectedFailed:  []uint64{10, 14},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			backfill := NewBackfill(logging.NoLog{}, &config.BackfillConfig{
+				SourceBlockchainID:  ids.GenerateTestID(),
+				FromHeight:          10,
+				ToHeight:            14,
+				MaxConcurrentBlocks: 2,
+			})
+			processor := &testBlockProcessor{
+				failingManagers: testCase.failingManagers,
+				failingHeights:  testCase.failingHeights,
+			}
+			for range 3 {
+				processor.checkpointManagers = append(processor.checkpointManagers, backfill.CheckpointManager())
+			}
+
+			blocks := make(chan *relayerTypes.WarpBlockInfo, 5)
+			for height := uint64(10); height <= 14; height++ {
+				blocks <- &relayerTypes.WarpBlockInfo{
+					BlockNumber: height,
+					Messages:    []*relayerTypes.WarpMessageInfo{{}},
+				}
+			}
+
+			report, err := backfill.Run(context.Background(), processor, blocks, make(chan error))
+			require.NoError(t, err)
+			require.Equal(t, uint64(5), report.ProcessedBlocks)
+			require.Equal(t, uint64(5), report.WarpMessages)
+			require.ElementsMatch(t, testCase.expectedFailed, report.FailedHeights)
+		})
+	}
+}
+
+func TestBackfillRunSubscriberError(t *testing.T) {
+	backfill := NewBackfill(logging.NoLog{}, &config.BackfillConfig{
+		SourceBlockchainID:  ids.GenerateTestID(),
+		FromHeight:          1,
+		ToHeight:            10,
+		MaxConcurrentBlocks: 1,
+	})
+	errChan := make(chan error, 1)
+	errChan <- errors.New("failed to get logs")
+
+	_, err := backfill.Run(context.Background(), &testBlockProcessor{}, make(chan *relayerTypes.WarpBlockInfo), errChan)
+	require.ErrorContains(t, err, "failed to get logs")
+}
@@ -30,6 +30,8 @@ type CheckpointManager struct {
 	pendingCommits  *utils.UInt64Heap
 	// Update the dirty flag when committedHeight is updated
 	dirty bool
+	// Closed once the final height has been written after writeSignal is closed
+	done chan struct{}
 }
 
 func NewCheckpointManager(
@@ -67,6 +69,7 @@ func NewCheckpointManager(
 		lock:            &sync.RWMutex{},
 		pendingCommits:  h,
 		dirty:           true,
+		done:            make(chan struct{}),
 	}
 
 	metrics.UpdateCommittedHeight(relayerID, committedHeight)
@@ -109,6 +112,16 @@ func (cm *CheckpointManager) listenForWriteSignal() {
 	for range cm.writeSignal {
 		cm.writeToDatabase()
 	}
+	// The write signal is closed once the checkpoint manager is no longer in use.
+	// Write the latest committed height so that it is not lost.
+	cm.writeToDatabase()
+	close(cm.done)
+}
+
+// Done returns a channel that is closed once the checkpoint manager has stopped. The checkpoint manager
+// stops when its write signal channel is closed, after writing the latest committed height to the database.
+func (cm *CheckpointManager) Done() <-chan struct{} {
+	return cm.done
 }
 
 // StageCommittedHeight queues a height to be written to the database.
@@ -77,3 +77,23 @@ func TestCommitHeight(t *testing.T) {
 		require.Equal(t, test.expectedMaxHeight, cm.committedHeight, test.name)
 	}
 }
+
+func TestWriteOnWriteSignalClosed(t *testing.T) {
+	id := database.RelayerID{
+		ID: common.BytesToHash(crypto.Keccak256([]byte("write on close"))),
+	}
+	db := mock_database.NewMockRelayerDatabase(gomock.NewController(t))
+	db.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, database.ErrKeyNotFound).AnyTimes()
+	db.EXPECT().Put(id.ID, database.LatestProcessedBlockKey, []byte(strconv.FormatUint(11, 10))).Return(nil).Times(1)
+
+	writeSignal := make(chan struct{})
+	metrics := NewCheckpointManagerMetrics(prometheus.NewRegistry())
+	cm, err := NewCheckpointManager(logging.NoLog{}, metrics, db, writeSignal, id, 10)
+	require.NoError(t, err)
+	cm.Run()
+	cm.StageCommittedHeight(11)
+
+	// Closing the write signal writes the latest committed height before stopping
+	close(writeSignal)
+	<-cm.Done()
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/icm-services/utils"
+	"github.com/spf13/pflag"
+)
+
+const defaultBackfillMaxConcurrentBlocks = uint64(10)
+
+// BackfillConfig specifies the block range replayed by the backfill command, as provided via its flags
+type BackfillConfig struct {
+	SourceBlockchainID ids.ID
+	FromHeight         uint64
+	ToHeight           uint64
+	// The destination blockchains of the routes to replay. All of the source blockchain's routes are replayed
+	// if empty.
+	DestinationBlockchainIDs set.Set[ids.ID]
+	// The maximum number of blocks processed concurrently
+	MaxConcurrentBlocks uint64
+}
+
+// BuildBackfillFlagSet returns the flags of the backfill command, which include the relayer's flags
+func BuildBackfillFlagSet() *pflag.FlagSet {
+	fs := BuildFlagSet()
+	fs.String(BackfillSourceKey, "", "The cb58 or hex encoded blockchain ID of the source blockchain to replay")
+	fs.Uint64(BackfillFromKey, 0, "The first block height to replay")
+	fs.Uint64(BackfillToKey, 0, "The last block height to replay")
+	fs.StringSlice(
+		BackfillRouteKey,
+		nil,
+		"The cb58 or hex encoded blockchain ID of a destination blockchain to replay. May be repeated. "+
+			"Defaults to all of the source blockchain's supported destinations",
+	)
+	fs.Uint64(
+		BackfillMaxConcurrentBlocksKey,
+		defaultBackfillMaxConcurrentBlocks,
+		"The maximum number of blocks processed concurrently",
+	)
+	return fs
+}
+
+// NewBackfillConfig reads the backfill command's flags from [fs], and validates them against [cfg]
+func NewBackfillConfig(fs *pflag.FlagSet, cfg *Config) (*BackfillConfig, error) {
+	source, err := fs.GetString(BackfillSourceKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading flag value: %s: %w", BackfillSourceKey, err)
+	}
+	fromHeight, err := fs.GetUint64(BackfillFromKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading flag value: %s: %w", BackfillFromKey, err)
+	}
+	toHeight, err := fs.GetUint64(BackfillToKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading flag value: %s: %w", BackfillToKey, err)
+	}
+	routes, err := fs.GetStringSlice(BackfillRouteKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading flag value: %s: %w", BackfillRouteKey, err)
+	}
+	maxConcurrentBlocks, err := fs.GetUint64(BackfillMaxConcurrentBlocksKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading flag value: %s: %w", BackfillMaxConcurrentBlocksKey, err)
+	}
+
+	backfillConfig := &BackfillConfig{
+		FromHeight:               fromHeight,
+		ToHeight:                 toHeight,
+		DestinationBlockchainIDs: set.NewSet[ids.ID](len(routes)),
+		MaxConcurrentBlocks:      maxConcurrentBlocks,
+	}
+	if err := backfillConfig.parse(source, routes, cfg); err != nil {
+		return nil, err
+	}
+	return backfillConfig, nil
+}
+
+func (c *BackfillConfig) parse(source string, routes []string, cfg *Config) error {
+	if source == "" {
+		return fmt.Errorf("%s must be set", BackfillSourceKey)
+	}
+	sourceBlockchainID, err := utils.HexOrCB58ToID(source)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", BackfillSourceKey, err)
+	}
+	var sourceBlockchain *SourceBlockchain
+	for _, s := range cfg.SourceBlockchains {
+		if s.GetBlockchainID() == sourceBlockchainID {
+			sourceBlockchain = s
+			break
+		}
+	}
+	if sourceBlockchain == nil {
+		return fmt.Errorf("source blockchain %s is not configured", sourceBlockchainID)
+	}
+	c.SourceBlockchainID = sourceBlockchainID
+
+	if c.FromHeight == 0 {
+		return fmt.Errorf("%s must be greater than 0", BackfillFromKey)
+	}
+	if c.ToHeight < c.FromHeight {
+		return fmt.Errorf("%s must be greater than or equal to %s", BackfillToKey, BackfillFromKey)
+	}
+	if c.MaxConcurrentBlocks == 0 {
+		return fmt.Errorf("%s must be greater than 0", BackfillMaxConcurrentBlocksKey)
+	}
+
+	supportedDestinations := set.NewSet[ids.ID](len(sourceBlockchain.SupportedDestinations))
+	for _, destination := range sourceBlockchain.SupportedDestinations {
+		supportedDestinations.Add(destination.GetBlockchainID())
+	}
+	for _, route := range routes {
+		destinationBlockchainID, err := utils.HexOrCB58ToID(route)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", BackfillRouteKey, err)
+		}
+		if !supportedDestinations.Contains(destinationBlockchainID) {
+			return fmt.Errorf(
+				"destination blockchain %s is not a supported destination of the source blockchain",
+				destinationBlockchainID,
+			)
+		}
+		c.DestinationBlockchainIDs.Add(destinationBlockchainID)
+	}
+	return nil
+}
+
+// IncludesRoute returns whether the messages to [destinationBlockchainID] are replayed
+func (c *BackfillConfig) IncludesRoute(destinationBlockchainID ids.ID) bool {
+	return c.DestinationBlockchainIDs.Len() == 0 || c.DestinationBlockchainIDs.Contains(destinationBlockchainID)
+}
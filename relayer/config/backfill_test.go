@@ -0,0 +1,114 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackfillConfig(t *testing.T) {
+	sourceBlockchainID := ids.GenerateTestID()
+	destinationBlockchainID1 := ids.GenerateTestID()
+	destinationBlockchainID2 := ids.GenerateTestID()
+	cfg := &Config{
+		SourceBlockchains: []*SourceBlockchain{
+			{
+				blockchainID: sourceBlockchainID,
+				SupportedDestinations: []*SupportedDestination{
+					{blockchainID: destinationBlockchainID1},
+					{blockchainID: destinationBlockchainID2},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		args                []string
+		expectedFrom        uint64
+		expectedTo          uint64
+		expectedRoutes      []ids.ID
+		expectedConcurrency uint64
+		expectedError       bool
+	}{
+		{
+			name:                "all routes",
+			args:                []string{"--source", sourceBlockchainID.String(), "--from", "10", "--to", "20"},
+			expectedFrom:        10,
+			expectedTo:          20,
+			expectedConcurrency: defaultBackfillMaxConcurrentBlocks,
+		},
+		{
+			name: "explicit routes",
+			args: []string{
+				"--source", sourceBlockchainID.String(), "--from", "10", "--to", "10",
+				"--route", destinationBlockchainID2.String(), "--concurrency", "3",
+			},
+			expectedFrom:        10,
+			expectedTo:          10,
+			expectedRoutes:      []ids.ID{destinationBlockchainID2},
+			expectedConcurrency: 3,
+		},
+		{
+			name:          "missing source",
+			args:          []string{"--from", "10", "--to", "20"},
+			expectedError: true,
+		},
+		{
+			name:          "unconfigured source",
+			args:          []string{"--source", ids.GenerateTestID().String(), "--from", "10", "--to", "20"},
+			expectedError: true,
+		},
+		{
+			name:          "missing from",
+			args:          []string{"--source", sourceBlockchainID.String(), "--to", "20"},
+			expectedError: true,
+		},
+		{
+			name:          "to before from",
+			args:          []string{"--source", sourceBlockchainID.String(), "--from", "20", "--to", "10"},
+			expectedError: true,
+		},
+		{
+			name: "unsupported route",
+			args: []string{
+				"--source", sourceBlockchainID.String(), "--from", "10", "--to", "20",
+				"--route", ids.GenerateTestID().String(),
+			},
+			expectedError: true,
+		},
+		{
+			name: "zero concurrency",
+			args: []string{
+				"--source", sourceBlockchainID.String(), "--from", "10", "--to", "20", "--concurrency", "0",
+			},
+			expectedError: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fs := BuildBackfillFlagSet()
+			require.NoError(t, fs.Parse(testCase.args))
+
+			backfillConfig, err := NewBackfillConfig(fs, cfg)
+			if testCase.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, sourceBlockchainID, backfillConfig.SourceBlockchainID)
+			require.Equal(t, testCase.expectedFrom, backfillConfig.FromHeight)
+			require.Equal(t, testCase.expectedTo, backfillConfig.ToHeight)
+			require.ElementsMatch(t, testCase.expectedRoutes, backfillConfig.DestinationBlockchainIDs.List())
+			require.Equal(t, testCase.expectedConcurrency, backfillConfig.MaxConcurrentBlocks)
+			for _, destinationBlockchainID := range []ids.ID{destinationBlockchainID1, destinationBlockchainID2} {
+				included := len(testCase.expectedRoutes) == 0 || destinationBlockchainID == destinationBlockchainID2
+				require.Equal(t, included, backfillConfig.IncludesRoute(destinationBlockchainID))
+			}
+		})
+	}
+}
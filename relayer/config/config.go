@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/ava-labs/avalanchego/graft/subnet-evm/params"
 	"github.com/ava-labs/avalanchego/graft/subnet-evm/precompile/contracts/warp"
@@ -34,6 +35,8 @@ const (
 
 const (
 	defaultStorageLocation                 = "./.icm-relayer-storage"
+	defaultStorageType                     = JSONStorageType
+	defaultRedisMode                       = RedisStandaloneMode
 	defaultProcessMissedBlocks             = true
 	defaultAPIPort                         = uint16(8080)
 	defaultMetricsPort                     = uint16(9090)
@@ -41,6 +44,15 @@ const (
 	defaultSignatureCacheSize              = uint64(1024 * 1024)
 	defaultInitialConnectionTimeoutSeconds = uint64(300)
 	defaultMaxConcurrentMessages           = uint64(250)
+	defaultDeadLetterQueueMaxSize          = uint64(10_000)
+	defaultLeaderLeaseName                 = "icm-relayer-leader"
+	defaultLeaderLeaseSeconds              = uint64(15)
+	minLeaderLeaseSeconds                  = uint64(3)
+	defaultDryRunNamespace                 = "dry-run"
+	defaultDeciderFailurePolicy            = DeciderFailOpen
+	defaultDeciderTimeoutSeconds           = uint64(30)
+	defaultDeciderBreakerFailures          = uint64(5)
+	defaultDeciderBreakerCooldownSeconds   = uint64(30)
 )
 
 var defaultLogLevel = logging.Info.String()
@@ -51,18 +63,60 @@ var sensitiveKeys = []string{
 	"x-api-key", "bearer",
 }
 
+// Storage types of the relayer's state when redis-url is not set. The state is stored in storage-location.
+const (
+	JSONStorageType    = "json"
+	LevelDBStorageType = "leveldb"
+)
+
+// Topologies of the Redis deployment at redis-url
+const (
+	RedisStandaloneMode = "standalone"
+	RedisSentinelMode   = "sentinel"
+	RedisClusterMode    = "cluster"
+)
+
 const usageText = `
 Usage:
 icm-relayer --config-file path-to-config                Specifies the relayer config file and begin relaying messages.
 icm-relayer --version                                   Display icm-relayer version and exit.
 icm-relayer --help                                      Display icm-relayer usage and exit.
+icm-relayer backfill --config-file path-to-config --source blockchain-id --from height --to height
+    [--route blockchain-id]... [--concurrency n]        Relay the messages sent in a block range and exit.
+icm-relayer state list --config-file path-to-config     Display the route and latest processed block of each relayer ID.
+icm-relayer state set-checkpoint --config-file path-to-config --relayer-id id... --height height
+                                                        Set the latest processed block of relayer IDs.
+icm-relayer state reset-checkpoint --config-file path-to-config --relayer-id id...
+                                                        Reset the latest processed block of relayer IDs.
+icm-relayer state export --config-file path-to-config [--file path]
+                                                        Export the state of the relayer IDs as JSON.
+icm-relayer state import --config-file path-to-config --file path
+                                                        Import the state of relayer IDs from JSON.
+icm-relayer state migrate --config-file path-to-config
+    (--target-storage-location path [--target-storage-type type] | --target-redis-url url)
+                                                        Copy the state of the relayer IDs to another storage.
 `
 
+// Behaviours of the relayer when the decider at decider-url can not be queried
+const (
+	// Messages are sent as if the decider approved them
+	DeciderFailOpen = "fail-open"
+	// Messages are not sent, and fail to be relayed
+	DeciderFailClosed = "fail-closed"
+)
+
 // Top-level configuration
 type Config struct {
 	LogLevel                        string                   `mapstructure:"log-level" json:"log-level"`
 	StorageLocation                 string                   `mapstructure:"storage-location" json:"storage-location"`
 	RedisURL                        string                   `mapstructure:"redis-url" json:"redis-url" sensitive:"true"`
+	StorageType                     string                   `mapstructure:"storage-type" json:"storage-type"`
+	RedisMode                       string                   `mapstructure:"redis-mode" json:"redis-mode"`
+	RedisKeyPrefix                  string                   `mapstructure:"redis-key-prefix" json:"redis-key-prefix"`
+	RedisKeyExpirySeconds           uint64                   `mapstructure:"redis-key-expiry-seconds" json:"redis-key-expiry-seconds"`       //nolint:lll
+	RedisTLSCertPath                string                   `mapstructure:"redis-tls-cert-path" json:"redis-tls-cert-path,omitempty"`       //nolint:lll
+	RedisTLSKeyPath                 string                   `mapstructure:"redis-tls-key-path" json:"redis-tls-key-path,omitempty"`         //nolint:lll
+	RedisTLSCACertPath              string                   `mapstructure:"redis-tls-ca-cert-path" json:"redis-tls-ca-cert-path,omitempty"` //nolint:lll
 	APIPort                         uint16                   `mapstructure:"api-port" json:"api-port"`
 	MetricsPort                     uint16                   `mapstructure:"metrics-port" json:"metrics-port"`
 	DBWriteIntervalSeconds          uint64                   `mapstructure:"db-write-interval-seconds" json:"db-write-interval-seconds"` //nolint:lll
@@ -72,13 +126,34 @@ type Config struct {
 	DestinationBlockchains          []*DestinationBlockchain `mapstructure:"destination-blockchains" json:"destination-blockchains"` //nolint:lll
 	ProcessMissedBlocks             bool                     `mapstructure:"process-missed-blocks" json:"process-missed-blocks"`     //nolint:lll
 	DeciderURL                      string                   `mapstructure:"decider-url" json:"decider-url"`
-	SignatureCacheSize              uint64                   `mapstructure:"signature-cache-size" json:"signature-cache-size"`     //nolint:lll
-	ManuallyTrackedPeers            []*basecfg.PeerConfig    `mapstructure:"manually-tracked-peers" json:"manually-tracked-peers"` //nolint:lll
+	DeciderFailurePolicy            string                   `mapstructure:"decider-failure-policy" json:"decider-failure-policy"`                                     //nolint:lll
+	DeciderTimeoutSeconds           uint64                   `mapstructure:"decider-timeout-seconds" json:"decider-timeout-seconds"`                                   //nolint:lll
+	DeciderBreakerFailures          uint64                   `mapstructure:"decider-circuit-breaker-failures" json:"decider-circuit-breaker-failures"`                 //nolint:lll
+	DeciderBreakerCooldownSeconds   uint64                   `mapstructure:"decider-circuit-breaker-cooldown-seconds" json:"decider-circuit-breaker-cooldown-seconds"` //nolint:lll
+	DeciderTLS                      bool                     `mapstructure:"decider-tls" json:"decider-tls"`
+	DeciderTLSCertPath              string                   `mapstructure:"decider-tls-cert-path" json:"decider-tls-cert-path,omitempty"`       //nolint:lll
+	DeciderTLSKeyPath               string                   `mapstructure:"decider-tls-key-path" json:"decider-tls-key-path,omitempty"`         //nolint:lll
+	DeciderTLSCACertPath            string                   `mapstructure:"decider-tls-ca-cert-path" json:"decider-tls-ca-cert-path,omitempty"` //nolint:lll
+	DeciderHeaders                  map[string]string        `mapstructure:"decider-headers" json:"decider-headers,omitempty" sensitive:"true"`  //nolint:lll
+	DeciderCacheTTLSeconds          uint64                   `mapstructure:"decider-cache-ttl-seconds" json:"decider-cache-ttl-seconds"`         //nolint:lll
+	DeciderRules                    []*DeciderRule           `mapstructure:"decider-rules" json:"decider-rules,omitempty"`                       //nolint:lll
+	DeciderRulesFile                string                   `mapstructure:"decider-rules-file" json:"decider-rules-file,omitempty"`             //nolint:lll
+	DeciderRulesDefaultAction       string                   `mapstructure:"decider-rules-default-action" json:"decider-rules-default-action"`   //nolint:lll
+	SignatureCacheSize              uint64                   `mapstructure:"signature-cache-size" json:"signature-cache-size"`                   //nolint:lll
+	ManuallyTrackedPeers            []*basecfg.PeerConfig    `mapstructure:"manually-tracked-peers" json:"manually-tracked-peers"`               //nolint:lll
 	AllowPrivateIPs                 bool                     `mapstructure:"allow-private-ips" json:"allow-private-ips"`
 	TLSCertPath                     string                   `mapstructure:"tls-cert-path" json:"tls-cert-path,omitempty"` //nolint:lll
 	TLSKeyPath                      string                   `mapstructure:"tls-key-path" json:"tls-key-path,omitempty"`
 	InitialConnectionTimeoutSeconds uint64                   `mapstructure:"initial-connection-timeout-seconds" json:"initial-connection-timeout-seconds,omitempty"` // nolint:lll
 	MaxConcurrentMessages           uint64                   `mapstructure:"max-concurrent-messages" json:"max-concurrent-messages,omitempty"`                       //nolint:lll
+	EnableDeadLetterQueue           bool                     `mapstructure:"enable-dead-letter-queue" json:"enable-dead-letter-queue"`                               //nolint:lll
+	DeadLetterQueueMaxSize          uint64                   `mapstructure:"dead-letter-queue-max-size" json:"dead-letter-queue-max-size"`                           //nolint:lll
+	EnableLeaderElection            bool                     `mapstructure:"enable-leader-election" json:"enable-leader-election"`                                   //nolint:lll
+	LeaderLeaseName                 string                   `mapstructure:"leader-lease-name" json:"leader-lease-name"`                                             //nolint:lll
+	LeaderLeaseSeconds              uint64                   `mapstructure:"leader-lease-seconds" json:"leader-lease-seconds"`                                       //nolint:lll
+	DryRun                          bool                     `mapstructure:"dry-run" json:"dry-run"`
+	DryRunNamespace                 string                   `mapstructure:"dry-run-namespace" json:"dry-run-namespace"`
+	EventSinks                      []*EventSink             `mapstructure:"event-sinks" json:"event-sinks"`
 
 	// convenience field to fetch a blockchain's subnet ID
 	tlsCert                *tls.Certificate
@@ -161,6 +236,46 @@ func (c *Config) Validate() error {
 		if _, err := url.ParseRequestURI(c.DeciderURL); err != nil {
 			return fmt.Errorf("invalid decider URL: %w", err)
 		}
+		if c.DeciderTimeoutSeconds == 0 {
+			return errors.New("decider-timeout-seconds must be greater than 0")
+		}
+	}
+	switch c.DeciderFailurePolicy {
+	case "", DeciderFailOpen, DeciderFailClosed:
+	default:
+		return fmt.Errorf("invalid decider-failure-policy %q", c.DeciderFailurePolicy)
+	}
+	if (c.DeciderTLSCertPath == "") != (c.DeciderTLSKeyPath == "") {
+		return errors.New("decider-tls-cert-path and decider-tls-key-path must be set together")
+	}
+	if !c.DeciderTLS && (c.DeciderTLSCertPath != "" || c.DeciderTLSCACertPath != "") {
+		return errors.New("decider-tls must be set if decider TLS certificates are set")
+	}
+	if c.DeciderTLS && strings.HasPrefix(c.DeciderURL, "http://") {
+		return errors.New("decider-tls can not be set for http decider-url")
+	}
+	// The headers typically authenticate the relayer, so they are not sent over unencrypted connections
+	if len(c.DeciderHeaders) != 0 && !c.DeciderTLS && !strings.HasPrefix(c.DeciderURL, "https://") {
+		return errors.New("decider-headers require decider-tls or an https decider-url")
+	}
+	if c.DeciderCacheTTLSeconds != 0 && !c.DeciderUsesHTTP() {
+		return errors.New("decider-cache-ttl-seconds can only be set for http or https decider-url")
+	}
+	if len(c.DeciderURL) != 0 && c.HasDeciderRules() {
+		return errors.New("decider-url can not be set with decider-rules or decider-rules-file")
+	}
+	switch c.DeciderRulesDefaultAction {
+	case "", DeciderRuleAllow, DeciderRuleDeny:
+	default:
+		return fmt.Errorf("invalid decider-rules-default-action %q", c.DeciderRulesDefaultAction)
+	}
+	for i, rule := range c.DeciderRules {
+		if rule == nil {
+			return fmt.Errorf("decider rule %d is empty", i)
+		}
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("failed to validate decider rule %d: %w", i, err)
+		}
 	}
 
 	for _, l1ID := range c.blockchainIDToSubnetID {
@@ -175,9 +290,101 @@ func (c *Config) Validate() error {
 		return errors.New("max-concurrent-messages must be greater than 0")
 	}
 
+	if c.EnableDeadLetterQueue && c.DeadLetterQueueMaxSize == 0 {
+		return errors.New("dead-letter-queue-max-size must be greater than 0")
+	}
+
+	if c.EnableLeaderElection {
+		if c.RedisURL == "" {
+			return errors.New("enable-leader-election requires redis-url to be set")
+		}
+		if c.LeaderLeaseName == "" {
+			return errors.New("leader-lease-name must be set if enable-leader-election is set")
+		}
+		if c.LeaderLeaseSeconds < minLeaderLeaseSeconds {
+			return fmt.Errorf("leader-lease-seconds must be at least %d", minLeaderLeaseSeconds)
+		}
+	}
+
+	switch c.StorageType {
+	case "", JSONStorageType:
+	case LevelDBStorageType:
+		if c.RedisURL != "" {
+			return fmt.Errorf("storage-type %s can not be used with redis-url", c.StorageType)
+		}
+	default:
+		return fmt.Errorf("invalid storage-type %q", c.StorageType)
+	}
+
+	switch c.RedisMode {
+	case "", RedisStandaloneMode, RedisSentinelMode, RedisClusterMode:
+	default:
+		return fmt.Errorf("invalid redis-mode %q", c.RedisMode)
+	}
+	if (c.RedisTLSCertPath == "") != (c.RedisTLSKeyPath == "") {
+		return errors.New("redis-tls-cert-path and redis-tls-key-path must be set together")
+	}
+	// Keys are refreshed when they are written, so they must not expire between checkpoint writes
+	if c.RedisKeyExpirySeconds != 0 && c.RedisKeyExpirySeconds <= c.DBWriteIntervalSeconds {
+		return errors.New("redis-key-expiry-seconds must be greater than db-write-interval-seconds")
+	}
+
+	if c.DryRun && c.DryRunNamespace == "" {
+		return errors.New("dry-run-namespace must be set if dry-run is set")
+	}
+
+	for i, sink := range c.EventSinks {
+		if err := sink.validate(); err != nil {
+			return fmt.Errorf("failed to validate event sink %d: %w", i, err)
+		}
+	}
+
 	return nil
 }
 
+// GetRedisKeyExpiry returns the expiry of the keys written to Redis, which is zero if they do not expire
+func (c *Config) GetRedisKeyExpiry() time.Duration {
+	return time.Duration(c.RedisKeyExpirySeconds) * time.Second
+}
+
+// GetDeciderTimeout returns the timeout of each query to the decider
+func (c *Config) GetDeciderTimeout() time.Duration {
+	return time.Duration(c.DeciderTimeoutSeconds) * time.Second
+}
+
+// DeciderUsesHTTP returns true if the decider at decider-url is queried with JSON over HTTP instead of gRPC
+func (c *Config) DeciderUsesHTTP() bool {
+	return strings.HasPrefix(c.DeciderURL, "http://") || strings.HasPrefix(c.DeciderURL, "https://")
+}
+
+// GetDeciderCacheTTL returns how long the decisions of an HTTP decider are cached for each message
+func (c *Config) GetDeciderCacheTTL() time.Duration {
+	return time.Duration(c.DeciderCacheTTLSeconds) * time.Second
+}
+
+// HasDeciderRules returns true if messages are evaluated against decider rules instead of querying decider-url
+func (c *Config) HasDeciderRules() bool {
+	return len(c.DeciderRules) != 0 || c.DeciderRulesFile != ""
+}
+
+// GetDeciderBreakerCooldown returns how long the decider is not queried after its circuit breaker opens
+func (c *Config) GetDeciderBreakerCooldown() time.Duration {
+	return time.Duration(c.DeciderBreakerCooldownSeconds) * time.Second
+}
+
+func (c *Config) GetLeaderLeaseDuration() time.Duration {
+	return time.Duration(c.LeaderLeaseSeconds) * time.Second
+}
+
+// GetLeaderLeaseName returns the name of the leader lease. In dry-run mode, the lease is held in the
+// dry-run namespace, so that shadow relayers do not contend with the relayers that send transactions.
+func (c *Config) GetLeaderLeaseName() string {
+	if c.DryRun {
+		return c.DryRunNamespace + ":" + c.LeaderLeaseName
+	}
+	return c.LeaderLeaseName
+}
+
 func (c *Config) GetSubnetID(blockchainID ids.ID) ids.ID {
 	return c.blockchainIDToSubnetID[blockchainID]
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/ava-labs/avalanchego/graft/subnet-evm/precompile/precompileconfig"
 	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/libevm/common"
 	"github.com/ryt-io/libevm/core/types"
 	basecfg "github.com/ryt-io/icm-services/config"
 	"github.com/ryt-io/icm-services/utils"
@@ -743,6 +744,30 @@ func TestValidateSourceBlockchain(t *testing.T) {
 			expectError:                   true,
 			expectedSupportedDestinations: []string{},
 		},
+		{
+			name: "valid source subnet; no ws endpoint",
+			sourceSubnet: func() SourceBlockchain {
+				cfg := validSourceCfg
+				cfg.WSEndpoint = basecfg.APIConfig{}
+				return cfg
+			},
+			destinationBlockchainIDs:      []string{testBlockchainID},
+			expectError:                   false,
+			expectedSupportedDestinations: []string{testBlockchainID},
+		},
+		{
+			name: "invalid source subnet; invalid ws endpoint",
+			sourceSubnet: func() SourceBlockchain {
+				cfg := validSourceCfg
+				cfg.WSEndpoint = basecfg.APIConfig{
+					BaseURL: "test.avax.network/ws",
+				}
+				return cfg
+			},
+			destinationBlockchainIDs:      []string{testBlockchainID},
+			expectError:                   true,
+			expectedSupportedDestinations: []string{},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -775,6 +800,531 @@ func TestValidateSourceBlockchain(t *testing.T) {
 	}
 }
 
+func TestSourceBlockchainPollingInterval(t *testing.T) {
+	blockchainIDs := set.Of(testBlockchainID)
+
+	// The WS endpoint is used if it is set
+	sourceBlockchain := TestValidSourceBlockchainConfig
+	sourceBlockchain.SupportedDestinations = nil
+	require.NoError(t, sourceBlockchain.Validate(&blockchainIDs))
+	require.False(t, sourceBlockchain.UsePolling())
+
+	// Otherwise blocks are polled at the default interval
+	sourceBlockchain.WSEndpoint = basecfg.APIConfig{}
+	require.NoError(t, sourceBlockchain.Validate(&blockchainIDs))
+	require.True(t, sourceBlockchain.UsePolling())
+	require.Equal(t, time.Duration(defaultPollingIntervalSeconds)*time.Second, sourceBlockchain.GetPollingInterval())
+
+	// Or at the configured interval
+	sourceBlockchain.PollingIntervalSeconds = 5
+	require.NoError(t, sourceBlockchain.Validate(&blockchainIDs))
+	require.Equal(t, 5*time.Second, sourceBlockchain.GetPollingInterval())
+}
+
+func TestSourceBlockchainFallbackEndpoints(t *testing.T) {
+	blockchainIDs := set.Of(testBlockchainID)
+	fallbackRPCEndpoint := &WeightedEndpoint{
+		APIConfig: basecfg.APIConfig{BaseURL: "https://fallback.avax.network/ext/bc/C/rpc"},
+	}
+	fallbackWSEndpoint := &WeightedEndpoint{
+		APIConfig: basecfg.APIConfig{BaseURL: "wss://fallback.avax.network/ext/bc/C/ws"},
+		Weight:    3,
+	}
+	testCases := []struct {
+		name        string
+		modify      func(*SourceBlockchain)
+		expectedErr bool
+	}{
+		{
+			name:   "no fallback endpoints",
+			modify: func(*SourceBlockchain) {},
+		},
+		{
+			name: "fallback endpoints",
+			modify: func(s *SourceBlockchain) {
+				s.FallbackRPCEndpoints = []*WeightedEndpoint{fallbackRPCEndpoint}
+				s.FallbackWSEndpoints = []*WeightedEndpoint{fallbackWSEndpoint}
+				s.EndpointSelection = WEIGHTED_ENDPOINT_SELECTION.String()
+			},
+		},
+		{
+			name: "primary endpoint weights",
+			modify: func(s *SourceBlockchain) {
+				s.FallbackWSEndpoints = []*WeightedEndpoint{fallbackWSEndpoint}
+				s.WSEndpointWeight = 5
+				s.EndpointSelection = WEIGHTED_ENDPOINT_SELECTION.String()
+			},
+		},
+		{
+			name: "invalid fallback rpc endpoint",
+			modify: func(s *SourceBlockchain) {
+				s.FallbackRPCEndpoints = []*WeightedEndpoint{{APIConfig: basecfg.APIConfig{BaseURL: "not a url"}}}
+			},
+			expectedErr: true,
+		},
+		{
+			name: "ws rpc endpoint with fallback rpc endpoints",
+			modify: func(s *SourceBlockchain) {
+				s.RPCEndpoint = basecfg.APIConfig{BaseURL: "ws://test.avax.network/ext/bc/C/ws"}
+				s.FallbackRPCEndpoints = []*WeightedEndpoint{fallbackRPCEndpoint}
+			},
+			expectedErr: true,
+		},
+		{
+			name: "fallback ws endpoints without ws endpoint",
+			modify: func(s *SourceBlockchain) {
+				s.WSEndpoint = basecfg.APIConfig{}
+				s.FallbackWSEndpoints = []*WeightedEndpoint{fallbackWSEndpoint}
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid endpoint selection",
+			modify: func(s *SourceBlockchain) {
+				s.EndpointSelection = "random"
+			},
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			sourceBlockchain := TestValidSourceBlockchainConfig
+			sourceBlockchain.SupportedDestinations = nil
+			testCase.modify(&sourceBlockchain)
+			err := sourceBlockchain.Validate(&blockchainIDs)
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			rpcEndpoints := sourceBlockchain.GetRPCEndpoints()
+			require.Len(t, rpcEndpoints, len(sourceBlockchain.FallbackRPCEndpoints)+1)
+			require.Equal(t, sourceBlockchain.RPCEndpoint, rpcEndpoints[0].APIConfig)
+			for _, endpoint := range rpcEndpoints {
+				require.Equal(t, uint64(defaultEndpointWeight), endpoint.Weight)
+			}
+			wsEndpoints := sourceBlockchain.GetWSEndpoints()
+			require.Len(t, wsEndpoints, len(sourceBlockchain.FallbackWSEndpoints)+1)
+			require.Equal(t, sourceBlockchain.WSEndpoint, wsEndpoints[0].APIConfig)
+			require.Equal(t, sourceBlockchain.WSEndpointWeight, wsEndpoints[0].Weight)
+			for i, endpoint := range sourceBlockchain.FallbackWSEndpoints {
+				require.Equal(t, *endpoint, wsEndpoints[i+1])
+			}
+			require.NotEqual(t, UNKNOWN_ENDPOINT_SELECTION, sourceBlockchain.GetEndpointSelection())
+			require.Equal(t,
+				time.Duration(defaultEndpointHealthCheckIntervalSeconds)*time.Second,
+				sourceBlockchain.GetEndpointHealthCheckInterval(),
+			)
+			require.Equal(t, uint64(defaultMaxEndpointHeadLagBlocks), sourceBlockchain.MaxEndpointHeadLagBlocks)
+		})
+	}
+}
+
+func TestDestinationBlockchainTxReplacement(t *testing.T) {
+	testCases := []struct {
+		name                                    string
+		modify                                  func(*DestinationBlockchain)
+		expectedErr                             bool
+		expectedTxReplacementFeeBumpPercent     uint64
+		expectedMaxReplacementPriorityFeePerGas uint64
+	}{
+		{
+			name:   "replacement disabled",
+			modify: func(*DestinationBlockchain) {},
+		},
+		{
+			name: "replacement enabled with defaults",
+			modify: func(d *DestinationBlockchain) {
+				d.TxReplacementDelaySeconds = 10
+				d.MaxReplacementFeePerGas = 100_000_000_000
+			},
+			expectedTxReplacementFeeBumpPercent:     defaultTxReplacementFeeBumpPercent,
+			expectedMaxReplacementPriorityFeePerGas: defaultMaxPriorityFeePerGas,
+		},
+		{
+			name: "replacement enabled",
+			modify: func(d *DestinationBlockchain) {
+				d.TxReplacementDelaySeconds = 10
+				d.TxReplacementFeeBumpPercent = 50
+				d.MaxReplacementFeePerGas = 100_000_000_000
+				d.MaxReplacementPriorityFeePerGas = 10_000_000_000
+			},
+			expectedTxReplacementFeeBumpPercent:     50,
+			expectedMaxReplacementPriorityFeePerGas: 10_000_000_000,
+		},
+		{
+			name: "delay not less than inclusion timeout",
+			modify: func(d *DestinationBlockchain) {
+				d.TxReplacementDelaySeconds = defaultTxInclusionTimeoutSeconds
+				d.MaxReplacementFeePerGas = 100_000_000_000
+			},
+			expectedErr: true,
+		},
+		{
+			name: "fee bump too low",
+			modify: func(d *DestinationBlockchain) {
+				d.TxReplacementDelaySeconds = 10
+				d.TxReplacementFeeBumpPercent = 5
+				d.MaxReplacementFeePerGas = 100_000_000_000
+			},
+			expectedErr: true,
+		},
+		{
+			name: "missing max replacement fee",
+			modify: func(d *DestinationBlockchain) {
+				d.TxReplacementDelaySeconds = 10
+			},
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			destinationBlockchain := TestValidDestinationBlockchainConfig
+			testCase.modify(&destinationBlockchain)
+			err := destinationBlockchain.Validate()
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.expectedTxReplacementFeeBumpPercent, destinationBlockchain.TxReplacementFeeBumpPercent)
+			require.Equal(t,
+				testCase.expectedMaxReplacementPriorityFeePerGas,
+				destinationBlockchain.MaxReplacementPriorityFeePerGas,
+			)
+		})
+	}
+}
+
+func TestDestinationBlockchainSpendLimits(t *testing.T) {
+	testCases := []struct {
+		name        string
+		spendLimits []*SpendLimit
+		expectedErr bool
+	}{
+		{
+			name: "destination and route spend limits",
+			spendLimits: []*SpendLimit{
+				{MaxSpend: "1000000000000000000", Period: "day"},
+				{SourceBlockchainID: testBlockchainID, MaxSpend: "100000000000000000", Period: "hour"},
+				{SourceBlockchainID: testBlockchainID, MaxSpend: "500000000000000000", Period: "day"},
+			},
+		},
+		{
+			name:        "invalid max spend",
+			spendLimits: []*SpendLimit{{MaxSpend: "1.5", Period: "day"}},
+			expectedErr: true,
+		},
+		{
+			name:        "zero max spend",
+			spendLimits: []*SpendLimit{{MaxSpend: "0", Period: "day"}},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid period",
+			spendLimits: []*SpendLimit{{MaxSpend: "1000", Period: "week"}},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid source blockchain ID",
+			spendLimits: []*SpendLimit{{SourceBlockchainID: "invalid", MaxSpend: "1000", Period: "hour"}},
+			expectedErr: true,
+		},
+		{
+			name: "duplicate spend limits",
+			spendLimits: []*SpendLimit{
+				{SourceBlockchainID: testBlockchainID, MaxSpend: "1000", Period: "hour"},
+				{SourceBlockchainID: testBlockchainID, MaxSpend: "2000", Period: "hour"},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			destinationBlockchain := TestValidDestinationBlockchainConfig
+			destinationBlockchain.SpendLimits = testCase.spendLimits
+			err := destinationBlockchain.Validate()
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, limit := range destinationBlockchain.SpendLimits {
+				require.Equal(t, limit.MaxSpend, limit.GetMaxSpend().String())
+				require.Equal(t, limit.Period, limit.GetPeriod().String())
+				if limit.SourceBlockchainID == "" {
+					require.Equal(t, ids.Empty, limit.GetSourceBlockchainID())
+				} else {
+					require.Equal(t, testBlockchainID, limit.GetSourceBlockchainID().String())
+				}
+			}
+		})
+	}
+}
+
+func TestDestinationBlockchainRewardRedemption(t *testing.T) {
+	teleporterAddress := "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf"
+	rewardAddress := "0x27aE10273D17Cd7e80de8580A51f476960626e5f"
+	feeTokenAddress := "0x0123456789abcdef0123456789abcdef01234567"
+	testCases := []struct {
+		name              string
+		rewardRedemption  *RewardRedemption
+		expectedThreshold string
+		expectedErr       bool
+	}{
+		{
+			name: "valid",
+			rewardRedemption: &RewardRedemption{
+				TeleporterAddress: teleporterAddress,
+				RewardAddress:     rewardAddress,
+				FeeTokens:         []*RewardFeeToken{{Address: feeTokenAddress, Threshold: "1000"}},
+			},
+			expectedThreshold: "1000",
+		},
+		{
+			name: "default threshold",
+			rewardRedemption: &RewardRedemption{
+				TeleporterAddress: teleporterAddress,
+				RewardAddress:     rewardAddress,
+				FeeTokens:         []*RewardFeeToken{{Address: feeTokenAddress}},
+			},
+			expectedThreshold: "0",
+		},
+		{
+			name: "invalid teleporter address",
+			rewardRedemption: &RewardRedemption{
+				TeleporterAddress: "invalid",
+				RewardAddress:     rewardAddress,
+				FeeTokens:         []*RewardFeeToken{{Address: feeTokenAddress}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid reward address",
+			rewardRedemption: &RewardRedemption{
+				TeleporterAddress: teleporterAddress,
+				FeeTokens:         []*RewardFeeToken{{Address: feeTokenAddress}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "no fee tokens",
+			rewardRedemption: &RewardRedemption{
+				TeleporterAddress: teleporterAddress,
+				RewardAddress:     rewardAddress,
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid threshold",
+			rewardRedemption: &RewardRedemption{
+				TeleporterAddress: teleporterAddress,
+				RewardAddress:     rewardAddress,
+				FeeTokens:         []*RewardFeeToken{{Address: feeTokenAddress, Threshold: "-1"}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "duplicate fee tokens",
+			rewardRedemption: &RewardRedemption{
+				TeleporterAddress: teleporterAddress,
+				RewardAddress:     rewardAddress,
+				FeeTokens: []*RewardFeeToken{
+					{Address: feeTokenAddress, Threshold: "1000"},
+					{Address: feeTokenAddress, Threshold: "2000"},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			destinationBlockchain := TestValidDestinationBlockchainConfig
+			destinationBlockchain.RewardRedemption = testCase.rewardRedemption
+			err := destinationBlockchain.Validate()
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			rewardRedemption := destinationBlockchain.RewardRedemption
+			require.Equal(t, uint64(defaultRewardPollingIntervalSeconds), rewardRedemption.PollingIntervalSeconds)
+			require.Equal(t, common.HexToAddress(teleporterAddress), rewardRedemption.GetTeleporterAddress())
+			require.Equal(t, common.HexToAddress(rewardAddress), rewardRedemption.GetRewardAddress())
+			require.Equal(t, common.HexToAddress(feeTokenAddress), rewardRedemption.FeeTokens[0].GetAddress())
+			require.Equal(t, testCase.expectedThreshold, rewardRedemption.FeeTokens[0].GetThreshold().String())
+		})
+	}
+}
+
+func TestDestinationBlockchainReceiptSweeper(t *testing.T) {
+	teleporterAddress := "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf"
+	rewardAddress := "0x27aE10273D17Cd7e80de8580A51f476960626e5f"
+	counterpartBlockchainID := testBlockchainID2
+	testCases := []struct {
+		name           string
+		receiptSweeper *ReceiptSweeper
+		expectedErr    bool
+	}{
+		{
+			name: "valid",
+			receiptSweeper: &ReceiptSweeper{
+				TeleporterAddress:        teleporterAddress,
+				RewardAddress:            rewardAddress,
+				CounterpartBlockchainIDs: []string{counterpartBlockchainID},
+			},
+		},
+		{
+			name: "invalid teleporter address",
+			receiptSweeper: &ReceiptSweeper{
+				TeleporterAddress:        "invalid",
+				RewardAddress:            rewardAddress,
+				CounterpartBlockchainIDs: []string{counterpartBlockchainID},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid reward address",
+			receiptSweeper: &ReceiptSweeper{
+				TeleporterAddress:        teleporterAddress,
+				CounterpartBlockchainIDs: []string{counterpartBlockchainID},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "no counterpart blockchains",
+			receiptSweeper: &ReceiptSweeper{
+				TeleporterAddress: teleporterAddress,
+				RewardAddress:     rewardAddress,
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid counterpart blockchain",
+			receiptSweeper: &ReceiptSweeper{
+				TeleporterAddress:        teleporterAddress,
+				RewardAddress:            rewardAddress,
+				CounterpartBlockchainIDs: []string{"invalid"},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "destination blockchain as counterpart",
+			receiptSweeper: &ReceiptSweeper{
+				TeleporterAddress:        teleporterAddress,
+				RewardAddress:            rewardAddress,
+				CounterpartBlockchainIDs: []string{TestValidDestinationBlockchainConfig.BlockchainID},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "duplicate counterpart blockchains",
+			receiptSweeper: &ReceiptSweeper{
+				TeleporterAddress:        teleporterAddress,
+				RewardAddress:            rewardAddress,
+				CounterpartBlockchainIDs: []string{counterpartBlockchainID, counterpartBlockchainID},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			destinationBlockchain := TestValidDestinationBlockchainConfig
+			destinationBlockchain.ReceiptSweeper = testCase.receiptSweeper
+			err := destinationBlockchain.Validate()
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			receiptSweeper := destinationBlockchain.ReceiptSweeper
+			require.Equal(t, uint64(defaultMaxPendingReceipts), receiptSweeper.MaxPendingReceipts)
+			require.Equal(t, uint64(defaultMaxReceiptAgeSeconds), receiptSweeper.MaxReceiptAgeSeconds)
+			require.Equal(t, uint64(defaultReceiptSweepIntervalSeconds), receiptSweeper.PollingIntervalSeconds)
+			require.Equal(t, common.HexToAddress(teleporterAddress), receiptSweeper.GetTeleporterAddress())
+			require.Equal(t, common.HexToAddress(rewardAddress), receiptSweeper.GetRewardAddress())
+			expectedID, err := ids.FromString(counterpartBlockchainID)
+			require.NoError(t, err)
+			require.Equal(t, []ids.ID{expectedID}, receiptSweeper.GetCounterpartBlockchainIDs())
+		})
+	}
+}
+
+func TestDestinationBlockchainExecutionRetry(t *testing.T) {
+	teleporterAddress := "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf"
+	allowedAddress := "0x27aE10273D17Cd7e80de8580A51f476960626e5f"
+	testCases := []struct {
+		name                string
+		executionRetry      *ExecutionRetry
+		expectedRetryDelays []uint64
+		expectedErr         bool
+	}{
+		{
+			name: "default schedule",
+			executionRetry: &ExecutionRetry{
+				TeleporterAddress:           teleporterAddress,
+				AllowedDestinationAddresses: []string{allowedAddress},
+			},
+			expectedRetryDelays: defaultExecutionRetryDelaysSeconds,
+		},
+		{
+			name: "configured schedule",
+			executionRetry: &ExecutionRetry{
+				TeleporterAddress:           teleporterAddress,
+				AllowedDestinationAddresses: []string{allowedAddress},
+				RetryDelaysSeconds:          []uint64{10, 20},
+			},
+			expectedRetryDelays: []uint64{10, 20},
+		},
+		{
+			name: "invalid teleporter address",
+			executionRetry: &ExecutionRetry{
+				TeleporterAddress:           "invalid",
+				AllowedDestinationAddresses: []string{allowedAddress},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "no allowed destination addresses",
+			executionRetry: &ExecutionRetry{
+				TeleporterAddress: teleporterAddress,
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid allowed destination address",
+			executionRetry: &ExecutionRetry{
+				TeleporterAddress:           teleporterAddress,
+				AllowedDestinationAddresses: []string{"invalid"},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			destinationBlockchain := TestValidDestinationBlockchainConfig
+			destinationBlockchain.ExecutionRetry = testCase.executionRetry
+			err := destinationBlockchain.Validate()
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			executionRetry := destinationBlockchain.ExecutionRetry
+			require.Equal(t, testCase.expectedRetryDelays, executionRetry.RetryDelaysSeconds)
+			require.Equal(t, uint64(defaultExecutionRetryIntervalSeconds), executionRetry.PollingIntervalSeconds)
+			require.Equal(t, common.HexToAddress(teleporterAddress), executionRetry.GetTeleporterAddress())
+			require.True(t, executionRetry.IsAllowedDestination(common.HexToAddress(allowedAddress)))
+			require.False(t, executionRetry.IsAllowedDestination(common.HexToAddress(teleporterAddress)))
+		})
+	}
+}
+
 func TestCountSuppliedSubnets(t *testing.T) {
 	config := Config{
 		SourceBlockchains: []*SourceBlockchain{
@@ -1120,3 +1670,636 @@ func TestGetJSONTag(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateLeaderElection(t *testing.T) {
+	testCases := []struct {
+		name          string
+		updateConfig  func(*Config)
+		expectedError string
+	}{
+		{
+			name:         "disabled",
+			updateConfig: func(*Config) {},
+		},
+		{
+			name: "enabled",
+			updateConfig: func(c *Config) {
+				c.EnableLeaderElection = true
+				c.RedisURL = "redis://localhost:6379"
+			},
+		},
+		{
+			name: "enabled without redis url",
+			updateConfig: func(c *Config) {
+				c.EnableLeaderElection = true
+			},
+			expectedError: "enable-leader-election requires redis-url to be set",
+		},
+		{
+			name: "lease too short",
+			updateConfig: func(c *Config) {
+				c.EnableLeaderElection = true
+				c.RedisURL = "redis://localhost:6379"
+				c.LeaderLeaseSeconds = 2
+			},
+			expectedError: "leader-lease-seconds must be at least 3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestValidConfig
+			cfg.LeaderLeaseName = defaultLeaderLeaseName
+			cfg.LeaderLeaseSeconds = defaultLeaderLeaseSeconds
+			tc.updateConfig(&cfg)
+
+			err := cfg.Validate()
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateDryRun(t *testing.T) {
+	testCases := []struct {
+		name              string
+		updateConfig      func(*Config)
+		expectedLeaseName string
+		expectedError     string
+	}{
+		{
+			name:              "disabled",
+			updateConfig:      func(*Config) {},
+			expectedLeaseName: defaultLeaderLeaseName,
+		},
+		{
+			name: "enabled",
+			updateConfig: func(c *Config) {
+				c.DryRun = true
+			},
+			expectedLeaseName: defaultDryRunNamespace + ":" + defaultLeaderLeaseName,
+		},
+		{
+			name: "enabled without namespace",
+			updateConfig: func(c *Config) {
+				c.DryRun = true
+				c.DryRunNamespace = ""
+			},
+			expectedError: "dry-run-namespace must be set if dry-run is set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestValidConfig
+			cfg.LeaderLeaseName = defaultLeaderLeaseName
+			cfg.DryRunNamespace = defaultDryRunNamespace
+			tc.updateConfig(&cfg)
+
+			err := cfg.Validate()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedLeaseName, cfg.GetLeaderLeaseName())
+		})
+	}
+}
+
+func TestValidateDeadLetterQueue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		updateConfig  func(*Config)
+		expectedError string
+	}{
+		{
+			name:         "disabled",
+			updateConfig: func(*Config) {},
+		},
+		{
+			name: "enabled",
+			updateConfig: func(c *Config) {
+				c.EnableDeadLetterQueue = true
+				c.DeadLetterQueueMaxSize = defaultDeadLetterQueueMaxSize
+			},
+		},
+		{
+			name: "enabled without max size",
+			updateConfig: func(c *Config) {
+				c.EnableDeadLetterQueue = true
+			},
+			expectedError: "dead-letter-queue-max-size must be greater than 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestValidConfig
+			tc.updateConfig(&cfg)
+
+			err := cfg.Validate()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateStorageType(t *testing.T) {
+	testCases := []struct {
+		name          string
+		updateConfig  func(*Config)
+		expectedError string
+	}{
+		{
+			name:         "unset",
+			updateConfig: func(*Config) {},
+		},
+		{
+			name: "json",
+			updateConfig: func(c *Config) {
+				c.StorageType = JSONStorageType
+			},
+		},
+		{
+			name: "leveldb",
+			updateConfig: func(c *Config) {
+				c.StorageType = LevelDBStorageType
+			},
+		},
+		{
+			name: "leveldb with redis url",
+			updateConfig: func(c *Config) {
+				c.StorageType = LevelDBStorageType
+				c.RedisURL = "redis://localhost:6379"
+			},
+			expectedError: "storage-type leveldb can not be used with redis-url",
+		},
+		{
+			name: "invalid",
+			updateConfig: func(c *Config) {
+				c.StorageType = "sqlite"
+			},
+			expectedError: `invalid storage-type "sqlite"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestValidConfig
+			tc.updateConfig(&cfg)
+
+			err := cfg.Validate()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateRedis(t *testing.T) {
+	testCases := []struct {
+		name          string
+		updateConfig  func(*Config)
+		expectedError string
+	}{
+		{
+			name:         "unset",
+			updateConfig: func(*Config) {},
+		},
+		{
+			name: "cluster with key prefix and expiry",
+			updateConfig: func(c *Config) {
+				c.RedisURL = "rediss://localhost:6379?addr=localhost:6380"
+				c.RedisMode = RedisClusterMode
+				c.RedisKeyPrefix = "relayer-a"
+				c.RedisKeyExpirySeconds = 86400
+			},
+		},
+		{
+			name: "client certificate",
+			updateConfig: func(c *Config) {
+				c.RedisURL = "rediss://localhost:6379"
+				c.RedisTLSCertPath = "client.crt"
+				c.RedisTLSKeyPath = "client.key"
+			},
+		},
+		{
+			name: "invalid mode",
+			updateConfig: func(c *Config) {
+				c.RedisMode = "replicated"
+			},
+			expectedError: `invalid redis-mode "replicated"`,
+		},
+		{
+			name: "client certificate without key",
+			updateConfig: func(c *Config) {
+				c.RedisTLSCertPath = "client.crt"
+			},
+			expectedError: "redis-tls-cert-path and redis-tls-key-path must be set together",
+		},
+		{
+			name: "key expiry within write interval",
+			updateConfig: func(c *Config) {
+				c.RedisKeyExpirySeconds = c.DBWriteIntervalSeconds
+			},
+			expectedError: "redis-key-expiry-seconds must be greater than db-write-interval-seconds",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestValidConfig
+			tc.updateConfig(&cfg)
+
+			err := cfg.Validate()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateDecider(t *testing.T) {
+	testCases := []struct {
+		name          string
+		updateConfig  func(*Config)
+		expectedError string
+	}{
+		{
+			name:         "unset",
+			updateConfig: func(*Config) {},
+		},
+		{
+			name: "fail closed with mutual tls",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "dns:///localhost:50051"
+				c.DeciderFailurePolicy = DeciderFailClosed
+				c.DeciderTimeoutSeconds = 5
+				c.DeciderTLS = true
+				c.DeciderTLSCertPath = "client.crt"
+				c.DeciderTLSKeyPath = "client.key"
+				c.DeciderTLSCACertPath = "ca.crt"
+				c.DeciderHeaders = map[string]string{"authorization": "Bearer token"}
+			},
+		},
+		{
+			name: "zero timeout",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "dns:///localhost:50051"
+				c.DeciderTimeoutSeconds = 0
+			},
+			expectedError: "decider-timeout-seconds must be greater than 0",
+		},
+		{
+			name: "invalid failure policy",
+			updateConfig: func(c *Config) {
+				c.DeciderFailurePolicy = "fail-slow"
+			},
+			expectedError: `invalid decider-failure-policy "fail-slow"`,
+		},
+		{
+			name: "client certificate without key",
+			updateConfig: func(c *Config) {
+				c.DeciderTLS = true
+				c.DeciderTLSCertPath = "client.crt"
+			},
+			expectedError: "decider-tls-cert-path and decider-tls-key-path must be set together",
+		},
+		{
+			name: "certificates without tls",
+			updateConfig: func(c *Config) {
+				c.DeciderTLSCACertPath = "ca.crt"
+			},
+			expectedError: "decider-tls must be set if decider TLS certificates are set",
+		},
+		{
+			name: "rules",
+			updateConfig: func(c *Config) {
+				c.DeciderRulesDefaultAction = DeciderRuleDeny
+				c.DeciderRules = []*DeciderRule{
+					{
+						Action:                   DeciderRuleAllow,
+						DestinationBlockchainIDs: []string{testBlockchainID},
+						SenderAddresses:          []string{testAddress},
+						MaxRequiredGasLimit:      1_000_000,
+						MinFeeAmount:             "1000",
+					},
+				}
+				c.DeciderRulesFile = "rules.json"
+			},
+		},
+		{
+			name: "http decider with cache",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "https://decider.example.com/should-send-message"
+				c.DeciderTimeoutSeconds = 5
+				c.DeciderCacheTTLSeconds = 60
+				c.DeciderTLS = true
+				c.DeciderTLSCACertPath = "ca.crt"
+			},
+		},
+		{
+			name: "cache with grpc decider",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "dns:///localhost:50051"
+				c.DeciderTimeoutSeconds = 5
+				c.DeciderCacheTTLSeconds = 60
+			},
+			expectedError: "decider-cache-ttl-seconds can only be set for http or https decider-url",
+		},
+		{
+			name: "tls with http decider",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "http://decider.example.com"
+				c.DeciderTimeoutSeconds = 5
+				c.DeciderTLS = true
+			},
+			expectedError: "decider-tls can not be set for http decider-url",
+		},
+		{
+			name: "headers with https decider",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "https://decider.example.com/should-send-message"
+				c.DeciderTimeoutSeconds = 5
+				c.DeciderHeaders = map[string]string{"authorization": "Bearer token"}
+			},
+		},
+		{
+			name: "headers without tls",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "dns:///localhost:50051"
+				c.DeciderTimeoutSeconds = 5
+				c.DeciderHeaders = map[string]string{"authorization": "Bearer token"}
+			},
+			expectedError: "decider-headers require decider-tls or an https decider-url",
+		},
+		{
+			name: "headers with http decider",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "http://decider.example.com"
+				c.DeciderTimeoutSeconds = 5
+				c.DeciderHeaders = map[string]string{"authorization": "Bearer token"}
+			},
+			expectedError: "decider-headers require decider-tls or an https decider-url",
+		},
+		{
+			name: "rules with decider url",
+			updateConfig: func(c *Config) {
+				c.DeciderURL = "dns:///localhost:50051"
+				c.DeciderTimeoutSeconds = 5
+				c.DeciderRulesFile = "rules.json"
+			},
+			expectedError: "decider-url can not be set with decider-rules or decider-rules-file",
+		},
+		{
+			name: "invalid default action",
+			updateConfig: func(c *Config) {
+				c.DeciderRulesDefaultAction = "skip"
+			},
+			expectedError: `invalid decider-rules-default-action "skip"`,
+		},
+		{
+			name: "invalid rule action",
+			updateConfig: func(c *Config) {
+				c.DeciderRules = []*DeciderRule{{Action: "skip"}}
+			},
+			expectedError: `failed to validate decider rule 0: invalid action "skip"`,
+		},
+		{
+			name: "invalid rule sender address",
+			updateConfig: func(c *Config) {
+				c.DeciderRules = []*DeciderRule{{Action: DeciderRuleDeny, SenderAddresses: []string{"0x1234"}}}
+			},
+			expectedError: "failed to validate decider rule 0: invalid sender-addresses: invalid address 0x1234",
+		},
+		{
+			name: "invalid rule gas limits",
+			updateConfig: func(c *Config) {
+				c.DeciderRules = []*DeciderRule{
+					{Action: DeciderRuleAllow, MinRequiredGasLimit: 200_000, MaxRequiredGasLimit: 100_000},
+				}
+			},
+			expectedError: "failed to validate decider rule 0: max-required-gas-limit 100000 is less than " +
+				"min-required-gas-limit 200000",
+		},
+		{
+			name: "invalid rule min fee amount",
+			updateConfig: func(c *Config) {
+				c.DeciderRules = []*DeciderRule{{Action: DeciderRuleAllow, MinFeeAmount: "-1"}}
+			},
+			expectedError: "failed to validate decider rule 0: invalid min-fee-amount: -1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestValidConfig
+			tc.updateConfig(&cfg)
+
+			err := cfg.Validate()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateEventSinks(t *testing.T) {
+	sourceBlockchainID := ids.GenerateTestID()
+	testCases := []struct {
+		name          string
+		sink          EventSink
+		expectedError string
+	}{
+		{
+			name: "webhook",
+			sink: EventSink{
+				Type:                WebhookEventSinkType,
+				URL:                 "https://example.com/events",
+				HMACSecret:          "secret",
+				SourceBlockchainIDs: []string{sourceBlockchainID.String()},
+			},
+		},
+		{
+			name: "file",
+			sink: EventSink{Type: FileEventSinkType, Path: "./events.jsonl"},
+		},
+		{
+			name:          "webhook without url",
+			sink:          EventSink{Type: WebhookEventSinkType},
+			expectedError: "failed to validate event sink 0: url must be set for webhook event sinks",
+		},
+		{
+			name:          "file without path",
+			sink:          EventSink{Type: FileEventSinkType},
+			expectedError: "failed to validate event sink 0: path must be set for file event sinks",
+		},
+		{
+			name:          "invalid type",
+			sink:          EventSink{Type: "kafka"},
+			expectedError: `failed to validate event sink 0: invalid event sink type "kafka"`,
+		},
+		{
+			name: "invalid destination blockchain id",
+			sink: EventSink{
+				Type:                     FileEventSinkType,
+				Path:                     "./events.jsonl",
+				DestinationBlockchainIDs: []string{"invalid"},
+			},
+			expectedError: "failed to validate event sink 0: invalid destination-blockchain-ids",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestValidConfig
+			sink := tc.sink
+			cfg.EventSinks = []*EventSink{&sink}
+
+			err := cfg.Validate()
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			if sink.Type == WebhookEventSinkType {
+				require.Equal(t, defaultEventSinkMaxRetries, sink.MaxRetries)
+			}
+		})
+	}
+}
+
+func TestEventSinkIncludesRoute(t *testing.T) {
+	sourceBlockchainID := ids.GenerateTestID()
+	destinationBlockchainID := ids.GenerateTestID()
+	otherBlockchainID := ids.GenerateTestID()
+
+	allRoutes := EventSink{Type: FileEventSinkType, Path: "./events.jsonl"}
+	require.NoError(t, allRoutes.validate())
+	require.True(t, allRoutes.IncludesRoute(sourceBlockchainID, destinationBlockchainID))
+
+	fromSource := EventSink{
+		Type:                FileEventSinkType,
+		Path:                "./events.jsonl",
+		SourceBlockchainIDs: []string{sourceBlockchainID.String()},
+	}
+	require.NoError(t, fromSource.validate())
+	require.True(t, fromSource.IncludesRoute(sourceBlockchainID, destinationBlockchainID))
+	require.True(t, fromSource.IncludesRoute(sourceBlockchainID, otherBlockchainID))
+	require.False(t, fromSource.IncludesRoute(otherBlockchainID, destinationBlockchainID))
+
+	route := EventSink{
+		Type:                     FileEventSinkType,
+		Path:                     "./events.jsonl",
+		SourceBlockchainIDs:      []string{sourceBlockchainID.String()},
+		DestinationBlockchainIDs: []string{destinationBlockchainID.String()},
+	}
+	require.NoError(t, route.validate())
+	require.True(t, route.IncludesRoute(sourceBlockchainID, destinationBlockchainID))
+	require.False(t, route.IncludesRoute(sourceBlockchainID, otherBlockchainID))
+}
+
+func TestValidateReload(t *testing.T) {
+	testCases := []struct {
+		name          string
+		updateConfig  func(*Config)
+		expectedError string
+	}{
+		{
+			name:         "unchanged",
+			updateConfig: func(*Config) {},
+		},
+		{
+			name: "reloadable options changed",
+			updateConfig: func(c *Config) {
+				c.LogLevel = "debug"
+				c.DeciderURL = "http://localhost:50051"
+				c.DeciderFailurePolicy = DeciderFailClosed
+				c.DeciderHeaders = map[string]string{"authorization": "Bearer token"}
+				c.SourceBlockchains = append(c.SourceBlockchains, &TestValidSourceBlockchainConfig)
+				c.DestinationBlockchains = append(c.DestinationBlockchains, &TestValidDestinationBlockchainConfig)
+			},
+		},
+		{
+			name: "api port changed",
+			updateConfig: func(c *Config) {
+				c.APIPort++
+			},
+			expectedError: "api-port cannot be changed without restarting the relayer",
+		},
+		{
+			name: "p-chain api changed",
+			updateConfig: func(c *Config) {
+				c.PChainAPI = &basecfg.APIConfig{
+					BaseURL: "http://localhost:9650",
+				}
+			},
+			expectedError: "p-chain-api cannot be changed without restarting the relayer",
+		},
+		{
+			name: "dead-letter queue enabled",
+			updateConfig: func(c *Config) {
+				c.EnableDeadLetterQueue = true
+			},
+			expectedError: "enable-dead-letter-queue cannot be changed without restarting the relayer",
+		},
+		{
+			name: "leader election enabled",
+			updateConfig: func(c *Config) {
+				c.EnableLeaderElection = true
+			},
+			expectedError: "enable-leader-election cannot be changed without restarting the relayer",
+		},
+		{
+			name: "dry run enabled",
+			updateConfig: func(c *Config) {
+				c.DryRun = true
+			},
+			expectedError: "dry-run cannot be changed without restarting the relayer",
+		},
+		{
+			name: "storage type changed",
+			updateConfig: func(c *Config) {
+				c.StorageType = LevelDBStorageType
+			},
+			expectedError: "storage-type cannot be changed without restarting the relayer",
+		},
+		{
+			name: "redis key prefix changed",
+			updateConfig: func(c *Config) {
+				c.RedisKeyPrefix = "relayer-b"
+			},
+			expectedError: "redis-key-prefix cannot be changed without restarting the relayer",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			current := TestValidConfig
+			updated := TestValidConfig
+			updated.SourceBlockchains = append([]*SourceBlockchain{}, TestValidConfig.SourceBlockchains...)
+			updated.DestinationBlockchains = append([]*DestinationBlockchain{}, TestValidConfig.DestinationBlockchains...)
+			tc.updateConfig(&updated)
+
+			err := current.ValidateReload(&updated)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/libevm/common"
+)
+
+// Actions of decider rules
+const (
+	DeciderRuleAllow = "allow"
+	DeciderRuleDeny  = "deny"
+)
+
+const defaultDeciderRulesDefaultAction = DeciderRuleAllow
+
+// DeciderRule allows or denies the messages that it matches. A message matches the rule if it matches all
+// of the rule's conditions that are set. Messages match AllowedRelayerAddresses if any of those addresses
+// are allowed to deliver the message, including messages that any relayer may deliver.
+type DeciderRule struct {
+	Action                   string   `mapstructure:"action" json:"action"`
+	Reason                   string   `mapstructure:"reason" json:"reason,omitempty"`
+	SourceBlockchainIDs      []string `mapstructure:"source-blockchain-ids" json:"source-blockchain-ids,omitempty"`
+	DestinationBlockchainIDs []string `mapstructure:"destination-blockchain-ids" json:"destination-blockchain-ids,omitempty"` //nolint:lll
+	SenderAddresses          []string `mapstructure:"sender-addresses" json:"sender-addresses,omitempty"`
+	DestinationAddresses     []string `mapstructure:"destination-addresses" json:"destination-addresses,omitempty"`
+	MinRequiredGasLimit      uint64   `mapstructure:"min-required-gas-limit" json:"min-required-gas-limit,omitempty"`
+	MaxRequiredGasLimit      uint64   `mapstructure:"max-required-gas-limit" json:"max-required-gas-limit,omitempty"`
+	FeeTokenAddresses        []string `mapstructure:"fee-token-addresses" json:"fee-token-addresses,omitempty"`
+	MinFeeAmount             string   `mapstructure:"min-fee-amount" json:"min-fee-amount,omitempty"`
+	AllowedRelayerAddresses  []string `mapstructure:"allowed-relayer-addresses" json:"allowed-relayer-addresses,omitempty"` //nolint:lll
+
+	// convenience fields to access parsed data after initialization
+	sourceBlockchainIDs      set.Set[ids.ID]
+	destinationBlockchainIDs set.Set[ids.ID]
+	senderAddresses          set.Set[common.Address]
+	destinationAddresses     set.Set[common.Address]
+	feeTokenAddresses        set.Set[common.Address]
+	minFeeAmount             *big.Int
+	allowedRelayerAddresses  set.Set[common.Address]
+}
+
+// DeciderRuleMessage is the message that decider rules are evaluated against
+type DeciderRuleMessage struct {
+	SourceBlockchainID      ids.ID
+	DestinationBlockchainID ids.ID
+	SenderAddress           common.Address
+	DestinationAddress      common.Address
+	RequiredGasLimit        uint64
+	// The zero address and amount if the message does not pay a fee
+	FeeTokenAddress common.Address
+	FeeAmount       *big.Int
+	// Empty if any relayer may deliver the message
+	AllowedRelayerAddresses []common.Address
+}
+
+// Validate checks the rule, and parses its conditions. Rules must be validated before they are evaluated.
+func (r *DeciderRule) Validate() error {
+	switch r.Action {
+	case DeciderRuleAllow, DeciderRuleDeny:
+	default:
+		return fmt.Errorf("invalid action %q", r.Action)
+	}
+	if r.MaxRequiredGasLimit != 0 && r.MaxRequiredGasLimit < r.MinRequiredGasLimit {
+		return fmt.Errorf(
+			"max-required-gas-limit %d is less than min-required-gas-limit %d",
+			r.MaxRequiredGasLimit,
+			r.MinRequiredGasLimit,
+		)
+	}
+
+	var err error
+	if r.sourceBlockchainIDs, err = parseBlockchainIDs(r.SourceBlockchainIDs); err != nil {
+		return fmt.Errorf("invalid source-blockchain-ids: %w", err)
+	}
+	if r.destinationBlockchainIDs, err = parseBlockchainIDs(r.DestinationBlockchainIDs); err != nil {
+		return fmt.Errorf("invalid destination-blockchain-ids: %w", err)
+	}
+	if r.senderAddresses, err = parseAddresses(r.SenderAddresses); err != nil {
+		return fmt.Errorf("invalid sender-addresses: %w", err)
+	}
+	if r.destinationAddresses, err = parseAddresses(r.DestinationAddresses); err != nil {
+		return fmt.Errorf("invalid destination-addresses: %w", err)
+	}
+	if r.feeTokenAddresses, err = parseAddresses(r.FeeTokenAddresses); err != nil {
+		return fmt.Errorf("invalid fee-token-addresses: %w", err)
+	}
+	if r.allowedRelayerAddresses, err = parseAddresses(r.AllowedRelayerAddresses); err != nil {
+		return fmt.Errorf("invalid allowed-relayer-addresses: %w", err)
+	}
+
+	r.minFeeAmount = nil
+	if r.MinFeeAmount != "" {
+		minFeeAmount, ok := new(big.Int).SetString(r.MinFeeAmount, 10)
+		if !ok || minFeeAmount.Sign() < 0 {
+			return fmt.Errorf("invalid min-fee-amount: %s", r.MinFeeAmount)
+		}
+		r.minFeeAmount = minFeeAmount
+	}
+	return nil
+}
+
+func parseAddresses(addresses []string) (set.Set[common.Address], error) {
+	parsed := set.NewSet[common.Address](len(addresses))
+	for _, address := range addresses {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address %s", address)
+		}
+		parsed.Add(common.HexToAddress(address))
+	}
+	return parsed, nil
+}
+
+// Matches returns true if [message] matches all of the rule's conditions
+func (r *DeciderRule) Matches(message *DeciderRuleMessage) bool {
+	if !matchesSet(r.sourceBlockchainIDs, message.SourceBlockchainID) ||
+		!matchesSet(r.destinationBlockchainIDs, message.DestinationBlockchainID) ||
+		!matchesSet(r.senderAddresses, message.SenderAddress) ||
+		!matchesSet(r.destinationAddresses, message.DestinationAddress) ||
+		!matchesSet(r.feeTokenAddresses, message.FeeTokenAddress) {
+		return false
+	}
+	if message.RequiredGasLimit < r.MinRequiredGasLimit {
+		return false
+	}
+	if r.MaxRequiredGasLimit != 0 && message.RequiredGasLimit > r.MaxRequiredGasLimit {
+		return false
+	}
+	if r.minFeeAmount != nil && (message.FeeAmount == nil || message.FeeAmount.Cmp(r.minFeeAmount) < 0) {
+		return false
+	}
+	if r.allowedRelayerAddresses.Len() == 0 || len(message.AllowedRelayerAddresses) == 0 {
+		return true
+	}
+	for _, address := range message.AllowedRelayerAddresses {
+		if r.allowedRelayerAddresses.Contains(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesSet returns true if [values] is empty or contains [value]
+func matchesSet[T comparable](values set.Set[T], value T) bool {
+	return values.Len() == 0 || values.Contains(value)
+}
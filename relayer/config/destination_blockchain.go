@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
+	"slices"
+	"time"
 
 	"github.com/ava-labs/avalanchego/graft/subnet-evm/precompile/contracts/warp"
 	"github.com/ryt-io/ryt-v2/ids"
@@ -11,17 +14,99 @@ import (
 	"github.com/ryt-io/ryt-v2/utils/set"
 	basecfg "github.com/ryt-io/icm-services/config"
 	"github.com/ryt-io/icm-services/utils"
+	"github.com/ryt-io/libevm/common"
 	"github.com/ryt-io/libevm/crypto"
 )
 
 const (
 	// The block gas limit that can be specified for a Teleporter message
 	// Based on the C-Chain 15_000_000 gas limit per block, with other Warp message gas overhead conservatively estimated.
-	defaultBlockGasLimit             = 12_000_000
-	defaultMaxPriorityFeePerGas      = 2500000000 // 2.5 gwei
-	defaultTxInclusionTimeoutSeconds = 30
+	defaultBlockGasLimit                      = 12_000_000
+	defaultMaxPriorityFeePerGas               = 2500000000 // 2.5 gwei
+	defaultTxInclusionTimeoutSeconds          = 30
+	defaultNonceReconciliationIntervalSeconds = 30
+	defaultRewardPollingIntervalSeconds       = 300
+	defaultReceiptSweepIntervalSeconds        = 60
+	defaultMaxPendingReceipts                 = 10
+	defaultMaxReceiptAgeSeconds               = 3600
+	defaultExecutionRetryIntervalSeconds      = 30
+
+	defaultTxReplacementFeeBumpPercent = 20
+	// The minimum fee increase for a replacement transaction to be accepted by the mempool
+	minTxReplacementFeeBumpPercent = 10
 )
 
+// SpendLimit caps the total fees spent on transactions to a destination blockchain over a period.
+// If SourceBlockchainID is set, the limit only applies to messages from that source blockchain.
+type SpendLimit struct {
+	SourceBlockchainID string `mapstructure:"source-blockchain-id" json:"source-blockchain-id"`
+	MaxSpend           string `mapstructure:"max-spend" json:"max-spend"`
+	Period             string `mapstructure:"period" json:"period"`
+
+	// convenience fields to access parsed data after initialization
+	sourceBlockchainID ids.ID
+	maxSpend           *big.Int
+	period             SpendLimitPeriod
+}
+
+// RewardRedemption configures the redemption of the relayer rewards that the reward address has earned on the
+// blockchain's TeleporterMessenger. Rewards can only be redeemed by the reward address, so it must be the address
+// of one of the destination blockchain's signers.
+type RewardRedemption struct {
+	TeleporterAddress      string            `mapstructure:"teleporter-address" json:"teleporter-address"`
+	RewardAddress          string            `mapstructure:"reward-address" json:"reward-address"`
+	FeeTokens              []*RewardFeeToken `mapstructure:"fee-tokens" json:"fee-tokens"`
+	PollingIntervalSeconds uint64            `mapstructure:"polling-interval-seconds" json:"polling-interval-seconds"`
+
+	// convenience fields to access parsed data after initialization
+	teleporterAddress common.Address
+	rewardAddress     common.Address
+}
+
+// RewardFeeToken is a fee token whose rewards are redeemed once they reach the threshold
+type RewardFeeToken struct {
+	Address   string `mapstructure:"address" json:"address"`
+	Threshold string `mapstructure:"threshold" json:"threshold"`
+
+	// convenience fields to access parsed data after initialization
+	address   common.Address
+	threshold *big.Int
+}
+
+// Delays before each retry of a failed message execution
+var defaultExecutionRetryDelaysSeconds = []uint64{60, 600, 3600}
+
+// ReceiptSweeper configures sending the receipts of messages delivered by the relayer back to the
+// counterpart blockchains they were sent from, so that the relayer's rewards are allocated without
+// waiting for messages to be sent in the other direction.
+type ReceiptSweeper struct {
+	TeleporterAddress        string   `mapstructure:"teleporter-address" json:"teleporter-address"`
+	RewardAddress            string   `mapstructure:"reward-address" json:"reward-address"`
+	CounterpartBlockchainIDs []string `mapstructure:"counterpart-blockchain-ids" json:"counterpart-blockchain-ids"`
+	MaxPendingReceipts       uint64   `mapstructure:"max-pending-receipts" json:"max-pending-receipts"`
+	MaxReceiptAgeSeconds     uint64   `mapstructure:"max-receipt-age-seconds" json:"max-receipt-age-seconds"`
+	PollingIntervalSeconds   uint64   `mapstructure:"polling-interval-seconds" json:"polling-interval-seconds"`
+
+	// convenience fields to access parsed data after initialization
+	teleporterAddress        common.Address
+	rewardAddress            common.Address
+	counterpartBlockchainIDs []ids.ID
+}
+
+// ExecutionRetry configures retrying the execution of Teleporter messages to allow-listed contracts that failed
+// when the messages were delivered to the destination blockchain.
+type ExecutionRetry struct {
+	TeleporterAddress           string   `mapstructure:"teleporter-address" json:"teleporter-address"`
+	AllowedDestinationAddresses []string `mapstructure:"allowed-destination-addresses" json:"allowed-destination-addresses"` //nolint:lll
+	GasLimit                    uint64   `mapstructure:"gas-limit" json:"gas-limit"`
+	RetryDelaysSeconds          []uint64 `mapstructure:"retry-delays-seconds" json:"retry-delays-seconds"`
+	PollingIntervalSeconds      uint64   `mapstructure:"polling-interval-seconds" json:"polling-interval-seconds"`
+
+	// convenience fields to access parsed data after initialization
+	teleporterAddress           common.Address
+	allowedDestinationAddresses set.Set[common.Address]
+}
+
 type KMSKey struct {
 	KeyID     string `mapstructure:"key-id" json:"key-id"`
 	AWSRegion string `mapstructure:"aws-region" json:"aws-region"`
@@ -45,6 +130,20 @@ type DestinationBlockchain struct {
 
 	TxInclusionTimeoutSeconds uint64 `mapstructure:"tx-inclusion-timeout-seconds" json:"tx-inclusion-timeout-seconds"`
 
+	TxReplacementDelaySeconds       uint64 `mapstructure:"tx-replacement-delay-seconds" json:"tx-replacement-delay-seconds"`                 //nolint:lll
+	TxReplacementFeeBumpPercent     uint64 `mapstructure:"tx-replacement-fee-bump-percent" json:"tx-replacement-fee-bump-percent"`           //nolint:lll
+	MaxReplacementFeePerGas         uint64 `mapstructure:"max-replacement-fee-per-gas" json:"max-replacement-fee-per-gas"`                   //nolint:lll
+	MaxReplacementPriorityFeePerGas uint64 `mapstructure:"max-replacement-priority-fee-per-gas" json:"max-replacement-priority-fee-per-gas"` //nolint:lll
+
+	NonceReconciliationIntervalSeconds uint64 `mapstructure:"nonce-reconciliation-interval-seconds" json:"nonce-reconciliation-interval-seconds"` //nolint:lll
+
+	SpendLimits              []*SpendLimit `mapstructure:"spend-limits" json:"spend-limits"`
+	MaxTransactionsPerMinute uint64        `mapstructure:"max-transactions-per-minute" json:"max-transactions-per-minute"` //nolint:lll
+
+	RewardRedemption *RewardRedemption `mapstructure:"reward-redemption" json:"reward-redemption"`
+	ReceiptSweeper   *ReceiptSweeper   `mapstructure:"receipt-sweeper" json:"receipt-sweeper"`
+	ExecutionRetry   *ExecutionRetry   `mapstructure:"execution-retry" json:"execution-retry"`
+
 	// Fetched from the chain after startup
 	warpConfig WarpConfig
 
@@ -134,6 +233,203 @@ func (s *DestinationBlockchain) Validate() error {
 		s.TxInclusionTimeoutSeconds = defaultTxInclusionTimeoutSeconds
 	}
 
+	if s.NonceReconciliationIntervalSeconds == 0 {
+		s.NonceReconciliationIntervalSeconds = defaultNonceReconciliationIntervalSeconds
+	}
+
+	if err := s.validateTxReplacement(); err != nil {
+		return err
+	}
+
+	if err := s.validateSpendLimits(); err != nil {
+		return err
+	}
+
+	if s.RewardRedemption != nil {
+		if err := s.RewardRedemption.validate(); err != nil {
+			return fmt.Errorf("invalid reward-redemption: %w", err)
+		}
+	}
+
+	if s.ReceiptSweeper != nil {
+		if err := s.ReceiptSweeper.validate(s.blockchainID); err != nil {
+			return fmt.Errorf("invalid receipt-sweeper: %w", err)
+		}
+	}
+
+	if s.ExecutionRetry != nil {
+		if err := s.ExecutionRetry.validate(); err != nil {
+			return fmt.Errorf("invalid execution-retry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateTxReplacement validates the replacement of transactions that are not included in time.
+// Replacement is disabled if tx-replacement-delay-seconds is not set.
+func (s *DestinationBlockchain) validateTxReplacement() error {
+	if s.TxReplacementDelaySeconds == 0 {
+		return nil
+	}
+	if s.TxReplacementDelaySeconds >= s.TxInclusionTimeoutSeconds {
+		return errors.New("tx-replacement-delay-seconds must be less than tx-inclusion-timeout-seconds")
+	}
+	if s.TxReplacementFeeBumpPercent == 0 {
+		s.TxReplacementFeeBumpPercent = defaultTxReplacementFeeBumpPercent
+	}
+	if s.TxReplacementFeeBumpPercent < minTxReplacementFeeBumpPercent {
+		return fmt.Errorf("tx-replacement-fee-bump-percent must be at least %d", minTxReplacementFeeBumpPercent)
+	}
+	if s.MaxReplacementFeePerGas == 0 {
+		return errors.New("max-replacement-fee-per-gas must be set if tx-replacement-delay-seconds is set")
+	}
+	if s.MaxReplacementPriorityFeePerGas == 0 {
+		s.MaxReplacementPriorityFeePerGas = s.MaxPriorityFeePerGas
+	}
+	return nil
+}
+
+func (s *DestinationBlockchain) validateSpendLimits() error {
+	type spendLimitKey struct {
+		sourceBlockchainID ids.ID
+		period             SpendLimitPeriod
+	}
+	spendLimitKeys := set.NewSet[spendLimitKey](len(s.SpendLimits))
+	for _, limit := range s.SpendLimits {
+		if limit.SourceBlockchainID != "" {
+			sourceBlockchainID, err := utils.HexOrCB58ToID(limit.SourceBlockchainID)
+			if err != nil {
+				return fmt.Errorf("invalid source-blockchain-id '%s' in spend limit: %w", limit.SourceBlockchainID, err)
+			}
+			limit.sourceBlockchainID = sourceBlockchainID
+		}
+		maxSpend, ok := new(big.Int).SetString(limit.MaxSpend, 10)
+		if !ok || maxSpend.Sign() <= 0 {
+			return fmt.Errorf("invalid max-spend '%s' in spend limit, must be a positive integer", limit.MaxSpend)
+		}
+		limit.maxSpend = maxSpend
+		limit.period = ParseSpendLimitPeriod(limit.Period)
+		if limit.period == UNKNOWN_SPEND_LIMIT_PERIOD {
+			return fmt.Errorf("invalid period '%s' in spend limit, must be one of 'hour' or 'day'", limit.Period)
+		}
+
+		key := spendLimitKey{sourceBlockchainID: limit.sourceBlockchainID, period: limit.period}
+		if spendLimitKeys.Contains(key) {
+			return fmt.Errorf(
+				"duplicate %s spend limit for source-blockchain-id '%s'",
+				limit.Period,
+				limit.SourceBlockchainID,
+			)
+		}
+		spendLimitKeys.Add(key)
+	}
+	return nil
+}
+
+func (r *RewardRedemption) validate() error {
+	if !common.IsHexAddress(r.TeleporterAddress) {
+		return fmt.Errorf("invalid teleporter-address '%s'", r.TeleporterAddress)
+	}
+	r.teleporterAddress = common.HexToAddress(r.TeleporterAddress)
+	if !common.IsHexAddress(r.RewardAddress) {
+		return fmt.Errorf("invalid reward-address '%s'", r.RewardAddress)
+	}
+	r.rewardAddress = common.HexToAddress(r.RewardAddress)
+	if len(r.FeeTokens) == 0 {
+		return errors.New("fee-tokens must not be empty")
+	}
+	feeTokenAddresses := set.NewSet[common.Address](len(r.FeeTokens))
+	for _, feeToken := range r.FeeTokens {
+		if !common.IsHexAddress(feeToken.Address) {
+			return fmt.Errorf("invalid fee token address '%s'", feeToken.Address)
+		}
+		feeToken.address = common.HexToAddress(feeToken.Address)
+		if feeTokenAddresses.Contains(feeToken.address) {
+			return fmt.Errorf("duplicate fee token address '%s'", feeToken.Address)
+		}
+		feeTokenAddresses.Add(feeToken.address)
+
+		feeToken.threshold = big.NewInt(0)
+		if feeToken.Threshold != "" {
+			threshold, ok := new(big.Int).SetString(feeToken.Threshold, 10)
+			if !ok || threshold.Sign() < 0 {
+				return fmt.Errorf(
+					"invalid threshold '%s' for fee token '%s', must be a non-negative integer",
+					feeToken.Threshold,
+					feeToken.Address,
+				)
+			}
+			feeToken.threshold = threshold
+		}
+	}
+	if r.PollingIntervalSeconds == 0 {
+		r.PollingIntervalSeconds = defaultRewardPollingIntervalSeconds
+	}
+	return nil
+}
+
+func (r *ReceiptSweeper) validate(blockchainID ids.ID) error {
+	if !common.IsHexAddress(r.TeleporterAddress) {
+		return fmt.Errorf("invalid teleporter-address '%s'", r.TeleporterAddress)
+	}
+	r.teleporterAddress = common.HexToAddress(r.TeleporterAddress)
+	if !common.IsHexAddress(r.RewardAddress) {
+		return fmt.Errorf("invalid reward-address '%s'", r.RewardAddress)
+	}
+	r.rewardAddress = common.HexToAddress(r.RewardAddress)
+	if len(r.CounterpartBlockchainIDs) == 0 {
+		return errors.New("counterpart-blockchain-ids must not be empty")
+	}
+	counterpartBlockchainIDs := set.NewSet[ids.ID](len(r.CounterpartBlockchainIDs))
+	r.counterpartBlockchainIDs = make([]ids.ID, 0, len(r.CounterpartBlockchainIDs))
+	for _, id := range r.CounterpartBlockchainIDs {
+		counterpartBlockchainID, err := utils.HexOrCB58ToID(id)
+		if err != nil {
+			return fmt.Errorf("invalid counterpart blockchain ID '%s': %w", id, err)
+		}
+		if counterpartBlockchainID == blockchainID {
+			return fmt.Errorf("counterpart blockchain ID '%s' is the destination blockchain", id)
+		}
+		if counterpartBlockchainIDs.Contains(counterpartBlockchainID) {
+			return fmt.Errorf("duplicate counterpart blockchain ID '%s'", id)
+		}
+		counterpartBlockchainIDs.Add(counterpartBlockchainID)
+		r.counterpartBlockchainIDs = append(r.counterpartBlockchainIDs, counterpartBlockchainID)
+	}
+	if r.MaxPendingReceipts == 0 {
+		r.MaxPendingReceipts = defaultMaxPendingReceipts
+	}
+	if r.MaxReceiptAgeSeconds == 0 {
+		r.MaxReceiptAgeSeconds = defaultMaxReceiptAgeSeconds
+	}
+	if r.PollingIntervalSeconds == 0 {
+		r.PollingIntervalSeconds = defaultReceiptSweepIntervalSeconds
+	}
+	return nil
+}
+
+func (r *ExecutionRetry) validate() error {
+	if !common.IsHexAddress(r.TeleporterAddress) {
+		return fmt.Errorf("invalid teleporter-address '%s'", r.TeleporterAddress)
+	}
+	r.teleporterAddress = common.HexToAddress(r.TeleporterAddress)
+	if len(r.AllowedDestinationAddresses) == 0 {
+		return errors.New("allowed-destination-addresses must not be empty")
+	}
+	r.allowedDestinationAddresses = set.NewSet[common.Address](len(r.AllowedDestinationAddresses))
+	for _, address := range r.AllowedDestinationAddresses {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("invalid allowed destination address '%s'", address)
+		}
+		r.allowedDestinationAddresses.Add(common.HexToAddress(address))
+	}
+	if len(r.RetryDelaysSeconds) == 0 {
+		r.RetryDelaysSeconds = slices.Clone(defaultExecutionRetryDelaysSeconds)
+	}
+	if r.PollingIntervalSeconds == 0 {
+		r.PollingIntervalSeconds = defaultExecutionRetryIntervalSeconds
+	}
 	return nil
 }
 
@@ -145,6 +441,67 @@ func (s *DestinationBlockchain) GetBlockchainID() ids.ID {
 	return s.blockchainID
 }
 
+// GetSourceBlockchainID returns the source blockchain that the limit applies to,
+// or ids.Empty if it applies to all messages sent to the destination blockchain
+func (l *SpendLimit) GetSourceBlockchainID() ids.ID {
+	return l.sourceBlockchainID
+}
+
+// GetMaxSpend returns the maximum fees in wei that may be spent in each period
+func (l *SpendLimit) GetMaxSpend() *big.Int {
+	return new(big.Int).Set(l.maxSpend)
+}
+
+func (l *SpendLimit) GetPeriod() SpendLimitPeriod {
+	return l.period
+}
+
+// GetPeriodDuration returns the length of the period. Periods are aligned to the start of each UTC hour or day.
+func (l *SpendLimit) GetPeriodDuration() time.Duration {
+	if l.period == DAILY_SPEND_LIMIT_PERIOD {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+func (r *RewardRedemption) GetTeleporterAddress() common.Address {
+	return r.teleporterAddress
+}
+
+func (r *RewardRedemption) GetRewardAddress() common.Address {
+	return r.rewardAddress
+}
+
+func (r *ReceiptSweeper) GetTeleporterAddress() common.Address {
+	return r.teleporterAddress
+}
+
+func (r *ReceiptSweeper) GetRewardAddress() common.Address {
+	return r.rewardAddress
+}
+
+func (r *ReceiptSweeper) GetCounterpartBlockchainIDs() []ids.ID {
+	return r.counterpartBlockchainIDs
+}
+
+func (r *ExecutionRetry) GetTeleporterAddress() common.Address {
+	return r.teleporterAddress
+}
+
+// IsAllowedDestination returns whether the failed executions of messages to [address] are retried
+func (r *ExecutionRetry) IsAllowedDestination(address common.Address) bool {
+	return r.allowedDestinationAddresses.Contains(address)
+}
+
+func (t *RewardFeeToken) GetAddress() common.Address {
+	return t.address
+}
+
+// GetThreshold returns the minimum reward amount to redeem, in the token's smallest denomination
+func (t *RewardFeeToken) GetThreshold() *big.Int {
+	return new(big.Int).Set(t.threshold)
+}
+
 func (s *DestinationBlockchain) initializeWarpConfigs(ctx context.Context) error {
 	blockchainID, err := ids.FromString(s.BlockchainID)
 	if err != nil {
@@ -0,0 +1,101 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/set"
+)
+
+// Types of event sinks
+const (
+	WebhookEventSinkType = "webhook"
+	FileEventSinkType    = "file"
+)
+
+const (
+	defaultEventSinkMaxRetries     = uint64(3)
+	defaultEventSinkTimeoutSeconds = uint64(10)
+	defaultEventSinkQueueSize      = uint64(1024)
+)
+
+// EventSink configures a destination for the lifecycle events of the messages relayed on the routes it includes.
+// A webhook sink posts each event to URL, and a file sink appends each event as a line of JSON to Path.
+// If SourceBlockchainIDs or DestinationBlockchainIDs are set, only routes from and to those blockchains are
+// included. If EventTypes is set, only those types of events are emitted to the sink.
+type EventSink struct {
+	Type                     string   `mapstructure:"type" json:"type"`
+	URL                      string   `mapstructure:"url" json:"url,omitempty"`
+	HMACSecret               string   `mapstructure:"hmac-secret" json:"hmac-secret,omitempty" sensitive:"true"`
+	MaxRetries               uint64   `mapstructure:"max-retries" json:"max-retries"`
+	TimeoutSeconds           uint64   `mapstructure:"timeout-seconds" json:"timeout-seconds"`
+	QueueSize                uint64   `mapstructure:"queue-size" json:"queue-size"`
+	Path                     string   `mapstructure:"path" json:"path,omitempty"`
+	SourceBlockchainIDs      []string `mapstructure:"source-blockchain-ids" json:"source-blockchain-ids"`
+	DestinationBlockchainIDs []string `mapstructure:"destination-blockchain-ids" json:"destination-blockchain-ids"`
+	EventTypes               []string `mapstructure:"event-types" json:"event-types"`
+
+	// convenience fields to access parsed data after initialization
+	sourceBlockchainIDs      set.Set[ids.ID]
+	destinationBlockchainIDs set.Set[ids.ID]
+}
+
+func (s *EventSink) validate() error {
+	switch s.Type {
+	case WebhookEventSinkType:
+		if s.URL == "" {
+			return errors.New("url must be set for webhook event sinks")
+		}
+		if _, err := url.ParseRequestURI(s.URL); err != nil {
+			return fmt.Errorf("invalid url: %w", err)
+		}
+		if s.MaxRetries == 0 {
+			s.MaxRetries = defaultEventSinkMaxRetries
+		}
+		if s.TimeoutSeconds == 0 {
+			s.TimeoutSeconds = defaultEventSinkTimeoutSeconds
+		}
+		if s.QueueSize == 0 {
+			s.QueueSize = defaultEventSinkQueueSize
+		}
+	case FileEventSinkType:
+		if s.Path == "" {
+			return errors.New("path must be set for file event sinks")
+		}
+	default:
+		return fmt.Errorf("invalid event sink type %q", s.Type)
+	}
+
+	var err error
+	if s.sourceBlockchainIDs, err = parseBlockchainIDs(s.SourceBlockchainIDs); err != nil {
+		return fmt.Errorf("invalid source-blockchain-ids: %w", err)
+	}
+	if s.destinationBlockchainIDs, err = parseBlockchainIDs(s.DestinationBlockchainIDs); err != nil {
+		return fmt.Errorf("invalid destination-blockchain-ids: %w", err)
+	}
+	return nil
+}
+
+func parseBlockchainIDs(blockchainIDs []string) (set.Set[ids.ID], error) {
+	parsed := set.NewSet[ids.ID](len(blockchainIDs))
+	for _, blockchainID := range blockchainIDs {
+		id, err := ids.FromString(blockchainID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blockchain ID %s: %w", blockchainID, err)
+		}
+		parsed.Add(id)
+	}
+	return parsed, nil
+}
+
+// IncludesRoute returns true if the events of messages relayed from [sourceBlockchainID] to
+// [destinationBlockchainID] are emitted to the sink
+func (s *EventSink) IncludesRoute(sourceBlockchainID ids.ID, destinationBlockchainID ids.ID) bool {
+	return (s.sourceBlockchainIDs.Len() == 0 || s.sourceBlockchainIDs.Contains(sourceBlockchainID)) &&
+		(s.destinationBlockchainIDs.Len() == 0 || s.destinationBlockchainIDs.Contains(destinationBlockchainID))
+}
@@ -3,7 +3,21 @@
 
 package config
 
-import "github.com/spf13/pflag"
+import (
+	"errors"
+	"slices"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// Name of the command that relays the messages of a block range, and exits
+	BackfillCommand = "backfill"
+	// Name of the command group that inspects and modifies the relayer's state in the database
+	StateCommand = "state"
+)
+
+var errCommandPosition = errors.New("commands must be provided before any flags")
 
 func BuildFlagSet() *pflag.FlagSet {
 	fs := pflag.NewFlagSet("icm-relayer", pflag.ContinueOnError)
@@ -12,3 +26,19 @@ func BuildFlagSet() *pflag.FlagSet {
 	fs.BoolP(HelpKey, "", false, "Display icm-relayer usage")
 	return fs
 }
+
+// ParseCommand returns the command invoked by [args], excluding the program name, or an empty string if the
+// relayer is run without a command. An error is returned if a command is provided after any flags.
+func ParseCommand(args []string) (string, error) {
+	commands := []string{BackfillCommand, StateCommand}
+	for i, arg := range args {
+		if !slices.Contains(commands, arg) {
+			continue
+		}
+		if i != 0 {
+			return "", errCommandPosition
+		}
+		return arg, nil
+	}
+	return "", nil
+}
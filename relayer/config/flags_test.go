@@ -0,0 +1,53 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name: "no arguments",
+		},
+		{
+			name: "relayer flags",
+			args: []string{"--config-file", "config.json"},
+		},
+		{
+			name:     "backfill command",
+			args:     []string{"backfill", "--config-file", "config.json"},
+			expected: BackfillCommand,
+		},
+		{
+			name:     "state command",
+			args:     []string{"state", "list", "--config-file", "config.json"},
+			expected: StateCommand,
+		},
+		{
+			name:          "command after flags",
+			args:          []string{"--config-file", "config.json", "backfill"},
+			expectedError: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			command, err := ParseCommand(testCase.args)
+			if testCase.expectedError {
+				require.ErrorIs(t, err, errCommandPosition)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, command)
+		})
+	}
+}
@@ -9,6 +9,23 @@ const (
 	VersionKey    = "version"
 	HelpKey       = "help"
 
+	// Backfill command line option keys
+	BackfillSourceKey              = "source"
+	BackfillFromKey                = "from"
+	BackfillToKey                  = "to"
+	BackfillRouteKey               = "route"
+	BackfillMaxConcurrentBlocksKey = "concurrency"
+
+	// State command line option keys
+	StateRelayerIDKey = "relayer-id"
+	StateHeightKey    = "height"
+	StateFileKey      = "file"
+
+	// State migrate command line option keys
+	StateTargetStorageLocationKey = "target-storage-location"
+	StateTargetRedisURLKey        = "target-redis-url"
+	StateTargetStorageTypeKey     = "target-storage-type"
+
 	// Environment variable keys
 	ConfigFileEnvKey = "CONFIG_FILE"
 
@@ -19,9 +36,20 @@ const (
 	AccountPrivateKeyKey               = "account-private-key"
 	AccountPrivateKeysKey              = "account-private-keys-list"
 	StorageLocationKey                 = "storage-location"
+	StorageTypeKey                     = "storage-type"
+	RedisModeKey                       = "redis-mode"
 	ProcessMissedBlocksKey             = "process-missed-blocks"
 	DBWriteIntervalSecondsKey          = "db-write-interval-seconds"
 	SignatureCacheSizeKey              = "signature-cache-size"
 	InitialConnectionTimeoutSecondsKey = "initial-connection-timeout-seconds"
 	MaxConcurrentMessagesKey           = "max-concurrent-messages"
+	DeadLetterQueueMaxSizeKey          = "dead-letter-queue-max-size"
+	LeaderLeaseNameKey                 = "leader-lease-name"
+	LeaderLeaseSecondsKey              = "leader-lease-seconds"
+	DryRunNamespaceKey                 = "dry-run-namespace"
+	DeciderFailurePolicyKey            = "decider-failure-policy"
+	DeciderTimeoutSecondsKey           = "decider-timeout-seconds"
+	DeciderBreakerFailuresKey          = "decider-circuit-breaker-failures"
+	DeciderBreakerCooldownSecondsKey   = "decider-circuit-breaker-cooldown-seconds"
+	DeciderRulesDefaultActionKey       = "decider-rules-default-action"
 )
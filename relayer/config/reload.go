@@ -0,0 +1,61 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ValidateReload checks that the updated configuration only differs from the current configuration in
+// options that can be applied without restarting the relayer. Source and destination blockchains,
+// the decider options, the log level and the missed block processing behaviour can be reloaded. The
+// remaining options configure components that are shared by all routes, so changing them requires a restart.
+// Both configurations are expected to have been validated.
+func (c *Config) ValidateReload(updated *Config) error {
+	restartOptions := []struct {
+		key     string
+		current any
+		updated any
+	}{
+		{key: "storage-location", current: c.StorageLocation, updated: updated.StorageLocation},
+		{key: "redis-url", current: c.RedisURL, updated: updated.RedisURL},
+		{key: "storage-type", current: c.StorageType, updated: updated.StorageType},
+		{key: "redis-mode", current: c.RedisMode, updated: updated.RedisMode},
+		{key: "redis-key-prefix", current: c.RedisKeyPrefix, updated: updated.RedisKeyPrefix},
+		{key: "redis-key-expiry-seconds", current: c.RedisKeyExpirySeconds, updated: updated.RedisKeyExpirySeconds},
+		{key: "redis-tls-cert-path", current: c.RedisTLSCertPath, updated: updated.RedisTLSCertPath},
+		{key: "redis-tls-key-path", current: c.RedisTLSKeyPath, updated: updated.RedisTLSKeyPath},
+		{key: "redis-tls-ca-cert-path", current: c.RedisTLSCACertPath, updated: updated.RedisTLSCACertPath},
+		{key: "api-port", current: c.APIPort, updated: updated.APIPort},
+		{key: "metrics-port", current: c.MetricsPort, updated: updated.MetricsPort},
+		{
+			key:     "db-write-interval-seconds",
+			current: c.DBWriteIntervalSeconds,
+			updated: updated.DBWriteIntervalSeconds,
+		},
+		{key: "p-chain-api", current: c.PChainAPI, updated: updated.PChainAPI},
+		{key: "info-api", current: c.InfoAPI, updated: updated.InfoAPI},
+		{key: "signature-cache-size", current: c.SignatureCacheSize, updated: updated.SignatureCacheSize},
+		{key: "manually-tracked-peers", current: c.ManuallyTrackedPeers, updated: updated.ManuallyTrackedPeers},
+		{key: "allow-private-ips", current: c.AllowPrivateIPs, updated: updated.AllowPrivateIPs},
+		{key: "tls-cert-path", current: c.TLSCertPath, updated: updated.TLSCertPath},
+		{key: "tls-key-path", current: c.TLSKeyPath, updated: updated.TLSKeyPath},
+		{key: "max-concurrent-messages", current: c.MaxConcurrentMessages, updated: updated.MaxConcurrentMessages},
+		{key: "enable-dead-letter-queue", current: c.EnableDeadLetterQueue, updated: updated.EnableDeadLetterQueue},
+		{key: "dead-letter-queue-max-size", current: c.DeadLetterQueueMaxSize, updated: updated.DeadLetterQueueMaxSize},
+		{key: "enable-leader-election", current: c.EnableLeaderElection, updated: updated.EnableLeaderElection},
+		{key: "leader-lease-name", current: c.LeaderLeaseName, updated: updated.LeaderLeaseName},
+		{key: "leader-lease-seconds", current: c.LeaderLeaseSeconds, updated: updated.LeaderLeaseSeconds},
+		{key: "dry-run", current: c.DryRun, updated: updated.DryRun},
+		{key: "dry-run-namespace", current: c.DryRunNamespace, updated: updated.DryRunNamespace},
+		{key: "event-sinks", current: c.EventSinks, updated: updated.EventSinks},
+	}
+	for _, option := range restartOptions {
+		if !reflect.DeepEqual(option.current, option.updated) {
+			return fmt.Errorf("%s cannot be changed without restarting the relayer", option.key)
+		}
+	}
+	return nil
+}
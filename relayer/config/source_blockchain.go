@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/set"
@@ -10,20 +12,35 @@ import (
 	"github.com/ryt-io/libevm/common"
 )
 
+const (
+	defaultPollingIntervalSeconds             = 1
+	defaultEndpointWeight                     = 1
+	defaultEndpointHealthCheckIntervalSeconds = 10
+	defaultMaxEndpointHeadLagBlocks           = 10
+)
+
 // Source blockchain configuration.
 // Specifies how to connect to and listen for messages on the source blockchain.
 // Specifies the message protocols supported by the relayer for this blockchain.
 // Specifies the supported source addresses, and destination blockchains and addresses.
 // Specifies the height from which to start processing historical blocks.
 type SourceBlockchain struct {
-	SubnetID                          string                           `mapstructure:"subnet-id" json:"subnet-id"`
-	BlockchainID                      string                           `mapstructure:"blockchain-id" json:"blockchain-id"`                                                 //nolint:lll
-	RPCEndpoint                       basecfg.APIConfig                `mapstructure:"rpc-endpoint" json:"rpc-endpoint"`                                                   //nolint:lll
-	WSEndpoint                        basecfg.APIConfig                `mapstructure:"ws-endpoint" json:"ws-endpoint"`                                                     //nolint:lll
-	MessageContracts                  map[string]MessageProtocolConfig `mapstructure:"message-contracts" json:"message-contracts"`                                         //nolint:lll
-	SupportedDestinations             []*SupportedDestination          `mapstructure:"supported-destinations" json:"supported-destinations"`                               //nolint:lll
-	ProcessHistoricalBlocksFromHeight uint64                           `mapstructure:"process-historical-blocks-from-height" json:"process-historical-blocks-from-height"` //nolint:lll
-	AllowedOriginSenderAddresses      []string                         `mapstructure:"allowed-origin-sender-addresses" json:"allowed-origin-sender-addresses"`             //nolint:lll
+	SubnetID                           string                           `mapstructure:"subnet-id" json:"subnet-id"`
+	BlockchainID                       string                           `mapstructure:"blockchain-id" json:"blockchain-id"`                                                   //nolint:lll
+	RPCEndpoint                        basecfg.APIConfig                `mapstructure:"rpc-endpoint" json:"rpc-endpoint"`                                                     //nolint:lll
+	WSEndpoint                         basecfg.APIConfig                `mapstructure:"ws-endpoint" json:"ws-endpoint"`                                                       //nolint:lll
+	MessageContracts                   map[string]MessageProtocolConfig `mapstructure:"message-contracts" json:"message-contracts"`                                           //nolint:lll
+	SupportedDestinations              []*SupportedDestination          `mapstructure:"supported-destinations" json:"supported-destinations"`                                 //nolint:lll
+	ProcessHistoricalBlocksFromHeight  uint64                           `mapstructure:"process-historical-blocks-from-height" json:"process-historical-blocks-from-height"`   //nolint:lll
+	AllowedOriginSenderAddresses       []string                         `mapstructure:"allowed-origin-sender-addresses" json:"allowed-origin-sender-addresses"`               //nolint:lll
+	PollingIntervalSeconds             uint64                           `mapstructure:"polling-interval-seconds" json:"polling-interval-seconds"`                             //nolint:lll
+	FallbackRPCEndpoints               []*WeightedEndpoint              `mapstructure:"fallback-rpc-endpoints" json:"fallback-rpc-endpoints"`                                 //nolint:lll
+	FallbackWSEndpoints                []*WeightedEndpoint              `mapstructure:"fallback-ws-endpoints" json:"fallback-ws-endpoints"`                                   //nolint:lll
+	RPCEndpointWeight                  uint64                           `mapstructure:"rpc-endpoint-weight" json:"rpc-endpoint-weight"`                                       //nolint:lll
+	WSEndpointWeight                   uint64                           `mapstructure:"ws-endpoint-weight" json:"ws-endpoint-weight"`                                         //nolint:lll
+	EndpointSelection                  string                           `mapstructure:"endpoint-selection" json:"endpoint-selection"`                                         //nolint:lll
+	EndpointHealthCheckIntervalSeconds uint64                           `mapstructure:"endpoint-health-check-interval-seconds" json:"endpoint-health-check-interval-seconds"` //nolint:lll
+	MaxEndpointHeadLagBlocks           uint64                           `mapstructure:"max-endpoint-head-lag-blocks" json:"max-endpoint-head-lag-blocks"`                     //nolint:lll
 	// DEPRECATED: WarpAPIEndpoint is deprecated. Use request network instead
 	WarpAPIEndpoint basecfg.APIConfig `mapstructure:"warp-api-endpoint" json:"warp-api-endpoint"` //nolint:lll
 
@@ -32,6 +49,14 @@ type SourceBlockchain struct {
 	blockchainID                 ids.ID
 	allowedOriginSenderAddresses []common.Address
 	useAppRequestNetwork         bool
+	endpointSelection            EndpointSelection
+}
+
+// An additional endpoint of a source blockchain, used if the higher priority endpoints are unhealthy.
+type WeightedEndpoint struct {
+	basecfg.APIConfig `mapstructure:",squash"`
+	// The relative weight of the endpoint when using the weighted endpoint selection
+	Weight uint64 `mapstructure:"weight" json:"weight"`
 }
 
 // Validates the source subnet configuration, including verifying that the supported destinations are present in
@@ -41,8 +66,16 @@ func (s *SourceBlockchain) Validate(destinationBlockchainIDs *set.Set[string]) e
 	if err := s.RPCEndpoint.Validate(); err != nil {
 		return fmt.Errorf("invalid rpc-endpoint in source subnet configuration: %w", err)
 	}
-	if err := s.WSEndpoint.Validate(); err != nil {
-		return fmt.Errorf("invalid ws-endpoint in source subnet configuration: %w", err)
+	// The WS endpoint is optional. If omitted, new blocks are polled from the RPC endpoint.
+	if s.WSEndpoint.BaseURL != "" {
+		if err := s.WSEndpoint.Validate(); err != nil {
+			return fmt.Errorf("invalid ws-endpoint in source subnet configuration: %w", err)
+		}
+	} else if s.PollingIntervalSeconds == 0 {
+		s.PollingIntervalSeconds = defaultPollingIntervalSeconds
+	}
+	if err := s.validateFallbackEndpoints(); err != nil {
+		return err
 	}
 	// DEPRECATED: The Warp API endpoint is optional. If omitted, signatures are fetched from validators via app request.
 	if s.WarpAPIEndpoint.BaseURL != "" {
@@ -142,6 +175,62 @@ func (s *SourceBlockchain) Validate(destinationBlockchainIDs *set.Set[string]) e
 	return nil
 }
 
+// Validates the fallback endpoints and endpoint health settings, and sets their default values
+func (s *SourceBlockchain) validateFallbackEndpoints() error {
+	if s.RPCEndpointWeight == 0 {
+		s.RPCEndpointWeight = defaultEndpointWeight
+	}
+	if s.WSEndpointWeight == 0 {
+		s.WSEndpointWeight = defaultEndpointWeight
+	}
+	for _, endpoint := range s.FallbackRPCEndpoints {
+		if err := endpoint.Validate(); err != nil {
+			return fmt.Errorf("invalid fallback-rpc-endpoints in source subnet configuration: %w", err)
+		}
+		if endpoint.Weight == 0 {
+			endpoint.Weight = defaultEndpointWeight
+		}
+	}
+	// Requests are routed between the RPC endpoints over HTTP
+	if len(s.FallbackRPCEndpoints) > 0 {
+		for _, endpoint := range s.GetRPCEndpoints() {
+			uri, err := url.ParseRequestURI(endpoint.BaseURL)
+			if err != nil || (uri.Scheme != "http" && uri.Scheme != "https") {
+				return fmt.Errorf(
+					"rpc endpoints must use http or https if fallback-rpc-endpoints are set: %s",
+					endpoint.BaseURL,
+				)
+			}
+		}
+	}
+	if len(s.FallbackWSEndpoints) > 0 && s.UsePolling() {
+		return fmt.Errorf("fallback-ws-endpoints can not be set without a ws-endpoint")
+	}
+	for _, endpoint := range s.FallbackWSEndpoints {
+		if err := endpoint.Validate(); err != nil {
+			return fmt.Errorf("invalid fallback-ws-endpoints in source subnet configuration: %w", err)
+		}
+		if endpoint.Weight == 0 {
+			endpoint.Weight = defaultEndpointWeight
+		}
+	}
+
+	if s.EndpointSelection == "" {
+		s.EndpointSelection = ORDERED_ENDPOINT_SELECTION.String()
+	}
+	s.endpointSelection = ParseEndpointSelection(s.EndpointSelection)
+	if s.endpointSelection == UNKNOWN_ENDPOINT_SELECTION {
+		return fmt.Errorf("invalid endpoint-selection in source subnet configuration: %s", s.EndpointSelection)
+	}
+	if s.EndpointHealthCheckIntervalSeconds == 0 {
+		s.EndpointHealthCheckIntervalSeconds = defaultEndpointHealthCheckIntervalSeconds
+	}
+	if s.MaxEndpointHeadLagBlocks == 0 {
+		s.MaxEndpointHeadLagBlocks = defaultMaxEndpointHeadLagBlocks
+	}
+	return nil
+}
+
 func (s *SourceBlockchain) GetSubnetID() ids.ID {
 	return s.subnetID
 }
@@ -158,6 +247,54 @@ func (s *SourceBlockchain) UseAppRequestNetwork() bool {
 	return s.useAppRequestNetwork
 }
 
+// UsePolling returns true if new blocks are polled from the RPC endpoint, rather than received
+// via the WS endpoint
+func (s *SourceBlockchain) UsePolling() bool {
+	return s.WSEndpoint.BaseURL == ""
+}
+
+func (s *SourceBlockchain) GetPollingInterval() time.Duration {
+	return time.Duration(s.PollingIntervalSeconds) * time.Second
+}
+
+// GetRPCEndpoints returns the RPC endpoint followed by the fallback RPC endpoints, in priority order
+func (s *SourceBlockchain) GetRPCEndpoints() []WeightedEndpoint {
+	return withFallbackEndpoints(s.RPCEndpoint, s.RPCEndpointWeight, s.FallbackRPCEndpoints)
+}
+
+// GetWSEndpoints returns the WS endpoint followed by the fallback WS endpoints, in priority order.
+// Returns an empty list if polling for new blocks.
+func (s *SourceBlockchain) GetWSEndpoints() []WeightedEndpoint {
+	if s.UsePolling() {
+		return nil
+	}
+	return withFallbackEndpoints(s.WSEndpoint, s.WSEndpointWeight, s.FallbackWSEndpoints)
+}
+
+func (s *SourceBlockchain) GetEndpointSelection() EndpointSelection {
+	return s.endpointSelection
+}
+
+func (s *SourceBlockchain) GetEndpointHealthCheckInterval() time.Duration {
+	return time.Duration(s.EndpointHealthCheckIntervalSeconds) * time.Second
+}
+
+func withFallbackEndpoints(
+	endpoint basecfg.APIConfig,
+	weight uint64,
+	fallbackEndpoints []*WeightedEndpoint,
+) []WeightedEndpoint {
+	endpoints := make([]WeightedEndpoint, 0, len(fallbackEndpoints)+1)
+	endpoints = append(endpoints, WeightedEndpoint{
+		APIConfig: endpoint,
+		Weight:    weight,
+	})
+	for _, fallbackEndpoint := range fallbackEndpoints {
+		endpoints = append(endpoints, *fallbackEndpoint)
+	}
+	return endpoints
+}
+
 // Specifies a supported destination blockchain and addresses for a source blockchain.
 type SupportedDestination struct {
 	BlockchainID string   `mapstructure:"blockchain-id" json:"blockchain-id"`
@@ -0,0 +1,124 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/common/hexutil"
+	"github.com/spf13/pflag"
+)
+
+// Subcommands of the state command
+const (
+	StateListCommand            = "list"
+	StateSetCheckpointCommand   = "set-checkpoint"
+	StateResetCheckpointCommand = "reset-checkpoint"
+	StateExportCommand          = "export"
+	StateImportCommand          = "import"
+	StateMigrateCommand         = "migrate"
+)
+
+// StateConfig specifies the state subcommand to run, as provided via its flags
+type StateConfig struct {
+	Command string
+	// The relayer IDs whose checkpoints are set or reset
+	RelayerIDs []common.Hash
+	// The height that the checkpoints are set to
+	Height uint64
+	// The file that the state is exported to or imported from. The state is exported to stdout if empty.
+	File string
+	// The storage that the state is migrated to. Only one of TargetStorageLocation and TargetRedisURL is set.
+	TargetStorageLocation string
+	TargetStorageType     string
+	TargetRedisURL        string
+}
+
+// BuildStateFlagSet returns the flags of the state subcommand [command], which include the relayer's flags
+func BuildStateFlagSet(command string) (*pflag.FlagSet, error) {
+	fs := BuildFlagSet()
+	switch command {
+	case StateListCommand:
+	case StateSetCheckpointCommand, StateResetCheckpointCommand:
+		fs.StringSlice(StateRelayerIDKey, nil, "The hex encoded relayer ID whose checkpoint to modify. May be repeated")
+		if command == StateSetCheckpointCommand {
+			fs.Uint64(StateHeightKey, 0, "The latest processed block height to set")
+		}
+	case StateExportCommand:
+		fs.String(StateFileKey, "", "The file to export the state to. Defaults to stdout")
+	case StateImportCommand:
+		fs.String(StateFileKey, "", "The file to import the state from")
+	case StateMigrateCommand:
+		fs.String(StateTargetStorageLocationKey, "", "The storage location to migrate the state to")
+		fs.String(StateTargetStorageTypeKey, defaultStorageType, "The storage type of the target storage location")
+		fs.String(StateTargetRedisURLKey, "", "The URL of the Redis database to migrate the state to")
+	default:
+		return nil, fmt.Errorf("unknown %s command: %q", StateCommand, command)
+	}
+	return fs, nil
+}
+
+// NewStateConfig reads the flags of the state subcommand [command] from [fs]
+func NewStateConfig(command string, fs *pflag.FlagSet) (*StateConfig, error) {
+	stateConfig := &StateConfig{
+		Command: command,
+	}
+	switch command {
+	case StateSetCheckpointCommand, StateResetCheckpointCommand:
+		relayerIDs, err := fs.GetStringSlice(StateRelayerIDKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading flag value: %s: %w", StateRelayerIDKey, err)
+		}
+		if len(relayerIDs) == 0 {
+			return nil, fmt.Errorf("%s must be set", StateRelayerIDKey)
+		}
+		for _, relayerID := range relayerIDs {
+			relayerIDBytes, err := hexutil.Decode(relayerID)
+			if err != nil || len(relayerIDBytes) != common.HashLength {
+				return nil, fmt.Errorf("invalid %s: %s", StateRelayerIDKey, relayerID)
+			}
+			stateConfig.RelayerIDs = append(stateConfig.RelayerIDs, common.BytesToHash(relayerIDBytes))
+		}
+		if command == StateSetCheckpointCommand {
+			if !fs.Changed(StateHeightKey) {
+				return nil, fmt.Errorf("%s must be set", StateHeightKey)
+			}
+			if stateConfig.Height, err = fs.GetUint64(StateHeightKey); err != nil {
+				return nil, fmt.Errorf("error reading flag value: %s: %w", StateHeightKey, err)
+			}
+		}
+	case StateExportCommand, StateImportCommand:
+		file, err := fs.GetString(StateFileKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading flag value: %s: %w", StateFileKey, err)
+		}
+		if command == StateImportCommand && file == "" {
+			return nil, fmt.Errorf("%s must be set", StateFileKey)
+		}
+		stateConfig.File = file
+	case StateMigrateCommand:
+		var err error
+		if stateConfig.TargetStorageLocation, err = fs.GetString(StateTargetStorageLocationKey); err != nil {
+			return nil, fmt.Errorf("error reading flag value: %s: %w", StateTargetStorageLocationKey, err)
+		}
+		if stateConfig.TargetStorageType, err = fs.GetString(StateTargetStorageTypeKey); err != nil {
+			return nil, fmt.Errorf("error reading flag value: %s: %w", StateTargetStorageTypeKey, err)
+		}
+		if stateConfig.TargetRedisURL, err = fs.GetString(StateTargetRedisURLKey); err != nil {
+			return nil, fmt.Errorf("error reading flag value: %s: %w", StateTargetRedisURLKey, err)
+		}
+		if (stateConfig.TargetStorageLocation == "") == (stateConfig.TargetRedisURL == "") {
+			return nil, fmt.Errorf(
+				"exactly one of %s and %s must be set",
+				StateTargetStorageLocationKey,
+				StateTargetRedisURLKey,
+			)
+		}
+		if stateConfig.TargetStorageType != JSONStorageType && stateConfig.TargetStorageType != LevelDBStorageType {
+			return nil, fmt.Errorf("invalid %s %q", StateTargetStorageTypeKey, stateConfig.TargetStorageType)
+		}
+	}
+	return stateConfig, nil
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/ryt-io/libevm/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStateConfig(t *testing.T) {
+	relayerID1 := common.HexToHash("0x01")
+	relayerID2 := common.HexToHash("0x02")
+	testCases := []struct {
+		name          string
+		command       string
+		args          []string
+		expected      *StateConfig
+		expectedError bool
+	}{
+		{
+			name:     "list",
+			command:  StateListCommand,
+			args:     []string{"--config-file", "config.json"},
+			expected: &StateConfig{Command: StateListCommand},
+		},
+		{
+			name:    "set checkpoint",
+			command: StateSetCheckpointCommand,
+			args:    []string{"--relayer-id", relayerID1.Hex(), "--relayer-id", relayerID2.Hex(), "--height", "0"},
+			expected: &StateConfig{
+				Command:    StateSetCheckpointCommand,
+				RelayerIDs: []common.Hash{relayerID1, relayerID2},
+			},
+		},
+		{
+			name:          "set checkpoint without height",
+			command:       StateSetCheckpointCommand,
+			args:          []string{"--relayer-id", relayerID1.Hex()},
+			expectedError: true,
+		},
+		{
+			name:          "reset checkpoint without relayer id",
+			command:       StateResetCheckpointCommand,
+			expectedError: true,
+		},
+		{
+			name:          "reset checkpoint with invalid relayer id",
+			command:       StateResetCheckpointCommand,
+			args:          []string{"--relayer-id", "0x1234"},
+			expectedError: true,
+		},
+		{
+			name:     "export to stdout",
+			command:  StateExportCommand,
+			expected: &StateConfig{Command: StateExportCommand},
+		},
+		{
+			name:     "import",
+			command:  StateImportCommand,
+			args:     []string{"--file", "state.json"},
+			expected: &StateConfig{Command: StateImportCommand, File: "state.json"},
+		},
+		{
+			name:          "import without file",
+			command:       StateImportCommand,
+			expectedError: true,
+		},
+		{
+			name:    "migrate to redis",
+			command: StateMigrateCommand,
+			args:    []string{"--target-redis-url", "redis://localhost:6379"},
+			expected: &StateConfig{
+				Command:           StateMigrateCommand,
+				TargetStorageType: JSONStorageType,
+				TargetRedisURL:    "redis://localhost:6379",
+			},
+		},
+		{
+			name:    "migrate to json file storage",
+			command: StateMigrateCommand,
+			args:    []string{"--target-storage-location", "./storage"},
+			expected: &StateConfig{
+				Command:               StateMigrateCommand,
+				TargetStorageLocation: "./storage",
+				TargetStorageType:     JSONStorageType,
+			},
+		},
+		{
+			name:    "migrate to leveldb storage",
+			command: StateMigrateCommand,
+			args:    []string{"--target-storage-location", "./storage", "--target-storage-type", "leveldb"},
+			expected: &StateConfig{
+				Command:               StateMigrateCommand,
+				TargetStorageLocation: "./storage",
+				TargetStorageType:     LevelDBStorageType,
+			},
+		},
+		{
+			name:          "migrate to invalid storage type",
+			command:       StateMigrateCommand,
+			args:          []string{"--target-storage-location", "./storage", "--target-storage-type", "sqlite"},
+			expectedError: true,
+		},
+		{
+			name:          "migrate without target",
+			command:       StateMigrateCommand,
+			expectedError: true,
+		},
+		{
+			name:    "migrate to both targets",
+			command: StateMigrateCommand,
+			args: []string{
+				"--target-storage-location", "./storage", "--target-redis-url", "redis://localhost:6379",
+			},
+			expectedError: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fs, err := BuildStateFlagSet(testCase.command)
+			require.NoError(t, err)
+			require.NoError(t, fs.Parse(testCase.args))
+
+			stateConfig, err := NewStateConfig(testCase.command, fs)
+			if testCase.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, stateConfig)
+		})
+	}
+}
+
+func TestBuildStateFlagSetUnknownCommand(t *testing.T) {
+	_, err := BuildStateFlagSet("unknown")
+	require.Error(t, err)
+}
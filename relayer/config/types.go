@@ -34,3 +34,67 @@ func ParseMessageProtocol(msg string) MessageProtocol {
 		return UNKNOWN_MESSAGE_PROTOCOL
 	}
 }
+
+// Strategies for selecting the active endpoint of a source blockchain with multiple endpoints
+type EndpointSelection int
+
+const (
+	UNKNOWN_ENDPOINT_SELECTION EndpointSelection = iota
+	ORDERED_ENDPOINT_SELECTION
+	WEIGHTED_ENDPOINT_SELECTION
+)
+
+func (s EndpointSelection) String() string {
+	switch s {
+	case ORDERED_ENDPOINT_SELECTION:
+		return "ordered"
+	case WEIGHTED_ENDPOINT_SELECTION:
+		return "weighted"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseEndpointSelection returns the EndpointSelection corresponding to [s]
+func ParseEndpointSelection(s string) EndpointSelection {
+	switch s {
+	case "ordered":
+		return ORDERED_ENDPOINT_SELECTION
+	case "weighted":
+		return WEIGHTED_ENDPOINT_SELECTION
+	default:
+		return UNKNOWN_ENDPOINT_SELECTION
+	}
+}
+
+// Periods over which a destination blockchain's spend limits apply
+type SpendLimitPeriod int
+
+const (
+	UNKNOWN_SPEND_LIMIT_PERIOD SpendLimitPeriod = iota
+	HOURLY_SPEND_LIMIT_PERIOD
+	DAILY_SPEND_LIMIT_PERIOD
+)
+
+func (p SpendLimitPeriod) String() string {
+	switch p {
+	case HOURLY_SPEND_LIMIT_PERIOD:
+		return "hour"
+	case DAILY_SPEND_LIMIT_PERIOD:
+		return "day"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSpendLimitPeriod returns the SpendLimitPeriod corresponding to [p]
+func ParseSpendLimitPeriod(p string) SpendLimitPeriod {
+	switch p {
+	case "hour":
+		return HOURLY_SPEND_LIMIT_PERIOD
+	case "day":
+		return DAILY_SPEND_LIMIT_PERIOD
+	default:
+		return UNKNOWN_SPEND_LIMIT_PERIOD
+	}
+}
@@ -54,6 +54,8 @@ func BuildViper(fs *pflag.FlagSet) (*viper.Viper, error) {
 func SetDefaultConfigValues(v *viper.Viper) {
 	v.SetDefault(LogLevelKey, defaultLogLevel)
 	v.SetDefault(StorageLocationKey, defaultStorageLocation)
+	v.SetDefault(StorageTypeKey, defaultStorageType)
+	v.SetDefault(RedisModeKey, defaultRedisMode)
 	v.SetDefault(ProcessMissedBlocksKey, defaultProcessMissedBlocks)
 	v.SetDefault(APIPortKey, defaultAPIPort)
 	v.SetDefault(MetricsPortKey, defaultMetricsPort)
@@ -64,6 +66,15 @@ func SetDefaultConfigValues(v *viper.Viper) {
 	)
 	v.SetDefault(InitialConnectionTimeoutSecondsKey, defaultInitialConnectionTimeoutSeconds)
 	v.SetDefault(MaxConcurrentMessagesKey, defaultMaxConcurrentMessages)
+	v.SetDefault(DeadLetterQueueMaxSizeKey, defaultDeadLetterQueueMaxSize)
+	v.SetDefault(LeaderLeaseNameKey, defaultLeaderLeaseName)
+	v.SetDefault(LeaderLeaseSecondsKey, defaultLeaderLeaseSeconds)
+	v.SetDefault(DryRunNamespaceKey, defaultDryRunNamespace)
+	v.SetDefault(DeciderFailurePolicyKey, defaultDeciderFailurePolicy)
+	v.SetDefault(DeciderTimeoutSecondsKey, defaultDeciderTimeoutSeconds)
+	v.SetDefault(DeciderBreakerFailuresKey, defaultDeciderBreakerFailures)
+	v.SetDefault(DeciderBreakerCooldownSecondsKey, defaultDeciderBreakerCooldownSeconds)
+	v.SetDefault(DeciderRulesDefaultActionKey, defaultDeciderRulesDefaultAction)
 }
 
 // BuildConfig constructs the relayer config using Viper.
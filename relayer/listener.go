@@ -12,7 +12,6 @@ import (
 	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/logging"
 	"github.com/ryt-io/icm-services/relayer/config"
-	"github.com/ryt-io/icm-services/utils"
 	"github.com/ryt-io/icm-services/vms/evm"
 	"github.com/ryt-io/libevm/ethclient"
 	"go.uber.org/atomic"
@@ -34,6 +33,8 @@ type Listener struct {
 	sourceBlockchain             config.SourceBlockchain
 	healthStatus                 *atomic.Bool
 	ethClient                    *ethclient.Client
+	ethWSClient                  *evm.FailoverWSClient // nil if polling for new blocks
+	wsEndpoints                  *evm.EndpointPool     // nil if polling for new blocks
 	messageCoordinator           *MessageCoordinator
 	maxConcurrentMsg             uint64
 	errChan                      chan error
@@ -47,6 +48,8 @@ func RunListener(
 	logger logging.Logger,
 	sourceBlockchain config.SourceBlockchain,
 	ethRPCClient *ethclient.Client,
+	rpcEndpoints *evm.EndpointPool,
+	endpointMetrics *evm.EndpointMetrics,
 	relayerHealth *atomic.Bool,
 	startingHeight uint64,
 	messageCoordinator *MessageCoordinator,
@@ -60,10 +63,10 @@ func RunListener(
 	)
 	// Create the Listener
 	listener, err := newListener(
-		ctx,
 		logger,
 		sourceBlockchain,
 		ethRPCClient,
+		endpointMetrics,
 		relayerHealth,
 		startingHeight,
 		messageCoordinator,
@@ -73,6 +76,12 @@ func RunListener(
 		return fmt.Errorf("failed to create listener instance: %w", err)
 	}
 
+	// Score the health of the source blockchain's endpoints for as long as the listener runs
+	go rpcEndpoints.RunHealthChecks(ctx)
+	if listener.wsEndpoints != nil {
+		go listener.wsEndpoints.RunHealthChecks(ctx)
+	}
+
 	logger.Info("Listener initialized. Listening for messages to relay.")
 
 	// Wait for logs from the subscribed node
@@ -81,10 +90,10 @@ func RunListener(
 }
 
 func newListener(
-	ctx context.Context,
 	logger logging.Logger,
 	sourceBlockchain config.SourceBlockchain,
 	ethRPCClient *ethclient.Client,
+	endpointMetrics *evm.EndpointMetrics,
 	relayerHealth *atomic.Bool,
 	startingHeight uint64,
 	messageCoordinator *MessageCoordinator,
@@ -95,17 +104,33 @@ func newListener(
 		return nil, fmt.Errorf("invalid blockchainID provided to subscriber: %w", err)
 	}
 
-	ethWSClient, err := utils.NewEthClientWithConfig(
-		ctx,
-		sourceBlockchain.WSEndpoint.BaseURL,
-		sourceBlockchain.WSEndpoint.HTTPHeaders,
-		sourceBlockchain.WSEndpoint.QueryParams,
+	errChan := make(chan error, maxConcurrentMsg)
+	var (
+		ethWSClient *evm.FailoverWSClient
+		wsEndpoints *evm.EndpointPool
+		sub         *evm.Subscriber
 	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to node via WS: %w", err)
+	if sourceBlockchain.UsePolling() {
+		logger.Info(
+			"No WS endpoint configured. Polling for new blocks",
+			zap.Duration("pollingInterval", sourceBlockchain.GetPollingInterval()),
+		)
+		sub = evm.NewPollingSubscriber(
+			logger,
+			blockchainID,
+			ethRPCClient,
+			sourceBlockchain.GetPollingInterval(),
+			errChan,
+		)
+	} else {
+		wsEndpoints, err = evm.NewSourceEndpointPool(logger, &sourceBlockchain, evm.WSEndpointType, endpointMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create WS endpoint pool: %w", err)
+		}
+		// The connection to the active WS endpoint is established when subscribing
+		ethWSClient = evm.NewFailoverWSClient(wsEndpoints)
+		sub = evm.NewSubscriber(logger, blockchainID, ethWSClient, ethRPCClient, errChan)
 	}
-	errChan := make(chan error, maxConcurrentMsg)
-	sub := evm.NewSubscriber(logger, blockchainID, ethWSClient, ethRPCClient, errChan)
 
 	logger.Info("Creating relayer")
 	lstnr := Listener{
@@ -116,6 +141,8 @@ func newListener(
 		errChan:                      errChan,
 		healthStatus:                 relayerHealth,
 		ethClient:                    ethRPCClient,
+		ethWSClient:                  ethWSClient,
+		wsEndpoints:                  wsEndpoints,
 		messageCoordinator:           messageCoordinator,
 		maxConcurrentMsg:             maxConcurrentMsg,
 		lastSubscriberBlockProcessed: startingHeight - 1,
@@ -125,6 +152,9 @@ func newListener(
 	// miss an incoming message in between fetching the latest block and subscribing.
 	err = lstnr.Subscriber.Subscribe(retrySubscribeTimeout)
 	if err != nil {
+		if ethWSClient != nil {
+			ethWSClient.Close()
+		}
 		return nil, fmt.Errorf("failed to subscribe to node: %w", err)
 	}
 
@@ -175,6 +205,10 @@ func (lstnr *Listener) processLogs(ctx context.Context) error {
 		case <-ctx.Done():
 			lstnr.healthStatus.Store(false)
 			lstnr.logger.Info("Exiting listener because context cancelled")
+			lstnr.Subscriber.Unsubscribe()
+			if lstnr.ethWSClient != nil {
+				lstnr.ethWSClient.Close()
+			}
 			return nil
 		}
 	}
@@ -0,0 +1,352 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ryt-io/ryt-v2/api/info"
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/message"
+	"github.com/ryt-io/ryt-v2/network/peer"
+	"github.com/ryt-io/ryt-v2/utils/constants"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/decider"
+	"github.com/ryt-io/icm-services/events"
+	"github.com/ryt-io/icm-services/peers"
+	"github.com/ryt-io/icm-services/peers/clients"
+	"github.com/ryt-io/icm-services/relayer"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/icm-services/signature-aggregator/aggregator"
+	sigAggMetrics "github.com/ryt-io/icm-services/signature-aggregator/metrics"
+	"github.com/ryt-io/icm-services/vms"
+	relayerEVM "github.com/ryt-io/icm-services/vms/evm"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/ethclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// runBackfill relays the messages sent in the block range provided via the backfill command's flags, and exits.
+// The backfill does not read or write the relayer's database, so the checkpoints of a running relayer are not
+// affected. Metrics are collected but not served, since the metrics port may be in use by a running relayer.
+// In leader election mode, the backfill holds the leader lease while it runs, since it sends transactions
+// from the same accounts as the relayer.
+func runBackfill(logger logging.Logger) {
+	fs, err := parseFlags(config.BuildBackfillFlagSet(), os.Args[2:])
+	if err != nil {
+		logger.Fatal("couldn't parse flags", zap.Error(err))
+		os.Exit(1)
+	}
+	cfg, err := loadConfig(fs)
+	if err != nil {
+		logger.Fatal("couldn't build config", zap.Error(err))
+		os.Exit(1)
+	}
+	backfillCfg, err := config.NewBackfillConfig(fs, cfg)
+	if err != nil {
+		logger.Fatal("couldn't build backfill config", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Exit with an error once the other deferred functions have run, such as releasing the leader lease,
+	// if the backfill failed
+	var failed bool
+	defer func() {
+		if failed {
+			os.Exit(1)
+		}
+	}()
+
+	// Stop the backfill on SIGINT or SIGTERM
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	configureHTTPClient()
+
+	logLevel, err := logging.ToLevel(cfg.LogLevel)
+	if err != nil {
+		logger.Error("error reading log level from config", zap.Error(err))
+		os.Exit(1)
+	}
+	logger.SetLevel(logLevel)
+
+	logger.Info("Initializing icm-relayer backfill")
+
+	if err = cfg.Initialize(ctx); err != nil {
+		logger.Fatal("couldn't initialize config", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Transactions sent by the backfill and a running relayer from the same accounts would conflict on nonces,
+	// so the backfill refuses to run while a relayer instance leads, and keeps standby instances from leading
+	if cfg.EnableLeaderElection {
+		lease, err := database.NewLeaderLease(logger, cfg)
+		if err != nil {
+			logger.Fatal("Failed to create leader lease", zap.Error(err))
+			os.Exit(1)
+		}
+		defer lease.Close()
+
+		acquired, err := lease.Acquire(ctx)
+		if err != nil {
+			logger.Fatal("Failed to acquire leader lease", zap.Error(err))
+			os.Exit(1)
+		}
+		if !acquired {
+			logger.Fatal("Leader lease is held by a running relayer, stop it before running the backfill")
+			os.Exit(1)
+		}
+		// Renewing the lease is stopped before it is released, so that it is not reacquired once released
+		holdCtx, stopHolding := context.WithCancel(ctx)
+		holdDone := make(chan struct{})
+		defer func() {
+			stopHolding()
+			<-holdDone
+			lease.Release()
+		}()
+		// Stop the backfill if the lease is lost, since a relayer instance may start sending transactions
+		go func() {
+			defer close(holdDone)
+			if err := lease.Hold(holdCtx); err != nil {
+				logger.Error("Stopping backfill", zap.Error(err))
+				cancel()
+			}
+		}()
+	}
+
+	var sourceBlockchain *config.SourceBlockchain
+	for _, s := range cfg.SourceBlockchains {
+		if s.GetBlockchainID() == backfillCfg.SourceBlockchainID {
+			sourceBlockchain = s
+		}
+	}
+	// The backfill only delivers messages, so the destination clients' background tasks are disabled
+	for _, destinationBlockchain := range cfg.DestinationBlockchains {
+		destinationBlockchain.RewardRedemption = nil
+		destinationBlockchain.ReceiptSweeper = nil
+		destinationBlockchain.ExecutionRetry = nil
+	}
+
+	registries := make(map[string]*prometheus.Registry)
+	for _, name := range []string{
+		relayerMetricsPrefix,
+		peerNetworkMetricsPrefix,
+		msgCreatorMetricsPrefix,
+		timeoutManagerMetricsPrefix,
+	} {
+		registries[name] = prometheus.NewRegistry()
+	}
+
+	networkLogLevel := logging.Error
+	if logLevel <= logging.Debug {
+		networkLogLevel = logLevel
+	}
+	networkLogger := logging.NewLogger(
+		"p2p-network",
+		logging.NewWrappedCore(
+			networkLogLevel,
+			os.Stdout,
+			logging.JSON.ConsoleEncoder(),
+		),
+	)
+
+	messageCreator, err := message.NewCreator(
+		registries[msgCreatorMetricsPrefix],
+		constants.DefaultNetworkCompressionType,
+		constants.DefaultNetworkMaximumInboundTimeout,
+	)
+	if err != nil {
+		logger.Fatal("Failed to create message creator", zap.Error(err))
+		failed = true
+		return
+	}
+
+	var manuallyTrackedPeers []info.Peer
+	for _, p := range cfg.ManuallyTrackedPeers {
+		manuallyTrackedPeers = append(manuallyTrackedPeers, info.Peer{
+			Info: peer.Info{
+				PublicIP: p.GetIP(),
+				ID:       p.GetID(),
+			},
+		})
+	}
+
+	network, err := peers.NewNetwork(
+		ctx,
+		networkLogger,
+		registries[relayerMetricsPrefix],
+		registries[peerNetworkMetricsPrefix],
+		registries[timeoutManagerMetricsPrefix],
+		cfg.GetTrackedSubnets(),
+		manuallyTrackedPeers,
+		cfg,
+		validatorSetCacheSize,
+	)
+	if err != nil {
+		logger.Fatal("Failed to create app request network", zap.Error(err))
+		failed = true
+		return
+	}
+	defer network.Shutdown()
+
+	err = relayer.InitializeConnectionsAndCheckStake(ctx, logger, network, cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize connections and check stake", zap.Error(err))
+		failed = true
+		return
+	}
+
+	deciderClient, err := decider.New(logger, cfg, decider.NewMetrics(registries[relayerMetricsPrefix]))
+	if err != nil {
+		logger.Fatal("Failed to instantiate decider", zap.Error(err))
+		failed = true
+		return
+	}
+	if deciderClient != nil {
+		defer deciderClient.Close()
+	}
+
+	messageHandlerFactories, err := createMessageHandlerFactories(logger, cfg, deciderClient)
+	if err != nil {
+		logger.Fatal("Failed to create message handler factories", zap.Error(err))
+		failed = true
+		return
+	}
+
+	signatureAggregator, err := aggregator.NewSignatureAggregator(
+		network,
+		messageCreator,
+		cfg.SignatureCacheSize,
+		sigAggMetrics.NewSignatureAggregatorMetrics(
+			registries[relayerMetricsPrefix],
+		),
+		clients.NewCanonicalValidatorClient(cfg.PChainAPI),
+	)
+	if err != nil {
+		logger.Fatal("Failed to create signature aggregator", zap.Error(err))
+		failed = true
+		return
+	}
+
+	// Only create the destination clients of the replayed routes
+	var relayerIDs []database.RelayerID
+	destinationBlockchainIDs := set.NewSet[ids.ID](len(sourceBlockchain.SupportedDestinations))
+	for _, relayerID := range database.GetSourceBlockchainRelayerIDs(sourceBlockchain) {
+		if backfillCfg.IncludesRoute(relayerID.DestinationBlockchainID) {
+			relayerIDs = append(relayerIDs, relayerID)
+			destinationBlockchainIDs.Add(relayerID.DestinationBlockchainID)
+		}
+	}
+	// The spend of the backfill is only tracked in memory, so it does not count against the budgets of
+	// the relayer's destination clients
+	logger.Info("Initializing destination clients")
+	destinationClients, err := vms.CreateDestinationClientsForBlockchains(
+		logger,
+		cfg,
+		destinationBlockchainIDs,
+		relayerEVM.NewDestinationClientMetrics(registries[relayerMetricsPrefix]),
+		nil,
+	)
+	if err != nil {
+		logger.Fatal("Failed to create destination clients", zap.Error(err))
+		failed = true
+		return
+	}
+	defer func() {
+		for _, destinationClient := range destinationClients {
+			destinationClient.Close()
+		}
+	}()
+
+	logger.Info("Initializing source client")
+	sourceClient, _, err := createSourceClient(
+		ctx,
+		logger,
+		relayerEVM.NewEndpointMetrics(registries[relayerMetricsPrefix]),
+		sourceBlockchain,
+	)
+	if err != nil {
+		logger.Fatal("Failed to create source client", zap.Error(err))
+		failed = true
+		return
+	}
+
+	// Messages delivered by the backfill are emitted to the event sinks like any other delivery
+	eventSinks, err := events.NewSinks(logger, cfg.EventSinks, events.NewMetrics(registries[relayerMetricsPrefix]))
+	if err != nil {
+		logger.Fatal("Failed to create event sinks", zap.Error(err))
+		failed = true
+		return
+	}
+	defer eventSinks.Close()
+
+	backfill := relayer.NewBackfill(logger, backfillCfg)
+	relayerMetrics := relayer.NewApplicationRelayerMetrics(registries[relayerMetricsPrefix])
+	processMessageSemaphore := make(chan struct{}, cfg.MaxConcurrentMessages)
+	applicationRelayers := make(map[common.Hash]*relayer.ApplicationRelayer)
+	for _, relayerID := range relayerIDs {
+		// Failed messages are reported instead of being dead-lettered, and statuses are not recorded, so that
+		// the backfill does not write to the database
+		applicationRelayer, err := relayer.NewApplicationRelayer(
+			logger.With(zap.Stringer("relayerID", relayerID.ID)),
+			relayerMetrics,
+			network,
+			relayerID,
+			destinationClients[relayerID.DestinationBlockchainID],
+			*sourceBlockchain,
+			backfill.CheckpointManager(),
+			cfg,
+			signatureAggregator,
+			processMessageSemaphore,
+			nil,
+			nil,
+			eventSinks,
+		)
+		if err != nil {
+			logger.Fatal("Failed to create application relayer", zap.Error(err))
+			failed = true
+			return
+		}
+		applicationRelayers[relayerID.ID] = applicationRelayer
+	}
+	messageCoordinator := relayer.NewMessageCoordinator(
+		logger,
+		messageHandlerFactories,
+		applicationRelayers,
+		map[ids.ID]*ethclient.Client{backfillCfg.SourceBlockchainID: sourceClient},
+		nil,
+	)
+
+	errChan := make(chan error, 1)
+	subscriber := relayerEVM.NewSubscriber(logger, backfillCfg.SourceBlockchainID, nil, sourceClient, errChan)
+	go subscriber.ProcessFromHeight(backfillCfg.FromHeight, backfillCfg.ToHeight)
+
+	report, err := backfill.Run(ctx, messageCoordinator, subscriber.ICMBlocks(), errChan)
+	if err != nil {
+		logger.Fatal("Backfill failed", zap.Error(err))
+		failed = true
+		return
+	}
+	if len(report.FailedHeights) > 0 {
+		logger.Fatal(
+			"Backfill completed with failed blocks",
+			zap.Uint64("processedBlocks", report.ProcessedBlocks),
+			zap.Uint64("warpMessages", report.WarpMessages),
+			zap.Uint64s("failedHeights", report.FailedHeights),
+		)
+		failed = true
+		return
+	}
+	logger.Info(
+		"Backfill completed",
+		zap.Uint64("processedBlocks", report.ProcessedBlocks),
+		zap.Uint64("warpMessages", report.WarpMessages),
+	)
+}
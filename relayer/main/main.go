@@ -19,6 +19,8 @@ import (
 	"github.com/ryt-io/ryt-v2/utils/constants"
 	"github.com/ryt-io/ryt-v2/utils/logging"
 	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/decider"
+	"github.com/ryt-io/icm-services/events"
 	"github.com/ryt-io/icm-services/messages"
 	offchainregistry "github.com/ryt-io/icm-services/messages/off-chain-registry"
 	"github.com/ryt-io/icm-services/messages/teleporter"
@@ -33,15 +35,15 @@ import (
 	sigAggMetrics "github.com/ryt-io/icm-services/signature-aggregator/metrics"
 	"github.com/ryt-io/icm-services/utils"
 	"github.com/ryt-io/icm-services/vms"
+	relayerEVM "github.com/ryt-io/icm-services/vms/evm"
 	"github.com/ryt-io/libevm/common"
 	"github.com/ryt-io/libevm/ethclient"
+	"github.com/spf13/pflag"
 	"go.uber.org/atomic"
 	// Sets GOMAXPROCS to the CPU quota for containerized environments
 	_ "go.uber.org/automaxprocs"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 var version = "v0.0.0-dev"
@@ -71,12 +73,40 @@ func main() {
 		),
 	)
 
-	cfg, err := buildConfig()
+	command, err := config.ParseCommand(os.Args[1:])
+	if err != nil {
+		logger.Fatal("couldn't parse command", zap.Error(err))
+		os.Exit(1)
+	}
+	switch command {
+	case config.BackfillCommand:
+		runBackfill(logger)
+		return
+	case config.StateCommand:
+		runState()
+		return
+	}
+
+	fs, err := parseFlags(config.BuildFlagSet(), os.Args[1:])
+	if err != nil {
+		logger.Fatal("couldn't parse flags", zap.Error(err))
+		os.Exit(1)
+	}
+	cfg, err := loadConfig(fs)
 	if err != nil {
 		logger.Fatal("couldn't build config", zap.Error(err))
 		os.Exit(1)
 	}
 
+	// Exit with an error once the other deferred functions have run, such as releasing the leader lease,
+	// if any of the relayer's goroutines failed
+	var shutdownErr error
+	defer func() {
+		if shutdownErr != nil {
+			os.Exit(1)
+		}
+	}()
+
 	// Create parent context with cancel function
 	parentCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -84,21 +114,7 @@ func main() {
 	// Create errgroup with parent context
 	errGroup, ctx := errgroup.WithContext(parentCtx)
 
-	// Modify the default http.DefaultClient globally
-	// TODO: Remove this temporary fix once the RPC clients used by the relayer
-	// start accepting custom underlying http clients.
-	{
-		// Set the timeout conservatively to catch any potential cases where the context is not used
-		// and the request hangs indefinitely.
-		http.DefaultClient.Timeout = 2 * utils.DefaultRPCTimeout
-		maxConns := 10_000
-		http.DefaultClient.Transport = &http.Transport{
-			MaxConnsPerHost:     maxConns,
-			MaxIdleConns:        maxConns,
-			MaxIdleConnsPerHost: maxConns,
-			IdleConnTimeout:     0, // Unlimited since handled by context and timeout on the client level.
-		}
-	}
+	configureHTTPClient()
 
 	logLevel, err := logging.ToLevel(cfg.LogLevel)
 	if err != nil {
@@ -118,22 +134,6 @@ func main() {
 
 	logger.Info("Config", cfg.LogSafeField())
 
-	// Initialize all destination clients
-	logger.Info("Initializing destination clients")
-	destinationClients, err := vms.CreateDestinationClients(logger, cfg)
-	if err != nil {
-		logger.Fatal("Failed to create destination clients", zap.Error(err))
-		os.Exit(1)
-	}
-
-	// Initialize all source clients
-	logger.Info("Initializing source clients")
-	sourceClients, err := createSourceClients(ctx, logger, cfg)
-	if err != nil {
-		logger.Fatal("Failed to create source clients", zap.Error(err))
-		os.Exit(1)
-	}
-
 	// Initialize metrics gathered through prometheus
 	registries, err := metricsServer.StartMetricsServer(
 		logger,
@@ -229,21 +229,34 @@ func main() {
 	ticker := utils.NewTicker(cfg.DBWriteIntervalSeconds)
 	go ticker.Run(ctx)
 
-	relayerHealth := createHealthTrackers(cfg)
-
-	deciderConnection, err := createDeciderConnection(cfg.DeciderURL)
+	// Refresh the expiry of the keys of all configured relayer IDs on every tick, so that keys that are rarely
+	// written do not expire while their relayer ID is configured
+	expirySignal := ticker.Subscribe()
+	expiryRefreshDone := make(chan struct{})
+	go func() {
+		defer close(expiryRefreshDone)
+		for range expirySignal {
+			if err := database.RefreshKeyExpiry(db); err != nil {
+				logger.Warn("Failed to refresh database key expiry", zap.Error(err))
+			}
+		}
+	}()
+	defer func() {
+		ticker.Unsubscribe(expirySignal)
+		<-expiryRefreshDone
+	}()
+
+	deciderMetrics := decider.NewMetrics(relayerMetricsRegistry)
+	deciderClient, err := decider.New(logger, cfg, deciderMetrics)
 	if err != nil {
-		logger.Fatal("Failed to instantiate decider connection", zap.Error(err))
+		logger.Fatal("Failed to instantiate decider", zap.Error(err))
 		os.Exit(1)
 	}
-	if deciderConnection != nil {
-		defer deciderConnection.Close()
-	}
 
 	messageHandlerFactories, err := createMessageHandlerFactories(
 		logger,
 		cfg,
-		deciderConnection,
+		deciderClient,
 	)
 	if err != nil {
 		logger.Fatal("Failed to create message handler factories", zap.Error(err))
@@ -268,37 +281,83 @@ func main() {
 	// to avoid trying to issue too many requests at once.
 	processMessageSemaphore := make(chan struct{}, cfg.MaxConcurrentMessages)
 
-	applicationRelayers, minHeights, err := createApplicationRelayers(
-		ctx,
+	var deadLetterQueue *database.DeadLetterQueue
+	if cfg.EnableDeadLetterQueue {
+		deadLetterQueue = database.NewDeadLetterQueue(
+			db,
+			cfg.DeadLetterQueueMaxSize,
+			database.NewDeadLetterQueueMetrics(relayerMetricsRegistry),
+		)
+	}
+
+	messageStatusStore, err := database.NewMessageStatusStore(
 		logger,
-		relayer.NewApplicationRelayerMetrics(relayerMetricsRegistry),
-		checkpoint.NewCheckpointManagerMetrics(relayerMetricsRegistry),
 		db,
-		ticker,
-		network,
-		cfg,
-		sourceClients,
-		destinationClients,
-		signatureAggregator,
-		processMessageSemaphore,
+		database.GetConfigRelayerIDs(cfg),
+		database.DefaultMaxMessageStatuses,
 	)
 	if err != nil {
-		logger.Fatal("Failed to create application relayers", zap.Error(err))
+		logger.Fatal("Failed to create message status store", zap.Error(err))
 		os.Exit(1)
 	}
-	messageCoordinator := relayer.NewMessageCoordinator(
-		logger,
-		messageHandlerFactories,
-		applicationRelayers,
-		sourceClients,
-	)
+	messageStatusWriteSignal := ticker.Subscribe()
+	messageStatusStore.Run(messageStatusWriteSignal)
+	defer func() {
+		// Write the latest statuses before the database is closed
+		ticker.Unsubscribe(messageStatusWriteSignal)
+		<-messageStatusStore.Done()
+	}()
 
-	networkHealthFunc := network.GetNetworkHealthFunc(cfg.GetTrackedSubnets().List())
+	eventSinks, err := events.NewSinks(logger, cfg.EventSinks, events.NewMetrics(relayerMetricsRegistry))
+	if err != nil {
+		logger.Fatal("Failed to create event sinks", zap.Error(err))
+		os.Exit(1)
+	}
+	defer eventSinks.Close()
+
+	relayerMetrics := relayer.NewApplicationRelayerMetrics(relayerMetricsRegistry)
+	checkpointMetrics := checkpoint.NewCheckpointManagerMetrics(relayerMetricsRegistry)
+	endpointMetrics := relayerEVM.NewEndpointMetrics(relayerMetricsRegistry)
+	destinationClientMetrics := relayerEVM.NewDestinationClientMetrics(relayerMetricsRegistry)
+
+	// The reloader owns the per-route components once they are created, and replaces them when the
+	// configuration is reloaded
+	reloader := &reloader{
+		logger:                   logger,
+		ctx:                      ctx,
+		errGroup:                 errGroup,
+		loadConfig:               func() (*config.Config, error) { return loadConfig(fs) },
+		network:                  network,
+		db:                       db,
+		ticker:                   ticker,
+		relayerMetrics:           relayerMetrics,
+		checkpointMetrics:        checkpointMetrics,
+		endpointMetrics:          endpointMetrics,
+		destinationClientMetrics: destinationClientMetrics,
+		signatureAggregator:      signatureAggregator,
+		processMessageSemaphore:  processMessageSemaphore,
+		deadLetterQueue:          deadLetterQueue,
+		messageStatusStore:       messageStatusStore,
+		eventSink:                eventSinks,
+		cfg:                      cfg,
+		deciderMetrics:           deciderMetrics,
+		deciderClient:            deciderClient,
+		sources:                  make(map[ids.ID]*sourceRoutes),
+		relayerHealth:            make(map[ids.ID]*atomic.Bool),
+		trackedSubnets:           cfg.GetTrackedSubnets().List(),
+		healthDeciderClient:      deciderClient,
+	}
+	defer reloader.close()
 
 	// Each Listener goroutine will have an atomic bool that it can set to false to indicate an unrecoverable error
-	api.HandleHealthCheck(logger, relayerHealth, networkHealthFunc)
-	api.HandleRelay(logger, messageCoordinator)
-	api.HandleRelayMessage(logger, messageCoordinator)
+	api.HandleHealthCheck(
+		logger,
+		reloader.getRelayerHealth,
+		reloader.networkHealth,
+		reloader.destinationHealth,
+		reloader.deciderHealth,
+	)
+	api.HandleMessageStatus(logger, messageStatusStore)
 
 	errGroup.Go(func() error {
 		httpServer := &http.Server{
@@ -319,29 +378,6 @@ func main() {
 		return nil
 	})
 
-	// Create listeners for each of the subnets configured as a source
-	for _, sourceBlockchain := range cfg.SourceBlockchains {
-		// errgroup will cancel the context when the first goroutine returns an error
-		errGroup.Go(func() error {
-			log := logger.With(zap.Stringer("sourceBlockchainID", sourceBlockchain.GetBlockchainID()))
-			// runListener runs until it errors or the context is canceled by another goroutine
-			err := relayer.RunListener(
-				ctx,
-				log,
-				*sourceBlockchain,
-				sourceClients[sourceBlockchain.GetBlockchainID()],
-				relayerHealth[sourceBlockchain.GetBlockchainID()],
-				minHeights[sourceBlockchain.GetBlockchainID()],
-				messageCoordinator,
-				cfg.MaxConcurrentMessages,
-			)
-			if err != nil {
-				log.Error("error running listener", zap.Error(err))
-			}
-			return err
-		})
-	}
-
 	// Handle os signal
 	errGroup.Go(func() error {
 		sigChan := make(chan os.Signal, 1)
@@ -358,22 +394,164 @@ func main() {
 		return nil
 	})
 
+	// Subscribe to SIGHUP before waiting for the leader lease, so that a standby instance is not terminated
+	// by the default SIGHUP handler. Signals received on standby are handled once the instance leads.
+	reloadSigChan := make(chan os.Signal, 1)
+	signal.Notify(reloadSigChan, syscall.SIGHUP)
+
+	// In leader election mode, stay on standby with the P2P connections established until this instance
+	// holds the leader lease. Only the leader creates the per-route components, runs listeners, and
+	// sends transactions.
+	if cfg.EnableLeaderElection {
+		lease, err := database.NewLeaderLease(logger, cfg)
+		if err != nil {
+			logger.Fatal("Failed to create leader lease", zap.Error(err))
+			os.Exit(1)
+		}
+		defer lease.Close()
+
+		if err := lease.WaitForLeadership(ctx); err != nil {
+			logger.Info("Stopped waiting for leader lease", zap.Error(err))
+			shutdownErr = waitForShutdown(logger, errGroup)
+			return
+		}
+		// Release the lease once the listeners have exited, or initialization failed, so that the standby takes
+		// over immediately. Also runs if the lease is lost, in which case the lease is only released if it is
+		// still held. Renewing the lease is stopped first, so that it is not reacquired once released.
+		holdCtx, stopHolding := context.WithCancel(ctx)
+		holdDone := make(chan struct{})
+		defer func() {
+			stopHolding()
+			<-holdDone
+			lease.Release()
+		}()
+		// errgroup will cancel the context if the lease is lost, which stops the listeners
+		errGroup.Go(func() error {
+			defer close(holdDone)
+			return lease.Hold(holdCtx)
+		})
+	}
+
+	// Initialize all destination clients
+	logger.Info("Initializing destination clients")
+	destinationClients, err := vms.CreateDestinationClients(logger, cfg, destinationClientMetrics, db)
+	if err != nil {
+		logger.Fatal("Failed to create destination clients", zap.Error(err))
+		shutdownErr = err
+		return
+	}
+
+	// Initialize all source clients
+	logger.Info("Initializing source clients")
+	sourceClients, rpcEndpoints, err := createSourceClients(ctx, logger, endpointMetrics, cfg)
+	if err != nil {
+		logger.Fatal("Failed to create source clients", zap.Error(err))
+		shutdownErr = err
+		return
+	}
+
+	applicationRelayers, minHeights, stopCheckpointManagers, err := createApplicationRelayers(
+		ctx,
+		logger,
+		relayerMetrics,
+		checkpointMetrics,
+		db,
+		ticker,
+		network,
+		cfg,
+		sourceClients,
+		destinationClients,
+		signatureAggregator,
+		processMessageSemaphore,
+		deadLetterQueue,
+		messageStatusStore,
+		eventSinks,
+	)
+	if err != nil {
+		logger.Fatal("Failed to create application relayers", zap.Error(err))
+		shutdownErr = err
+		return
+	}
+	messageCoordinator := relayer.NewMessageCoordinator(
+		logger,
+		messageHandlerFactories,
+		applicationRelayers,
+		sourceClients,
+		deadLetterQueue,
+	)
+	reloader.setDestinationClients(destinationClients)
+	reloader.messageCoordinator = messageCoordinator
+
+	api.HandleRelay(logger, messageCoordinator)
+	api.HandleRelayMessage(logger, messageCoordinator)
+	if deadLetterQueue != nil {
+		api.HandleDeadLetters(logger, messageCoordinator)
+	}
+	api.HandleReload(logger, reloader.reload)
+
+	// Create listeners for each of the subnets configured as a source
+	for _, sourceBlockchain := range cfg.SourceBlockchains {
+		blockchainID := sourceBlockchain.GetBlockchainID()
+		reloader.runListener(
+			*sourceBlockchain,
+			sourceClients[blockchainID],
+			rpcEndpoints[blockchainID],
+			minHeights[blockchainID],
+			stopCheckpointManagers[blockchainID],
+		)
+	}
+
+	// Reload the configuration on SIGHUP
+	errGroup.Go(func() error {
+		for {
+			select {
+			case <-reloadSigChan:
+				logger.Info("Reloading configuration on SIGHUP")
+				if err := reloader.reload(); err != nil {
+					logger.Error("Failed to reload configuration", zap.Error(err))
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+
 	logger.Info("Initialization complete")
+	shutdownErr = waitForShutdown(logger, errGroup)
+}
+
+// waitForShutdown waits for the relayer's goroutines to exit, and returns the error of the first of them that
+// failed. The caller exits with an error once its deferred functions have run.
+func waitForShutdown(logger logging.Logger, errGroup *errgroup.Group) error {
 	if err := errGroup.Wait(); err != nil {
 		logger.Fatal("Relayer exiting with error.", zap.Error(err))
-		os.Exit(1)
+		return err
 	}
 
 	logger.Info("Relayer exited gracefully")
+	return nil
+}
+
+// configureHTTPClient modifies the default http.DefaultClient globally
+// TODO: Remove this temporary fix once the RPC clients used by the relayer
+// start accepting custom underlying http clients.
+func configureHTTPClient() {
+	// Set the timeout conservatively to catch any potential cases where the context is not used
+	// and the request hangs indefinitely.
+	http.DefaultClient.Timeout = 2 * utils.DefaultRPCTimeout
+	maxConns := 10_000
+	http.DefaultClient.Transport = &http.Transport{
+		MaxConnsPerHost:     maxConns,
+		MaxIdleConns:        maxConns,
+		MaxIdleConnsPerHost: maxConns,
+		IdleConnTimeout:     0, // Unlimited since handled by context and timeout on the client level.
+	}
 }
 
-// buildConfig parses the flags and builds the config
-// Errors here should call log.Fatalf to exit the program
-// since these errors are prior to building the logger struct
-func buildConfig() (*config.Config, error) {
-	fs := config.BuildFlagSet()
+// parseFlags parses [args] into [fs], and handles the version and help flags
+func parseFlags(fs *pflag.FlagSet, args []string) (*pflag.FlagSet, error) {
 	// Parse the flags
-	if err := fs.Parse(os.Args[1:]); err != nil {
+	if err := fs.Parse(args); err != nil {
 		config.DisplayUsageText()
 		return nil, fmt.Errorf("couldn't parse flags: %w", err)
 	}
@@ -397,7 +575,12 @@ func buildConfig() (*config.Config, error) {
 		config.DisplayUsageText()
 		os.Exit(0)
 	}
+	return fs, nil
+}
 
+// loadConfig reads and validates the config from the config file set via the parsed flags.
+// It is called on startup and each time the configuration is reloaded.
+func loadConfig(fs *pflag.FlagSet) (*config.Config, error) {
 	v, err := config.BuildViper(fs)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't build viper: %w", err)
@@ -413,7 +596,7 @@ func buildConfig() (*config.Config, error) {
 func createMessageHandlerFactories(
 	logger logging.Logger,
 	globalConfig *config.Config,
-	deciderConnection *grpc.ClientConn,
+	deciderClient decider.Decider,
 ) (map[ids.ID]map[common.Address]messages.MessageHandlerFactory, error) {
 	messageHandlerFactories := make(map[ids.ID]map[common.Address]messages.MessageHandlerFactory)
 	for _, sourceBlockchain := range globalConfig.SourceBlockchains {
@@ -431,7 +614,7 @@ func createMessageHandlerFactories(
 				m, err = teleporter.NewMessageHandlerFactory(
 					address,
 					cfg,
-					deciderConnection,
+					deciderClient,
 				)
 			case config.OFF_CHAIN_REGISTRY:
 				m, err = offchainregistry.NewMessageHandlerFactory(cfg)
@@ -448,34 +631,58 @@ func createMessageHandlerFactories(
 	return messageHandlerFactories, nil
 }
 
+// Returns maps of source blockchain IDs to RPC clients, and to the pools of RPC endpoints the clients fail
+// over between
 func createSourceClients(
 	ctx context.Context,
 	logger logging.Logger,
+	endpointMetrics *relayerEVM.EndpointMetrics,
 	cfg *config.Config,
-) (map[ids.ID]*ethclient.Client, error) {
-	var err error
+) (map[ids.ID]*ethclient.Client, map[ids.ID]*relayerEVM.EndpointPool, error) {
 	clients := make(map[ids.ID]*ethclient.Client)
+	rpcEndpoints := make(map[ids.ID]*relayerEVM.EndpointPool)
 
 	for _, sourceBlockchain := range cfg.SourceBlockchains {
-		clients[sourceBlockchain.GetBlockchainID()], err = utils.NewEthClientWithConfig(
-			ctx,
-			sourceBlockchain.RPCEndpoint.BaseURL,
-			sourceBlockchain.RPCEndpoint.HTTPHeaders,
-			sourceBlockchain.RPCEndpoint.QueryParams,
-		)
+		blockchainID := sourceBlockchain.GetBlockchainID()
+		client, endpoints, err := createSourceClient(ctx, logger, endpointMetrics, sourceBlockchain)
 		if err != nil {
-			logger.Error(
-				"Failed to connect to node via RPC",
-				zap.String("blockchainID", sourceBlockchain.BlockchainID),
-				zap.Error(err),
-			)
-			return nil, err
+			return nil, nil, err
 		}
+		clients[blockchainID] = client
+		rpcEndpoints[blockchainID] = endpoints
+	}
+	return clients, rpcEndpoints, nil
+}
+
+func createSourceClient(
+	ctx context.Context,
+	logger logging.Logger,
+	endpointMetrics *relayerEVM.EndpointMetrics,
+	sourceBlockchain *config.SourceBlockchain,
+) (*ethclient.Client, *relayerEVM.EndpointPool, error) {
+	rpcEndpoints, err := relayerEVM.NewSourceEndpointPool(
+		logger,
+		sourceBlockchain,
+		relayerEVM.RPCEndpointType,
+		endpointMetrics,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := relayerEVM.NewFailoverEthClient(ctx, rpcEndpoints)
+	if err != nil {
+		logger.Error(
+			"Failed to connect to node via RPC",
+			zap.String("blockchainID", sourceBlockchain.BlockchainID),
+			zap.Error(err),
+		)
+		return nil, nil, err
 	}
-	return clients, nil
+	return client, rpcEndpoints, nil
 }
 
-// Returns a map of application relayers, as well as a map of source blockchain IDs to starting heights.
+// Returns a map of application relayers, as well as maps of source blockchain IDs to starting heights and to
+// functions that stop the source blockchain's checkpoint managers.
 func createApplicationRelayers(
 	ctx context.Context,
 	logger logging.Logger,
@@ -489,9 +696,13 @@ func createApplicationRelayers(
 	destinationClients map[ids.ID]vms.DestinationClient,
 	signatureAggregator *aggregator.SignatureAggregator,
 	processMessagesSemaphore chan struct{},
-) (map[common.Hash]*relayer.ApplicationRelayer, map[ids.ID]uint64, error) {
+	deadLetterQueue *database.DeadLetterQueue,
+	messageStatusStore *database.MessageStatusStore,
+	eventSink events.Sink,
+) (map[common.Hash]*relayer.ApplicationRelayer, map[ids.ID]uint64, map[ids.ID]func(), error) {
 	applicationRelayers := make(map[common.Hash]*relayer.ApplicationRelayer)
 	minHeights := make(map[ids.ID]uint64)
+	stopCheckpointManagers := make(map[ids.ID]func())
 	for _, sourceBlockchain := range cfg.SourceBlockchains {
 		logger = logger.With(
 			zap.Stringer("sourceBlockchainID", sourceBlockchain.GetBlockchainID()),
@@ -499,11 +710,11 @@ func createApplicationRelayers(
 		currentHeight, err := sourceClients[sourceBlockchain.GetBlockchainID()].BlockNumber(ctx)
 		if err != nil {
 			logger.Error("Failed to get current block height", zap.Error(err))
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		// Create the ApplicationRelayers
-		applicationRelayersForSource, minHeight, err := createApplicationRelayersForSourceChain(
+		applicationRelayersForSource, minHeight, stop, err := createApplicationRelayersForSourceChain(
 			ctx,
 			logger,
 			relayerMetrics,
@@ -517,22 +728,27 @@ func createApplicationRelayers(
 			destinationClients,
 			signatureAggregator,
 			processMessagesSemaphore,
+			deadLetterQueue,
+			messageStatusStore,
+			eventSink,
 		)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create application relayers: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to create application relayers: %w", err)
 		}
 
 		for relayerID, applicationRelayer := range applicationRelayersForSource {
 			applicationRelayers[relayerID] = applicationRelayer
 		}
 		minHeights[sourceBlockchain.GetBlockchainID()] = minHeight
+		stopCheckpointManagers[sourceBlockchain.GetBlockchainID()] = stop
 
 		logger.Info("Created application relayers")
 	}
-	return applicationRelayers, minHeights, nil
+	return applicationRelayers, minHeights, stopCheckpointManagers, nil
 }
 
 // createApplicationRelayersForSourceChain creates Application Relayers for a given source blockchain.
+// The returned function flushes and stops the Application Relayers' checkpoint managers.
 func createApplicationRelayersForSourceChain(
 	ctx context.Context,
 	logger logging.Logger,
@@ -547,11 +763,26 @@ func createApplicationRelayersForSourceChain(
 	destinationClients map[ids.ID]vms.DestinationClient,
 	signatureAggregator *aggregator.SignatureAggregator,
 	processMessageSemaphore chan struct{},
-) (map[common.Hash]*relayer.ApplicationRelayer, uint64, error) {
+	deadLetterQueue *database.DeadLetterQueue,
+	messageStatusStore *database.MessageStatusStore,
+	eventSink events.Sink,
+) (map[common.Hash]*relayer.ApplicationRelayer, uint64, func(), error) {
 	// Create the ApplicationRelayers
 	logger.Info("Creating application relayers")
 	applicationRelayers := make(map[common.Hash]*relayer.ApplicationRelayer)
 
+	var (
+		writeSignals       []chan struct{}
+		checkpointManagers []*checkpoint.CheckpointManager
+	)
+	stopCheckpointManagers := func() {
+		// Unsubscribing closes the write signal, which writes the committed height a final time
+		for i, writeSignal := range writeSignals {
+			ticker.Unsubscribe(writeSignal)
+			<-checkpointManagers[i].Done()
+		}
+	}
+
 	// Each ApplicationRelayer determines its starting height based on the configuration and database state.
 	// The Listener begins processing messages starting from the minimum height across all the ApplicationRelayers
 	// If catch up is disabled, the first block the ApplicationRelayer processes is the next block after the current height
@@ -581,7 +812,8 @@ func createApplicationRelayersForSourceChain(
 			)
 			if err != nil {
 				logger.Error("Failed to calculate starting block height", zap.Error(err))
-				return nil, 0, err
+				stopCheckpointManagers()
+				return nil, 0, nil, err
 			}
 
 			// Update the min height. This is the height that the listener will start processing from
@@ -590,17 +822,20 @@ func createApplicationRelayersForSourceChain(
 			}
 		}
 
+		writeSignal := ticker.Subscribe()
 		checkpointManager, err := checkpoint.NewCheckpointManager(
 			logger,
 			checkpointMetrics,
 			db,
-			ticker.Subscribe(),
+			writeSignal,
 			relayerID,
 			height,
 		)
 		if err != nil {
 			logger.Error("Failed to create checkpoint manager", zap.Error(err))
-			return nil, 0, err
+			ticker.Unsubscribe(writeSignal)
+			stopCheckpointManagers()
+			return nil, 0, nil, err
 		}
 
 		applicationRelayer, err := relayer.NewApplicationRelayer(
@@ -614,43 +849,22 @@ func createApplicationRelayersForSourceChain(
 			cfg,
 			signatureAggregator,
 			processMessageSemaphore,
+			deadLetterQueue,
+			messageStatusStore,
+			eventSink,
 		)
 		if err != nil {
 			logger.Error("Failed to create application relayer", zap.Error(err))
-			return nil, 0, err
+			// The checkpoint manager is only run once the application relayer is created
+			ticker.Unsubscribe(writeSignal)
+			stopCheckpointManagers()
+			return nil, 0, nil, err
 		}
 		applicationRelayers[relayerID.ID] = applicationRelayer
+		writeSignals = append(writeSignals, writeSignal)
+		checkpointManagers = append(checkpointManagers, checkpointManager)
 
 		logger.Info("Created application relayer")
 	}
-	return applicationRelayers, minHeight, nil
-}
-
-// create a connection to the "should send message" decider service.
-// if url is unspecified, returns a nil client pointer
-func createDeciderConnection(url string) (*grpc.ClientConn, error) {
-	if len(url) == 0 {
-		return nil, nil
-	}
-
-	connection, err := grpc.NewClient(
-		url,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		return nil, fmt.Errorf(
-			"Failed to instantiate grpc client: %w",
-			err,
-		)
-	}
-
-	return connection, nil
-}
-
-func createHealthTrackers(cfg *config.Config) map[ids.ID]*atomic.Bool {
-	healthTrackers := make(map[ids.ID]*atomic.Bool, len(cfg.SourceBlockchains))
-	for _, sourceBlockchain := range cfg.SourceBlockchains {
-		healthTrackers[sourceBlockchain.GetBlockchainID()] = atomic.NewBool(true)
-	}
-	return healthTrackers
+	return applicationRelayers, minHeight, stopCheckpointManagers, nil
 }
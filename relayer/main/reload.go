@@ -0,0 +1,490 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/decider"
+	"github.com/ryt-io/icm-services/events"
+	"github.com/ryt-io/icm-services/peers"
+	"github.com/ryt-io/icm-services/relayer"
+	"github.com/ryt-io/icm-services/relayer/checkpoint"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/icm-services/signature-aggregator/aggregator"
+	"github.com/ryt-io/icm-services/utils"
+	"github.com/ryt-io/icm-services/vms"
+	"github.com/ryt-io/icm-services/vms/evm"
+	"github.com/ryt-io/libevm/ethclient"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// sourceRoutes tracks the running components of a source blockchain's routes
+type sourceRoutes struct {
+	cancelListener         context.CancelFunc
+	listenerDone           chan struct{}
+	stopCheckpointManagers func()
+}
+
+// reloader applies configuration changes without restarting the relayer. Components that are shared by
+// all routes are created once at startup. Listeners, application relayers, checkpoint managers and
+// destination clients are only recreated for the routes affected by the change.
+type reloader struct {
+	logger     logging.Logger
+	ctx        context.Context
+	errGroup   *errgroup.Group
+	loadConfig func() (*config.Config, error)
+
+	network                  *peers.AppRequestNetwork
+	db                       database.RelayerDatabase
+	ticker                   *utils.Ticker
+	relayerMetrics           *relayer.ApplicationRelayerMetrics
+	checkpointMetrics        *checkpoint.CheckpointManagerMetrics
+	endpointMetrics          *evm.EndpointMetrics
+	destinationClientMetrics *evm.DestinationClientMetrics
+	signatureAggregator      *aggregator.SignatureAggregator
+	processMessageSemaphore  chan struct{}
+	deadLetterQueue          *database.DeadLetterQueue
+	messageStatusStore       *database.MessageStatusStore
+	eventSink                events.Sink
+	deciderMetrics           *decider.Metrics
+	messageCoordinator       *relayer.MessageCoordinator
+
+	// Serializes reloads, and guards the fields below
+	lock               sync.Mutex
+	cfg                *config.Config
+	destinationClients map[ids.ID]vms.DestinationClient
+	deciderClient      decider.Decider
+	sources            map[ids.ID]*sourceRoutes
+
+	// Guards the fields read by the health check
+	healthLock     sync.RWMutex
+	relayerHealth  map[ids.ID]*atomic.Bool
+	trackedSubnets []ids.ID
+	// The destination clients, shared with the health check so that it is not blocked by reloads
+	healthDestinationClients map[ids.ID]vms.DestinationClient
+	// The decider, shared with the health check. Nil if the decider is not configured.
+	healthDeciderClient decider.Decider
+}
+
+// reload reads the configuration file and applies the changes to the running relayer.
+// The new configuration is rejected if it changes options that require a restart.
+func (r *reloader) reload() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cfg, err := r.loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := r.cfg.ValidateReload(cfg); err != nil {
+		return err
+	}
+	logLevel, err := logging.ToLevel(cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("error reading log level from config: %w", err)
+	}
+	if err := cfg.Initialize(r.ctx); err != nil {
+		return fmt.Errorf("failed to initialize config: %w", err)
+	}
+
+	// Create the message handler factories up front, so that the running routes are not stopped
+	// if the new decider client can not be created.
+	deciderClient := r.deciderClient
+	if deciderConfigChanged(r.cfg, cfg) {
+		deciderClient, err = decider.New(r.logger, cfg, r.deciderMetrics)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate decider: %w", err)
+		}
+	}
+	closeDeciderClient := func(client decider.Decider) {
+		if client == nil || client == r.deciderClient {
+			return
+		}
+		if err := client.Close(); err != nil {
+			r.logger.Warn("Failed to close decider client", zap.Error(err))
+		}
+	}
+	messageHandlerFactories, err := createMessageHandlerFactories(r.logger, cfg, deciderClient)
+	if err != nil {
+		closeDeciderClient(deciderClient)
+		return fmt.Errorf("failed to create message handler factories: %w", err)
+	}
+
+	// Destinations that were added, removed or modified, or whose clients were closed by a reload that failed
+	// to create their replacements
+	currentDestinations := make(map[ids.ID]*config.DestinationBlockchain)
+	for _, destinationBlockchain := range r.cfg.DestinationBlockchains {
+		currentDestinations[destinationBlockchain.GetBlockchainID()] = destinationBlockchain
+	}
+	changedDestinations := set.NewSet[ids.ID](len(cfg.DestinationBlockchains))
+	for _, destinationBlockchain := range cfg.DestinationBlockchains {
+		blockchainID := destinationBlockchain.GetBlockchainID()
+		_, running := r.destinationClients[blockchainID]
+		if current, ok := currentDestinations[blockchainID]; !ok || !running ||
+			!reflect.DeepEqual(current, destinationBlockchain) {
+			changedDestinations.Add(blockchainID)
+		}
+		delete(currentDestinations, blockchainID)
+	}
+	removedDestinations := set.NewSet[ids.ID](len(currentDestinations))
+	for blockchainID := range currentDestinations {
+		removedDestinations.Add(blockchainID)
+	}
+
+	// Sources that were modified, that route to a changed destination, or that are not running because
+	// a previous reload failed to start them are restarted
+	currentSources := make(map[ids.ID]*config.SourceBlockchain)
+	for _, sourceBlockchain := range r.cfg.SourceBlockchains {
+		currentSources[sourceBlockchain.GetBlockchainID()] = sourceBlockchain
+	}
+	var affectedSources []*config.SourceBlockchain
+	for _, sourceBlockchain := range cfg.SourceBlockchains {
+		blockchainID := sourceBlockchain.GetBlockchainID()
+		current, ok := currentSources[blockchainID]
+		delete(currentSources, blockchainID)
+		if _, running := r.sources[blockchainID]; running && ok && reflect.DeepEqual(current, sourceBlockchain) &&
+			!routesToDestination(sourceBlockchain, changedDestinations) {
+			continue
+		}
+		affectedSources = append(affectedSources, sourceBlockchain)
+	}
+
+	// Stop the routes of the removed and affected sources before creating their replacements
+	for blockchainID := range currentSources {
+		r.logger.Info("Removing source blockchain", zap.Stringer("sourceBlockchainID", blockchainID))
+		r.stopSource(blockchainID)
+		r.removeRelayerHealth(blockchainID)
+	}
+	for _, sourceBlockchain := range affectedSources {
+		r.stopSource(sourceBlockchain.GetBlockchainID())
+	}
+
+	for _, subnetID := range cfg.GetTrackedSubnets().List() {
+		if !r.cfg.GetTrackedSubnets().Contains(subnetID) {
+			r.logger.Info("Tracking subnet", zap.Stringer("subnetID", subnetID))
+			r.network.TrackSubnet(r.ctx, subnetID)
+		}
+	}
+
+	// The sources that use the replaced destination clients were stopped above, once their in-flight messages
+	// were processed. The replaced clients are closed before creating their replacements, which wait for the
+	// pending transactions of the replaced clients to be accepted, so that their signers do not issue
+	// transactions concurrently.
+	destinationClients := make(map[ids.ID]vms.DestinationClient)
+	for blockchainID, destinationClient := range r.destinationClients {
+		if !changedDestinations.Contains(blockchainID) && !removedDestinations.Contains(blockchainID) {
+			destinationClients[blockchainID] = destinationClient
+		}
+	}
+	r.setDestinationClients(destinationClients)
+
+	newDestinationClients := make(map[ids.ID]vms.DestinationClient)
+	if changedDestinations.Len() > 0 {
+		newDestinationClients, err = vms.CreateDestinationClientsForBlockchains(
+			r.logger,
+			cfg,
+			changedDestinations,
+			r.destinationClientMetrics,
+			r.db,
+		)
+		if err != nil {
+			closeDeciderClient(deciderClient)
+			return fmt.Errorf("failed to create destination clients: %w", err)
+		}
+	}
+	// The map shared with the health check is replaced rather than modified
+	destinationClients = maps.Clone(destinationClients)
+	for blockchainID, destinationClient := range newDestinationClients {
+		destinationClients[blockchainID] = destinationClient
+	}
+
+	replacedHandlersDone := r.messageCoordinator.SetMessageHandlerFactories(messageHandlerFactories)
+	replacedDeciderClient := r.deciderClient
+	if deciderClient != replacedDeciderClient && replacedDeciderClient != nil {
+		// The replaced decider is closed once the messages of all sources whose handlers may still query it
+		// are processed
+		go func() {
+			<-replacedHandlersDone
+			if err := replacedDeciderClient.Close(); err != nil {
+				r.logger.Warn("Failed to close decider client", zap.Error(err))
+			}
+		}()
+	}
+	r.deciderClient = deciderClient
+	r.setDestinationClients(destinationClients)
+	r.cfg = cfg
+	r.logger.SetLevel(logLevel)
+	r.healthLock.Lock()
+	r.trackedSubnets = cfg.GetTrackedSubnets().List()
+	r.healthDeciderClient = deciderClient
+	r.healthLock.Unlock()
+
+	// Sources that fail to start are retried on the next reload
+	var errs []error
+	for _, sourceBlockchain := range affectedSources {
+		if err := r.startSource(sourceBlockchain); err != nil {
+			errs = append(errs, fmt.Errorf("failed to start source blockchain %s: %w", sourceBlockchain.BlockchainID, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	r.logger.Info(
+		"Reloaded configuration",
+		zap.Int("restartedSourceBlockchains", len(affectedSources)),
+		zap.Int("removedSourceBlockchains", len(currentSources)),
+		zap.Int("recreatedDestinationClients", len(newDestinationClients)),
+	)
+	return nil
+}
+
+// startSource creates the source client and application relayers of a source blockchain, and runs its listener.
+// Must be called with the lock held.
+func (r *reloader) startSource(sourceBlockchain *config.SourceBlockchain) error {
+	blockchainID := sourceBlockchain.GetBlockchainID()
+	logger := r.logger.With(zap.Stringer("sourceBlockchainID", blockchainID))
+	logger.Info("Starting source blockchain")
+
+	// Mark the source unhealthy until its listener is running
+	r.setRelayerHealth(blockchainID, atomic.NewBool(false))
+
+	sourceClient, rpcEndpoints, err := createSourceClient(r.ctx, logger, r.endpointMetrics, sourceBlockchain)
+	if err != nil {
+		return err
+	}
+	currentHeight, err := sourceClient.BlockNumber(r.ctx)
+	if err != nil {
+		logger.Error("Failed to get current block height", zap.Error(err))
+		return err
+	}
+	relayerIDs := database.GetSourceBlockchainRelayerIDs(sourceBlockchain)
+	if err := database.AddRelayerIDs(r.db, relayerIDs); err != nil {
+		return fmt.Errorf("failed to configure database: %w", err)
+	}
+	if err := r.messageStatusStore.AddRelayerIDs(relayerIDs); err != nil {
+		return fmt.Errorf("failed to load message statuses: %w", err)
+	}
+
+	applicationRelayers, minHeight, stopCheckpointManagers, err := createApplicationRelayersForSourceChain(
+		r.ctx,
+		logger,
+		r.relayerMetrics,
+		r.checkpointMetrics,
+		r.db,
+		r.ticker,
+		*sourceBlockchain,
+		r.network,
+		r.cfg,
+		currentHeight,
+		r.destinationClients,
+		r.signatureAggregator,
+		r.processMessageSemaphore,
+		r.deadLetterQueue,
+		r.messageStatusStore,
+		r.eventSink,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create application relayers: %w", err)
+	}
+	r.messageCoordinator.SetSourceBlockchain(blockchainID, sourceClient, applicationRelayers)
+	r.runListener(*sourceBlockchain, sourceClient, rpcEndpoints, minHeight, stopCheckpointManagers)
+	return nil
+}
+
+// stopSource stops the listener of a source blockchain, stops routing its messages, waits for its in-flight
+// messages to be processed, and flushes its checkpoints.
+// Must be called with the lock held.
+func (r *reloader) stopSource(blockchainID ids.ID) {
+	routes, ok := r.sources[blockchainID]
+	if !ok {
+		return
+	}
+	delete(r.sources, blockchainID)
+
+	routes.cancelListener()
+	<-routes.listenerDone
+	r.messageCoordinator.RemoveSourceBlockchain(blockchainID)
+	routes.stopCheckpointManagers()
+}
+
+// runListener runs the listener of a source blockchain in the errgroup, until it errors, the relayer
+// shuts down, or the source blockchain is stopped by a reload.
+// Must be called with the lock held, or before the relayer finishes initializing.
+func (r *reloader) runListener(
+	sourceBlockchain config.SourceBlockchain,
+	sourceClient *ethclient.Client,
+	rpcEndpoints *evm.EndpointPool,
+	minHeight uint64,
+	stopCheckpointManagers func(),
+) {
+	blockchainID := sourceBlockchain.GetBlockchainID()
+	health := atomic.NewBool(true)
+	r.setRelayerHealth(blockchainID, health)
+
+	listenerCtx, cancel := context.WithCancel(r.ctx)
+	done := make(chan struct{})
+	r.sources[blockchainID] = &sourceRoutes{
+		cancelListener:         cancel,
+		listenerDone:           done,
+		stopCheckpointManagers: stopCheckpointManagers,
+	}
+
+	maxConcurrentMessages := r.cfg.MaxConcurrentMessages
+	// errgroup will cancel the context when the first goroutine returns an error
+	r.errGroup.Go(func() error {
+		defer close(done)
+		log := r.logger.With(zap.Stringer("sourceBlockchainID", blockchainID))
+		// runListener runs until it errors or the context is canceled by another goroutine
+		err := relayer.RunListener(
+			listenerCtx,
+			log,
+			sourceBlockchain,
+			sourceClient,
+			rpcEndpoints,
+			r.endpointMetrics,
+			health,
+			minHeight,
+			r.messageCoordinator,
+			maxConcurrentMessages,
+		)
+		if err != nil && listenerCtx.Err() != nil && r.ctx.Err() == nil {
+			// The listener was stopped by a reload, which should not shut down the relayer
+			log.Info("Listener stopped", zap.Error(err))
+			return nil
+		}
+		if err != nil {
+			log.Error("error running listener", zap.Error(err))
+		}
+		return err
+	})
+}
+
+// close releases the resources owned by the reloader that are not tied to the relayer's context
+func (r *reloader) close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.deciderClient != nil {
+		r.deciderClient.Close()
+	}
+	for _, destinationClient := range r.destinationClients {
+		destinationClient.Close()
+	}
+}
+
+// setDestinationClients replaces the destination clients, and closes the replaced clients.
+// Must be called with lock held, or before the relayer can be reloaded.
+func (r *reloader) setDestinationClients(destinationClients map[ids.ID]vms.DestinationClient) {
+	for blockchainID, destinationClient := range r.destinationClients {
+		if destinationClients[blockchainID] != destinationClient {
+			destinationClient.Close()
+		}
+	}
+	r.destinationClients = destinationClients
+
+	r.healthLock.Lock()
+	defer r.healthLock.Unlock()
+	r.healthDestinationClients = destinationClients
+}
+
+func (r *reloader) setRelayerHealth(blockchainID ids.ID, health *atomic.Bool) {
+	r.healthLock.Lock()
+	defer r.healthLock.Unlock()
+
+	r.relayerHealth[blockchainID] = health
+}
+
+func (r *reloader) removeRelayerHealth(blockchainID ids.ID) {
+	r.healthLock.Lock()
+	defer r.healthLock.Unlock()
+
+	delete(r.relayerHealth, blockchainID)
+}
+
+// getRelayerHealth returns the health of each source blockchain's listener
+func (r *reloader) getRelayerHealth() map[ids.ID]*atomic.Bool {
+	r.healthLock.RLock()
+	defer r.healthLock.RUnlock()
+
+	relayerHealth := make(map[ids.ID]*atomic.Bool, len(r.relayerHealth))
+	for blockchainID, health := range r.relayerHealth {
+		relayerHealth[blockchainID] = health
+	}
+	return relayerHealth
+}
+
+func (r *reloader) networkHealth(ctx context.Context) error {
+	r.healthLock.RLock()
+	trackedSubnets := r.trackedSubnets
+	r.healthLock.RUnlock()
+
+	return r.network.GetNetworkHealthFunc(trackedSubnets)(ctx)
+}
+
+// destinationHealth returns an error if any of the destination clients can not currently issue transactions
+func (r *reloader) destinationHealth(context.Context) error {
+	r.healthLock.RLock()
+	destinationClients := r.healthDestinationClients
+	r.healthLock.RUnlock()
+
+	var errs []error
+	for blockchainID, destinationClient := range destinationClients {
+		if err := destinationClient.Health(); err != nil {
+			errs = append(errs, fmt.Errorf("destination blockchain %s: %w", blockchainID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func routesToDestination(sourceBlockchain *config.SourceBlockchain, destinationBlockchainIDs set.Set[ids.ID]) bool {
+	for _, supportedDestination := range sourceBlockchain.SupportedDestinations {
+		if destinationBlockchainIDs.Contains(supportedDestination.GetBlockchainID()) {
+			return true
+		}
+	}
+	return false
+}
+
+// deciderHealth returns an error if the decider can not currently be queried
+func (r *reloader) deciderHealth(context.Context) error {
+	r.healthLock.RLock()
+	deciderClient := r.healthDeciderClient
+	r.healthLock.RUnlock()
+
+	if deciderClient == nil {
+		return nil
+	}
+	return deciderClient.Health()
+}
+
+// deciderConfigChanged returns true if the decider must be recreated to apply [updated]
+func deciderConfigChanged(current, updated *config.Config) bool {
+	return current.DeciderURL != updated.DeciderURL ||
+		current.DeciderFailurePolicy != updated.DeciderFailurePolicy ||
+		current.DeciderTimeoutSeconds != updated.DeciderTimeoutSeconds ||
+		current.DeciderBreakerFailures != updated.DeciderBreakerFailures ||
+		current.DeciderBreakerCooldownSeconds != updated.DeciderBreakerCooldownSeconds ||
+		current.DeciderTLS != updated.DeciderTLS ||
+		current.DeciderTLSCertPath != updated.DeciderTLSCertPath ||
+		current.DeciderTLSKeyPath != updated.DeciderTLSKeyPath ||
+		current.DeciderTLSCACertPath != updated.DeciderTLSCACertPath ||
+		current.DeciderCacheTTLSeconds != updated.DeciderCacheTTLSeconds ||
+		!maps.Equal(current.DeciderHeaders, updated.DeciderHeaders) ||
+		!reflect.DeepEqual(current.DeciderRules, updated.DeciderRules) ||
+		current.DeciderRulesFile != updated.DeciderRulesFile ||
+		current.DeciderRulesDefaultAction != updated.DeciderRulesDefaultAction
+}
@@ -0,0 +1,296 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/relayer/config"
+	relayerEVM "github.com/ryt-io/icm-services/vms/evm"
+	"github.com/ryt-io/libevm/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// runState runs the state subcommand provided as the first argument after the state command, and exits.
+// The subcommands read and write the relayer's database directly, so they should not be run while a relayer
+// using the same database is running. Logs are written to stderr so that the output can be redirected.
+func runState() {
+	logger := logging.NewLogger(
+		"icm-relayer",
+		logging.NewWrappedCore(
+			logging.Info,
+			os.Stderr,
+			logging.JSON.ConsoleEncoder(),
+		),
+	)
+
+	if len(os.Args) < 3 {
+		config.DisplayUsageText()
+		os.Exit(1)
+	}
+	command := os.Args[2]
+	fs, err := config.BuildStateFlagSet(command)
+	if err != nil {
+		config.DisplayUsageText()
+		logger.Fatal("couldn't parse command", zap.Error(err))
+		os.Exit(1)
+	}
+	fs, err = parseFlags(fs, os.Args[3:])
+	if err != nil {
+		logger.Fatal("couldn't parse flags", zap.Error(err))
+		os.Exit(1)
+	}
+	cfg, err := loadConfig(fs)
+	if err != nil {
+		logger.Fatal("couldn't build config", zap.Error(err))
+		os.Exit(1)
+	}
+	stateCfg, err := config.NewStateConfig(command, fs)
+	if err != nil {
+		logger.Fatal("couldn't build state config", zap.Error(err))
+		os.Exit(1)
+	}
+
+	logLevel, err := logging.ToLevel(cfg.LogLevel)
+	if err != nil {
+		logger.Error("error reading log level from config", zap.Error(err))
+		os.Exit(1)
+	}
+	logger.SetLevel(logLevel)
+
+	db, err := database.NewDatabase(logger, cfg)
+	if err != nil {
+		logger.Fatal("Failed to create database", zap.Error(err))
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	relayerIDs := database.GetConfigRelayerIDs(cfg)
+	switch stateCfg.Command {
+	case config.StateListCommand:
+		err = listState(db, relayerIDs)
+	case config.StateSetCheckpointCommand:
+		getHeight := func(database.RelayerID) (uint64, error) {
+			return stateCfg.Height, nil
+		}
+		err = setCheckpoints(logger, db, relayerIDs, stateCfg.RelayerIDs, getHeight)
+	case config.StateResetCheckpointCommand:
+		getHeight := func(relayerID database.RelayerID) (uint64, error) {
+			return getUncheckpointedStartingHeight(logger, cfg, relayerID)
+		}
+		err = setCheckpoints(logger, db, relayerIDs, stateCfg.RelayerIDs, getHeight)
+	case config.StateExportCommand:
+		err = exportState(db, relayerIDs, stateCfg.File)
+	case config.StateImportCommand:
+		err = importState(logger, db, relayerIDs, stateCfg.File)
+	case config.StateMigrateCommand:
+		// The state of the destination clients, such as their spend budgets, is migrated along with the routes
+		relayerIDs = append(relayerIDs, database.GetConfigDestinationStateIDs(cfg)...)
+		err = migrateState(logger, cfg, db, relayerIDs, stateCfg)
+	}
+	if err != nil {
+		logger.Fatal("Failed to run state command", zap.String("command", stateCfg.Command), zap.Error(err))
+		os.Exit(1)
+	}
+}
+
+// listState writes a table of the configured relayer IDs, their routes and their checkpoints to stdout
+func listState(db database.RelayerDatabase, relayerIDs []database.RelayerID) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(
+		w,
+		"RELAYER ID\tSOURCE BLOCKCHAIN ID\tDESTINATION BLOCKCHAIN ID\tORIGIN SENDER ADDRESS\t"+
+			"DESTINATION ADDRESS\tLATEST PROCESSED BLOCK",
+	)
+	for _, relayerID := range relayerIDs {
+		latestProcessedBlock := "-"
+		height, err := database.GetLatestProcessedBlockHeight(db, relayerID)
+		if err == nil {
+			latestProcessedBlock = strconv.FormatUint(height, 10)
+		} else if !database.IsKeyNotFoundError(err) {
+			return fmt.Errorf("failed to get latest processed block of relayer id %s: %w", relayerID.ID, err)
+		}
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\t%s\n",
+			relayerID.ID,
+			relayerID.SourceBlockchainID,
+			relayerID.DestinationBlockchainID,
+			relayerID.OriginSenderAddress,
+			relayerID.DestinationAddress,
+			latestProcessedBlock,
+		)
+	}
+	return w.Flush()
+}
+
+// setCheckpoints sets the latest processed block of each of [ids] to the height returned by [getHeight].
+// Each of [ids] must be one of the configured [relayerIDs].
+func setCheckpoints(
+	logger logging.Logger,
+	db database.RelayerDatabase,
+	relayerIDs []database.RelayerID,
+	ids []common.Hash,
+	getHeight func(database.RelayerID) (uint64, error),
+) error {
+	configured := make(map[common.Hash]database.RelayerID, len(relayerIDs))
+	for _, relayerID := range relayerIDs {
+		configured[relayerID.ID] = relayerID
+	}
+	for _, id := range ids {
+		if _, ok := configured[id]; !ok {
+			return fmt.Errorf("%w: %s", database.ErrUnknownRelayerID, id)
+		}
+	}
+	for _, id := range ids {
+		relayerID := configured[id]
+		height, err := getHeight(relayerID)
+		if err != nil {
+			return err
+		}
+		if err := database.SetLatestProcessedBlockHeight(db, relayerID, height); err != nil {
+			return fmt.Errorf("failed to set latest processed block of relayer id %s: %w", id, err)
+		}
+		logger.Info(
+			"Set latest processed block",
+			zap.Stringer("relayerID", id),
+			zap.Uint64("latestProcessedBlock", height),
+		)
+	}
+	return nil
+}
+
+// getUncheckpointedStartingHeight returns the height that [relayerID] would start processing from if it had no
+// checkpoint. That is the source blockchain's process-historical-blocks-from-height if set, and otherwise its
+// current height.
+func getUncheckpointedStartingHeight(
+	logger logging.Logger,
+	cfg *config.Config,
+	relayerID database.RelayerID,
+) (uint64, error) {
+	for _, sourceBlockchain := range cfg.SourceBlockchains {
+		if sourceBlockchain.GetBlockchainID() != relayerID.SourceBlockchainID {
+			continue
+		}
+		if sourceBlockchain.ProcessHistoricalBlocksFromHeight != 0 {
+			return sourceBlockchain.ProcessHistoricalBlocksFromHeight, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		client, _, err := createSourceClient(
+			ctx,
+			logger,
+			relayerEVM.NewEndpointMetrics(prometheus.NewRegistry()),
+			sourceBlockchain,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create source client: %w", err)
+		}
+		defer client.Close()
+		height, err := client.BlockNumber(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get current block height: %w", err)
+		}
+		return height, nil
+	}
+	return 0, fmt.Errorf("source blockchain %s is not configured", relayerID.SourceBlockchainID)
+}
+
+// exportState writes the state of the configured relayer IDs as JSON to [file], or to stdout if [file] is empty
+func exportState(db database.RelayerDatabase, relayerIDs []database.RelayerID, file string) error {
+	states, err := database.ExportState(db, relayerIDs)
+	if err != nil {
+		return err
+	}
+	stateBytes, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	stateBytes = append(stateBytes, '\n')
+	if file == "" {
+		_, err = os.Stdout.Write(stateBytes)
+		return err
+	}
+	return os.WriteFile(file, stateBytes, 0600)
+}
+
+// importState writes the state exported to [file] to the database
+func importState(
+	logger logging.Logger,
+	db database.RelayerDatabase,
+	relayerIDs []database.RelayerID,
+	file string,
+) error {
+	stateBytes, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read state file: %w", err)
+	}
+	var states []database.RelayerState
+	if err := json.Unmarshal(stateBytes, &states); err != nil {
+		return fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+	if err := database.ImportState(db, relayerIDs, states); err != nil {
+		return err
+	}
+	logger.Info("Imported state", zap.Int("numRelayerIDs", len(states)))
+	return nil
+}
+
+// migrateState copies the state of the configured relayer IDs from the configured database to the database at the
+// target storage of [stateCfg]
+func migrateState(
+	logger logging.Logger,
+	cfg *config.Config,
+	db database.RelayerDatabase,
+	relayerIDs []database.RelayerID,
+	stateCfg *config.StateConfig,
+) error {
+	sameStorage := stateCfg.TargetRedisURL == cfg.RedisURL &&
+		(cfg.RedisURL != "" ||
+			(filepath.Clean(stateCfg.TargetStorageLocation) == filepath.Clean(cfg.StorageLocation) &&
+				stateCfg.TargetStorageType == storageType(cfg)))
+	if sameStorage {
+		return errors.New("the target storage is the configured storage")
+	}
+	target, err := database.NewDatabaseWithStorage(
+		logger,
+		cfg,
+		stateCfg.TargetStorageLocation,
+		stateCfg.TargetStorageType,
+		stateCfg.TargetRedisURL,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create target database: %w", err)
+	}
+	defer target.Close()
+
+	copied, err := database.Migrate(db, target, relayerIDs)
+	if err != nil {
+		return err
+	}
+	logger.Info(
+		"Migrated state",
+		zap.Int("numRelayerIDs", len(relayerIDs)),
+		zap.Int("numValues", copied),
+	)
+	return nil
+}
+
+// storageType returns the storage type of the configured storage location, which defaults to JSON files
+func storageType(cfg *config.Config) string {
+	if cfg.StorageType == "" {
+		return config.JSONStorageType
+	}
+	return cfg.StorageType
+}
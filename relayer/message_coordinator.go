@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ava-labs/avalanchego/graft/subnet-evm/precompile/contracts/warp"
 	"github.com/ryt-io/ryt-v2/ids"
@@ -29,21 +31,33 @@ type MessageCoordinator struct {
 	logger logging.Logger
 	// Maps Source blockchain ID and protocol address to a Message Handler Factory
 	messageHandlerFactories map[ids.ID]map[common.Address]messages.MessageHandlerFactory
-	applicationRelayers     map[common.Hash]*ApplicationRelayer
-	sourceClients           map[ids.ID]*ethclient.Client
+	// Counts the messages being processed by handlers created by messageHandlerFactories. Replaced together with
+	// the factories, so that the resources of the replaced factories can be released once their handlers are done.
+	handlersInUse       *sync.WaitGroup
+	applicationRelayers map[common.Hash]*ApplicationRelayer
+	sourceClients       map[ids.ID]*ethclient.Client
+	deadLetterQueue     *database.DeadLetterQueue // nil if the dead-letter queue is disabled
+
+	// Guards the routing maps above, which are replaced when the configuration is reloaded
+	lock sync.RWMutex
 }
 
+var errDeadLetterQueueDisabled = errors.New("dead-letter queue is not enabled")
+
 func NewMessageCoordinator(
 	logger logging.Logger,
 	messageHandlerFactories map[ids.ID]map[common.Address]messages.MessageHandlerFactory,
 	applicationRelayers map[common.Hash]*ApplicationRelayer,
 	sourceClients map[ids.ID]*ethclient.Client,
+	deadLetterQueue *database.DeadLetterQueue,
 ) *MessageCoordinator {
 	return &MessageCoordinator{
 		logger:                  logger,
 		messageHandlerFactories: messageHandlerFactories,
+		handlersInUse:           &sync.WaitGroup{},
 		applicationRelayers:     applicationRelayers,
 		sourceClients:           sourceClients,
+		deadLetterQueue:         deadLetterQueue,
 	}
 }
 
@@ -51,6 +65,8 @@ func NewMessageCoordinator(
 // as well as a one-time MessageHandler instance that the ApplicationRelayer uses to relay this specific message.
 // The MessageHandler and ApplicationRelayer are decoupled to support batch workflows in which a single
 // ApplicationRelayer processes multiple messages (using their corresponding MessageHandlers) in a single shot.
+// Must be called with the lock held, which must not be released before the processing of the message is
+// registered with handlersInUse and the ApplicationRelayer's inFlight.
 func (mc *MessageCoordinator) getAppRelayerMessageHandler(
 	warpMessageInfo *relayerTypes.WarpMessageInfo,
 ) (
@@ -98,6 +114,7 @@ func (mc *MessageCoordinator) getAppRelayerMessageHandler(
 	messageHandler, err := messageHandlerFactory.NewMessageHandler(
 		mc.logger,
 		warpMessageInfo.UnsignedMessage,
+		mc.sourceClients[routeInfo.SourceChainID],
 		appRelayer.destinationClient,
 	)
 	if err != nil {
@@ -108,6 +125,7 @@ func (mc *MessageCoordinator) getAppRelayerMessageHandler(
 }
 
 // Unpacks the Warp message and fetches the appropriate application relayer
+// Must be called with the lock held.
 // Checks for the following registered keys. At most one of these keys should be registered.
 // 1. An exact match on sourceBlockchainID, destinationBlockchainID, originSenderAddress, and destinationAddress
 // 2. A match on sourceBlockchainID and destinationBlockchainID, with a specific originSenderAddress and
@@ -179,7 +197,7 @@ func (mc *MessageCoordinator) getApplicationRelayer(
 }
 
 func (mc *MessageCoordinator) ProcessWarpMessage(warpMessage *relayerTypes.WarpMessageInfo) (common.Hash, error) {
-	appRelayer, handler, err := mc.getAppRelayerMessageHandler(warpMessage)
+	appRelayer, handler, handlersInUse, err := mc.trackWarpMessage(warpMessage)
 	if err != nil {
 		mc.logger.Error(
 			"Failed to parse Warp message.",
@@ -192,16 +210,40 @@ func (mc *MessageCoordinator) ProcessWarpMessage(warpMessage *relayerTypes.WarpM
 		mc.logger.Error("Application relayer not found")
 		return common.Hash{}, errors.New("application relayer not found")
 	}
+	defer func() {
+		appRelayer.inFlight.Done()
+		handlersInUse.Done()
+	}()
 
 	return appRelayer.ProcessMessage(handler)
 }
 
+// trackWarpMessage creates the message handler of a Warp message, and registers the message as being processed
+// by its application relayer and by a handler of the current message handler factories. The caller must mark
+// both as done once the message is processed. Returns a nil application relayer if the message is not routed.
+func (mc *MessageCoordinator) trackWarpMessage(
+	warpMessage *relayerTypes.WarpMessageInfo,
+) (*ApplicationRelayer, messages.MessageHandler, *sync.WaitGroup, error) {
+	mc.lock.RLock()
+	defer mc.lock.RUnlock()
+
+	appRelayer, handler, err := mc.getAppRelayerMessageHandler(warpMessage)
+	if err != nil || appRelayer == nil {
+		return nil, nil, nil, err
+	}
+	appRelayer.inFlight.Add(1)
+	mc.handlersInUse.Add(1)
+	return appRelayer, handler, mc.handlersInUse, nil
+}
+
 func (mc *MessageCoordinator) ProcessMessageID(
 	blockchainID ids.ID,
 	messageID ids.ID,
 	blockNum *big.Int,
 ) (common.Hash, error) {
+	mc.lock.RLock()
 	ethClient, ok := mc.sourceClients[blockchainID]
+	mc.lock.RUnlock()
 	if !ok {
 		mc.logger.Error(
 			"Source client not found",
@@ -223,6 +265,79 @@ func (mc *MessageCoordinator) ProcessMessageID(
 	return mc.ProcessWarpMessage(warpMessage)
 }
 
+// DeadLetters returns the dead letters of all application relayers.
+func (mc *MessageCoordinator) DeadLetters() ([]database.DeadLetter, error) {
+	if mc.deadLetterQueue == nil {
+		return nil, errDeadLetterQueueDisabled
+	}
+	mc.lock.RLock()
+	defer mc.lock.RUnlock()
+
+	deadLetters := []database.DeadLetter{}
+	for relayerID := range mc.applicationRelayers {
+		relayerDeadLetters, err := mc.deadLetterQueue.List(relayerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dead letters for relayer %s: %w", relayerID.Hex(), err)
+		}
+		deadLetters = append(deadLetters, relayerDeadLetters...)
+	}
+	return deadLetters, nil
+}
+
+// RetryDeadLetter relays a message from the dead-letter queue. The message is removed from the queue
+// if it is successfully relayed, otherwise its queue entry is updated with the latest error.
+func (mc *MessageCoordinator) RetryDeadLetter(relayerID common.Hash, warpMessageID ids.ID) (common.Hash, error) {
+	if mc.deadLetterQueue == nil {
+		return common.Hash{}, errDeadLetterQueueDisabled
+	}
+	deadLetter, err := mc.deadLetterQueue.Get(relayerID, warpMessageID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	unsignedMessage, err := relayerTypes.UnpackWarpMessage(deadLetter.UnsignedMessageBytes)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to unpack dead letter: %w", err)
+	}
+
+	txHash, err := mc.ProcessWarpMessage(&relayerTypes.WarpMessageInfo{
+		SourceAddress:   deadLetter.SourceAddress,
+		UnsignedMessage: unsignedMessage,
+	})
+	if err != nil {
+		deadLetter.LastError = err.Error()
+		deadLetter.Attempts = maxRetryCount
+		deadLetter.LastFailedAt = time.Now().UTC()
+		if addErr := mc.deadLetterQueue.Add(deadLetter); addErr != nil {
+			mc.logger.Error(
+				"Failed to update dead letter",
+				zap.Stringer("relayerID", relayerID),
+				zap.Stringer("warpMessageID", warpMessageID),
+				zap.Error(addErr),
+			)
+		}
+		return common.Hash{}, err
+	}
+
+	if err := mc.deadLetterQueue.Remove(relayerID, warpMessageID); err != nil {
+		// The message has already been delivered, so a subsequent retry is a no-op.
+		mc.logger.Error(
+			"Failed to remove relayed message from dead-letter queue",
+			zap.Stringer("relayerID", relayerID),
+			zap.Stringer("warpMessageID", warpMessageID),
+			zap.Error(err),
+		)
+	}
+	return txHash, nil
+}
+
+// DiscardDeadLetter removes a message from the dead-letter queue without relaying it.
+func (mc *MessageCoordinator) DiscardDeadLetter(relayerID common.Hash, warpMessageID ids.ID) error {
+	if mc.deadLetterQueue == nil {
+		return errDeadLetterQueueDisabled
+	}
+	return mc.deadLetterQueue.Remove(relayerID, warpMessageID)
+}
+
 // Meant to be ran asynchronously. Errors should be sent to errChan.
 func (mc *MessageCoordinator) ProcessBlock(
 	icmBlockInfo *relayerTypes.WarpBlockInfo,
@@ -235,6 +350,11 @@ func (mc *MessageCoordinator) ProcessBlock(
 		zap.Stringer("blockchainID", blockchainID),
 	)
 
+	// The lock is held until the messages are dispatched, so that they are processed by the application relayers
+	// and with the message handler factories that are current when their handlers are created
+	mc.lock.RLock()
+	defer mc.lock.RUnlock()
+
 	// Register each message in the block with the appropriate application relayer
 	messageHandlers := make(map[common.Hash][]messages.MessageHandler)
 	for _, warpLogInfo := range icmBlockInfo.Messages {
@@ -269,6 +389,7 @@ func (mc *MessageCoordinator) ProcessBlock(
 		messageHandlers[appRelayer.relayerID.ID] = append(messageHandlers[appRelayer.relayerID.ID], handler)
 	}
 	// Initiate message relay of all registered messages
+	handlersInUse := mc.handlersInUse
 	for _, appRelayer := range mc.applicationRelayers {
 		if appRelayer.sourceBlockchain.GetBlockchainID() != blockchainID {
 			continue
@@ -281,8 +402,81 @@ func (mc *MessageCoordinator) ProcessBlock(
 			zap.Stringer("relayerID", appRelayer.relayerID.ID),
 			zap.Int("numMessages", len(handlers)),
 		)
-		go appRelayer.ProcessHeight(icmBlockInfo.BlockNumber, handlers, errChan)
+		appRelayer.inFlight.Add(1)
+		handlersInUse.Add(1)
+		go func() {
+			defer func() {
+				appRelayer.inFlight.Done()
+				handlersInUse.Done()
+			}()
+			appRelayer.ProcessHeight(icmBlockInfo.BlockNumber, handlers, errChan)
+		}()
+	}
+}
+
+// SetMessageHandlerFactories replaces the message handler factories of all source blockchains. The returned
+// channel is closed once the messages being processed by handlers created by the replaced factories are done,
+// after which the resources that the replaced factories share with their handlers can be released.
+func (mc *MessageCoordinator) SetMessageHandlerFactories(
+	messageHandlerFactories map[ids.ID]map[common.Address]messages.MessageHandlerFactory,
+) <-chan struct{} {
+	mc.lock.Lock()
+	replacedHandlersInUse := mc.handlersInUse
+	mc.messageHandlerFactories = messageHandlerFactories
+	mc.handlersInUse = &sync.WaitGroup{}
+	mc.lock.Unlock()
+
+	replacedHandlersDone := make(chan struct{})
+	go func() {
+		defer close(replacedHandlersDone)
+		replacedHandlersInUse.Wait()
+	}()
+	return replacedHandlersDone
+}
+
+// SetSourceBlockchain replaces the source client and the application relayers of a source blockchain.
+// Application relayers previously registered for the source blockchain are no longer dispatched to.
+func (mc *MessageCoordinator) SetSourceBlockchain(
+	blockchainID ids.ID,
+	sourceClient *ethclient.Client,
+	applicationRelayers map[common.Hash]*ApplicationRelayer,
+) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+
+	mc.removeApplicationRelayers(blockchainID)
+	for relayerID, applicationRelayer := range applicationRelayers {
+		mc.applicationRelayers[relayerID] = applicationRelayer
+	}
+	mc.sourceClients[blockchainID] = sourceClient
+}
+
+// RemoveSourceBlockchain stops routing messages from a source blockchain, and waits for the messages that its
+// application relayers are processing, so that their destination clients can be safely replaced.
+func (mc *MessageCoordinator) RemoveSourceBlockchain(blockchainID ids.ID) {
+	mc.lock.Lock()
+	removed := mc.removeApplicationRelayers(blockchainID)
+	delete(mc.sourceClients, blockchainID)
+	delete(mc.messageHandlerFactories, blockchainID)
+	mc.lock.Unlock()
+
+	for _, applicationRelayer := range removed {
+		applicationRelayer.inFlight.Wait()
+	}
+}
+
+// removeApplicationRelayers removes, stops and returns the application relayers of a source blockchain.
+// Must be called with the lock held.
+func (mc *MessageCoordinator) removeApplicationRelayers(sourceBlockchainID ids.ID) []*ApplicationRelayer {
+	var removed []*ApplicationRelayer
+	for relayerID, applicationRelayer := range mc.applicationRelayers {
+		if applicationRelayer.sourceBlockchain.GetBlockchainID() == sourceBlockchainID {
+			delete(mc.applicationRelayers, relayerID)
+			applicationRelayer.stop()
+			removed = append(removed, applicationRelayer)
+		}
 	}
+	return removed
 }
 
 func FetchWarpMessage(
@@ -34,6 +34,20 @@ func (t *Ticker) Subscribe() chan struct{} {
 	return sub
 }
 
+// Unsubscribe removes the subscription and closes its channel.
+func (t *Ticker) Unsubscribe(sub chan struct{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for i, s := range t.subscriptions {
+		if s == sub {
+			t.subscriptions = append(t.subscriptions[:i], t.subscriptions[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
 func (t *Ticker) Run(ctx context.Context) {
 	ticker := time.NewTicker(t.interval)
 	for {
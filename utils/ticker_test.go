@@ -0,0 +1,37 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package utils
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTickerUnsubscribe(t *testing.T) {
+	ticker := &Ticker{
+		interval: time.Millisecond,
+		lock:     &sync.Mutex{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub1 := ticker.Subscribe()
+	sub2 := ticker.Subscribe()
+	go ticker.Run(ctx)
+
+	<-sub1
+	<-sub2
+
+	// The unsubscribed channel is closed, and no longer blocks the ticker.
+	ticker.Unsubscribe(sub1)
+	_, ok := <-sub1
+	require.False(t, ok)
+	<-sub2
+	<-sub2
+	require.Len(t, ticker.subscriptions, 1)
+}
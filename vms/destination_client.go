@@ -14,6 +14,7 @@ import (
 	"github.com/ryt-io/ryt-v2/utils/logging"
 	"github.com/ryt-io/ryt-v2/utils/set"
 	"github.com/ryt-io/ryt-v2/vms/platformvm/warp"
+	"github.com/ryt-io/icm-services/database"
 	"github.com/ryt-io/icm-services/peers/clients"
 	"github.com/ryt-io/icm-services/relayer/config"
 	"github.com/ryt-io/icm-services/vms/evm"
@@ -27,7 +28,8 @@ import (
 // concurrently by the application relayers.
 type DestinationClient interface {
 	// SendTx constructs the transaction from warp primitives, and sends to the configured destination chain endpoint.
-	// Returns the hash of the sent transaction.
+	// Returns the hash of the sent transaction. [onSent], if non-nil, is called with the hash of the transaction
+	// once it is sent, before its receipt is awaited, and must not block.
 	// TODO: Make generic for any VM.
 	SendTx(
 		signedMessage *warp.Message,
@@ -35,8 +37,20 @@ type DestinationClient interface {
 		toAddress string,
 		gasLimit uint64,
 		callData []byte,
+		onSent func(txHash common.Hash),
 	) (*types.Receipt, error)
 
+	// SimulateTx calls the transaction that SendTx would send with the same arguments against the latest state
+	// of the destination chain, without signing or sending it. The returned error wraps evm.ErrSimulationReverted
+	// if the transaction would revert.
+	SimulateTx(
+		signedMessage *warp.Message,
+		deliverers set.Set[common.Address],
+		toAddress string,
+		gasLimit uint64,
+		callData []byte,
+	) error
+
 	// Client returns the underlying client for the destination chain
 	Client() evm.Client
 
@@ -57,12 +71,46 @@ type DestinationClient interface {
 	GetPChainHeightForDestination(
 		ctx context.Context,
 	) (uint64, error)
+
+	// Health returns an error if the relayer's accounts on the destination chain can not currently issue transactions
+	Health() error
+
+	// Close stops the background tasks of the client. Transactions that are in flight are not affected.
+	Close()
 }
 
-// CreateDestinationClients creates destination clients for all subnets configured as destinations
+// DryRunRecorder is implemented by destination clients that can record the transactions that SendTx would send
+// instead of sending them, when the relayer is in dry-run mode
+type DryRunRecorder interface {
+	// DryRunTransaction returns the unsigned transaction recorded by the SendTx call that returned a receipt with
+	// [txHash], along with the address of the signer that would have sent it. Returns false if no transaction
+	// with [txHash] was recorded.
+	DryRunTransaction(txHash common.Hash) (*types.Transaction, common.Address, bool)
+}
+
+// CreateDestinationClients creates destination clients for all subnets configured as destinations.
+// The state of the clients is stored in [db], or only kept in memory if [db] is nil.
 func CreateDestinationClients(
 	logger logging.Logger,
 	relayerConfig *config.Config,
+	destinationClientMetrics *evm.DestinationClientMetrics,
+	db database.RelayerDatabase,
+) (map[ids.ID]DestinationClient, error) {
+	blockchainIDs := set.NewSet[ids.ID](len(relayerConfig.DestinationBlockchains))
+	for _, subnetInfo := range relayerConfig.DestinationBlockchains {
+		blockchainIDs.Add(subnetInfo.GetBlockchainID())
+	}
+	return CreateDestinationClientsForBlockchains(logger, relayerConfig, blockchainIDs, destinationClientMetrics, db)
+}
+
+// CreateDestinationClientsForBlockchains creates destination clients for the given subset of the
+// configured destination blockchains
+func CreateDestinationClientsForBlockchains(
+	logger logging.Logger,
+	relayerConfig *config.Config,
+	blockchainIDs set.Set[ids.ID],
+	destinationClientMetrics *evm.DestinationClientMetrics,
+	db database.RelayerDatabase,
 ) (map[ids.ID]DestinationClient, error) {
 	// Fetch epoch duration once since it's global across all blockchains
 	var epochDuration time.Duration
@@ -92,12 +140,30 @@ func CreateDestinationClients(
 			log.Error("Failed to decode base-58 encoded source chain ID", zap.Error(err))
 			return nil, err
 		}
+		if !blockchainIDs.Contains(blockchainID) {
+			continue
+		}
 		if _, ok := destinationClients[blockchainID]; ok {
 			log.Info("Destination client already found for blockchainID. Continuing")
 			continue
 		}
 
-		destinationClient, err := evm.NewDestinationClient(log, subnetInfo, epochDuration)
+		if db != nil {
+			stateIDs := []database.RelayerID{database.NewDestinationStateID(blockchainID)}
+			if err := database.AddRelayerIDs(db, stateIDs); err != nil {
+				log.Error("Failed to add destination state ID to database", zap.Error(err))
+				return nil, err
+			}
+		}
+		destinationClient, err := evm.NewDestinationClient(
+			log,
+			subnetInfo,
+			epochDuration,
+			relayerConfig.DryRun,
+			destinationClientMetrics,
+			db,
+			time.Duration(relayerConfig.DBWriteIntervalSeconds)*time.Second,
+		)
 		if err != nil {
 			log.Error("Could not create destination client", zap.Error(err))
 			return nil, err
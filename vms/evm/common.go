@@ -10,16 +10,23 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/logging"
 	"github.com/ryt-io/ryt-v2/utils/set"
 	avalancheWarp "github.com/ryt-io/ryt-v2/vms/platformvm/warp"
 	"github.com/ryt-io/icm-services/utils"
 	"github.com/ryt-io/icm-services/vms/evm/signer"
 	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/accounts/abi"
 	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/common/hexutil"
 	"github.com/ryt-io/libevm/core/types"
+	"github.com/ryt-io/libevm/core/vm"
+	"github.com/ryt-io/libevm/rpc"
 	"go.uber.org/zap"
 )
 
@@ -35,6 +42,8 @@ type CommonDestinationClient interface {
 	ConcurrentSigners() []*readonlyConcurrentSigner
 	AccessList(data txData) types.AccessList
 	TxInclusionTimeout() time.Duration
+	// TxLimiter returns the limiter of the transaction rate and spend, or nil if transactions are not limited
+	TxLimiter() *txLimiter
 }
 
 // DestionationRPCClient interface represents the minimal interface needed for querying RPC endpoints.
@@ -59,6 +68,8 @@ type txData struct {
 	callData      []byte
 	signedMessage *avalancheWarp.Message
 	resultChan    chan txResult
+	// Called with the hash of the transaction once it is sent. May be nil.
+	onSent func(txHash common.Hash)
 }
 
 type txResult struct {
@@ -71,34 +82,97 @@ type GasFeeConfig struct {
 	maxBaseFee                 *big.Int
 	suggestedPriorityFeeBuffer *big.Int
 	maxPriorityFeePerGas       *big.Int
+
+	// Transactions that are not included within txReplacementDelay are replaced with higher fees,
+	// up to the maximum replacement fees. Replacement is disabled if txReplacementDelay is zero.
+	txReplacementDelay              time.Duration
+	txReplacementFeeBumpPercent     uint64
+	maxReplacementFeePerGas         *big.Int
+	maxReplacementPriorityFeePerGas *big.Int
+}
+
+func (g *GasFeeConfig) replacementEnabled() bool {
+	return g.txReplacementDelay > 0
+}
+
+// maxGasFeeCap returns the highest gas fee cap that a transaction sent with [gasFeeCap] may be replaced with
+func (g *GasFeeConfig) maxGasFeeCap(gasFeeCap *big.Int) *big.Int {
+	if g.replacementEnabled() && g.maxReplacementFeePerGas.Cmp(gasFeeCap) > 0 {
+		return g.maxReplacementFeePerGas
+	}
+	return gasFeeCap
+}
+
+// bumpFees returns the gas fee cap and gas tip cap of a transaction replacing one sent with [gasFeeCap] and
+// [gasTipCap]. Returns false if either fee can not be increased by the fee bump without exceeding its maximum,
+// in which case the mempool would reject the replacement.
+func (g *GasFeeConfig) bumpFees(gasFeeCap *big.Int, gasTipCap *big.Int) (*big.Int, *big.Int, bool) {
+	bump := func(fee *big.Int) *big.Int {
+		bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+g.txReplacementFeeBumpPercent))
+		return bumped.Div(bumped, big.NewInt(100))
+	}
+	bumpedGasFeeCap := bump(gasFeeCap)
+	bumpedGasTipCap := bump(gasTipCap)
+	if bumpedGasFeeCap.Cmp(g.maxReplacementFeePerGas) > 0 ||
+		bumpedGasTipCap.Cmp(g.maxReplacementPriorityFeePerGas) > 0 ||
+		bumpedGasTipCap.Cmp(bumpedGasFeeCap) > 0 {
+		return nil, nil, false
+	}
+	return bumpedGasFeeCap, bumpedGasTipCap, true
 }
 
 // Type alias for the destinationClient to have access to the fields but not the methods of the concurrentSigner.
 type readonlyConcurrentSigner concurrentSigner
 
 type concurrentSigner struct {
-	logger       logging.Logger
-	signer       signer.Signer
-	currentNonce uint64
+	logger logging.Logger
+	signer signer.Signer
 	// Unbuffered channel to receive messages to be processed
 	messageChan chan txData
 	// Semaphore to limit the number of transactions in the mempool for
 	// each account, otherwise they may be dropped.
 	queuedTxSemaphore chan struct{}
 	destinationClient CommonDestinationClient
+	// Closed to stop processIncomingTransactions. May be nil if the signer is never stopped.
+	stop chan struct{}
+
+	// Used to report the nonce metrics. metrics may be nil.
+	destinationBlockchainID ids.ID
+	metrics                 *DestinationClientMetrics
+
+	// Guards the fields below, since the nonce is reconciled with the destination chain
+	// concurrently with issuing transactions
+	nonceLock    sync.Mutex
+	currentNonce uint64
+	// The latest transaction sent with each nonce whose receipt is awaited
+	sentTxs map[uint64]*types.Transaction
+	// The start of the nonce gap observed by the previous reconciliation, if any
+	nonceGapStart *uint64
+	// Set while a nonce gap persists
+	nonceErr error
 }
 
 // processIncomingTransactions is a worker that issues transactions from a given concurrentSigner.
-// Must be called at most once per concurrentSigner.
+// Must be called at most once per concurrentSigner, and returns once s.stop is closed.
 // It guarantees that for any messageData read from s.messageChan,
 // exactly 1 value is written to messageData.resultChan.
 func (s *concurrentSigner) processIncomingTransactions() {
 	for {
 		// We can only get to listen to messageChan if there is an open queued tx slot
-		s.queuedTxSemaphore <- struct{}{}
+		select {
+		case s.queuedTxSemaphore <- struct{}{}:
+		case <-s.stop:
+			return
+		}
 		s.logger.Debug("Waiting for incoming transaction")
 
-		messageData := <-s.messageChan
+		var messageData txData
+		select {
+		case messageData = <-s.messageChan:
+		case <-s.stop:
+			<-s.queuedTxSemaphore
+			return
+		}
 
 		err := s.issueTransaction(messageData)
 		if err != nil {
@@ -125,6 +199,9 @@ func (s *concurrentSigner) processIncomingTransactions() {
 func (s *concurrentSigner) issueTransaction(
 	data txData,
 ) error {
+	s.nonceLock.Lock()
+	defer s.nonceLock.Unlock()
+
 	s.logger.Debug(
 		"Processing transaction",
 		zap.Stringer("to", data.to),
@@ -178,46 +255,81 @@ func (s *concurrentSigner) issueTransaction(
 	log.Info("Sent transaction")
 
 	s.currentNonce++
+	s.trackSentTx(signedTx)
+	if data.onSent != nil {
+		data.onSent(signedTx.Hash())
+	}
 
 	// We wait for the transaction receipt asynchronously because the transaction has already
 	// been accepted by the mempool, so we can send another transaction using the same key
 	// while we wait for the receipt of the previous transaction.
-	go s.waitForReceipt(signedTx.Hash(), data.resultChan)
+	go s.waitForReceipt(signedTx, data.resultChan)
 
 	return nil
 }
 
 // waitForReceipt always writes to the result channel,
 // always closes the result channel,
-// may be called concurrently on a given concurrentSigner instance
+// may be called concurrently on a given concurrentSigner instance.
+// If transaction replacement is enabled, [tx] is replaced with higher fees each time it is not included
+// within the replacement delay, and the receipt of whichever of the transactions is included is returned.
 func (s *concurrentSigner) waitForReceipt(
-	txHash common.Hash,
+	tx *types.Transaction,
 	resultChan chan<- txResult,
 ) {
 	defer close(resultChan)
 
-	var receipt *types.Receipt
+	gasFeeConfig := s.destinationClient.GasFeeConfig()
+	// The hashes of the transaction and its replacements, only one of which can be included
+	txHashes := []common.Hash{tx.Hash()}
+	replaceable := gasFeeConfig.replacementEnabled()
+	lastSent := time.Now()
+
+	var (
+		receipt        *types.Receipt
+		includedTxHash common.Hash
+	)
 	operation := func() (err error) {
-		callCtx, callCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
-		defer callCtxCancel()
-		receipt, err = s.destinationClient.RPCClient().TransactionReceipt(callCtx, txHash)
+		for _, txHash := range txHashes {
+			callCtx, callCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+			receipt, err = s.destinationClient.RPCClient().TransactionReceipt(callCtx, txHash)
+			callCtxCancel()
+			if err == nil {
+				includedTxHash = txHash
+				return nil
+			}
+		}
+		if replaceable && time.Since(lastSent) >= gasFeeConfig.txReplacementDelay {
+			replacement, ok := s.replaceTransaction(tx)
+			if !ok {
+				replaceable = false
+			} else if replacement != nil {
+				tx = replacement
+				txHashes = append(txHashes, tx.Hash())
+				s.nonceLock.Lock()
+				s.trackSentTx(tx)
+				s.nonceLock.Unlock()
+			}
+			lastSent = time.Now()
+		}
 		return err
 	}
 	notify := func(err error, duration time.Duration) {
 		s.logger.Info(
 			"waiting for receipt failed, retrying...",
-			zap.Stringer("txID", txHash),
+			zap.Stringer("txID", tx.Hash()),
 			zap.Duration("retryIn", duration),
 			zap.Error(err),
 		)
 	}
 
 	err := utils.WithRetriesTimeout(operation, notify, s.destinationClient.TxInclusionTimeout())
+	s.untrackSentTx(tx.Nonce())
 	if err != nil {
 		resultChan <- txResult{
 			receipt: nil,
 			err:     fmt.Errorf("failed to get transaction receipt: %w", err),
-			txID:    txHash,
+			txID:    tx.Hash(),
 		}
 		return
 	}
@@ -228,10 +340,68 @@ func (s *concurrentSigner) waitForReceipt(
 	resultChan <- txResult{
 		receipt: receipt,
 		err:     nil,
-		txID:    txHash,
+		txID:    includedTxHash,
 	}
 }
 
+// replaceTransaction re-signs [tx] with the same nonce and bumped fees, and sends it.
+// Returns the replacement, or nil if it could not be sent. Returns false if the fees can not be bumped further.
+// Unlike issueTransaction, it may be called concurrently, since it does not modify the signer's nonce.
+func (s *concurrentSigner) replaceTransaction(tx *types.Transaction) (*types.Transaction, bool) {
+	log := s.logger.With(
+		zap.Stringer("txID", tx.Hash()),
+		zap.Uint64("nonce", tx.Nonce()),
+	)
+	gasFeeCap, gasTipCap, ok := s.destinationClient.GasFeeConfig().bumpFees(tx.GasFeeCap(), tx.GasTipCap())
+	if !ok {
+		log.Warn(
+			"Transaction not included, but its fees can not be increased further",
+			zap.Stringer("gasFeeCap", tx.GasFeeCap()),
+			zap.Stringer("gasTipCap", tx.GasTipCap()),
+		)
+		return nil, false
+	}
+
+	replacement := types.NewTx(&types.DynamicFeeTx{
+		ChainID:    s.destinationClient.EVMChainID(),
+		Nonce:      tx.Nonce(),
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		GasFeeCap:  gasFeeCap,
+		GasTipCap:  gasTipCap,
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	})
+	signedTx, err := s.signer.SignTx(replacement, s.destinationClient.EVMChainID())
+	if err != nil {
+		log.Error(
+			"Failed to sign replacement transaction",
+			zap.Error(err),
+		)
+		return nil, true
+	}
+
+	log = log.With(
+		zap.Stringer("replacementTxID", signedTx.Hash()),
+		zap.Stringer("gasFeeCap", gasFeeCap),
+		zap.Stringer("gasTipCap", gasTipCap),
+	)
+	log.Info("Replacing transaction that was not included")
+
+	sendTxCtx, sendTxCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	defer sendTxCtxCancel()
+	if err := s.destinationClient.RPCClient().SendTransaction(sendTxCtx, signedTx); err != nil {
+		// The transaction may have been included since its receipt was queried
+		log.Warn(
+			"Failed to send replacement transaction",
+			zap.Error(err),
+		)
+		return nil, true
+	}
+	return signedTx, true
+}
+
 // getFeePerGas returns the gas fee cap and gas tip cap for the destination chain.
 // If the maximum base fee value is not configured, the maximum base is calculated as the current base
 // fee multiplied by the default base fee factor. The maximum priority fee per gas is set the minimum
@@ -287,6 +457,35 @@ func getFeePerGas(
 	return gasFeeCap, gasTipCap, nil
 }
 
+// getFeePerGasWithinLimits returns the gas fee cap and gas tip cap for the destination chain, and reserves
+// the maximum fee of the transaction against the spend limits of the route. If a spend limit is reached,
+// a SpendLimitExhaustedError is returned, so that the caller can retry once the limit resets.
+func getFeePerGasWithinLimits(
+	c CommonDestinationClient,
+	sourceBlockchainID ids.ID,
+	gasLimit uint64,
+) (*big.Int, *big.Int, *spendReservation, error) {
+	gasFeeCap, gasTipCap, err := getFeePerGas(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	limiter := c.TxLimiter()
+	if limiter == nil {
+		return gasFeeCap, gasTipCap, nil, nil
+	}
+	// Reserve enough to cover any replacements of the transaction
+	maxGasFeeCap := c.GasFeeConfig().maxGasFeeCap(gasFeeCap)
+	maxFee := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), maxGasFeeCap)
+	reservation, err := limiter.reserve(sourceBlockchainID, maxFee)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return gasFeeCap, gasTipCap, reservation, nil
+}
+
+// SendTx sends a transaction from one of the [deliverers], or from any signer if [deliverers] is empty, and waits
+// for its receipt. [signedMessage] is the Warp message delivered by the transaction, or nil if it does not deliver
+// a message. [onSent], if non-nil, is called with the hash of the transaction once it is sent.
 func SendTx(
 	c CommonDestinationClient,
 	signedMessage *avalancheWarp.Message,
@@ -295,12 +494,26 @@ func SendTx(
 	gasLimit uint64,
 	callData []byte,
 	txInclusionTimeout time.Duration,
+	onSent func(txHash common.Hash),
 ) (*types.Receipt, error) {
 	logger := c.Logger()
-	gasFeeCap, gasTipCap, err := getFeePerGas(c)
+	// Transactions that do not deliver a message only count against the destination's spend limits
+	sourceBlockchainID := ids.Empty
+	if signedMessage != nil {
+		sourceBlockchainID = signedMessage.SourceChainID
+	}
+	// The spend is reserved before waiting for the transaction rate, so that a transaction that exceeds the spend
+	// limits does not use up the rate of the transactions that do not
+	gasFeeCap, gasTipCap, reservation, err := getFeePerGasWithinLimits(c, sourceBlockchainID, gasLimit)
 	if err != nil {
 		return nil, err
 	}
+	if limiter := c.TxLimiter(); limiter != nil {
+		if err := limiter.waitForRate(); err != nil {
+			reservation.settle(big.NewInt(0))
+			return nil, fmt.Errorf("failed to wait for the transaction rate: %w", err)
+		}
+	}
 
 	resultChan := make(chan txResult)
 	to := common.HexToAddress(toAddress)
@@ -312,9 +525,13 @@ func SendTx(
 		callData:      callData,
 		signedMessage: signedMessage,
 		resultChan:    resultChan,
+		onSent:        onSent,
 	}
 
-	var cases []reflect.SelectCase
+	var (
+		cases []reflect.SelectCase
+		stop  chan struct{}
+	)
 	for _, concurrentSigner := range c.ConcurrentSigners() {
 		signerAddress := concurrentSigner.signer.Address()
 		if deliverers.Len() != 0 && !deliverers.Contains(signerAddress) {
@@ -333,10 +550,21 @@ func SendTx(
 			Chan: reflect.ValueOf(concurrentSigner.messageChan),
 			Send: reflect.ValueOf(messageData),
 		})
+		// The signers of a client are stopped together
+		stop = concurrentSigner.stop
+	}
+	if stop != nil {
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(stop),
+		})
 	}
 
 	// Select an available, eligible signer
-	reflect.Select(cases)
+	if chosen, _, _ := reflect.Select(cases); stop != nil && chosen == len(cases)-1 {
+		reservation.settle(big.NewInt(0))
+		return nil, errSignersStopped
+	}
 
 	// Wait for the receipt or error to be returned
 	// We need to wait for the transaction inclusion, and also the receipt to be returned.
@@ -351,10 +579,15 @@ func SendTx(
 			return nil, errors.New("channel closed unexpectedly")
 		}
 	case <-timeout.C:
+		// The transaction may still be included, so the reserved spend is kept
 		return nil, errors.New("timed out waiting for transaction result")
 	}
 
 	if result.err != nil {
+		// Only release the reserved spend if the transaction was not sent
+		if result.txID == (common.Hash{}) {
+			reservation.settle(big.NewInt(0))
+		}
 		logger.Error(
 			"Transaction failed to be issued or confirmed",
 			zap.Error(result.err),
@@ -363,9 +596,89 @@ func SendTx(
 		return nil, result.err
 	}
 
+	if result.receipt.EffectiveGasPrice != nil {
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(result.receipt.GasUsed), result.receipt.EffectiveGasPrice)
+		reservation.settle(fee)
+	}
+
 	return result.receipt, nil
 }
 
+// errSignersStopped is returned by SendTx if the signers are stopped because their client was closed
+var errSignersStopped = errors.New("signers stopped")
+
+// ErrSimulationReverted is wrapped by the errors returned by SimulateTx for transactions that would revert
+var ErrSimulationReverted = errors.New("transaction simulation reverted")
+
+// SimulateTx calls the transaction that SendTx would send with the same arguments against the latest state of the
+// destination blockchain, without signing or sending it. The returned error wraps ErrSimulationReverted, along
+// with the revert reason, if the transaction would revert.
+func SimulateTx(
+	c CommonDestinationClient,
+	signedMessage *avalancheWarp.Message,
+	deliverers set.Set[common.Address],
+	toAddress string,
+	gasLimit uint64,
+	callData []byte,
+) error {
+	// The transaction is simulated from the first signer that SendTx could send it from
+	from, err := firstEligibleSender(c, deliverers)
+	if err != nil {
+		return err
+	}
+
+	to := common.HexToAddress(toAddress)
+	callCtx, callCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	defer callCtxCancel()
+	_, err = c.RPCClient().CallContract(callCtx, ethereum.CallMsg{
+		From:       from,
+		To:         &to,
+		Gas:        gasLimit,
+		Data:       callData,
+		AccessList: c.AccessList(txData{signedMessage: signedMessage}),
+	}, nil)
+	if err == nil {
+		return nil
+	}
+	if reason, ok := revertReason(err); ok {
+		return fmt.Errorf("%w: %s", ErrSimulationReverted, reason)
+	}
+	return fmt.Errorf("failed to simulate transaction: %w", err)
+}
+
+// revertReason returns the reason of the revert that caused [err], or false if [err] is not caused by a revert.
+// Reasons that are not a revert string are returned as the hex encoded revert data.
+func revertReason(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			data, decodeErr := hexutil.Decode(hexData)
+			if decodeErr != nil {
+				return hexData, true
+			}
+			if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+				return reason, true
+			}
+			return hexData, true
+		}
+	}
+	if strings.Contains(err.Error(), vm.ErrExecutionReverted.Error()) {
+		return err.Error(), true
+	}
+	return "", false
+}
+
+// firstEligibleSender returns the address of the first signer that SendTx could send a transaction
+// from on behalf of [deliverers]
+func firstEligibleSender(c CommonDestinationClient, deliverers set.Set[common.Address]) (common.Address, error) {
+	for _, concurrentSigner := range c.ConcurrentSigners() {
+		if deliverers.Len() == 0 || deliverers.Contains(concurrentSigner.signer.Address()) {
+			return concurrentSigner.signer.Address(), nil
+		}
+	}
+	return common.Address{}, errors.New("no signer is eligible to send the transaction")
+}
+
 func SenderAddresses(c CommonDestinationClient) []common.Address {
 	addresses := make([]common.Address, len(c.ConcurrentSigners()))
 	for i, concurrentSigner := range c.ConcurrentSigners() {
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"math/big"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego/graft/subnet-evm/precompile/contracts/warp"
@@ -21,6 +22,7 @@ import (
 	pchainapi "github.com/ryt-io/ryt-v2/vms/platformvm/api"
 	avalancheWarp "github.com/ryt-io/ryt-v2/vms/platformvm/warp"
 	"github.com/ryt-io/ryt-v2/vms/proposervm/block"
+	"github.com/ryt-io/icm-services/database"
 	"github.com/ryt-io/icm-services/peers/clients"
 	"github.com/ryt-io/icm-services/relayer/config"
 	"github.com/ryt-io/icm-services/utils"
@@ -54,6 +56,8 @@ type Client interface {
 type destinationClient struct {
 	avaRPCClient DestinationRPCClient
 	ethClient    bind.ContractBackend
+	// Closes the client's RPC connection. May be nil.
+	closeRPC func()
 
 	readonlyConcurrentSigners []*readonlyConcurrentSigner
 
@@ -64,6 +68,15 @@ type destinationClient struct {
 	gasFeeConfig            *GasFeeConfig
 	logger                  logging.Logger
 	txInclusionTimeout      time.Duration
+	txLimiter               *txLimiter
+	metrics                 *DestinationClientMetrics
+	// Records the transactions that would be sent instead of sending them in dry-run mode, or nil
+	dryRun *dryRunRecorder
+	// Closed to stop the client's background tasks, such as the signers' workers and reconciling their nonces
+	stop      chan struct{}
+	closeOnce sync.Once
+	// Tracks the background tasks, which Close waits for
+	backgroundTasks sync.WaitGroup
 
 	// Epoch cache for Granite - cached per destination blockchain
 	epochValue        block.Epoch
@@ -73,10 +86,17 @@ type destinationClient struct {
 	epochDuration     time.Duration
 }
 
+// NewDestinationClient creates a client for [destinationBlockchain]. The state of its components, such as
+// the spend of its limits, is loaded from [db] and written back every [dbWriteInterval]. If [db] is nil,
+// the state is only kept in memory.
 func NewDestinationClient(
 	logger logging.Logger,
 	destinationBlockchain *config.DestinationBlockchain,
 	epochDuration time.Duration,
+	dryRun bool,
+	destinationClientMetrics *DestinationClientMetrics,
+	db database.RelayerDatabase,
+	dbWriteInterval time.Duration,
 ) (*destinationClient, error) {
 	destinationID, err := ids.FromString(destinationBlockchain.BlockchainID)
 	if err != nil {
@@ -109,6 +129,7 @@ func NewDestinationClient(
 		destClient                 destinationClient
 		pendingNonce, currentNonce uint64
 		readonlyConcurrentSigners  = make([]*readonlyConcurrentSigner, len(signers))
+		stop                       = make(chan struct{})
 	)
 
 	// Block until all pending txs are accepted
@@ -147,16 +168,16 @@ func NewDestinationClient(
 			log.Debug("Pending txs accepted")
 
 			concurrentSigner := &concurrentSigner{
-				logger:            log,
-				signer:            signer,
-				currentNonce:      currentNonce,
-				messageChan:       make(chan txData),
-				queuedTxSemaphore: make(chan struct{}, poolTxsPerAccount),
-				destinationClient: &destClient,
+				logger:                  log,
+				signer:                  signer,
+				currentNonce:            currentNonce,
+				messageChan:             make(chan txData),
+				queuedTxSemaphore:       make(chan struct{}, poolTxsPerAccount),
+				destinationClient:       &destClient,
+				destinationBlockchainID: destinationID,
+				metrics:                 destinationClientMetrics,
+				stop:                    stop,
 			}
-
-			go concurrentSigner.processIncomingTransactions()
-
 			readonlyConcurrentSigners[i] = (*readonlyConcurrentSigner)(concurrentSigner)
 
 			break
@@ -178,14 +199,29 @@ func NewDestinationClient(
 	baseURL := fmt.Sprintf("%s://%s", endpoint.Scheme, endpoint.Host)
 	blockchainID := destinationBlockchain.BlockchainID
 	proposerClient := clients.NewProposerVMAPI(baseURL, blockchainID, &destinationBlockchain.RPCEndpoint)
+	txLimiter, err := newTxLimiter(
+		logger,
+		destinationBlockchain,
+		destinationClientMetrics,
+		newDestinationState(db, destinationID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction limiter: %w", err)
+	}
 	gasFeeConfig := &GasFeeConfig{
 		maxBaseFee:                 new(big.Int).SetUint64(destinationBlockchain.MaxBaseFee),
 		suggestedPriorityFeeBuffer: new(big.Int).SetUint64(destinationBlockchain.SuggestedPriorityFeeBuffer),
 		maxPriorityFeePerGas:       new(big.Int).SetUint64(destinationBlockchain.MaxPriorityFeePerGas),
+
+		txReplacementDelay:              time.Duration(destinationBlockchain.TxReplacementDelaySeconds) * time.Second,
+		txReplacementFeeBumpPercent:     destinationBlockchain.TxReplacementFeeBumpPercent,
+		maxReplacementFeePerGas:         new(big.Int).SetUint64(destinationBlockchain.MaxReplacementFeePerGas),
+		maxReplacementPriorityFeePerGas: new(big.Int).SetUint64(destinationBlockchain.MaxReplacementPriorityFeePerGas),
 	}
 	destClient = destinationClient{
 		avaRPCClient:              NewAvaDestinationClient(ethClient, rpcClient),
 		ethClient:                 ethClient,
+		closeRPC:                  rpcClient.Close,
 		readonlyConcurrentSigners: readonlyConcurrentSigners,
 		destinationBlockchainID:   destinationID,
 		rpcEndpointURL:            destinationBlockchain.RPCEndpoint.BaseURL,
@@ -194,9 +230,90 @@ func NewDestinationClient(
 		blockGasLimit:             destinationBlockchain.BlockGasLimit,
 		gasFeeConfig:              gasFeeConfig,
 		txInclusionTimeout:        time.Duration(destinationBlockchain.TxInclusionTimeoutSeconds) * time.Second,
+		txLimiter:                 txLimiter,
+		metrics:                   destinationClientMetrics,
+		stop:                      stop,
 		proposerClient:            proposerClient,
 		epochDuration:             epochDuration,
 	}
+	for _, readonlySigner := range readonlyConcurrentSigners {
+		destClient.runBackgroundTask((*concurrentSigner)(readonlySigner).processIncomingTransactions)
+	}
+
+	// In dry-run mode, transactions are only recorded, so the background tasks that send transactions
+	// are not started, and the spend of recorded transactions is not stored
+	if dryRun {
+		destClient.dryRun, err = newDryRunRecorder()
+		if err != nil {
+			destClient.Close()
+			return nil, fmt.Errorf("failed to create dry-run recorder: %w", err)
+		}
+		logger.Info("Recording transactions instead of sending them in dry-run mode")
+		return &destClient, nil
+	}
+
+	destClient.runBackgroundTask(func() {
+		destClient.writeStatePeriodically(dbWriteInterval)
+	})
+
+	nonceReconciliationInterval := time.Duration(destinationBlockchain.NonceReconciliationIntervalSeconds) * time.Second
+	for _, readonlySigner := range readonlyConcurrentSigners {
+		destClient.runBackgroundTask(func() {
+			(*concurrentSigner)(readonlySigner).reconcileNonces(nonceReconciliationInterval, destClient.stop)
+		})
+	}
+
+	if rewardRedemption := destinationBlockchain.RewardRedemption; rewardRedemption != nil {
+		redeemer, err := newRewardRedeemer(
+			logger,
+			&destClient,
+			ethClient,
+			destinationID,
+			rewardRedemption,
+			destinationClientMetrics,
+			newDestinationState(db, destinationID),
+		)
+		if err != nil {
+			destClient.Close()
+			return nil, fmt.Errorf("failed to create reward redeemer: %w", err)
+		}
+		destClient.runBackgroundTask(func() {
+			redeemer.run(time.Duration(rewardRedemption.PollingIntervalSeconds)*time.Second, destClient.stop)
+		})
+	}
+
+	if receiptSweeper := destinationBlockchain.ReceiptSweeper; receiptSweeper != nil {
+		sweeper := newReceiptSweeper(
+			logger,
+			&destClient,
+			ethClient,
+			destinationID,
+			receiptSweeper,
+			destinationClientMetrics,
+		)
+		destClient.runBackgroundTask(func() {
+			sweeper.run(time.Duration(receiptSweeper.PollingIntervalSeconds)*time.Second, destClient.stop)
+		})
+	}
+
+	if executionRetry := destinationBlockchain.ExecutionRetry; executionRetry != nil {
+		retrier, err := newExecutionRetrier(
+			logger,
+			&destClient,
+			ethClient,
+			destinationID,
+			executionRetry,
+			destinationClientMetrics,
+			newDestinationState(db, destinationID),
+		)
+		if err != nil {
+			destClient.Close()
+			return nil, fmt.Errorf("failed to create execution retrier: %w", err)
+		}
+		destClient.runBackgroundTask(func() {
+			retrier.run(time.Duration(executionRetry.PollingIntervalSeconds)*time.Second, destClient.stop)
+		})
+	}
 
 	return &destClient, nil
 }
@@ -226,6 +343,9 @@ func (c *destinationClient) ConcurrentSigners() []*readonlyConcurrentSigner {
 }
 
 func (c *destinationClient) AccessList(data txData) types.AccessList {
+	if data.signedMessage == nil {
+		return nil
+	}
 	// Construct the actual transaction to broadcast on the destination chain
 	// Create predicate from the signed warp message
 	predicate := predicateutils.New(data.signedMessage.Bytes())
@@ -243,26 +363,91 @@ func (c *destinationClient) TxInclusionTimeout() time.Duration {
 	return c.txInclusionTimeout
 }
 
+func (c *destinationClient) TxLimiter() *txLimiter {
+	return c.txLimiter
+}
+
 func (c *destinationClient) getFeePerGas() (*big.Int, *big.Int, error) {
 	return getFeePerGas(c)
 }
 
 // SendTx constructs, signs, and broadcast a transaction to deliver the given {signedMessage}
-// to this chain with the provided {callData}.
+// to this chain with the provided {callData}. In dry-run mode, the transaction is recorded instead.
 func (c *destinationClient) SendTx(
 	signedMessage *avalancheWarp.Message,
 	deliverers set.Set[common.Address],
 	toAddress string,
 	gasLimit uint64,
 	callData []byte,
+	onSent func(txHash common.Hash),
 ) (*types.Receipt, error) {
-	return SendTx(c, signedMessage, deliverers, toAddress, gasLimit, callData, c.txInclusionTimeout)
+	if c.dryRun != nil {
+		return c.recordTx(signedMessage, deliverers, toAddress, gasLimit, callData)
+	}
+	return SendTx(c, signedMessage, deliverers, toAddress, gasLimit, callData, c.txInclusionTimeout, onSent)
+}
+
+// SimulateTx calls the transaction that SendTx would send to deliver {signedMessage} without sending it
+func (c *destinationClient) SimulateTx(
+	signedMessage *avalancheWarp.Message,
+	deliverers set.Set[common.Address],
+	toAddress string,
+	gasLimit uint64,
+	callData []byte,
+) error {
+	return SimulateTx(c, signedMessage, deliverers, toAddress, gasLimit, callData)
 }
 
 func (c *destinationClient) SenderAddresses() []common.Address {
 	return SenderAddresses(c)
 }
 
+func (c *destinationClient) Health() error {
+	return SignersHealth(c)
+}
+
+// Close stops the client's background tasks, including the workers of its signers, and waits for them to
+// return, so that the transactions they send are not issued concurrently with the transactions of a client that
+// replaces this one. The client's RPC connection is then closed.
+func (c *destinationClient) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		if c.txLimiter != nil {
+			c.txLimiter.close()
+		}
+		c.backgroundTasks.Wait()
+		if c.closeRPC != nil {
+			c.closeRPC()
+		}
+	})
+	c.backgroundTasks.Wait()
+}
+
+// writeStatePeriodically writes the state of the client's components to the database every [interval],
+// and once more when the client is closed
+func (c *destinationClient) writeStatePeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.txLimiter.writeState()
+		case <-c.stop:
+			c.txLimiter.writeState()
+			return
+		}
+	}
+}
+
+// runBackgroundTask runs [task] in a goroutine that Close waits for. [task] must return once c.stop is closed.
+func (c *destinationClient) runBackgroundTask(task func()) {
+	c.backgroundTasks.Add(1)
+	go func() {
+		defer c.backgroundTasks.Done()
+		task()
+	}()
+}
+
 func (c *destinationClient) Client() Client {
 	return c.ethClient
 }
@@ -0,0 +1,133 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type DestinationClientMetrics struct {
+	spendBudgetRemaining *prometheus.GaugeVec
+	spendBudgetPaused    *prometheus.GaugeVec
+	nonceDrift           *prometheus.GaugeVec
+	nonceGapsFilled      *prometheus.CounterVec
+	rewardsUnredeemed    *prometheus.GaugeVec
+	rewardsRedeemed      *prometheus.GaugeVec
+	receiptQueueSize     *prometheus.GaugeVec
+	receiptsSwept        *prometheus.CounterVec
+	failedMessages       *prometheus.GaugeVec
+	executionRetries     *prometheus.CounterVec
+	dryRunTxs            *prometheus.CounterVec
+	dryRunEstimatedFees  *prometheus.CounterVec
+}
+
+func NewDestinationClientMetrics(registerer prometheus.Registerer) *DestinationClientMetrics {
+	spendBudgetLabels := []string{"destination_chain_id", "source_chain_id", "period"}
+	signerLabels := []string{"destination_chain_id", "sender_address"}
+	rewardLabels := []string{"destination_chain_id", "reward_address", "fee_token_address"}
+	receiptLabels := []string{"destination_chain_id", "counterpart_chain_id"}
+	executionRetryLabels := []string{"destination_chain_id", "destination_address", "outcome"}
+	dryRunLabels := []string{"destination_chain_id", "source_chain_id"}
+	m := DestinationClientMetrics{
+		spendBudgetRemaining: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "spend_budget_remaining_wei",
+				Help: "Remaining fees in wei that may be spent on the destination blockchain in the current period",
+			},
+			spendBudgetLabels,
+		),
+		spendBudgetPaused: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "spend_budget_paused",
+				Help: "Whether transactions are paused until the spend budget resets (1) or not (0)",
+			},
+			spendBudgetLabels,
+		),
+		nonceDrift: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "signer_nonce_drift",
+				Help: "Difference between the signer's local nonce and its pending nonce on the destination blockchain",
+			},
+			signerLabels,
+		),
+		nonceGapsFilled: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "signer_nonce_gaps_filled",
+				Help: "Number of missing nonces of the signer that were filled by resubmitting or self-transferring",
+			},
+			append(signerLabels, "method"),
+		),
+		rewardsUnredeemed: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "relayer_rewards_unredeemed",
+				Help: "Relayer rewards of the fee token that the reward address can redeem on the blockchain",
+			},
+			rewardLabels,
+		),
+		rewardsRedeemed: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "relayer_rewards_redeemed",
+				Help: "Total relayer rewards of the fee token redeemed by the reward address on the blockchain",
+			},
+			rewardLabels,
+		),
+		receiptQueueSize: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "receipt_queue_size",
+				Help: "Number of receipts of messages from the counterpart blockchain queued on the blockchain",
+			},
+			receiptLabels,
+		),
+		receiptsSwept: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "receipts_swept",
+				Help: "Number of the relayer's receipts sent back to the counterpart blockchain by the receipt sweeper",
+			},
+			receiptLabels,
+		),
+		failedMessages: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "failed_message_executions_pending",
+				Help: "Number of messages with failed executions that are awaiting a retry",
+			},
+			[]string{"destination_chain_id"},
+		),
+		executionRetries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "message_execution_retries",
+				Help: "Number of retries of failed message executions by outcome",
+			},
+			executionRetryLabels,
+		),
+		dryRunTxs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "dry_run_transactions",
+				Help: "Number of transactions recorded instead of being sent in dry-run mode",
+			},
+			dryRunLabels,
+		),
+		dryRunEstimatedFees: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "dry_run_estimated_fees_wei",
+				Help: "Maximum fees in wei of the transactions recorded instead of being sent in dry-run mode",
+			},
+			dryRunLabels,
+		),
+	}
+
+	registerer.MustRegister(m.spendBudgetRemaining)
+	registerer.MustRegister(m.spendBudgetPaused)
+	registerer.MustRegister(m.nonceDrift)
+	registerer.MustRegister(m.nonceGapsFilled)
+	registerer.MustRegister(m.rewardsUnredeemed)
+	registerer.MustRegister(m.rewardsRedeemed)
+	registerer.MustRegister(m.receiptQueueSize)
+	registerer.MustRegister(m.receiptsSwept)
+	registerer.MustRegister(m.failedMessages)
+	registerer.MustRegister(m.executionRetries)
+	registerer.MustRegister(m.dryRunTxs)
+	registerer.MustRegister(m.dryRunEstimatedFees)
+
+	return &m
+}
@@ -5,6 +5,7 @@ package evm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
@@ -12,13 +13,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
 	avalancheWarp "github.com/ryt-io/ryt-v2/vms/platformvm/warp"
 	basecfg "github.com/ryt-io/icm-services/config"
 	"github.com/ryt-io/icm-services/relayer/config"
 	mock_ethclient "github.com/ryt-io/icm-services/vms/evm/mocks"
 	"github.com/ryt-io/icm-services/vms/evm/signer"
+	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/accounts/abi"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/common/hexutil"
 	"github.com/ryt-io/libevm/core/types"
+	"github.com/ryt-io/libevm/crypto"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -32,6 +40,43 @@ var destinationSubnet = config.DestinationBlockchain{
 	AccountPrivateKeys: []string{"56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"},
 }
 
+// newTestSendingDestinationClient returns a destination client that sends transactions from [txSigner] through
+// [mockRPCClient]. The signer's worker is stopped once the test completes.
+func newTestSendingDestinationClient(
+	t *testing.T,
+	txSigner signer.Signer,
+	mockRPCClient *mock_ethclient.MockDestinationRPCClient,
+	mockClient *mock_ethclient.MockClient,
+) *destinationClient {
+	destClient := &destinationClient{
+		logger:       logging.NoLog{},
+		avaRPCClient: mockRPCClient,
+		ethClient:    mockClient,
+		evmChainID:   big.NewInt(5),
+		gasFeeConfig: &GasFeeConfig{
+			maxBaseFee:                 big.NewInt(100),
+			suggestedPriorityFeeBuffer: big.NewInt(0),
+			maxPriorityFeePerGas:       big.NewInt(10),
+		},
+		txInclusionTimeout: 30 * time.Second,
+		stop:               make(chan struct{}),
+	}
+	concurrentSigner := &concurrentSigner{
+		logger:            logging.NoLog{},
+		signer:            txSigner,
+		messageChan:       make(chan txData),
+		queuedTxSemaphore: make(chan struct{}, poolTxsPerAccount),
+		destinationClient: destClient,
+		stop:              destClient.stop,
+	}
+	destClient.readonlyConcurrentSigners = []*readonlyConcurrentSigner{
+		(*readonlyConcurrentSigner)(concurrentSigner),
+	}
+	destClient.runBackgroundTask(concurrentSigner.processIncomingTransactions)
+	t.Cleanup(destClient.Close)
+	return destClient
+}
+
 func TestGetFeePerGas(t *testing.T) {
 	testCases := []struct {
 		name                       string
@@ -263,16 +308,336 @@ func TestSendTx(t *testing.T) {
 					).Times(test.txReceiptTimes),
 			)
 
-			_, err := destClient.SendTx(warpMsg, nil, toAddress, 0, []byte{})
+			// The hash of the transaction is reported once it is sent
+			var sentTxHashes []common.Hash
+			_, err := destClient.SendTx(warpMsg, nil, toAddress, 0, []byte{}, func(txHash common.Hash) {
+				sentTxHashes = append(sentTxHashes, txHash)
+			})
 			if test.expectError {
 				require.Error(t, err)
+				require.Empty(t, sentTxHashes)
 			} else {
 				require.NoError(t, err)
+				require.Len(t, sentTxHashes, 1)
+			}
+		})
+	}
+}
+
+type testRevertError struct {
+	data string
+}
+
+func (e *testRevertError) Error() string {
+	return "execution reverted"
+}
+
+func (e *testRevertError) ErrorData() interface{} {
+	return e.data
+}
+
+func TestSimulateTx(t *testing.T) {
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	reason, err := abi.Arguments{{Type: stringType}}.Pack("invalid warp message")
+	require.NoError(t, err)
+	revertData := hexutil.Encode(append(crypto.Keccak256([]byte("Error(string)"))[:4], reason...))
+
+	testCases := []struct {
+		name           string
+		callErr        error
+		expectReverted bool
+		expectedReason string
+		expectError    bool
+	}{
+		{
+			name: "would succeed",
+		},
+		{
+			name:           "would revert with reason",
+			callErr:        &testRevertError{data: revertData},
+			expectReverted: true,
+			expectedReason: "invalid warp message",
+			expectError:    true,
+		},
+		{
+			name:           "would revert without data",
+			callErr:        errors.New("execution reverted"),
+			expectReverted: true,
+			expectError:    true,
+		},
+		{
+			name:        "call failed",
+			callErr:     errors.New("connection refused"),
+			expectError: true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+			txSigners, err := signer.NewTxSigners(destinationSubnet.AccountPrivateKeys)
+			require.NoError(t, err)
+			destClient := &destinationClient{
+				logger:       logging.NoLog{},
+				avaRPCClient: mockClient,
+			}
+			destClient.readonlyConcurrentSigners = []*readonlyConcurrentSigner{
+				(*readonlyConcurrentSigner)(&concurrentSigner{
+					logger:            logging.NoLog{},
+					signer:            txSigners[0],
+					destinationClient: destClient,
+				}),
+			}
+
+			unsignedMessage, err := avalancheWarp.NewUnsignedMessage(0, ids.GenerateTestID(), []byte{1, 2, 3})
+			require.NoError(t, err)
+			signedMessage, err := avalancheWarp.NewMessage(unsignedMessage, &avalancheWarp.BitSetSignature{})
+			require.NoError(t, err)
+			to := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+			callData := []byte{4, 5, 6}
+			mockClient.EXPECT().CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).DoAndReturn(
+				func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+					require.Equal(t, txSigners[0].Address(), msg.From)
+					require.Equal(t, to, *msg.To)
+					require.Equal(t, uint64(100_000), msg.Gas)
+					require.Equal(t, callData, msg.Data)
+					require.Equal(t, destClient.AccessList(txData{signedMessage: signedMessage}), msg.AccessList)
+					return nil, test.callErr
+				},
+			)
+
+			err = SimulateTx(destClient, signedMessage, set.Of(txSigners[0].Address()), to.Hex(), 100_000, callData)
+			if !test.expectError {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, test.expectReverted, errors.Is(err, ErrSimulationReverted))
+			require.Contains(t, err.Error(), test.expectedReason)
+		})
+	}
+}
+
+func TestSendTxDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+	txSigners, err := signer.NewTxSigners(destinationSubnet.AccountPrivateKeys)
+	require.NoError(t, err)
+	recorder, err := newDryRunRecorder()
+	require.NoError(t, err)
+	destClient := &destinationClient{
+		logger:       logging.NoLog{},
+		avaRPCClient: mockClient,
+		evmChainID:   big.NewInt(5),
+		gasFeeConfig: &GasFeeConfig{
+			maxBaseFee:                 big.NewInt(100),
+			suggestedPriorityFeeBuffer: big.NewInt(0),
+			maxPriorityFeePerGas:       big.NewInt(10),
+		},
+		dryRun: recorder,
+	}
+	// The signers do not process transactions, so any attempt to send one would block
+	destClient.readonlyConcurrentSigners = []*readonlyConcurrentSigner{
+		(*readonlyConcurrentSigner)(&concurrentSigner{
+			logger:            logging.NoLog{},
+			signer:            txSigners[0],
+			messageChan:       make(chan txData),
+			destinationClient: destClient,
+		}),
+	}
+
+	unsignedMessage, err := avalancheWarp.NewUnsignedMessage(0, ids.GenerateTestID(), []byte{1, 2, 3})
+	require.NoError(t, err)
+	signedMessage, err := avalancheWarp.NewMessage(unsignedMessage, &avalancheWarp.BitSetSignature{})
+	require.NoError(t, err)
+	to := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	callData := []byte{4, 5, 6}
+	mockClient.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(1), nil)
+
+	receipt, err := destClient.SendTx(signedMessage, set.Set[common.Address]{}, to.Hex(), 100_000, callData, nil)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	tx, signerAddress, ok := destClient.DryRunTransaction(receipt.TxHash)
+	require.True(t, ok)
+	require.Equal(t, txSigners[0].Address(), signerAddress)
+	require.Equal(t, to, *tx.To())
+	require.Equal(t, uint64(100_000), tx.Gas())
+	require.Equal(t, callData, tx.Data())
+	require.Equal(t, big.NewInt(101), tx.GasFeeCap())
+	require.Equal(t, destClient.AccessList(txData{signedMessage: signedMessage}), tx.AccessList())
+
+	_, _, ok = destClient.DryRunTransaction(common.Hash{})
+	require.False(t, ok)
+}
+
+func TestCloseStopsSignerWorkers(t *testing.T) {
+	txSigners, err := signer.NewTxSigners(destinationSubnet.AccountPrivateKeys)
+	require.NoError(t, err)
+	destClient := &destinationClient{
+		logger: logging.NoLog{},
+		stop:   make(chan struct{}),
+	}
+	rpcClosed := false
+	destClient.closeRPC = func() { rpcClosed = true }
+
+	// One worker waits for a transaction, the other for a free slot in its queue
+	idleSigner := &concurrentSigner{
+		logger:            logging.NoLog{},
+		signer:            txSigners[0],
+		messageChan:       make(chan txData),
+		queuedTxSemaphore: make(chan struct{}, poolTxsPerAccount),
+		destinationClient: destClient,
+		stop:              destClient.stop,
+	}
+	fullSigner := &concurrentSigner{
+		logger:            logging.NoLog{},
+		signer:            txSigners[0],
+		messageChan:       make(chan txData),
+		queuedTxSemaphore: make(chan struct{}, 1),
+		destinationClient: destClient,
+		stop:              destClient.stop,
+	}
+	fullSigner.queuedTxSemaphore <- struct{}{}
+	destClient.runBackgroundTask(idleSigner.processIncomingTransactions)
+	destClient.runBackgroundTask(fullSigner.processIncomingTransactions)
+
+	closed := make(chan struct{})
+	go func() {
+		destClient.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "signer workers did not exit after the client was closed")
+	}
+	require.True(t, rpcClosed)
+	require.Empty(t, idleSigner.queuedTxSemaphore)
+}
+
+func TestBumpFees(t *testing.T) {
+	testCases := []struct {
+		name                            string
+		maxReplacementFeePerGas         *big.Int
+		maxReplacementPriorityFeePerGas *big.Int
+		expectedGasFeeCap               *big.Int
+		expectedGasTipCap               *big.Int
+		expectedOk                      bool
+	}{
+		{
+			name:                            "bumped within maximums",
+			maxReplacementFeePerGas:         big.NewInt(1000),
+			maxReplacementPriorityFeePerGas: big.NewInt(100),
+			expectedGasFeeCap:               big.NewInt(120),
+			expectedGasTipCap:               big.NewInt(12),
+			expectedOk:                      true,
+		},
+		{
+			name:                            "bumped to maximums",
+			maxReplacementFeePerGas:         big.NewInt(120),
+			maxReplacementPriorityFeePerGas: big.NewInt(12),
+			expectedGasFeeCap:               big.NewInt(120),
+			expectedGasTipCap:               big.NewInt(12),
+			expectedOk:                      true,
+		},
+		{
+			name:                            "gas fee cap exceeds maximum",
+			maxReplacementFeePerGas:         big.NewInt(119),
+			maxReplacementPriorityFeePerGas: big.NewInt(100),
+		},
+		{
+			name:                            "gas tip cap exceeds maximum",
+			maxReplacementFeePerGas:         big.NewInt(1000),
+			maxReplacementPriorityFeePerGas: big.NewInt(11),
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			gasFeeConfig := GasFeeConfig{
+				txReplacementDelay:              time.Second,
+				txReplacementFeeBumpPercent:     20,
+				maxReplacementFeePerGas:         test.maxReplacementFeePerGas,
+				maxReplacementPriorityFeePerGas: test.maxReplacementPriorityFeePerGas,
 			}
+			gasFeeCap, gasTipCap, ok := gasFeeConfig.bumpFees(big.NewInt(100), big.NewInt(10))
+			require.Equal(t, test.expectedOk, ok)
+			require.Equal(t, test.expectedGasFeeCap, gasFeeCap)
+			require.Equal(t, test.expectedGasTipCap, gasTipCap)
 		})
 	}
 }
 
+func TestWaitForReceiptReplacesTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+	txSigners, err := signer.NewTxSigners(destinationSubnet.AccountPrivateKeys)
+	require.NoError(t, err)
+
+	destClient := destinationClient{
+		logger:       logging.NoLog{},
+		avaRPCClient: mockClient,
+		evmChainID:   big.NewInt(5),
+		gasFeeConfig: &GasFeeConfig{
+			txReplacementDelay:              time.Millisecond,
+			txReplacementFeeBumpPercent:     20,
+			maxReplacementFeePerGas:         big.NewInt(1000),
+			maxReplacementPriorityFeePerGas: big.NewInt(100),
+		},
+		txInclusionTimeout: 30 * time.Second,
+	}
+	signer := &concurrentSigner{
+		logger:            logging.NoLog{},
+		signer:            txSigners[0],
+		queuedTxSemaphore: make(chan struct{}, poolTxsPerAccount),
+		destinationClient: &destClient,
+	}
+	// Occupy the queued tx slot that is released once the receipt is received
+	signer.queuedTxSemaphore <- struct{}{}
+
+	to := common.HexToAddress("0x27aE10273D17Cd7e80de8580A51f476960626e5f")
+	tx, err := signer.signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   destClient.evmChainID,
+		Nonce:     3,
+		To:        &to,
+		Gas:       100_000,
+		GasFeeCap: big.NewInt(100),
+		GasTipCap: big.NewInt(10),
+		Value:     big.NewInt(0),
+	}), destClient.evmChainID)
+	require.NoError(t, err)
+
+	// The original transaction is never included, and the replacement is included once it is sent
+	var replacementTx *types.Transaction
+	mockClient.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, tx *types.Transaction) error {
+			replacementTx = tx
+			return nil
+		},
+	).Times(1)
+	mockClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+			if replacementTx != nil && txHash == replacementTx.Hash() {
+				return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: txHash}, nil
+			}
+			return nil, ethereum.NotFound
+		},
+	).MinTimes(2)
+
+	resultChan := make(chan txResult, 1)
+	signer.waitForReceipt(tx, resultChan)
+	result := <-resultChan
+	require.NoError(t, result.err)
+
+	require.NotNil(t, replacementTx)
+	require.Equal(t, replacementTx.Hash(), result.txID)
+	require.Equal(t, tx.Nonce(), replacementTx.Nonce())
+	require.Equal(t, big.NewInt(120), replacementTx.GasFeeCap())
+	require.Equal(t, big.NewInt(12), replacementTx.GasTipCap())
+	require.Empty(t, signer.queuedTxSemaphore)
+}
+
 // TestDestinationClient_QueryParamsForwarding verifies that query parameters are forwarded correctly
 func TestDestinationClient_QueryParamsForwarding(t *testing.T) {
 	tests := []struct {
@@ -332,7 +697,7 @@ func TestDestinationClient_QueryParamsForwarding(t *testing.T) {
 
 			// Create destination client (this will make ChainID call)
 			logger := logging.NoLog{}
-			_, err := NewDestinationClient(logger, &destinationBlockchain, time.Minute)
+			_, err := NewDestinationClient(logger, &destinationBlockchain, time.Minute, false, nil, nil, time.Second)
 			require.NoError(t, err)
 
 			// Verify all query params were received
@@ -405,7 +770,7 @@ func TestDestinationClient_HTTPHeadersForwarding(t *testing.T) {
 
 			// Create destination client (this will make ChainID call)
 			logger := logging.NoLog{}
-			_, err := NewDestinationClient(logger, &destinationBlockchain, time.Minute)
+			_, err := NewDestinationClient(logger, &destinationBlockchain, time.Minute, false, nil, nil, time.Second)
 			require.NoError(t, err)
 
 			// Verify all headers were received
@@ -459,7 +824,9 @@ func TestDestinationClient_CombinedQueryParamsAndHeaders(t *testing.T) {
 		AccountPrivateKeys: []string{"56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"},
 	}
 
-	_, err := NewDestinationClient(logging.NoLog{}, &destinationBlockchain, time.Minute)
+	_, err := NewDestinationClient(
+		logging.NoLog{}, &destinationBlockchain, time.Minute, false, nil, nil, time.Second,
+	)
 	require.NoError(t, err)
 
 	for key, expectedValue := range queryParams {
@@ -513,7 +880,9 @@ func TestDestinationClient_AllRPCCallsForwardQueryParams(t *testing.T) {
 		AccountPrivateKeys: []string{"56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"},
 	}
 
-	client, err := NewDestinationClient(logging.NoLog{}, &destinationBlockchain, time.Minute)
+	client, err := NewDestinationClient(
+		logging.NoLog{}, &destinationBlockchain, time.Minute, false, nil, nil, time.Second,
+	)
 	require.NoError(t, err)
 
 	ctx := t.Context()
@@ -571,7 +940,9 @@ func TestDestinationClient_AllRPCCallsForwardHTTPHeaders(t *testing.T) {
 		AccountPrivateKeys: []string{"56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"},
 	}
 
-	client, err := NewDestinationClient(logging.NoLog{}, &destinationBlockchain, time.Minute)
+	client, err := NewDestinationClient(
+		logging.NoLog{}, &destinationBlockchain, time.Minute, false, nil, nil, time.Second,
+	)
 	require.NoError(t, err)
 
 	client.avaRPCClient.BlockNumber(t.Context())
@@ -0,0 +1,60 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/libevm/common"
+)
+
+// destinationState stores the state of a destination client's components in the database, under the destination
+// blockchain's state ID, so that it is kept across restarts and reloads. Nothing is stored if the database is nil.
+type destinationState struct {
+	db database.RelayerDatabase
+	id common.Hash
+}
+
+func newDestinationState(db database.RelayerDatabase, destinationBlockchainID ids.ID) *destinationState {
+	return &destinationState{
+		db: db,
+		id: database.NewDestinationStateID(destinationBlockchainID).ID,
+	}
+}
+
+// get decodes the JSON value stored under [key] into [v]. Returns false if no value is stored.
+func (s *destinationState) get(key database.DataKey, v any) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, nil
+	}
+	value, err := s.db.Get(s.id, key)
+	if database.IsKeyNotFoundError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	if err := json.Unmarshal(value, v); err != nil {
+		return false, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// put stores [v] under [key], encoded as JSON
+func (s *destinationState) put(key database.DataKey, v any) error {
+	if s == nil || s.db == nil {
+		return nil
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	if err := s.db.Put(s.id, key, value); err != nil {
+		return fmt.Errorf("failed to put %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	avalancheWarp "github.com/ryt-io/ryt-v2/vms/platformvm/warp"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/common/hexutil"
+	"github.com/ryt-io/libevm/core/types"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.uber.org/zap"
+)
+
+// Number of recorded transactions retained to be looked up by hash
+const dryRunTxCacheSize = 1024
+
+// dryRunTx is a transaction recorded instead of being sent in dry-run mode
+type dryRunTx struct {
+	tx     *types.Transaction
+	signer common.Address
+}
+
+// dryRunRecorder records the transactions that SendTx would send instead of sending them
+type dryRunRecorder struct {
+	txs *lru.Cache[common.Hash, dryRunTx]
+}
+
+func newDryRunRecorder() (*dryRunRecorder, error) {
+	txs, err := lru.New[common.Hash, dryRunTx](dryRunTxCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &dryRunRecorder{txs: txs}, nil
+}
+
+// recordTx builds the transaction that SendTx would send with the same arguments, along with the signer it
+// would be sent from, and records it instead of sending it. The fees are estimated without reserving them
+// against the spend limits. The returned receipt is successful, and its hash identifies the recorded transaction.
+func (c *destinationClient) recordTx(
+	signedMessage *avalancheWarp.Message,
+	deliverers set.Set[common.Address],
+	toAddress string,
+	gasLimit uint64,
+	callData []byte,
+) (*types.Receipt, error) {
+	from, err := firstEligibleSender(c, deliverers)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap, gasTipCap, err := c.getFeePerGas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate fees: %w", err)
+	}
+	to := common.HexToAddress(toAddress)
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:    c.evmChainID,
+		GasTipCap:  gasTipCap,
+		GasFeeCap:  gasFeeCap,
+		Gas:        gasLimit,
+		To:         &to,
+		Data:       callData,
+		AccessList: c.AccessList(txData{signedMessage: signedMessage}),
+	})
+	c.dryRun.txs.Add(tx.Hash(), dryRunTx{tx: tx, signer: from})
+
+	sourceBlockchainID := ids.Empty
+	if signedMessage != nil {
+		sourceBlockchainID = signedMessage.SourceChainID
+	}
+	estimatedFee := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasFeeCap)
+	c.logger.Info(
+		"Recorded transaction in dry-run mode",
+		zap.Stringer("txID", tx.Hash()),
+		zap.Stringer("sourceBlockchainID", sourceBlockchainID),
+		zap.Stringer("signerAddress", from),
+		zap.Stringer("toAddress", to),
+		zap.Uint64("gasLimit", gasLimit),
+		zap.String("callData", hexutil.Encode(callData)),
+		zap.Stringer("gasFeeCap", gasFeeCap),
+		zap.Stringer("estimatedFee", estimatedFee),
+	)
+	if c.metrics != nil {
+		labels := []string{c.destinationBlockchainID.String(), sourceBlockchainID.String()}
+		c.metrics.dryRunTxs.WithLabelValues(labels...).Inc()
+		fee, _ := new(big.Float).SetInt(estimatedFee).Float64()
+		c.metrics.dryRunEstimatedFees.WithLabelValues(labels...).Add(fee)
+	}
+
+	return &types.Receipt{
+		Type:   types.DynamicFeeTxType,
+		Status: types.ReceiptStatusSuccessful,
+		TxHash: tx.Hash(),
+	}, nil
+}
+
+// DryRunTransaction returns the transaction recorded by the SendTx call that returned a receipt with [txHash]
+// in dry-run mode, along with the address of the signer that would have sent it.
+func (c *destinationClient) DryRunTransaction(txHash common.Hash) (*types.Transaction, common.Address, bool) {
+	if c.dryRun == nil {
+		return nil, common.Address{}, false
+	}
+	recorded, ok := c.dryRun.txs.Get(txHash)
+	if !ok {
+		return nil, common.Address{}, false
+	}
+	return recorded.tx, recorded.signer, true
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type EndpointMetrics struct {
+	activeEndpoint     *prometheus.GaugeVec
+	endpointLatencyMS  *prometheus.GaugeVec
+	endpointErrorCount *prometheus.CounterVec
+	endpointHeadLag    *prometheus.GaugeVec
+}
+
+func NewEndpointMetrics(registerer prometheus.Registerer) *EndpointMetrics {
+	labels := []string{"source_chain_id", "endpoint_type", "endpoint_index", "endpoint_host"}
+	m := EndpointMetrics{
+		activeEndpoint: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "source_endpoint_active",
+				Help: "Whether the source blockchain endpoint is the active endpoint (1) or not (0)",
+			},
+			labels,
+		),
+		endpointLatencyMS: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "source_endpoint_latency_ms",
+				Help: "Moving average latency of requests to the source blockchain endpoint in milliseconds",
+			},
+			labels,
+		),
+		endpointErrorCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "source_endpoint_error_count",
+				Help: "Number of failed requests to the source blockchain endpoint",
+			},
+			labels,
+		),
+		endpointHeadLag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "source_endpoint_head_lag_blocks",
+				Help: "Number of blocks the source blockchain endpoint lags behind the most up to date endpoint",
+			},
+			labels,
+		),
+	}
+
+	registerer.MustRegister(m.activeEndpoint)
+	registerer.MustRegister(m.endpointLatencyMS)
+	registerer.MustRegister(m.endpointErrorCount)
+	registerer.MustRegister(m.endpointHeadLag)
+
+	return &m
+}
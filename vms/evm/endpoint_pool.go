@@ -0,0 +1,389 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/icm-services/utils"
+	"github.com/ryt-io/libevm/ethclient"
+	"go.uber.org/zap"
+)
+
+// Endpoint types reported in the endpoint metrics
+const (
+	RPCEndpointType = "rpc"
+	WSEndpointType  = "ws"
+)
+
+const (
+	// Weight of the latest observation in an endpoint's moving average latency and error rate
+	endpointHealthSmoothing = 0.2
+	// Endpoints with a higher moving average error rate are considered unhealthy
+	maxEndpointErrorRate       = 0.5
+	endpointHealthCheckTimeout = 5 * time.Second
+	// With the weighted selection, a healthy active endpoint is only replaced by a healthy endpoint whose score
+	// is higher by at least this fraction, once it has been active for at least minActiveEndpointDuration.
+	// This keeps the active endpoint from flapping between endpoints with similar scores.
+	minEndpointScoreMargin    = 0.25
+	minActiveEndpointDuration = time.Minute
+)
+
+type endpoint struct {
+	config config.WeightedEndpoint
+	// The base URL with the endpoint's query parameters applied
+	url *url.URL
+	// The host of the endpoint, used to identify it in logs and metrics without leaking credentials
+	host string
+
+	latency   time.Duration
+	errorRate float64
+	headLag   uint64
+}
+
+// EndpointPool tracks the health of a source blockchain's endpoints of a single type (RPC or WS), and selects
+// the active endpoint that requests are sent to. The health of an endpoint is scored by its latency, its
+// error rate, and how far its chain head lags behind the other endpoints.
+type EndpointPool struct {
+	logger              logging.Logger
+	blockchainID        ids.ID
+	endpointType        string
+	selection           config.EndpointSelection
+	maxHeadLag          uint64
+	healthCheckInterval time.Duration
+	metrics             *EndpointMetrics
+
+	lock      sync.RWMutex
+	endpoints []*endpoint
+	active    int
+	// When the active endpoint was selected
+	activeSince time.Time
+	now         func() time.Time
+}
+
+// NewSourceEndpointPool returns an EndpointPool of the RPC or WS endpoints of [sourceBlockchain]
+func NewSourceEndpointPool(
+	logger logging.Logger,
+	sourceBlockchain *config.SourceBlockchain,
+	endpointType string,
+	metrics *EndpointMetrics,
+) (*EndpointPool, error) {
+	endpoints := sourceBlockchain.GetRPCEndpoints()
+	if endpointType == WSEndpointType {
+		endpoints = sourceBlockchain.GetWSEndpoints()
+	}
+	return NewEndpointPool(
+		logger,
+		sourceBlockchain.GetBlockchainID(),
+		endpointType,
+		endpoints,
+		sourceBlockchain.GetEndpointSelection(),
+		sourceBlockchain.MaxEndpointHeadLagBlocks,
+		sourceBlockchain.GetEndpointHealthCheckInterval(),
+		metrics,
+	)
+}
+
+// NewEndpointPool returns an EndpointPool of [endpoints], in priority order
+func NewEndpointPool(
+	logger logging.Logger,
+	blockchainID ids.ID,
+	endpointType string,
+	endpoints []config.WeightedEndpoint,
+	selection config.EndpointSelection,
+	maxHeadLag uint64,
+	healthCheckInterval time.Duration,
+	metrics *EndpointMetrics,
+) (*EndpointPool, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no %s endpoints configured", endpointType)
+	}
+	pool := &EndpointPool{
+		logger: logger.With(
+			zap.Stringer("blockchainID", blockchainID),
+			zap.String("endpointType", endpointType),
+		),
+		blockchainID:        blockchainID,
+		endpointType:        endpointType,
+		selection:           selection,
+		maxHeadLag:          maxHeadLag,
+		healthCheckInterval: healthCheckInterval,
+		metrics:             metrics,
+		now:                 time.Now,
+	}
+	for _, cfg := range endpoints {
+		uri, err := url.ParseRequestURI(cfg.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrInvalidEndpoint, err)
+		}
+		values := uri.Query()
+		for key, value := range cfg.QueryParams {
+			values.Add(key, value)
+		}
+		uri.RawQuery = values.Encode()
+		pool.endpoints = append(pool.endpoints, &endpoint{
+			config: cfg,
+			url:    uri,
+			host:   uri.Host,
+		})
+	}
+	pool.active = pool.selectionOrder()[0]
+	pool.activeSince = pool.now()
+	pool.setActiveMetric(pool.active, 1)
+	return pool, nil
+}
+
+// Len returns the number of endpoints in the pool
+func (p *EndpointPool) Len() int {
+	return len(p.endpoints)
+}
+
+// Active returns the index of the active endpoint
+func (p *EndpointPool) Active() int {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.active
+}
+
+// Config returns the configuration of the endpoint at [index]
+func (p *EndpointPool) Config(index int) config.WeightedEndpoint {
+	return p.endpoints[index].config
+}
+
+// URL returns the URL of the endpoint at [index], including its query parameters
+func (p *EndpointPool) URL(index int) *url.URL {
+	uri := *p.endpoints[index].url
+	return &uri
+}
+
+// SelectionOrder returns the indices of the endpoints in the order they should be tried, starting with the
+// active endpoint. Healthy endpoints are tried before unhealthy ones. With the ordered selection, endpoints are
+// otherwise tried in their configured order. With the weighted selection, they are tried in order of their score.
+func (p *EndpointPool) SelectionOrder() []int {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	order := p.selectionOrder()
+	for i, index := range order {
+		if index == p.active {
+			copy(order[1:i+1], order[:i])
+			order[0] = p.active
+			break
+		}
+	}
+	return order
+}
+
+// selectionOrder returns the endpoints in order of preference, regardless of the active endpoint.
+// Assumes that the caller holds the lock.
+func (p *EndpointPool) selectionOrder() []int {
+	order := make([]int, len(p.endpoints))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := p.endpoints[order[i]], p.endpoints[order[j]]
+		if p.healthy(a) != p.healthy(b) {
+			return p.healthy(a)
+		}
+		if p.selection == config.WEIGHTED_ENDPOINT_SELECTION {
+			return score(a) > score(b)
+		}
+		return false
+	})
+	return order
+}
+
+func (p *EndpointPool) healthy(e *endpoint) bool {
+	return e.errorRate <= maxEndpointErrorRate && e.headLag <= p.maxHeadLag
+}
+
+// score weighs an endpoint by its configured weight, its success rate, and its latency
+func score(e *endpoint) float64 {
+	return float64(e.config.Weight) * (1 - e.errorRate) / (1 + e.latency.Seconds())
+}
+
+// RecordSuccess records a successful request to the endpoint at [index] that took [latency]
+func (p *EndpointPool) RecordSuccess(index int, latency time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	e := p.endpoints[index]
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(
+			endpointHealthSmoothing*float64(latency) + (1-endpointHealthSmoothing)*float64(e.latency),
+		)
+	}
+	e.errorRate = (1 - endpointHealthSmoothing) * e.errorRate
+	if p.metrics != nil {
+		p.metrics.endpointLatencyMS.
+			WithLabelValues(p.labelValues(index)...).
+			Set(float64(e.latency.Milliseconds()))
+	}
+	p.updateActive()
+}
+
+// RecordError records a failed request to the endpoint at [index]
+func (p *EndpointPool) RecordError(index int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	e := p.endpoints[index]
+	e.errorRate = endpointHealthSmoothing + (1-endpointHealthSmoothing)*e.errorRate
+	if p.metrics != nil {
+		p.metrics.endpointErrorCount.WithLabelValues(p.labelValues(index)...).Inc()
+	}
+	p.updateActive()
+}
+
+// RecordHeights records the latest block heights reported by the endpoints, keyed by endpoint index.
+// Each endpoint's head lag is measured against the highest reported height. The head lag of endpoints
+// that did not report a height is reset, since their failed health checks are recorded as errors.
+func (p *EndpointPool) RecordHeights(heights map[int]uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var maxHeight uint64
+	for _, height := range heights {
+		maxHeight = max(maxHeight, height)
+	}
+	for index, e := range p.endpoints {
+		e.headLag = 0
+		if height, ok := heights[index]; ok {
+			e.headLag = maxHeight - height
+		}
+		if p.metrics != nil {
+			p.metrics.endpointHeadLag.WithLabelValues(p.labelValues(index)...).Set(float64(e.headLag))
+		}
+	}
+	p.updateActive()
+}
+
+// updateActive switches to the preferred endpoint if it is not already active. With the weighted selection,
+// a healthy active endpoint is kept unless the preferred endpoint scores higher by at least minEndpointScoreMargin
+// and the active endpoint has been active for at least minActiveEndpointDuration.
+// Assumes that the caller holds the lock.
+func (p *EndpointPool) updateActive() {
+	preferred := p.selectionOrder()[0]
+	if preferred == p.active {
+		return
+	}
+	active, preferredEndpoint := p.endpoints[p.active], p.endpoints[preferred]
+	if p.selection == config.WEIGHTED_ENDPOINT_SELECTION && p.healthy(active) &&
+		(score(preferredEndpoint) < (1+minEndpointScoreMargin)*score(active) ||
+			p.now().Sub(p.activeSince) < minActiveEndpointDuration) {
+		return
+	}
+	if !p.healthy(preferredEndpoint) {
+		p.logger.Warn("No healthy endpoints available")
+	}
+	p.logger.Info(
+		"Switching active endpoint",
+		zap.String("previousHost", p.endpoints[p.active].host),
+		zap.String("host", p.endpoints[preferred].host),
+	)
+	p.setActiveMetric(p.active, 0)
+	p.setActiveMetric(preferred, 1)
+	p.active = preferred
+	p.activeSince = p.now()
+}
+
+func (p *EndpointPool) setActiveMetric(index int, value float64) {
+	if p.metrics == nil {
+		return
+	}
+	p.metrics.activeEndpoint.WithLabelValues(p.labelValues(index)...).Set(value)
+}
+
+func (p *EndpointPool) labelValues(index int) []string {
+	return []string{
+		p.blockchainID.String(),
+		p.endpointType,
+		strconv.Itoa(index),
+		p.endpoints[index].host,
+	}
+}
+
+// RunHealthChecks periodically queries the latest block height from each endpoint to update their health
+// scores, until [ctx] is cancelled. Does nothing if the pool only has a single endpoint.
+func (p *EndpointPool) RunHealthChecks(ctx context.Context) {
+	if len(p.endpoints) < 2 {
+		return
+	}
+	clients := make([]*ethclient.Client, len(p.endpoints))
+	defer func() {
+		for _, client := range clients {
+			if client != nil {
+				client.Close()
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(p.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkHealth(ctx, clients)
+		}
+	}
+}
+
+func (p *EndpointPool) checkHealth(ctx context.Context, clients []*ethclient.Client) {
+	var (
+		lock    sync.Mutex
+		wg      sync.WaitGroup
+		heights = make(map[int]uint64)
+	)
+	for i, e := range p.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, endpointHealthCheckTimeout)
+			defer cancel()
+
+			if clients[i] == nil {
+				client, err := utils.NewEthClientWithConfig(
+					ctx,
+					e.config.BaseURL,
+					e.config.HTTPHeaders,
+					e.config.QueryParams,
+				)
+				if err != nil {
+					p.logger.Debug("Failed to connect to endpoint", zap.String("host", e.host), zap.Error(err))
+					p.RecordError(i)
+					return
+				}
+				clients[i] = client
+			}
+			start := time.Now()
+			height, err := clients[i].BlockNumber(ctx)
+			if err != nil {
+				p.logger.Debug("Endpoint health check failed", zap.String("host", e.host), zap.Error(err))
+				p.RecordError(i)
+				return
+			}
+			p.RecordSuccess(i, time.Since(start))
+
+			lock.Lock()
+			defer lock.Unlock()
+			heights[i] = height
+		}()
+	}
+	wg.Wait()
+	p.RecordHeights(heights)
+}
@@ -0,0 +1,272 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	basecfg "github.com/ryt-io/icm-services/config"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEndpointPool(
+	t *testing.T,
+	selection config.EndpointSelection,
+	baseURLs []string,
+	weights []uint64,
+) *EndpointPool {
+	endpoints := make([]config.WeightedEndpoint, len(baseURLs))
+	for i, baseURL := range baseURLs {
+		endpoints[i] = config.WeightedEndpoint{
+			APIConfig: basecfg.APIConfig{BaseURL: baseURL},
+			Weight:    weights[i],
+		}
+	}
+	pool, err := NewEndpointPool(
+		logging.NoLog{},
+		ids.GenerateTestID(),
+		RPCEndpointType,
+		endpoints,
+		selection,
+		10,
+		time.Second,
+		nil,
+	)
+	require.NoError(t, err)
+	return pool
+}
+
+func TestEndpointPoolSelection(t *testing.T) {
+	baseURLs := []string{"http://primary:9650", "http://fallback-1:9650", "http://fallback-2:9650"}
+	testCases := []struct {
+		name          string
+		selection     config.EndpointSelection
+		weights       []uint64
+		record        func(pool *EndpointPool)
+		expectedOrder []int
+	}{
+		{
+			name:          "ordered, all healthy",
+			selection:     config.ORDERED_ENDPOINT_SELECTION,
+			weights:       []uint64{1, 1, 1},
+			record:        func(*EndpointPool) {},
+			expectedOrder: []int{0, 1, 2},
+		},
+		{
+			name:      "ordered, primary erroring",
+			selection: config.ORDERED_ENDPOINT_SELECTION,
+			weights:   []uint64{1, 1, 1},
+			record: func(pool *EndpointPool) {
+				for i := 0; i < 4; i++ {
+					pool.RecordError(0)
+				}
+			},
+			expectedOrder: []int{1, 2, 0},
+		},
+		{
+			name:      "ordered, primary lagging",
+			selection: config.ORDERED_ENDPOINT_SELECTION,
+			weights:   []uint64{1, 1, 1},
+			record: func(pool *EndpointPool) {
+				pool.RecordHeights(map[int]uint64{0: 100, 1: 120, 2: 115})
+			},
+			expectedOrder: []int{1, 2, 0},
+		},
+		{
+			name:      "ordered, lagging primary failed health check",
+			selection: config.ORDERED_ENDPOINT_SELECTION,
+			weights:   []uint64{1, 1, 1},
+			record: func(pool *EndpointPool) {
+				pool.RecordHeights(map[int]uint64{0: 100, 1: 120, 2: 115})
+				pool.RecordHeights(map[int]uint64{1: 121, 2: 121})
+			},
+			expectedOrder: []int{0, 1, 2},
+		},
+		{
+			name:      "ordered, primary recovered",
+			selection: config.ORDERED_ENDPOINT_SELECTION,
+			weights:   []uint64{1, 1, 1},
+			record: func(pool *EndpointPool) {
+				for i := 0; i < 4; i++ {
+					pool.RecordError(0)
+				}
+				for i := 0; i < 2; i++ {
+					pool.RecordSuccess(0, time.Millisecond)
+				}
+			},
+			expectedOrder: []int{0, 1, 2},
+		},
+		{
+			name:          "weighted, by weight",
+			selection:     config.WEIGHTED_ENDPOINT_SELECTION,
+			weights:       []uint64{1, 3, 2},
+			record:        func(*EndpointPool) {},
+			expectedOrder: []int{1, 2, 0},
+		},
+		{
+			name:      "weighted, by latency",
+			selection: config.WEIGHTED_ENDPOINT_SELECTION,
+			weights:   []uint64{1, 1, 1},
+			record: func(pool *EndpointPool) {
+				pool.RecordSuccess(0, 2*time.Second)
+				pool.RecordSuccess(1, time.Second)
+				pool.RecordSuccess(2, 10*time.Millisecond)
+			},
+			expectedOrder: []int{2, 1, 0},
+		},
+		{
+			name:      "weighted, unhealthy last",
+			selection: config.WEIGHTED_ENDPOINT_SELECTION,
+			weights:   []uint64{1, 3, 2},
+			record: func(pool *EndpointPool) {
+				for i := 0; i < 4; i++ {
+					pool.RecordError(1)
+				}
+			},
+			expectedOrder: []int{2, 0, 1},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			pool := newTestEndpointPool(t, testCase.selection, baseURLs, testCase.weights)
+			// Let each endpoint stay active long enough to be replaced by a better one
+			now := time.Now()
+			pool.now = func() time.Time {
+				now = now.Add(minActiveEndpointDuration)
+				return now
+			}
+			testCase.record(pool)
+			require.Equal(t, testCase.expectedOrder, pool.SelectionOrder())
+			require.Equal(t, testCase.expectedOrder[0], pool.Active())
+		})
+	}
+}
+
+func TestEndpointPoolWeightedHysteresis(t *testing.T) {
+	pool := newTestEndpointPool(
+		t,
+		config.WEIGHTED_ENDPOINT_SELECTION,
+		[]string{"http://primary:9650", "http://fallback:9650"},
+		[]uint64{1, 1},
+	)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+	pool.activeSince = now
+	require.Equal(t, 0, pool.Active())
+
+	// A slightly better endpoint does not replace the active endpoint
+	pool.RecordSuccess(0, 110*time.Millisecond)
+	pool.RecordSuccess(1, 10*time.Millisecond)
+	now = now.Add(minActiveEndpointDuration)
+	pool.RecordSuccess(1, 10*time.Millisecond)
+	require.Equal(t, 0, pool.Active())
+	require.Equal(t, []int{0, 1}, pool.SelectionOrder())
+
+	// A much better endpoint does not replace the active endpoint before it has been active long enough
+	pool.activeSince = now
+	pool.RecordSuccess(0, 5*time.Second)
+	require.Equal(t, 0, pool.Active())
+	require.Equal(t, []int{0, 1}, pool.SelectionOrder())
+
+	now = now.Add(minActiveEndpointDuration)
+	pool.RecordSuccess(1, 10*time.Millisecond)
+	require.Equal(t, 1, pool.Active())
+	require.Equal(t, []int{1, 0}, pool.SelectionOrder())
+
+	// An unhealthy active endpoint is replaced immediately
+	for i := 0; i < 4; i++ {
+		pool.RecordError(1)
+	}
+	require.Equal(t, 0, pool.Active())
+	require.Equal(t, []int{0, 1}, pool.SelectionOrder())
+}
+
+// newTestRPCServer returns a server that responds to eth_blockNumber with [height], or with [statusCode]
+// if it is not http.StatusOK. If [height] is empty, it responds with a rate limiting JSON-RPC error.
+func newTestRPCServer(t *testing.T, statusCode int, height string, requests *atomic.Int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if statusCode != http.StatusOK {
+			w.WriteHeader(statusCode)
+			return
+		}
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if height == "" {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) +
+				`,"error":{"code":-32005,"message":"Rate limit exceeded"}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":"` + height + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFailoverEthClient(t *testing.T) {
+	testCases := []struct {
+		name          string
+		primaryStatus int
+	}{
+		{
+			name:          "error status",
+			primaryStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:          "json-rpc error",
+			primaryStatus: http.StatusOK,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFailoverEthClient(t, testCase.primaryStatus)
+		})
+	}
+}
+
+func testFailoverEthClient(t *testing.T, primaryStatus int) {
+	var primaryRequests, fallbackRequests atomic.Int32
+	primary := newTestRPCServer(t, primaryStatus, "", &primaryRequests)
+	fallback := newTestRPCServer(t, http.StatusOK, "0x10", &fallbackRequests)
+
+	pool := newTestEndpointPool(
+		t,
+		config.ORDERED_ENDPOINT_SELECTION,
+		[]string{primary.URL, fallback.URL},
+		[]uint64{1, 1},
+	)
+	client, err := NewFailoverEthClient(context.Background(), pool)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Requests fail over to the fallback endpoint until the primary endpoint is unhealthy
+	for i := 0; i < 4; i++ {
+		height, err := client.BlockNumber(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(0x10), height)
+	}
+	require.Equal(t, int32(4), primaryRequests.Load())
+	require.Equal(t, int32(4), fallbackRequests.Load())
+	require.Equal(t, 1, pool.Active())
+
+	// Requests are then sent directly to the fallback endpoint
+	height, err := client.BlockNumber(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x10), height)
+	require.Equal(t, int32(4), primaryRequests.Load())
+	require.Equal(t, int32(5), fallbackRequests.Load())
+}
@@ -0,0 +1,460 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/icm-services/utils"
+	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/accounts/abi/bind"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/core/types"
+	"go.uber.org/zap"
+)
+
+// Outcomes of failed message execution retries reported by the message_execution_retries metric
+const (
+	executionRetrySucceeded = "succeeded"
+	executionRetryFailed    = "failed"
+	executionRetryAbandoned = "abandoned"
+)
+
+// Maximum number of failed messages retried at each poll. The retries are sent concurrently, and the remaining
+// due retries are sent at the following polls.
+const maxRetriesPerPoll = 16
+
+// errRetryFailed is returned when a retried message execution failed again, or is expected to fail
+var errRetryFailed = errors.New("message execution failed")
+
+// failedMessage is a message whose execution failed, and that is awaiting a retry
+type failedMessage struct {
+	sourceBlockchainID ids.ID
+	message            teleportermessenger.TeleporterMessage
+	// Number of retries that have failed
+	attempts  int
+	nextRetry time.Time
+}
+
+// storedExecutionRetries is the state of an executionRetrier that is stored in the database
+type storedExecutionRetries struct {
+	NextBlock      uint64                `json:"nextBlock"`
+	FailedMessages []storedFailedMessage `json:"failedMessages"`
+}
+
+type storedFailedMessage struct {
+	MessageID          ids.ID                                `json:"messageID"`
+	SourceBlockchainID ids.ID                                `json:"sourceBlockchainID"`
+	Message            teleportermessenger.TeleporterMessage `json:"message"`
+	Attempts           int                                   `json:"attempts"`
+	NextRetry          time.Time                             `json:"nextRetry"`
+}
+
+// executionRetrier watches the destination blockchain's TeleporterMessenger for messages to the allow-listed
+// contracts whose execution failed, and retries their execution on a schedule. The next block to watch and the
+// scheduled retries are stored in the database, so that they are kept across restarts and reloads.
+type executionRetrier struct {
+	logger                  logging.Logger
+	destinationClient       CommonDestinationClient
+	client                  Client
+	destinationBlockchainID ids.ID
+	teleporterAddress       common.Address
+	executionRetry          *config.ExecutionRetry
+	metrics                 *DestinationClientMetrics
+	state                   *destinationState
+
+	// The next block to read MessageExecutionFailed events from, or 0 before the first block was read
+	nextBlock      uint64
+	failedMessages map[ids.ID]*failedMessage
+	// Whether the next block or the failed messages changed since they were last stored
+	dirty bool
+	now   func() time.Time
+}
+
+func newExecutionRetrier(
+	logger logging.Logger,
+	destinationClient CommonDestinationClient,
+	client Client,
+	destinationBlockchainID ids.ID,
+	executionRetry *config.ExecutionRetry,
+	metrics *DestinationClientMetrics,
+	state *destinationState,
+) (*executionRetrier, error) {
+	r := &executionRetrier{
+		logger:                  logger.With(zap.Stringer("teleporterAddress", executionRetry.GetTeleporterAddress())),
+		destinationClient:       destinationClient,
+		client:                  client,
+		destinationBlockchainID: destinationBlockchainID,
+		teleporterAddress:       executionRetry.GetTeleporterAddress(),
+		executionRetry:          executionRetry,
+		metrics:                 metrics,
+		state:                   state,
+		failedMessages:          make(map[ids.ID]*failedMessage),
+		now:                     time.Now,
+	}
+	if err := r.loadState(); err != nil {
+		return nil, fmt.Errorf("failed to load failed message executions: %w", err)
+	}
+	return r, nil
+}
+
+// loadState loads the stored next block and failed messages
+func (r *executionRetrier) loadState() error {
+	var stored storedExecutionRetries
+	ok, err := r.state.get(database.ExecutionRetriesKey, &stored)
+	if err != nil || !ok {
+		return err
+	}
+	r.nextBlock = stored.NextBlock
+	for _, storedMessage := range stored.FailedMessages {
+		r.failedMessages[storedMessage.MessageID] = &failedMessage{
+			sourceBlockchainID: storedMessage.SourceBlockchainID,
+			message:            storedMessage.Message,
+			attempts:           storedMessage.Attempts,
+			nextRetry:          storedMessage.NextRetry,
+		}
+	}
+	r.setFailedMessages()
+	return nil
+}
+
+// writeState stores the next block and the failed messages if they changed since they were last stored
+func (r *executionRetrier) writeState() {
+	if !r.dirty {
+		return
+	}
+	stored := storedExecutionRetries{
+		NextBlock:      r.nextBlock,
+		FailedMessages: make([]storedFailedMessage, 0, len(r.failedMessages)),
+	}
+	for messageID, failed := range r.failedMessages {
+		stored.FailedMessages = append(stored.FailedMessages, storedFailedMessage{
+			MessageID:          messageID,
+			SourceBlockchainID: failed.sourceBlockchainID,
+			Message:            failed.message,
+			Attempts:           failed.attempts,
+			NextRetry:          failed.nextRetry,
+		})
+	}
+	if err := r.state.put(database.ExecutionRetriesKey, stored); err != nil {
+		r.logger.Error("Failed to store failed message executions", zap.Error(err))
+		return
+	}
+	r.dirty = false
+}
+
+// run periodically watches for failed message executions and retries them until [stop] is closed
+func (r *executionRetrier) run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := r.watchFailedMessages(); err != nil {
+				r.logger.Warn("Failed to read failed message executions", zap.Error(err))
+			}
+			r.retryFailedMessages()
+			r.writeState()
+		}
+	}
+}
+
+// watchFailedMessages reads the MessageExecutionFailed events emitted since the previous call, and schedules
+// the retry of those of messages to allow-listed contracts. Events emitted before the first call are ignored.
+// The events are read in ranges of at most MaxBlocksPerRequest blocks, and the progress is kept after each range.
+func (r *executionRetrier) watchFailedMessages() error {
+	defer r.setFailedMessages()
+	blockCtx, blockCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	latestBlock, err := r.destinationClient.RPCClient().BlockNumber(blockCtx)
+	blockCtxCancel()
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+	if r.nextBlock == 0 {
+		r.nextBlock = latestBlock
+		r.dirty = true
+	}
+	if latestBlock < r.nextBlock {
+		return nil
+	}
+
+	filterer, err := teleportermessenger.NewTeleporterMessengerFilterer(r.teleporterAddress, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to get teleporter messenger contract: %w", err)
+	}
+	for r.nextBlock <= latestBlock {
+		endBlock := min(r.nextBlock+MaxBlocksPerRequest-1, latestBlock)
+		if err := r.watchBlockRange(filterer, r.nextBlock, endBlock); err != nil {
+			return err
+		}
+		r.nextBlock = endBlock + 1
+		r.dirty = true
+	}
+	return nil
+}
+
+// watchBlockRange schedules the retry of the failed executions of messages to allow-listed contracts
+// in the blocks from [startBlock] to [endBlock]
+func (r *executionRetrier) watchBlockRange(
+	filterer *teleportermessenger.TeleporterMessengerFilterer,
+	startBlock uint64,
+	endBlock uint64,
+) error {
+	filterCtx, filterCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	defer filterCtxCancel()
+	events, err := filterer.FilterMessageExecutionFailed(
+		&bind.FilterOpts{Start: startBlock, End: &endBlock, Context: filterCtx},
+		nil,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to filter MessageExecutionFailed events: %w", err)
+	}
+	defer events.Close()
+	for events.Next() {
+		event := events.Event
+		messageID := ids.ID(event.MessageID)
+		log := r.logger.With(
+			zap.Stringer("messageID", messageID),
+			zap.Stringer("destinationAddress", event.Message.DestinationAddress),
+		)
+		if !r.executionRetry.IsAllowedDestination(event.Message.DestinationAddress) {
+			log.Debug("Ignoring failed execution of message to destination address that is not allow-listed")
+			continue
+		}
+		if _, ok := r.failedMessages[messageID]; ok {
+			continue
+		}
+		log.Info("Scheduling retry of failed message execution")
+		r.failedMessages[messageID] = &failedMessage{
+			sourceBlockchainID: ids.ID(event.SourceBlockchainID),
+			message:            event.Message,
+			nextRetry:          r.now().Add(r.retryDelay(0)),
+		}
+	}
+	if err := events.Error(); err != nil {
+		return fmt.Errorf("failed to read MessageExecutionFailed events: %w", err)
+	}
+	return nil
+}
+
+// retryFailedMessages retries the execution of the failed messages whose retry is due, starting with those that
+// have been due the longest. The retries are sent concurrently from the signers in turn, and their outcomes are
+// recorded once all of them have completed.
+func (r *executionRetrier) retryFailedMessages() {
+	defer r.setFailedMessages()
+	now := r.now()
+	var due []ids.ID
+	for messageID, failed := range r.failedMessages {
+		if !now.Before(failed.nextRetry) {
+			due = append(due, messageID)
+		}
+	}
+	slices.SortFunc(due, func(a, b ids.ID) int {
+		return r.failedMessages[a].nextRetry.Compare(r.failedMessages[b].nextRetry)
+	})
+	if len(due) > maxRetriesPerPoll {
+		due = due[:maxRetriesPerPoll]
+	}
+
+	var (
+		senders = SenderAddresses(r.destinationClient)
+		logs    = make([]logging.Logger, len(due))
+		results = make([]retryResult, len(due))
+		wg      sync.WaitGroup
+	)
+	for i, messageID := range due {
+		failed := r.failedMessages[messageID]
+		logs[i] = r.logger.With(
+			zap.Stringer("messageID", messageID),
+			zap.Stringer("sourceBlockchainID", failed.sourceBlockchainID),
+			zap.Stringer("destinationAddress", failed.message.DestinationAddress),
+			zap.Int("attempt", failed.attempts+1),
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.attemptRetry(logs[i], messageID, failed, senders[i%len(senders)])
+		}()
+	}
+	wg.Wait()
+
+	for i, messageID := range due {
+		r.recordRetryResult(logs[i], messageID, results[i], now)
+	}
+}
+
+// retryResult is the outcome of an attempt to retry the execution of a failed message
+type retryResult struct {
+	// Error checking whether the message's execution still failed, in which case the retry was not sent
+	checkErr    error
+	stillFailed bool
+	txHash      common.Hash
+	err         error
+}
+
+// attemptRetry retries the execution of [failed] from [sender], unless it was already retried successfully
+func (r *executionRetrier) attemptRetry(
+	log logging.Logger,
+	messageID ids.ID,
+	failed *failedMessage,
+	sender common.Address,
+) retryResult {
+	stillFailed, err := r.stillFailed(messageID)
+	if err != nil || !stillFailed {
+		return retryResult{checkErr: err}
+	}
+	txHash, err := r.retry(log, failed, sender)
+	return retryResult{
+		stillFailed: true,
+		txHash:      txHash,
+		err:         err,
+	}
+}
+
+// recordRetryResult updates the failed message [messageID] with the outcome of its retry at [now]
+func (r *executionRetrier) recordRetryResult(log logging.Logger, messageID ids.ID, result retryResult, now time.Time) {
+	failed := r.failedMessages[messageID]
+	if result.checkErr != nil {
+		log.Warn("Failed to check whether message execution failed", zap.Error(result.checkErr))
+		return
+	}
+	if !result.stillFailed {
+		log.Info("Failed message execution was retried by another account")
+		delete(r.failedMessages, messageID)
+		r.dirty = true
+		return
+	}
+
+	err := result.err
+	if err != nil && !errors.Is(err, errRetryFailed) {
+		// The retry may not have been executed, so it is attempted again at the next poll
+		log.Warn("Failed to retry message execution", zap.Error(err))
+		return
+	}
+	if err == nil {
+		log.Info("Retried message execution", zap.Stringer("txID", result.txHash))
+		r.recordRetry(failed, executionRetrySucceeded)
+		delete(r.failedMessages, messageID)
+		r.dirty = true
+		return
+	}
+
+	r.dirty = true
+	failed.attempts++
+	if failed.attempts >= len(r.executionRetry.RetryDelaysSeconds) {
+		log.Error("Abandoning retries of failed message execution", zap.Error(err))
+		r.recordRetry(failed, executionRetryAbandoned)
+		delete(r.failedMessages, messageID)
+		return
+	}
+	failed.nextRetry = now.Add(r.retryDelay(failed.attempts))
+	log.Warn(
+		"Message execution retry failed",
+		zap.Time("nextRetry", failed.nextRetry),
+		zap.Error(err),
+	)
+	r.recordRetry(failed, executionRetryFailed)
+}
+
+// stillFailed returns whether the message's execution has not yet been retried successfully
+func (r *executionRetrier) stillFailed(messageID ids.ID) (bool, error) {
+	messenger, err := teleportermessenger.NewTeleporterMessengerCaller(r.teleporterAddress, r.client)
+	if err != nil {
+		return false, fmt.Errorf("failed to get teleporter messenger contract: %w", err)
+	}
+	callCtx, callCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	defer callCtxCancel()
+	messageHash, err := messenger.ReceivedFailedMessageHashes(&bind.CallOpts{Context: callCtx}, messageID)
+	if err != nil {
+		return false, err
+	}
+	return messageHash != [32]byte{}, nil
+}
+
+// retry sends a transaction from [sender] retrying the execution of [failed], and returns its hash. errRetryFailed
+// is returned if the execution failed again, or gas estimation failed, in which case the retry is not sent.
+func (r *executionRetrier) retry(
+	log logging.Logger,
+	failed *failedMessage,
+	sender common.Address,
+) (common.Hash, error) {
+	callData, err := teleportermessenger.PackRetryMessageExecution(failed.sourceBlockchainID, failed.message)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to pack retryMessageExecution call data: %w", err)
+	}
+	gasLimit := r.executionRetry.GasLimit
+	if gasLimit == 0 {
+		estimateCtx, estimateCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+		gasLimit, err = r.client.EstimateGas(estimateCtx, ethereum.CallMsg{
+			From: sender,
+			To:   &r.teleporterAddress,
+			Data: callData,
+		})
+		estimateCtxCancel()
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("%w: failed to estimate gas: %w", errRetryFailed, err)
+		}
+	}
+
+	log.Info("Retrying message execution", zap.Uint64("gasLimit", gasLimit))
+	receipt, err := SendTx(
+		r.destinationClient,
+		nil,
+		set.Of(sender),
+		r.teleporterAddress.Hex(),
+		gasLimit,
+		callData,
+		r.destinationClient.TxInclusionTimeout(),
+		nil,
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt.TxHash, fmt.Errorf(
+			"%w: transaction %s failed with status: %d",
+			errRetryFailed,
+			receipt.TxHash,
+			receipt.Status,
+		)
+	}
+	return receipt.TxHash, nil
+}
+
+// retryDelay returns the delay before the retry following [attempts] failed retries
+func (r *executionRetrier) retryDelay(attempts int) time.Duration {
+	return time.Duration(r.executionRetry.RetryDelaysSeconds[attempts]) * time.Second
+}
+
+func (r *executionRetrier) recordRetry(failed *failedMessage, outcome string) {
+	if r.metrics == nil {
+		return
+	}
+	r.metrics.executionRetries.WithLabelValues(
+		r.destinationBlockchainID.String(),
+		failed.message.DestinationAddress.String(),
+		outcome,
+	).Inc()
+}
+
+func (r *executionRetrier) setFailedMessages() {
+	if r.metrics == nil {
+		return
+	}
+	r.metrics.failedMessages.WithLabelValues(r.destinationBlockchainID.String()).Set(float64(len(r.failedMessages)))
+}
@@ -0,0 +1,359 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/relayer/config"
+	mock_ethclient "github.com/ryt-io/icm-services/vms/evm/mocks"
+	"github.com/ryt-io/icm-services/vms/evm/signer"
+	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	testRetryTeleporterAddress  = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	testRetryAllowedDestination = common.HexToAddress("0x27aE10273D17Cd7e80de8580A51f476960626e5f")
+)
+
+func newTestExecutionRetrier(
+	t *testing.T,
+	mockRPCClient *mock_ethclient.MockDestinationRPCClient,
+	mockClient *mock_ethclient.MockClient,
+	state *destinationState,
+) (*executionRetrier, common.Address) {
+	destinationBlockchain := config.TestValidDestinationBlockchainConfig
+	txSigners, err := signer.NewTxSigners([]string{destinationBlockchain.AccountPrivateKey})
+	require.NoError(t, err)
+	executionRetry := &config.ExecutionRetry{
+		TeleporterAddress:           testRetryTeleporterAddress.Hex(),
+		AllowedDestinationAddresses: []string{testRetryAllowedDestination.Hex()},
+	}
+	destinationBlockchain.ExecutionRetry = executionRetry
+	require.NoError(t, destinationBlockchain.Validate())
+
+	destClient := newTestSendingDestinationClient(t, txSigners[0], mockRPCClient, mockClient)
+	retrier, err := newExecutionRetrier(
+		logging.NoLog{},
+		destClient,
+		mockClient,
+		ids.GenerateTestID(),
+		executionRetry,
+		nil,
+		state,
+	)
+	require.NoError(t, err)
+	return retrier, txSigners[0].Address()
+}
+
+func testFailedMessage(destinationAddress common.Address) teleportermessenger.TeleporterMessage {
+	return teleportermessenger.TeleporterMessage{
+		MessageNonce:            big.NewInt(1),
+		OriginSenderAddress:     common.HexToAddress("0x0A1b9bB0bA2dA7e6F55B7A7bC1d4A5A77ed2E6A3"),
+		DestinationBlockchainID: ids.GenerateTestID(),
+		DestinationAddress:      destinationAddress,
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte("message"),
+	}
+}
+
+func TestWatchFailedMessages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRPCClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+	mockClient := mock_ethclient.NewMockClient(ctrl)
+	retrier, _ := newTestExecutionRetrier(t, mockRPCClient, mockClient, nil)
+	now := time.Now()
+	retrier.now = func() time.Time { return now }
+	retrier.nextBlock = 10
+
+	teleporterABI, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	event := teleporterABI.Events["MessageExecutionFailed"]
+	sourceBlockchainID := ids.GenerateTestID()
+	executionFailedLog := func(messageID ids.ID, message teleportermessenger.TeleporterMessage) types.Log {
+		data, err := event.Inputs.NonIndexed().Pack(message)
+		require.NoError(t, err)
+		return types.Log{
+			Address: testRetryTeleporterAddress,
+			Topics:  []common.Hash{event.ID, common.Hash(messageID), common.Hash(sourceBlockchainID)},
+			Data:    data,
+		}
+	}
+	allowedMessageID := ids.GenerateTestID()
+	allowedMessage := testFailedMessage(testRetryAllowedDestination)
+	otherMessageID := ids.GenerateTestID()
+	otherMessage := testFailedMessage(common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"))
+
+	mockRPCClient.EXPECT().BlockNumber(gomock.Any()).Return(uint64(12), nil)
+	mockClient.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+			require.Equal(t, big.NewInt(10), query.FromBlock)
+			require.Equal(t, big.NewInt(12), query.ToBlock)
+			return []types.Log{
+				executionFailedLog(allowedMessageID, allowedMessage),
+				executionFailedLog(otherMessageID, otherMessage),
+			}, nil
+		},
+	)
+
+	require.NoError(t, retrier.watchFailedMessages())
+
+	require.Equal(t, uint64(13), retrier.nextBlock)
+	require.Len(t, retrier.failedMessages, 1)
+	failed := retrier.failedMessages[allowedMessageID]
+	require.NotNil(t, failed)
+	require.Equal(t, sourceBlockchainID, failed.sourceBlockchainID)
+	require.Equal(t, allowedMessage, failed.message)
+	require.Equal(t, now.Add(time.Minute), failed.nextRetry)
+}
+
+func TestWatchFailedMessagesBlockRanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRPCClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+	mockClient := mock_ethclient.NewMockClient(ctrl)
+	retrier, _ := newTestExecutionRetrier(t, mockRPCClient, mockClient, nil)
+	retrier.nextBlock = 1
+	latestBlock := uint64(MaxBlocksPerRequest + 10)
+
+	mockRPCClient.EXPECT().BlockNumber(gomock.Any()).Return(latestBlock, nil)
+	gomock.InOrder(
+		mockClient.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+				require.Equal(t, big.NewInt(1), query.FromBlock)
+				require.Equal(t, big.NewInt(MaxBlocksPerRequest), query.ToBlock)
+				return nil, nil
+			},
+		),
+		mockClient.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+				require.Equal(t, big.NewInt(MaxBlocksPerRequest+1), query.FromBlock)
+				require.Equal(t, new(big.Int).SetUint64(latestBlock), query.ToBlock)
+				return nil, errors.New("request failed")
+			},
+		),
+	)
+
+	// The progress of the ranges that were read is kept
+	require.Error(t, retrier.watchFailedMessages())
+	require.Equal(t, uint64(MaxBlocksPerRequest+1), retrier.nextBlock)
+}
+
+func TestExecutionRetrierStoredState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRPCClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+	mockClient := mock_ethclient.NewMockClient(ctrl)
+	destinationBlockchainID := ids.GenerateTestID()
+	db, err := database.NewJSONFileStorage(
+		logging.NoLog{},
+		t.TempDir(),
+		[]database.RelayerID{database.NewDestinationStateID(destinationBlockchainID)},
+	)
+	require.NoError(t, err)
+	state := newDestinationState(db, destinationBlockchainID)
+
+	retrier, _ := newTestExecutionRetrier(t, mockRPCClient, mockClient, state)
+	messageID := ids.GenerateTestID()
+	failed := &failedMessage{
+		sourceBlockchainID: ids.GenerateTestID(),
+		message:            testFailedMessage(testRetryAllowedDestination),
+		attempts:           1,
+		nextRetry:          time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	retrier.nextBlock = 13
+	retrier.failedMessages[messageID] = failed
+	retrier.dirty = true
+	retrier.writeState()
+
+	reloaded, _ := newTestExecutionRetrier(t, mockRPCClient, mockClient, state)
+	require.Equal(t, uint64(13), reloaded.nextBlock)
+	require.Equal(t, map[ids.ID]*failedMessage{messageID: failed}, reloaded.failedMessages)
+}
+
+func TestRetryFailedMessages(t *testing.T) {
+	testCases := []struct {
+		name             string
+		attempts         int
+		notDue           bool
+		executed         bool
+		estimateErr      error
+		receiptStatus    uint64
+		expectRetry      bool
+		expectRemoved    bool
+		expectedAttempts int
+	}{
+		{
+			name:   "not due",
+			notDue: true,
+		},
+		{
+			name:          "executed by another account",
+			executed:      true,
+			expectRemoved: true,
+		},
+		{
+			name:          "retry succeeded",
+			receiptStatus: types.ReceiptStatusSuccessful,
+			expectRetry:   true,
+			expectRemoved: true,
+		},
+		{
+			name:             "retry failed",
+			receiptStatus:    types.ReceiptStatusFailed,
+			expectRetry:      true,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "gas estimation failed",
+			estimateErr:      errors.New("execution reverted"),
+			expectedAttempts: 1,
+		},
+		{
+			name:          "retries abandoned",
+			attempts:      2,
+			receiptStatus: types.ReceiptStatusFailed,
+			expectRetry:   true,
+			expectRemoved: true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRPCClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+			mockClient := mock_ethclient.NewMockClient(ctrl)
+			retrier, senderAddress := newTestExecutionRetrier(t, mockRPCClient, mockClient, nil)
+			now := time.Now()
+			retrier.now = func() time.Time { return now }
+
+			messageID := ids.GenerateTestID()
+			sourceBlockchainID := ids.GenerateTestID()
+			message := testFailedMessage(testRetryAllowedDestination)
+			nextRetry := now
+			if test.notDue {
+				nextRetry = now.Add(time.Second)
+			}
+			retrier.failedMessages[messageID] = &failedMessage{
+				sourceBlockchainID: sourceBlockchainID,
+				message:            message,
+				attempts:           test.attempts,
+				nextRetry:          nextRetry,
+			}
+
+			checks := 1
+			if test.notDue {
+				checks = 0
+			}
+			teleporterABI, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+			require.NoError(t, err)
+			failedHashInput, err := teleporterABI.Pack("receivedFailedMessageHashes", messageID)
+			require.NoError(t, err)
+			var failedHash [32]byte
+			if !test.executed {
+				failedHash = common.HexToHash("0x01")
+			}
+			failedHashOutput, err := teleporterABI.PackOutput("receivedFailedMessageHashes", failedHash)
+			require.NoError(t, err)
+			mockClient.EXPECT().
+				CallContract(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+					To:   &testRetryTeleporterAddress,
+					Data: failedHashInput,
+				}), gomock.Any()).
+				Return(failedHashOutput, nil).
+				Times(checks)
+
+			estimates := 0
+			if !test.notDue && !test.executed {
+				estimates = 1
+			}
+			retryInput, err := teleportermessenger.PackRetryMessageExecution(sourceBlockchainID, message)
+			require.NoError(t, err)
+			mockClient.EXPECT().
+				EstimateGas(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+					From: senderAddress,
+					To:   &testRetryTeleporterAddress,
+					Data: retryInput,
+				})).
+				Return(uint64(200_000), test.estimateErr).
+				Times(estimates)
+
+			retries := 0
+			if test.expectRetry {
+				retries = 1
+			}
+			mockRPCClient.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(1), nil).Times(retries)
+			mockRPCClient.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, tx *types.Transaction) error {
+					require.Equal(t, testRetryTeleporterAddress, *tx.To())
+					require.Equal(t, retryInput, tx.Data())
+					require.Equal(t, uint64(200_000), tx.Gas())
+					return nil
+				},
+			).Times(retries)
+			mockRPCClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(
+				&types.Receipt{Status: test.receiptStatus},
+				nil,
+			).Times(retries)
+
+			retrier.retryFailedMessages()
+
+			failed, ok := retrier.failedMessages[messageID]
+			if test.expectRemoved {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, test.expectedAttempts, failed.attempts)
+			if test.expectedAttempts > 0 {
+				require.Equal(t, now.Add(10*time.Minute), failed.nextRetry)
+			}
+		})
+	}
+}
+
+func TestRetryFailedMessagesPerPoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRPCClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+	mockClient := mock_ethclient.NewMockClient(ctrl)
+	retrier, _ := newTestExecutionRetrier(t, mockRPCClient, mockClient, nil)
+	now := time.Now()
+	retrier.now = func() time.Time { return now }
+
+	// The messages were all retried by another account, and the one due the latest is left for the next poll
+	var latestMessageID ids.ID
+	for i := 0; i <= maxRetriesPerPoll; i++ {
+		messageID := ids.GenerateTestID()
+		retrier.failedMessages[messageID] = &failedMessage{
+			sourceBlockchainID: ids.GenerateTestID(),
+			message:            testFailedMessage(testRetryAllowedDestination),
+			nextRetry:          now.Add(-time.Duration(maxRetriesPerPoll-i) * time.Second),
+		}
+		latestMessageID = messageID
+	}
+	teleporterABI, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	executedHashOutput, err := teleporterABI.PackOutput("receivedFailedMessageHashes", [32]byte{})
+	require.NoError(t, err)
+	mockClient.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(executedHashOutput, nil).
+		Times(maxRetriesPerPoll)
+
+	retrier.retryFailedMessages()
+
+	require.Len(t, retrier.failedMessages, 1)
+	require.Contains(t, retrier.failedMessages, latestMessageID)
+}
@@ -8,6 +8,7 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ryt-io/ryt-v2/ids"
@@ -51,6 +52,7 @@ func (p *PrivateKeySigner) SignTx(tx *types.Transaction, evmChainID *big.Int) (*
 // Implements vms.DestinationClient interface.
 type ExternalEVMDestinationClient struct {
 	ethClient       EthClient
+	closeRPC        func()
 	logger          logging.Logger
 	chainID         string
 	evmChainID      *big.Int
@@ -64,6 +66,9 @@ type ExternalEVMDestinationClient struct {
 
 	// Concurrent senders for transaction processing
 	concurrentSenders []*readonlyConcurrentSigner
+	// Closed to stop the workers of the concurrent senders
+	stop      chan struct{}
+	closeOnce sync.Once
 }
 
 // NewExternalEVMDestinationClient creates a new external EVM destination client.
@@ -114,6 +119,7 @@ func NewExternalEVMDestinationClient(
 	}
 	destClient := &ExternalEVMDestinationClient{
 		ethClient:          wrappedClient,
+		closeRPC:           rawClient.Close,
 		logger:             logger,
 		chainID:            chainID,
 		evmChainID:         evmChainID,
@@ -122,6 +128,7 @@ func NewExternalEVMDestinationClient(
 		blockGasLimit:      blockGasLimit,
 		gasFeeConfig:       gasFeeData,
 		txInclusionTimeout: time.Duration(txInclusionTimeoutSeconds) * time.Second,
+		stop:               make(chan struct{}),
 	}
 
 	// Initialize concurrent senders from private keys
@@ -148,6 +155,7 @@ func NewExternalEVMDestinationClient(
 			messageChan:       make(chan txData),
 			queuedTxSemaphore: make(chan struct{}, externalEVMPoolTxsPerAccount),
 			destinationClient: destClient,
+			stop:              destClient.stop,
 		}
 
 		// Start the transaction processing goroutine
@@ -201,6 +209,11 @@ func (c *ExternalEVMDestinationClient) TxInclusionTimeout() time.Duration {
 	return c.txInclusionTimeout
 }
 
+// TxLimiter returns nil, since transactions to external EVM chains are not limited.
+func (c *ExternalEVMDestinationClient) TxLimiter() *txLimiter {
+	return nil
+}
+
 // getFeePerGas calculates the gas fee cap and gas tip cap for transactions.
 // nolint:unused
 func (c *ExternalEVMDestinationClient) getFeePerGas() (*big.Int, *big.Int, error) {
@@ -215,8 +228,20 @@ func (c *ExternalEVMDestinationClient) SendTx(
 	toAddress string,
 	gasLimit uint64,
 	callData []byte,
+	onSent func(txHash common.Hash),
 ) (*types.Receipt, error) {
-	return SendTx(c, signedMessage, deliverers, toAddress, gasLimit, callData, c.txInclusionTimeout)
+	return SendTx(c, signedMessage, deliverers, toAddress, gasLimit, callData, c.txInclusionTimeout, onSent)
+}
+
+// SimulateTx calls the transaction that SendTx would send without sending it.
+func (c *ExternalEVMDestinationClient) SimulateTx(
+	signedMessage *avalancheWarp.Message,
+	deliverers set.Set[common.Address],
+	toAddress string,
+	gasLimit uint64,
+	callData []byte,
+) error {
+	return SimulateTx(c, signedMessage, deliverers, toAddress, gasLimit, callData)
 }
 
 // SenderAddresses returns the addresses of all senders.
@@ -224,6 +249,21 @@ func (c *ExternalEVMDestinationClient) SenderAddresses() []common.Address {
 	return SenderAddresses(c)
 }
 
+// Health returns nil, since the nonces of external EVM senders are not reconciled.
+func (c *ExternalEVMDestinationClient) Health() error {
+	return nil
+}
+
+// Close stops the workers of the client's concurrent senders and closes its RPC connection.
+func (c *ExternalEVMDestinationClient) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		if c.closeRPC != nil {
+			c.closeRPC()
+		}
+	})
+}
+
 // Client returns the underlying ethclient.
 func (c *ExternalEVMDestinationClient) Client() Client {
 	return c.ethClient
@@ -0,0 +1,268 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/icm-services/utils"
+	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/core/types"
+	"github.com/ryt-io/libevm/ethclient"
+	"github.com/ryt-io/libevm/rpc"
+)
+
+// NewFailoverEthClient returns an ethclient.Client that sends each request to the active endpoint of
+// [endpoints], and retries it on the other endpoints if it fails. If the pool only has a single endpoint,
+// the client connects to it directly.
+func NewFailoverEthClient(ctx context.Context, endpoints *EndpointPool) (*ethclient.Client, error) {
+	if endpoints.Len() == 1 {
+		cfg := endpoints.Config(0)
+		return utils.NewEthClientWithConfig(ctx, cfg.BaseURL, cfg.HTTPHeaders, cfg.QueryParams)
+	}
+	// Each endpoint's headers and query parameters are applied by the transport, so that they are
+	// only sent to the endpoint they are configured for.
+	httpClient := &http.Client{
+		Transport: &failoverTransport{endpoints: endpoints},
+		// Allow each endpoint as long as the default client would allow a single request
+		Timeout: time.Duration(endpoints.Len()) * http.DefaultClient.Timeout,
+	}
+	client, err := rpc.DialOptions(ctx, endpoints.Config(0).BaseURL, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// failoverTransport is an [http.RoundTripper] that routes requests between the endpoints of an EndpointPool
+type failoverTransport struct {
+	endpoints *EndpointPool
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	transport := http.DefaultClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	order := t.endpoints.SelectionOrder()
+	var errs []error
+	for attempt, index := range order {
+		endpointReq := req.Clone(req.Context())
+		endpointReq.URL = t.endpoints.URL(index)
+		endpointReq.Host = ""
+		for key, value := range t.endpoints.Config(index).HTTPHeaders {
+			endpointReq.Header.Set(key, value)
+		}
+		endpointReq.Body = io.NopCloser(bytes.NewReader(body))
+		endpointReq.ContentLength = int64(len(body))
+
+		start := time.Now()
+		resp, err := transport.RoundTrip(endpointReq)
+		if req.Context().Err() != nil {
+			// The request was cancelled by the caller, which says nothing about the endpoint's health
+			return resp, err
+		}
+		endpointErr := err
+		if err == nil {
+			endpointErr = endpointResponseError(resp)
+		}
+		if endpointErr == nil {
+			t.endpoints.RecordSuccess(index, time.Since(start))
+			return resp, nil
+		}
+		t.endpoints.RecordError(index)
+		if attempt == len(order)-1 {
+			return resp, err
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+		errs = append(errs, fmt.Errorf("endpoint %s: %w", endpointReq.URL.Host, endpointErr))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// endpointJSONRPCErrors are fragments of the messages of JSON-RPC errors that are caused by the endpoint
+// rather than by the request, such as rate limiting or an endpoint that has not synced the requested block.
+// Other JSON-RPC errors, such as reverted calls, are results of the request.
+var endpointJSONRPCErrors = []string{
+	"rate limit",
+	"too many requests",
+	"header not found",
+	"missing trie node",
+}
+
+// endpointResponseError returns an error if [resp] indicates a problem with the endpoint that sent it, either
+// by its status code, or by a JSON-RPC error in a successful response. The body of [resp] is buffered so that
+// it can still be read by the caller.
+func endpointResponseError(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	type jsonRPCResponse struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	// Bodies that are not JSON-RPC responses are left for the client to report
+	var responses []jsonRPCResponse
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &responses); err != nil {
+			return nil
+		}
+	} else {
+		var response jsonRPCResponse
+		if err := json.Unmarshal(trimmed, &response); err != nil {
+			return nil
+		}
+		responses = append(responses, response)
+	}
+	for _, response := range responses {
+		if response.Error == nil {
+			continue
+		}
+		message := strings.ToLower(response.Error.Message)
+		for _, fragment := range endpointJSONRPCErrors {
+			if strings.Contains(message, fragment) {
+				return fmt.Errorf("json-rpc error %d: %s", response.Error.Code, response.Error.Message)
+			}
+		}
+	}
+	return nil
+}
+
+// FailoverWSClient subscribes to new heads on the active endpoint of an EndpointPool, trying the other
+// endpoints if the subscription can not be established. Errors from established subscriptions are recorded
+// against the endpoint, so that the Subscriber's resubscription fails over if the endpoint is unhealthy.
+type FailoverWSClient struct {
+	endpoints *EndpointPool
+
+	lock    sync.Mutex
+	clients map[int]*ethclient.Client
+}
+
+func NewFailoverWSClient(endpoints *EndpointPool) *FailoverWSClient {
+	return &FailoverWSClient{
+		endpoints: endpoints,
+		clients:   make(map[int]*ethclient.Client),
+	}
+}
+
+func (c *FailoverWSClient) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *types.Header,
+) (ethereum.Subscription, error) {
+	var errs []error
+	for _, index := range c.endpoints.SelectionOrder() {
+		start := time.Now()
+		sub, err := c.subscribeNewHead(ctx, index, ch)
+		if err == nil {
+			c.endpoints.RecordSuccess(index, time.Since(start))
+			return c.watch(index, sub), nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		c.endpoints.RecordError(index)
+		c.closeClient(index)
+		errs = append(errs, fmt.Errorf("endpoint %s: %w", c.endpoints.URL(index).Host, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (c *FailoverWSClient) subscribeNewHead(
+	ctx context.Context,
+	index int,
+	ch chan<- *types.Header,
+) (ethereum.Subscription, error) {
+	c.lock.Lock()
+	client, ok := c.clients[index]
+	c.lock.Unlock()
+	if !ok {
+		cfg := c.endpoints.Config(index)
+		var err error
+		client, err = utils.NewEthClientWithConfig(ctx, cfg.BaseURL, cfg.HTTPHeaders, cfg.QueryParams)
+		if err != nil {
+			return nil, err
+		}
+		c.lock.Lock()
+		c.clients[index] = client
+		c.lock.Unlock()
+	}
+	return client.SubscribeNewHead(ctx, ch)
+}
+
+// watch wraps [sub] to record its error against the endpoint at [index]
+func (c *FailoverWSClient) watch(index int, sub ethereum.Subscription) ethereum.Subscription {
+	wrapped := &failoverSubscription{
+		Subscription: sub,
+		err:          make(chan error, 1),
+	}
+	go func() {
+		defer close(wrapped.err)
+		for err := range sub.Err() {
+			c.endpoints.RecordError(index)
+			c.closeClient(index)
+			wrapped.err <- err
+		}
+	}()
+	return wrapped
+}
+
+func (c *FailoverWSClient) closeClient(index int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if client, ok := c.clients[index]; ok {
+		client.Close()
+		delete(c.clients, index)
+	}
+}
+
+// Close closes the connections to all endpoints
+func (c *FailoverWSClient) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for index, client := range c.clients {
+		client.Close()
+		delete(c.clients, index)
+	}
+}
+
+// failoverSubscription is the [ethereum.Subscription] returned by FailoverWSClient
+type failoverSubscription struct {
+	ethereum.Subscription
+	err chan error
+}
+
+func (s *failoverSubscription) Err() <-chan error {
+	return s.err
+}
@@ -0,0 +1,246 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/avalanchego/graft/subnet-evm/rpc"
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/icm-services/utils"
+	"github.com/ryt-io/libevm/core/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// Gas used by a transfer of the native token to an externally owned account
+	selfTransferGas = 21_000
+
+	// Methods of filling nonce gaps reported in the nonce metrics
+	resubmitGapFillMethod     = "resubmit"
+	selfTransferGapFillMethod = "self-transfer"
+)
+
+// trackSentTx records [tx] as the latest transaction sent with its nonce, so that it can be resubmitted if it is
+// dropped from the mempool. Must be called with nonceLock held.
+func (s *concurrentSigner) trackSentTx(tx *types.Transaction) {
+	if s.sentTxs == nil {
+		s.sentTxs = make(map[uint64]*types.Transaction)
+	}
+	s.sentTxs[tx.Nonce()] = tx
+}
+
+// untrackSentTx stops tracking the transaction sent with [nonce] once its receipt is no longer awaited
+func (s *concurrentSigner) untrackSentTx(nonce uint64) {
+	s.nonceLock.Lock()
+	defer s.nonceLock.Unlock()
+
+	delete(s.sentTxs, nonce)
+}
+
+// reconcileNonces periodically reconciles the local nonce with the destination chain until [stop] is closed
+func (s *concurrentSigner) reconcileNonces(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.reconcileNonce()
+		}
+	}
+}
+
+// reconcileNonce compares the local nonce with the signer's latest and pending nonces on the destination chain.
+// If transactions were included without the local nonce being incremented, the local nonce skips the used nonces.
+// If transactions are missing from the mempool, later transactions can not be included until the missing nonces
+// are filled, either by resubmitting the missing transactions, or by self-transfers if they are no longer known.
+func (s *concurrentSigner) reconcileNonce() {
+	address := s.signer.Address()
+	rpcClient := s.destinationClient.RPCClient()
+
+	// The nonces are fetched without holding nonceLock, so that transactions can be sent in the meantime.
+	// Only the nonces sent before they are fetched are considered missing.
+	s.nonceLock.Lock()
+	sentNonce := s.currentNonce
+	s.nonceLock.Unlock()
+
+	latestNonceCtx, latestNonceCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	latestNonce, err := rpcClient.NonceAt(latestNonceCtx, address, nil)
+	latestNonceCtxCancel()
+	if err != nil {
+		s.logger.Warn("Failed to get latest nonce", zap.Error(err))
+		return
+	}
+	pendingNonceCtx, pendingNonceCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	pendingNonce, err := rpcClient.NonceAt(pendingNonceCtx, address, big.NewInt(int64(rpc.PendingBlockNumber)))
+	pendingNonceCtxCancel()
+	if err != nil {
+		s.logger.Warn("Failed to get pending nonce", zap.Error(err))
+		return
+	}
+
+	s.nonceLock.Lock()
+	missing := s.updateNonce(sentNonce, latestNonce, pendingNonce)
+	s.nonceLock.Unlock()
+
+	s.fillNonceGap(missing)
+}
+
+// missingNonce is a nonce that is missing from the mempool, along with the transaction that was last sent
+// with it, or nil if it is no longer known
+type missingNonce struct {
+	nonce uint64
+	tx    *types.Transaction
+}
+
+// updateNonce updates the local nonce with the latest and pending nonces fetched once the local nonce was
+// [sentNonce], and returns the nonces that are missing from the mempool. Must be called with nonceLock held.
+func (s *concurrentSigner) updateNonce(sentNonce, latestNonce, pendingNonce uint64) []missingNonce {
+	// Transactions with nonces below the latest nonce are included, so they no longer need to be resubmitted
+	for nonce := range s.sentTxs {
+		if nonce < latestNonce {
+			delete(s.sentTxs, nonce)
+		}
+	}
+
+	if s.metrics != nil {
+		drift := new(big.Int).Sub(new(big.Int).SetUint64(s.currentNonce), new(big.Int).SetUint64(pendingNonce))
+		driftValue, _ := new(big.Float).SetInt(drift).Float64()
+		s.metrics.nonceDrift.WithLabelValues(s.metricLabelValues()...).Set(driftValue)
+	}
+
+	log := s.logger.With(
+		zap.Uint64("localNonce", s.currentNonce),
+		zap.Uint64("latestNonce", latestNonce),
+		zap.Uint64("pendingNonce", pendingNonce),
+	)
+	switch {
+	case s.currentNonce < pendingNonce:
+		// Transactions were accepted even though sending them returned an error, or were sent by another
+		// process using the same key. Transactions sent with the used nonces would be rejected.
+		log.Warn("Local nonce is behind the pending nonce, skipping the used nonces")
+		s.currentNonce = pendingNonce
+		s.nonceGapStart = nil
+		s.nonceErr = nil
+	case sentNonce > pendingNonce:
+		// The mempool may briefly lag behind the sent transactions, so the gap is only filled once it has
+		// been observed by consecutive reconciliations
+		if s.nonceGapStart == nil || *s.nonceGapStart != pendingNonce {
+			log.Info("Detected nonce gap, filling it if it persists")
+			s.nonceGapStart = &pendingNonce
+			return nil
+		}
+		s.nonceErr = fmt.Errorf("nonces %d to %d are missing from the mempool", pendingNonce, sentNonce-1)
+		log.Warn("Transactions are missing from the mempool, filling the nonce gap")
+		missing := make([]missingNonce, 0, sentNonce-pendingNonce)
+		for nonce := pendingNonce; nonce < sentNonce; nonce++ {
+			missing = append(missing, missingNonce{nonce: nonce, tx: s.sentTxs[nonce]})
+		}
+		return missing
+	default:
+		s.nonceGapStart = nil
+		s.nonceErr = nil
+	}
+	return nil
+}
+
+// fillNonceGap sends a transaction for each of the [missing] nonces. Self-transfers count against the
+// destination's spend limits that apply to all source blockchains, and the gap is no longer filled once
+// one of them is exhausted.
+func (s *concurrentSigner) fillNonceGap(missing []missingNonce) {
+	for _, missingNonce := range missing {
+		log := s.logger.With(zap.Uint64("nonce", missingNonce.nonce))
+		method := resubmitGapFillMethod
+		tx := missingNonce.tx
+		var reservation *spendReservation
+		if tx == nil {
+			// The transaction's receipt is no longer awaited, so its message has already failed
+			method = selfTransferGapFillMethod
+			var err error
+			tx, reservation, err = s.selfTransferTx(missingNonce.nonce)
+			var exhausted *SpendLimitExhaustedError
+			if errors.As(err, &exhausted) {
+				log.Warn("Spend limit reached, not filling the rest of the nonce gap", zap.Error(err))
+				return
+			}
+			if err != nil {
+				log.Error("Failed to create self-transfer to fill nonce gap", zap.Error(err))
+				continue
+			}
+		}
+
+		sendTxCtx, sendTxCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+		err := s.destinationClient.RPCClient().SendTransaction(sendTxCtx, tx)
+		sendTxCtxCancel()
+		if err != nil {
+			// Only release the reserved spend if the transaction was not sent
+			reservation.settle(big.NewInt(0))
+			log.Warn(
+				"Failed to fill nonce gap",
+				zap.String("method", method),
+				zap.Stringer("txID", tx.Hash()),
+				zap.Error(err),
+			)
+			continue
+		}
+		log.Info(
+			"Filled nonce gap",
+			zap.String("method", method),
+			zap.Stringer("txID", tx.Hash()),
+		)
+		if s.metrics != nil {
+			s.metrics.nonceGapsFilled.WithLabelValues(append(s.metricLabelValues(), method)...).Inc()
+		}
+	}
+}
+
+// selfTransferTx returns a signed transfer of zero value from the signer to itself with [nonce], and reserves
+// its maximum fee against the destination's spend limits. The receipt of the transfer is not awaited, so the
+// reservation is kept once it is sent.
+func (s *concurrentSigner) selfTransferTx(nonce uint64) (*types.Transaction, *spendReservation, error) {
+	gasFeeCap, gasTipCap, reservation, err := getFeePerGasWithinLimits(s.destinationClient, ids.Empty, selfTransferGas)
+	if err != nil {
+		return nil, nil, err
+	}
+	to := s.signer.Address()
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   s.destinationClient.EVMChainID(),
+		Nonce:     nonce,
+		To:        &to,
+		Gas:       selfTransferGas,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Value:     big.NewInt(0),
+	})
+	signedTx, err := s.signer.SignTx(tx, s.destinationClient.EVMChainID())
+	if err != nil {
+		reservation.settle(big.NewInt(0))
+		return nil, nil, err
+	}
+	return signedTx, reservation, nil
+}
+
+func (s *concurrentSigner) metricLabelValues() []string {
+	return []string{s.destinationBlockchainID.String(), s.signer.Address().String()}
+}
+
+// SignersHealth returns an error if the nonce of any of the signers is out of sync with the destination chain
+func SignersHealth(c CommonDestinationClient) error {
+	var errs []error
+	for _, concurrentSigner := range c.ConcurrentSigners() {
+		concurrentSigner.nonceLock.Lock()
+		err := concurrentSigner.nonceErr
+		concurrentSigner.nonceLock.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("signer %s: %w", concurrentSigner.signer.Address(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
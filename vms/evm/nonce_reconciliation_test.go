@@ -0,0 +1,174 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	mock_ethclient "github.com/ryt-io/icm-services/vms/evm/mocks"
+	"github.com/ryt-io/icm-services/vms/evm/signer"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconcileNonce(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		currentNonce          uint64
+		latestNonce           uint64
+		pendingNonce          uint64
+		sentWhileFetching     uint64
+		nonceGapStart         *uint64
+		trackedNonces         []uint64
+		expectedCurrentNonce  uint64
+		expectedNonceGapStart *uint64
+		expectedResubmitted   []uint64
+		expectedSelfTransfers []uint64
+		expectUnhealthy       bool
+	}{
+		{
+			name:                 "in sync",
+			currentNonce:         5,
+			latestNonce:          4,
+			pendingNonce:         5,
+			expectedCurrentNonce: 5,
+		},
+		{
+			name:                 "behind pending nonce",
+			currentNonce:         3,
+			latestNonce:          5,
+			pendingNonce:         5,
+			expectedCurrentNonce: 5,
+		},
+		{
+			name:                  "gap observed",
+			currentNonce:          7,
+			latestNonce:           5,
+			pendingNonce:          5,
+			trackedNonces:         []uint64{5},
+			expectedCurrentNonce:  7,
+			expectedNonceGapStart: uint64Ptr(5),
+		},
+		{
+			name:                  "gap persists",
+			currentNonce:          7,
+			latestNonce:           5,
+			pendingNonce:          5,
+			nonceGapStart:         uint64Ptr(5),
+			trackedNonces:         []uint64{5},
+			expectedCurrentNonce:  7,
+			expectedNonceGapStart: uint64Ptr(5),
+			expectedResubmitted:   []uint64{5},
+			expectedSelfTransfers: []uint64{6},
+			expectUnhealthy:       true,
+		},
+		{
+			name:                 "sent while fetching nonces",
+			currentNonce:         5,
+			latestNonce:          4,
+			pendingNonce:         5,
+			sentWhileFetching:    2,
+			nonceGapStart:        uint64Ptr(5),
+			expectedCurrentNonce: 7,
+		},
+		{
+			name:                 "gap filled",
+			currentNonce:         7,
+			latestNonce:          5,
+			pendingNonce:         7,
+			nonceGapStart:        uint64Ptr(5),
+			expectedCurrentNonce: 7,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+			txSigners, err := signer.NewTxSigners(destinationSubnet.AccountPrivateKeys)
+			require.NoError(t, err)
+
+			destClient := destinationClient{
+				logger:       logging.NoLog{},
+				avaRPCClient: mockClient,
+				evmChainID:   big.NewInt(5),
+				gasFeeConfig: &GasFeeConfig{
+					maxBaseFee:                 big.NewInt(100),
+					suggestedPriorityFeeBuffer: big.NewInt(0),
+					maxPriorityFeePerGas:       big.NewInt(10),
+				},
+				txInclusionTimeout: 30 * time.Second,
+			}
+			signer := &concurrentSigner{
+				logger:            logging.NoLog{},
+				signer:            txSigners[0],
+				currentNonce:      test.currentNonce,
+				destinationClient: &destClient,
+				nonceGapStart:     test.nonceGapStart,
+			}
+			destClient.readonlyConcurrentSigners = []*readonlyConcurrentSigner{(*readonlyConcurrentSigner)(signer)}
+			to := common.HexToAddress("0x27aE10273D17Cd7e80de8580A51f476960626e5f")
+			for _, nonce := range test.trackedNonces {
+				tx, err := signer.signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+					ChainID:   destClient.evmChainID,
+					Nonce:     nonce,
+					To:        &to,
+					Gas:       100_000,
+					GasFeeCap: big.NewInt(100),
+					GasTipCap: big.NewInt(10),
+					Value:     big.NewInt(0),
+				}), destClient.evmChainID)
+				require.NoError(t, err)
+				signer.trackSentTx(tx)
+			}
+
+			mockClient.EXPECT().NonceAt(gomock.Any(), signer.signer.Address(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ common.Address, blockNumber *big.Int) (uint64, error) {
+					if blockNumber == nil {
+						return test.latestNonce, nil
+					}
+					// Transactions sent after the pending nonce is fetched are not yet in the mempool
+					signer.nonceLock.Lock()
+					signer.currentNonce += test.sentWhileFetching
+					signer.nonceLock.Unlock()
+					return test.pendingNonce, nil
+				},
+			).Times(2)
+			mockClient.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(1), nil).
+				Times(len(test.expectedSelfTransfers))
+			var resubmitted, selfTransfers []uint64
+			mockClient.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, tx *types.Transaction) error {
+					if *tx.To() == signer.signer.Address() {
+						selfTransfers = append(selfTransfers, tx.Nonce())
+					} else {
+						resubmitted = append(resubmitted, tx.Nonce())
+					}
+					return nil
+				},
+			).Times(len(test.expectedResubmitted) + len(test.expectedSelfTransfers))
+
+			signer.reconcileNonce()
+
+			require.Equal(t, test.expectedCurrentNonce, signer.currentNonce)
+			require.Equal(t, test.expectedNonceGapStart, signer.nonceGapStart)
+			require.Equal(t, test.expectedResubmitted, resubmitted)
+			require.Equal(t, test.expectedSelfTransfers, selfTransfers)
+			if test.expectUnhealthy {
+				require.Error(t, destClient.Health())
+			} else {
+				require.NoError(t, destClient.Health())
+			}
+		})
+	}
+}
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
@@ -0,0 +1,298 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	teleporterUtils "github.com/ryt-io/icm-services/icm-contracts/utils/teleporter-utils"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/icm-services/utils"
+	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/accounts/abi/bind"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/core/types"
+	"go.uber.org/zap"
+)
+
+// Maximum number of receipts sent in a single transaction
+const maxSweptReceipts = 100
+
+// receiptSweeper periodically sends the receipts of the messages delivered by the relayer that are queued on the
+// destination blockchain's TeleporterMessenger back to the blockchains the messages were sent from, so that the
+// relayer's rewards are allocated on routes with little or no traffic in the opposite direction.
+//
+// Receipts sent with sendSpecifiedReceipts are not removed from the receipt queues, so the sweeper tracks which
+// receipts it has sent until they leave the queue.
+type receiptSweeper struct {
+	logger                   logging.Logger
+	destinationClient        CommonDestinationClient
+	client                   Client
+	destinationBlockchainID  ids.ID
+	teleporterAddress        common.Address
+	rewardAddress            common.Address
+	counterpartBlockchainIDs []ids.ID
+	maxPendingReceipts       uint64
+	maxReceiptAge            time.Duration
+	maxSweptReceipts         int
+	metrics                  *DestinationClientMetrics
+
+	// When each queued receipt of the relayer that has not been sent was first observed, keyed by the
+	// counterpart blockchain and the nonce of the received message
+	pending map[ids.ID]map[uint64]time.Time
+	// Nonces of the receipts of each counterpart blockchain that have been sent but are still queued
+	swept map[ids.ID]set.Set[uint64]
+	now   func() time.Time
+}
+
+func newReceiptSweeper(
+	logger logging.Logger,
+	destinationClient CommonDestinationClient,
+	client Client,
+	destinationBlockchainID ids.ID,
+	sweeperConfig *config.ReceiptSweeper,
+	metrics *DestinationClientMetrics,
+) *receiptSweeper {
+	return &receiptSweeper{
+		logger: logger.With(
+			zap.Stringer("rewardAddress", sweeperConfig.GetRewardAddress()),
+			zap.Stringer("teleporterAddress", sweeperConfig.GetTeleporterAddress()),
+		),
+		destinationClient:        destinationClient,
+		client:                   client,
+		destinationBlockchainID:  destinationBlockchainID,
+		teleporterAddress:        sweeperConfig.GetTeleporterAddress(),
+		rewardAddress:            sweeperConfig.GetRewardAddress(),
+		counterpartBlockchainIDs: sweeperConfig.GetCounterpartBlockchainIDs(),
+		maxPendingReceipts:       sweeperConfig.MaxPendingReceipts,
+		maxReceiptAge:            time.Duration(sweeperConfig.MaxReceiptAgeSeconds) * time.Second,
+		maxSweptReceipts:         maxSweptReceipts,
+		metrics:                  metrics,
+		pending:                  make(map[ids.ID]map[uint64]time.Time),
+		swept:                    make(map[ids.ID]set.Set[uint64]),
+		now:                      time.Now,
+	}
+}
+
+// run periodically sweeps the receipt queues until [stop] is closed
+func (s *receiptSweeper) run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.sweepReceipts()
+		}
+	}
+}
+
+// sweepReceipts sends the queued receipts of the relayer to each counterpart blockchain once there are
+// too many of them, or the oldest of them has been queued for too long
+func (s *receiptSweeper) sweepReceipts() {
+	messenger, err := teleportermessenger.NewTeleporterMessengerCaller(s.teleporterAddress, s.client)
+	if err != nil {
+		s.logger.Error("Failed to get teleporter messenger contract", zap.Error(err))
+		return
+	}
+	for _, counterpartBlockchainID := range s.counterpartBlockchainIDs {
+		log := s.logger.With(zap.Stringer("counterpartBlockchainID", counterpartBlockchainID))
+
+		nonces, err := s.queuedReceipts(messenger, counterpartBlockchainID)
+		if err != nil {
+			log.Warn("Failed to read receipt queue", zap.Error(err))
+			continue
+		}
+		s.trackReceipts(counterpartBlockchainID, nonces)
+
+		pending := s.pending[counterpartBlockchainID]
+		if len(pending) == 0 {
+			continue
+		}
+		var oldest time.Time
+		for _, firstSeen := range pending {
+			if oldest.IsZero() || firstSeen.Before(oldest) {
+				oldest = firstSeen
+			}
+		}
+		age := s.now().Sub(oldest)
+		if uint64(len(pending)) < s.maxPendingReceipts && age < s.maxReceiptAge {
+			log.Debug(
+				"Queued receipts below sweep thresholds",
+				zap.Int("pendingReceipts", len(pending)),
+				zap.Duration("oldestReceiptAge", age),
+			)
+			continue
+		}
+		if err := s.sweep(log, counterpartBlockchainID); err != nil {
+			log.Error("Failed to send queued receipts", zap.Int("pendingReceipts", len(pending)), zap.Error(err))
+		}
+	}
+}
+
+// queuedReceipts returns the nonces of the relayer's receipts in the receipt queue of [counterpartBlockchainID].
+// The queue is read from its front, where the oldest receipts are, until enough receipts that have not been sent
+// are found to reach the sweep threshold and fill a sweep. Receipts that were sent remain at the front of the queue
+// until they leave it, so they are returned but not counted.
+func (s *receiptSweeper) queuedReceipts(
+	messenger *teleportermessenger.TeleporterMessengerCaller,
+	counterpartBlockchainID ids.ID,
+) ([]uint64, error) {
+	sizeCtx, sizeCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	size, err := messenger.GetReceiptQueueSize(&bind.CallOpts{Context: sizeCtx}, counterpartBlockchainID)
+	sizeCtxCancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt queue size: %w", err)
+	}
+	if s.metrics != nil {
+		queueSize, _ := new(big.Float).SetInt(size).Float64()
+		s.metrics.receiptQueueSize.
+			WithLabelValues(s.destinationBlockchainID.String(), counterpartBlockchainID.String()).
+			Set(queueSize)
+	}
+
+	if !size.IsUint64() {
+		return nil, fmt.Errorf("invalid receipt queue size %s", size)
+	}
+	var (
+		nonces   []uint64
+		unswept  uint64
+		maxFound = max(s.maxPendingReceipts, uint64(s.maxSweptReceipts))
+	)
+	for i := uint64(0); i < size.Uint64() && unswept < maxFound; i++ {
+		receiptCtx, receiptCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+		receipt, err := messenger.GetReceiptAtIndex(
+			&bind.CallOpts{Context: receiptCtx},
+			counterpartBlockchainID,
+			new(big.Int).SetUint64(i),
+		)
+		receiptCtxCancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipt at index %d: %w", i, err)
+		}
+		if receipt.RelayerRewardAddress != s.rewardAddress {
+			continue
+		}
+		nonce := receipt.ReceivedMessageNonce.Uint64()
+		if !s.swept[counterpartBlockchainID].Contains(nonce) {
+			unswept++
+		}
+		nonces = append(nonces, nonce)
+	}
+	return nonces, nil
+}
+
+// trackReceipts records the receipts of [counterpartBlockchainID] currently queued, and forgets those
+// that have left the queue
+func (s *receiptSweeper) trackReceipts(counterpartBlockchainID ids.ID, nonces []uint64) {
+	prevPending := s.pending[counterpartBlockchainID]
+	prevSwept := s.swept[counterpartBlockchainID]
+	pending := make(map[uint64]time.Time)
+	swept := set.NewSet[uint64](0)
+	now := s.now()
+	for _, nonce := range nonces {
+		if prevSwept.Contains(nonce) {
+			swept.Add(nonce)
+			continue
+		}
+		if firstSeen, ok := prevPending[nonce]; ok {
+			pending[nonce] = firstSeen
+		} else {
+			pending[nonce] = now
+		}
+	}
+	s.pending[counterpartBlockchainID] = pending
+	s.swept[counterpartBlockchainID] = swept
+}
+
+// sweep sends a transaction sending the pending receipts to [counterpartBlockchainID]. At most maxSweptReceipts
+// receipts are sent, starting with the lowest nonces, and the rest are sent by later sweeps.
+func (s *receiptSweeper) sweep(log logging.Logger, counterpartBlockchainID ids.ID) error {
+	pending := s.pending[counterpartBlockchainID]
+	nonces := slices.Sorted(maps.Keys(pending))
+	if len(nonces) > s.maxSweptReceipts {
+		nonces = nonces[:s.maxSweptReceipts]
+	}
+	messageIDs := make([][32]byte, 0, len(nonces))
+	for _, nonce := range nonces {
+		messageID, err := teleporterUtils.CalculateMessageID(
+			s.teleporterAddress,
+			counterpartBlockchainID,
+			s.destinationBlockchainID,
+			new(big.Int).SetUint64(nonce),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to calculate message ID: %w", err)
+		}
+		messageIDs = append(messageIDs, messageID)
+	}
+	callData, err := teleportermessenger.PackSendSpecifiedReceipts(
+		counterpartBlockchainID,
+		messageIDs,
+		teleportermessenger.TeleporterFeeInfo{
+			FeeTokenAddress: common.Address{},
+			Amount:          big.NewInt(0),
+		},
+		[]common.Address{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to pack sendSpecifiedReceipts call data: %w", err)
+	}
+	// The transaction is sent from the signer its gas is estimated for
+	sender := SenderAddresses(s.destinationClient)[0]
+	estimateCtx, estimateCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	gasLimit, err := s.client.EstimateGas(estimateCtx, ethereum.CallMsg{
+		From: sender,
+		To:   &s.teleporterAddress,
+		Data: callData,
+	})
+	estimateCtxCancel()
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	log.Info("Sending queued receipts", zap.Int("receipts", len(messageIDs)))
+	receipt, err := SendTx(
+		s.destinationClient,
+		nil,
+		set.Of(sender),
+		s.teleporterAddress.Hex(),
+		gasLimit,
+		callData,
+		s.destinationClient.TxInclusionTimeout(),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s failed with status: %d", receipt.TxHash, receipt.Status)
+	}
+	log.Info(
+		"Sent queued receipts",
+		zap.Int("receipts", len(messageIDs)),
+		zap.Stringer("txID", receipt.TxHash),
+	)
+
+	for _, nonce := range nonces {
+		delete(pending, nonce)
+		s.swept[counterpartBlockchainID].Add(nonce)
+	}
+	if s.metrics != nil {
+		s.metrics.receiptsSwept.
+			WithLabelValues(s.destinationBlockchainID.String(), counterpartBlockchainID.String()).
+			Add(float64(len(nonces)))
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"maps"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	teleporterUtils "github.com/ryt-io/icm-services/icm-contracts/utils/teleporter-utils"
+	"github.com/ryt-io/icm-services/relayer/config"
+	mock_ethclient "github.com/ryt-io/icm-services/vms/evm/mocks"
+	"github.com/ryt-io/icm-services/vms/evm/signer"
+	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSweepReceipts(t *testing.T) {
+	teleporterAddress := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	rewardAddress := common.HexToAddress("0x27aE10273D17Cd7e80de8580A51f476960626e5f")
+	otherRewardAddress := common.HexToAddress("0x0A1b9bB0bA2dA7e6F55B7A7bC1d4A5A77ed2E6A3")
+	destinationBlockchainID := ids.GenerateTestID()
+	counterpartBlockchainID := ids.GenerateTestID()
+
+	testCases := []struct {
+		name               string
+		maxPendingReceipts uint64
+		maxSweptReceipts   int
+		// Receipt reward addresses of the queue, keyed by nonce
+		queue map[uint64]common.Address
+		// Age of the receipts already pending, keyed by nonce
+		pendingAges map[uint64]time.Duration
+		swept       []uint64
+		// Number of receipts read from the front of the queue, or 0 if the whole queue is read
+		readReceipts    int
+		receiptStatus   uint64
+		expectedSweep   []uint64
+		expectedPending []uint64
+		expectedSwept   []uint64
+	}{
+		{
+			name:               "empty queue",
+			maxPendingReceipts: 1,
+		},
+		{
+			name:               "receipts of other relayers",
+			maxPendingReceipts: 1,
+			queue:              map[uint64]common.Address{1: otherRewardAddress, 2: otherRewardAddress},
+		},
+		{
+			name:               "below thresholds",
+			maxPendingReceipts: 3,
+			queue:              map[uint64]common.Address{1: rewardAddress, 2: rewardAddress},
+			pendingAges:        map[uint64]time.Duration{1: time.Minute},
+			expectedPending:    []uint64{1, 2},
+		},
+		{
+			name:               "too many receipts",
+			maxPendingReceipts: 2,
+			queue:              map[uint64]common.Address{1: rewardAddress, 2: otherRewardAddress, 3: rewardAddress},
+			receiptStatus:      types.ReceiptStatusSuccessful,
+			expectedSweep:      []uint64{1, 3},
+			expectedSwept:      []uint64{1, 3},
+		},
+		{
+			name:               "more receipts than sent in a transaction",
+			maxPendingReceipts: 3,
+			maxSweptReceipts:   2,
+			queue:              map[uint64]common.Address{1: rewardAddress, 2: rewardAddress, 3: rewardAddress},
+			receiptStatus:      types.ReceiptStatusSuccessful,
+			expectedSweep:      []uint64{1, 2},
+			expectedPending:    []uint64{3},
+			expectedSwept:      []uint64{1, 2},
+		},
+		{
+			name:               "receipt too old",
+			maxPendingReceipts: 3,
+			queue:              map[uint64]common.Address{1: rewardAddress},
+			pendingAges:        map[uint64]time.Duration{1: 2 * time.Hour},
+			receiptStatus:      types.ReceiptStatusSuccessful,
+			expectedSweep:      []uint64{1},
+			expectedSwept:      []uint64{1},
+		},
+		{
+			name:               "receipts already swept",
+			maxPendingReceipts: 1,
+			queue:              map[uint64]common.Address{2: rewardAddress, 3: rewardAddress},
+			swept:              []uint64{1, 2},
+			receiptStatus:      types.ReceiptStatusSuccessful,
+			expectedSweep:      []uint64{3},
+			expectedSwept:      []uint64{2, 3},
+		},
+		{
+			name:               "enough receipts found",
+			maxPendingReceipts: 2,
+			maxSweptReceipts:   2,
+			queue: map[uint64]common.Address{
+				1: rewardAddress,
+				2: otherRewardAddress,
+				3: rewardAddress,
+				4: rewardAddress,
+				5: rewardAddress,
+			},
+			swept:         []uint64{1},
+			readReceipts:  4,
+			receiptStatus: types.ReceiptStatusSuccessful,
+			expectedSweep: []uint64{3, 4},
+			expectedSwept: []uint64{1, 3, 4},
+		},
+		{
+			name:               "sweep reverted",
+			maxPendingReceipts: 1,
+			queue:              map[uint64]common.Address{1: rewardAddress},
+			receiptStatus:      types.ReceiptStatusFailed,
+			expectedSweep:      []uint64{1},
+			expectedPending:    []uint64{1},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRPCClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+			mockClient := mock_ethclient.NewMockClient(ctrl)
+
+			destinationBlockchain := config.TestValidDestinationBlockchainConfig
+			txSigners, err := signer.NewTxSigners([]string{destinationBlockchain.AccountPrivateKey})
+			require.NoError(t, err)
+			sweeperConfig := &config.ReceiptSweeper{
+				TeleporterAddress:        teleporterAddress.Hex(),
+				RewardAddress:            rewardAddress.Hex(),
+				CounterpartBlockchainIDs: []string{counterpartBlockchainID.String()},
+				MaxPendingReceipts:       test.maxPendingReceipts,
+			}
+			destinationBlockchain.ReceiptSweeper = sweeperConfig
+			require.NoError(t, destinationBlockchain.Validate())
+
+			destClient := newTestSendingDestinationClient(t, txSigners[0], mockRPCClient, mockClient)
+			sweeper := newReceiptSweeper(
+				logging.NoLog{},
+				destClient,
+				mockClient,
+				destinationBlockchainID,
+				sweeperConfig,
+				nil,
+			)
+			now := time.Now()
+			sweeper.now = func() time.Time { return now }
+			if test.maxSweptReceipts != 0 {
+				sweeper.maxSweptReceipts = test.maxSweptReceipts
+			}
+			sweeper.pending[counterpartBlockchainID] = make(map[uint64]time.Time)
+			for nonce, age := range test.pendingAges {
+				sweeper.pending[counterpartBlockchainID][nonce] = now.Add(-age)
+			}
+			sweeper.swept[counterpartBlockchainID] = set.Of(test.swept...)
+
+			// Serve the receipt queue from the teleporter messenger calls
+			callResults := make(map[string][]byte)
+			queueSizeInput, err := teleportermessenger.PackGetReceiptQueueSize(counterpartBlockchainID)
+			require.NoError(t, err)
+			queueSizeOutput, err := teleportermessenger.PackGetReceiptQueueSizeOutput(
+				big.NewInt(int64(len(test.queue))),
+			)
+			require.NoError(t, err)
+			callResults[string(queueSizeInput)] = queueSizeOutput
+			readReceipts := test.readReceipts
+			if readReceipts == 0 {
+				readReceipts = len(test.queue)
+			}
+			for i, nonce := range slices.Sorted(maps.Keys(test.queue))[:readReceipts] {
+				receiptInput, err := teleportermessenger.PackGetReceiptAtIndex(
+					counterpartBlockchainID,
+					big.NewInt(int64(i)),
+				)
+				require.NoError(t, err)
+				receiptOutput, err := teleportermessenger.PackGetReceiptAtIndexOutput(
+					teleportermessenger.TeleporterMessageReceipt{
+						ReceivedMessageNonce: new(big.Int).SetUint64(nonce),
+						RelayerRewardAddress: test.queue[nonce],
+					},
+				)
+				require.NoError(t, err)
+				callResults[string(receiptInput)] = receiptOutput
+			}
+			mockClient.EXPECT().CallContract(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+					require.Equal(t, teleporterAddress, *call.To)
+					result, ok := callResults[string(call.Data)]
+					require.True(t, ok)
+					return result, nil
+				},
+			).Times(len(callResults))
+
+			sweeps := 0
+			var sweepInput []byte
+			if len(test.expectedSweep) != 0 {
+				sweeps = 1
+				messageIDs := make([][32]byte, 0, len(test.expectedSweep))
+				for _, nonce := range test.expectedSweep {
+					messageID, err := teleporterUtils.CalculateMessageID(
+						teleporterAddress,
+						counterpartBlockchainID,
+						destinationBlockchainID,
+						new(big.Int).SetUint64(nonce),
+					)
+					require.NoError(t, err)
+					messageIDs = append(messageIDs, messageID)
+				}
+				sweepInput, err = teleportermessenger.PackSendSpecifiedReceipts(
+					counterpartBlockchainID,
+					messageIDs,
+					teleportermessenger.TeleporterFeeInfo{
+						FeeTokenAddress: common.Address{},
+						Amount:          big.NewInt(0),
+					},
+					[]common.Address{},
+				)
+				require.NoError(t, err)
+			}
+			mockClient.EXPECT().
+				EstimateGas(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+					From: txSigners[0].Address(),
+					To:   &teleporterAddress,
+					Data: sweepInput,
+				})).
+				Return(uint64(100_000), nil).
+				Times(sweeps)
+			mockRPCClient.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(1), nil).Times(sweeps)
+			mockRPCClient.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, tx *types.Transaction) error {
+					require.Equal(t, teleporterAddress, *tx.To())
+					require.Equal(t, sweepInput, tx.Data())
+					return nil
+				},
+			).Times(sweeps)
+			mockRPCClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(
+				&types.Receipt{Status: test.receiptStatus},
+				nil,
+			).Times(sweeps)
+
+			sweeper.sweepReceipts()
+
+			pending := slices.Collect(maps.Keys(sweeper.pending[counterpartBlockchainID]))
+			require.ElementsMatch(t, test.expectedPending, pending)
+			require.ElementsMatch(t, test.expectedSwept, sweeper.swept[counterpartBlockchainID].List())
+		})
+	}
+}
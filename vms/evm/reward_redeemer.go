@@ -0,0 +1,258 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"github.com/ryt-io/icm-services/utils"
+	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/accounts/abi/bind"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/core/types"
+	"go.uber.org/zap"
+)
+
+// rewardRedeemer periodically redeems the relayer rewards earned by the reward address on the destination
+// blockchain's TeleporterMessenger, using the signer of the reward address.
+type rewardRedeemer struct {
+	logger                  logging.Logger
+	destinationClient       CommonDestinationClient
+	client                  Client
+	destinationBlockchainID ids.ID
+	teleporterAddress       common.Address
+	rewardAddress           common.Address
+	feeTokens               []*config.RewardFeeToken
+	metrics                 *DestinationClientMetrics
+	state                   *destinationState
+
+	// Total rewards of each fee token redeemed by the reward address, which are stored in the database
+	redeemed map[common.Address]*big.Int
+}
+
+func newRewardRedeemer(
+	logger logging.Logger,
+	destinationClient CommonDestinationClient,
+	client Client,
+	destinationBlockchainID ids.ID,
+	rewardRedemption *config.RewardRedemption,
+	metrics *DestinationClientMetrics,
+	state *destinationState,
+) (*rewardRedeemer, error) {
+	rewardAddress := rewardRedemption.GetRewardAddress()
+	if !set.Of(SenderAddresses(destinationClient)...).Contains(rewardAddress) {
+		return nil, fmt.Errorf("reward address %s is not the address of any of the signers", rewardAddress)
+	}
+	r := &rewardRedeemer{
+		logger: logger.With(
+			zap.Stringer("rewardAddress", rewardAddress),
+			zap.Stringer("teleporterAddress", rewardRedemption.GetTeleporterAddress()),
+		),
+		destinationClient:       destinationClient,
+		client:                  client,
+		destinationBlockchainID: destinationBlockchainID,
+		teleporterAddress:       rewardRedemption.GetTeleporterAddress(),
+		rewardAddress:           rewardAddress,
+		feeTokens:               rewardRedemption.FeeTokens,
+		metrics:                 metrics,
+		state:                   state,
+		redeemed:                make(map[common.Address]*big.Int),
+	}
+	if err := r.loadRedeemed(); err != nil {
+		return nil, fmt.Errorf("failed to load redeemed rewards: %w", err)
+	}
+	return r, nil
+}
+
+// loadRedeemed loads the stored totals of the redeemed rewards, which are stored as decimal strings
+func (r *rewardRedeemer) loadRedeemed() error {
+	var stored map[common.Address]string
+	ok, err := r.state.get(database.RedeemedRewardsKey, &stored)
+	if err != nil || !ok {
+		return err
+	}
+	for feeTokenAddress, storedTotal := range stored {
+		total, ok := new(big.Int).SetString(storedTotal, 10)
+		if !ok {
+			return fmt.Errorf("invalid redeemed rewards %q of fee token %s", storedTotal, feeTokenAddress)
+		}
+		r.redeemed[feeTokenAddress] = total
+		r.setRedeemed(feeTokenAddress, total)
+	}
+	return nil
+}
+
+// storeRedeemed stores the totals of the redeemed rewards
+func (r *rewardRedeemer) storeRedeemed() error {
+	stored := make(map[common.Address]string, len(r.redeemed))
+	for feeTokenAddress, total := range r.redeemed {
+		stored[feeTokenAddress] = total.String()
+	}
+	return r.state.put(database.RedeemedRewardsKey, stored)
+}
+
+// run periodically redeems the rewards until [stop] is closed
+func (r *rewardRedeemer) run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.redeemRewards()
+		}
+	}
+}
+
+// redeemRewards redeems the rewards of each fee token that has reached its threshold
+func (r *rewardRedeemer) redeemRewards() {
+	messenger, err := teleportermessenger.NewTeleporterMessengerCaller(r.teleporterAddress, r.client)
+	if err != nil {
+		r.logger.Error("Failed to get teleporter messenger contract", zap.Error(err))
+		return
+	}
+	for _, feeToken := range r.feeTokens {
+		log := r.logger.With(zap.Stringer("feeTokenAddress", feeToken.GetAddress()))
+
+		callCtx, callCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+		amount, err := messenger.CheckRelayerRewardAmount(
+			&bind.CallOpts{Context: callCtx},
+			r.rewardAddress,
+			feeToken.GetAddress(),
+		)
+		callCtxCancel()
+		if err != nil {
+			log.Warn("Failed to check relayer reward amount", zap.Error(err))
+			continue
+		}
+		r.setUnredeemed(feeToken.GetAddress(), amount)
+
+		threshold := feeToken.GetThreshold()
+		if amount.Sign() == 0 || amount.Cmp(threshold) < 0 {
+			log.Debug(
+				"Relayer rewards below redemption threshold",
+				zap.Stringer("amount", amount),
+				zap.Stringer("threshold", threshold),
+			)
+			continue
+		}
+		if err := r.redeemReward(log, feeToken.GetAddress(), amount); err != nil {
+			log.Error("Failed to redeem relayer rewards", zap.Stringer("amount", amount), zap.Error(err))
+		}
+	}
+}
+
+// redeemReward sends a transaction redeeming the rewards of [feeTokenAddress], which are expected to be [amount]
+func (r *rewardRedeemer) redeemReward(log logging.Logger, feeTokenAddress common.Address, amount *big.Int) error {
+	callData, err := teleportermessenger.PackRedeemRelayerRewards(feeTokenAddress)
+	if err != nil {
+		return fmt.Errorf("failed to pack redeemRelayerRewards call data: %w", err)
+	}
+	estimateCtx, estimateCtxCancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+	gasLimit, err := r.client.EstimateGas(estimateCtx, ethereum.CallMsg{
+		From: r.rewardAddress,
+		To:   &r.teleporterAddress,
+		Data: callData,
+	})
+	estimateCtxCancel()
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	log.Info("Redeeming relayer rewards", zap.Stringer("amount", amount))
+	receipt, err := SendTx(
+		r.destinationClient,
+		nil,
+		set.Of(r.rewardAddress),
+		r.teleporterAddress.Hex(),
+		gasLimit,
+		callData,
+		r.destinationClient.TxInclusionTimeout(),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	log = log.With(zap.Stringer("txID", receipt.TxHash))
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s failed with status: %d", receipt.TxHash, receipt.Status)
+	}
+
+	// The rewards earned since they were checked are redeemed too, so the amount is read from the receipt
+	redeemedAmount := r.redeemedAmount(receipt, feeTokenAddress)
+	if redeemedAmount == nil {
+		log.Warn("Redemption event not found in receipt, assuming the checked amount was redeemed")
+		redeemedAmount = amount
+	}
+	total, ok := r.redeemed[feeTokenAddress]
+	if !ok {
+		total = big.NewInt(0)
+		r.redeemed[feeTokenAddress] = total
+	}
+	total.Add(total, redeemedAmount)
+	log.Info(
+		"Redeemed relayer rewards",
+		zap.Stringer("amount", redeemedAmount),
+		zap.Stringer("totalRedeemed", total),
+	)
+	if err := r.storeRedeemed(); err != nil {
+		log.Error("Failed to store redeemed relayer rewards", zap.Error(err))
+	}
+
+	r.setUnredeemed(feeTokenAddress, big.NewInt(0))
+	r.setRedeemed(feeTokenAddress, total)
+	return nil
+}
+
+// redeemedAmount returns the amount of the RelayerRewardsRedeemed event of [feeTokenAddress] in [receipt],
+// or nil if it is not found
+func (r *rewardRedeemer) redeemedAmount(receipt *types.Receipt, feeTokenAddress common.Address) *big.Int {
+	filterer, err := teleportermessenger.NewTeleporterMessengerFilterer(r.teleporterAddress, nil)
+	if err != nil {
+		return nil
+	}
+	for _, log := range receipt.Logs {
+		if log.Address != r.teleporterAddress {
+			continue
+		}
+		event, err := filterer.ParseRelayerRewardsRedeemed(*log)
+		if err != nil {
+			continue
+		}
+		if event.Redeemer == r.rewardAddress && event.Asset == feeTokenAddress {
+			return event.Amount
+		}
+	}
+	return nil
+}
+
+func (r *rewardRedeemer) setUnredeemed(feeTokenAddress common.Address, amount *big.Int) {
+	if r.metrics == nil {
+		return
+	}
+	value, _ := new(big.Float).SetInt(amount).Float64()
+	r.metrics.rewardsUnredeemed.WithLabelValues(r.metricLabelValues(feeTokenAddress)...).Set(value)
+}
+
+func (r *rewardRedeemer) setRedeemed(feeTokenAddress common.Address, total *big.Int) {
+	if r.metrics == nil {
+		return
+	}
+	value, _ := new(big.Float).SetInt(total).Float64()
+	r.metrics.rewardsRedeemed.WithLabelValues(r.metricLabelValues(feeTokenAddress)...).Set(value)
+}
+
+func (r *rewardRedeemer) metricLabelValues(feeTokenAddress common.Address) []string {
+	return []string{r.destinationBlockchainID.String(), r.rewardAddress.String(), feeTokenAddress.String()}
+}
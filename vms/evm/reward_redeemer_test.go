@@ -0,0 +1,195 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	teleportermessenger "github.com/ryt-io/icm-services/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/relayer/config"
+	mock_ethclient "github.com/ryt-io/icm-services/vms/evm/mocks"
+	"github.com/ryt-io/icm-services/vms/evm/signer"
+	ethereum "github.com/ava-labs/libevm"
+	"github.com/ryt-io/libevm/common"
+	"github.com/ryt-io/libevm/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRedeemRewards(t *testing.T) {
+	teleporterAddress := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	feeTokenAddress := common.HexToAddress("0x27aE10273D17Cd7e80de8580A51f476960626e5f")
+	rewardsRedeemedEvent := func(t *testing.T, redeemer common.Address, amount int64) *types.Log {
+		teleporterABI, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+		require.NoError(t, err)
+		event := teleporterABI.Events["RelayerRewardsRedeemed"]
+		data, err := event.Inputs.NonIndexed().Pack(big.NewInt(amount))
+		require.NoError(t, err)
+		return &types.Log{
+			Address: teleporterAddress,
+			Topics: []common.Hash{
+				event.ID,
+				common.BytesToHash(redeemer.Bytes()),
+				common.BytesToHash(feeTokenAddress.Bytes()),
+			},
+			Data: data,
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		threshold        string
+		rewardAmount     int64
+		receiptStatus    uint64
+		receiptLogs      func(t *testing.T, redeemer common.Address) []*types.Log
+		expectRedemption bool
+		expectedRedeemed *big.Int
+	}{
+		{
+			name:         "no rewards",
+			rewardAmount: 0,
+		},
+		{
+			name:         "below threshold",
+			threshold:    "100",
+			rewardAmount: 99,
+		},
+		{
+			name:          "redeemed",
+			threshold:     "100",
+			rewardAmount:  100,
+			receiptStatus: types.ReceiptStatusSuccessful,
+			receiptLogs: func(t *testing.T, redeemer common.Address) []*types.Log {
+				return []*types.Log{rewardsRedeemedEvent(t, redeemer, 120)}
+			},
+			expectRedemption: true,
+			expectedRedeemed: big.NewInt(120),
+		},
+		{
+			name:             "redeemed without event",
+			rewardAmount:     100,
+			receiptStatus:    types.ReceiptStatusSuccessful,
+			expectRedemption: true,
+			expectedRedeemed: big.NewInt(100),
+		},
+		{
+			name:             "redemption reverted",
+			rewardAmount:     100,
+			receiptStatus:    types.ReceiptStatusFailed,
+			expectRedemption: true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRPCClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+			mockClient := mock_ethclient.NewMockClient(ctrl)
+
+			destinationBlockchain := config.TestValidDestinationBlockchainConfig
+			txSigners, err := signer.NewTxSigners([]string{destinationBlockchain.AccountPrivateKey})
+			require.NoError(t, err)
+			rewardAddress := txSigners[0].Address()
+			rewardRedemption := &config.RewardRedemption{
+				TeleporterAddress: teleporterAddress.Hex(),
+				RewardAddress:     rewardAddress.Hex(),
+				FeeTokens: []*config.RewardFeeToken{
+					{Address: feeTokenAddress.Hex(), Threshold: test.threshold},
+				},
+			}
+			destinationBlockchain.RewardRedemption = rewardRedemption
+			require.NoError(t, destinationBlockchain.Validate())
+
+			destClient := newTestSendingDestinationClient(t, txSigners[0], mockRPCClient, mockClient)
+			destinationBlockchainID := ids.GenerateTestID()
+			db, err := database.NewJSONFileStorage(
+				logging.NoLog{},
+				t.TempDir(),
+				[]database.RelayerID{database.NewDestinationStateID(destinationBlockchainID)},
+			)
+			require.NoError(t, err)
+			state := newDestinationState(db, destinationBlockchainID)
+			redeemer, err := newRewardRedeemer(
+				logging.NoLog{},
+				destClient,
+				mockClient,
+				destinationBlockchainID,
+				rewardRedemption,
+				nil,
+				state,
+			)
+			require.NoError(t, err)
+
+			checkRewardInput, err := teleportermessenger.PackCheckRelayerRewardAmount(rewardAddress, feeTokenAddress)
+			require.NoError(t, err)
+			checkRewardOutput, err := teleportermessenger.PackCheckRelayerRewardAmountOutput(
+				big.NewInt(test.rewardAmount),
+			)
+			require.NoError(t, err)
+			mockClient.EXPECT().
+				CallContract(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+					To:   &teleporterAddress,
+					Data: checkRewardInput,
+				}), gomock.Any()).
+				Return(checkRewardOutput, nil).
+				Times(1)
+
+			redemptions := 0
+			if test.expectRedemption {
+				redemptions = 1
+			}
+			redeemInput, err := teleportermessenger.PackRedeemRelayerRewards(feeTokenAddress)
+			require.NoError(t, err)
+			mockClient.EXPECT().
+				EstimateGas(gomock.Any(), gomock.Eq(ethereum.CallMsg{
+					From: rewardAddress,
+					To:   &teleporterAddress,
+					Data: redeemInput,
+				})).
+				Return(uint64(100_000), nil).
+				Times(redemptions)
+			mockRPCClient.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(1), nil).Times(redemptions)
+			mockRPCClient.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, tx *types.Transaction) error {
+					require.Equal(t, teleporterAddress, *tx.To())
+					require.Equal(t, redeemInput, tx.Data())
+					require.Empty(t, tx.AccessList())
+					return nil
+				},
+			).Times(redemptions)
+			var receiptLogs []*types.Log
+			if test.receiptLogs != nil {
+				receiptLogs = test.receiptLogs(t, rewardAddress)
+			}
+			mockRPCClient.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(
+				&types.Receipt{
+					Status: test.receiptStatus,
+					Logs:   receiptLogs,
+				},
+				nil,
+			).Times(redemptions)
+
+			redeemer.redeemRewards()
+
+			require.Equal(t, test.expectedRedeemed, redeemer.redeemed[feeTokenAddress])
+
+			// The total is carried over to a new redeemer
+			reloaded, err := newRewardRedeemer(
+				logging.NoLog{},
+				destClient,
+				mockClient,
+				destinationBlockchainID,
+				rewardRedemption,
+				nil,
+				state,
+			)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedRedeemed, reloaded.redeemed[feeTokenAddress])
+		})
+	}
+}
@@ -5,8 +5,10 @@ package evm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego/graft/subnet-evm/precompile/contracts/warp"
@@ -26,6 +28,8 @@ const (
 	MaxBlocksPerRequest         = 200
 )
 
+var errUnsubscribed = errors.New("subscriber is unsubscribed")
+
 type SubscriberRPCClient interface {
 	BlockNumber(ctx context.Context) (uint64, error)
 	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
@@ -43,7 +47,14 @@ type Subscriber struct {
 	icmBlocks    chan *relayerTypes.WarpBlockInfo
 	sub          ethereum.Subscription
 
+	// If non-zero, new blocks are polled from the RPC endpoint at this interval instead of
+	// being received from a WS subscription
+	pollingInterval time.Duration
+
 	errChan chan error
+	// Closed by Unsubscribe to stop the goroutines that write to the blocks and error channels
+	done            chan struct{}
+	unsubscribeOnce sync.Once
 
 	logger logging.Logger
 }
@@ -64,16 +75,31 @@ func NewSubscriber(
 		icmBlocks:    make(chan *relayerTypes.WarpBlockInfo, maxClientSubscriptionBuffer),
 		headers:      make(chan *types.Header, maxClientSubscriptionBuffer),
 		errChan:      errChan,
+		done:         make(chan struct{}),
 	}
 	go subscriber.blocksInfoFromHeaders()
 	return subscriber
 }
 
+// NewPollingSubscriber returns a Subscriber that polls the RPC endpoint for new blocks, for source
+// blockchains that do not expose a WS endpoint
+func NewPollingSubscriber(
+	logger logging.Logger,
+	blockchainID ids.ID,
+	rpcClient SubscriberRPCClient,
+	pollingInterval time.Duration,
+	errChan chan error,
+) *Subscriber {
+	subscriber := NewSubscriber(logger, blockchainID, nil, rpcClient, errChan)
+	subscriber.pollingInterval = pollingInterval
+	return subscriber
+}
+
 // Process logs from the starting block to the ending block, inclusive. Limits the
 // number of blocks retrieved in a single eth_getLogs request to
 // `MaxBlocksPerRequest`; if processing more than that, multiple eth_getLogs
 // requests will be made.
-// Writes to the error channel if an error occurs
+// Writes to the error channel if an error occurs. Returns early if the Subscriber is unsubscribed.
 func (s *Subscriber) ProcessFromHeight(startingHeight uint64, endingHeight uint64) {
 	log := s.logger.With(
 		zap.Uint64("fromBlockHeight", startingHeight),
@@ -85,8 +111,12 @@ func (s *Subscriber) ProcessFromHeight(startingHeight uint64, endingHeight uint6
 		toBlock := min(fromBlock+MaxBlocksPerRequest-1, endingHeight)
 
 		err := s.processBlockRange(fromBlock, toBlock)
+		if errors.Is(err, errUnsubscribed) {
+			log.Info("Stopped processing historical logs")
+			return
+		}
 		if err != nil {
-			s.errChan <- fmt.Errorf("failed to process block range: %w", err)
+			s.sendErr(fmt.Errorf("failed to process block range: %w", err))
 			return
 		}
 	}
@@ -102,29 +132,49 @@ func (s *Subscriber) processBlockRange(
 		zap.Uint64("fromBlockHeight", fromBlock),
 		zap.Uint64("toBlockHeight", toBlock),
 	)
+	blocks, err := s.getBlockRange(fromBlock, toBlock, true)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		select {
+		case s.icmBlocks <- block:
+		case <-s.done:
+			return errUnsubscribed
+		}
+	}
+	return nil
+}
+
+// Returns the Warp messages of each block in the range [fromBlock, toBlock], inclusive
+func (s *Subscriber) getBlockRange(
+	fromBlock, toBlock uint64,
+	isCatchup bool,
+) ([]*relayerTypes.WarpBlockInfo, error) {
 	logs, err := s.getFilterLogsByBlockRangeRetryable(fromBlock, toBlock)
 	if err != nil {
-		return fmt.Errorf("failed to get header by number after max attempts: %w", err)
+		return nil, fmt.Errorf("failed to get header by number after max attempts: %w", err)
 	}
 
 	blocksWithICMMessages, err := relayerTypes.LogsToBlocks(logs)
 	if err != nil {
 		s.logger.Error("Failed to convert logs to blocks", zap.Error(err))
-		return err
+		return nil, err
 	}
+	blocks := make([]*relayerTypes.WarpBlockInfo, 0, toBlock-fromBlock+1)
 	for i := fromBlock; i <= toBlock; i++ {
-		if block, ok := blocksWithICMMessages[i]; ok {
-			s.icmBlocks <- block
-		} else {
+		block, ok := blocksWithICMMessages[i]
+		if !ok {
 			// Blocks with no ICM messages also need to be explicitly processed.
-			s.icmBlocks <- &relayerTypes.WarpBlockInfo{
+			block = &relayerTypes.WarpBlockInfo{
 				BlockNumber: i,
 				Messages:    []*relayerTypes.WarpMessageInfo{},
-				IsCatchup:   true,
 			}
 		}
+		block.IsCatchup = isCatchup
+		blocks = append(blocks, block)
 	}
-	return nil
+	return blocks, nil
 }
 
 func (s *Subscriber) getFilterLogsByBlockRangeRetryable(fromBlock, toBlock uint64) ([]types.Log, error) {
@@ -170,8 +220,24 @@ func (s *Subscriber) Subscribe(retryTimeout time.Duration) error {
 	return nil
 }
 
+// Unsubscribe closes the subscription, stops converting headers to [relayerTypes.WarpBlockInfo], and stops
+// processing historical logs. The Subscriber must not be used after calling Unsubscribe.
+func (s *Subscriber) Unsubscribe() {
+	if s.sub != nil {
+		// No further headers are delivered once Unsubscribe returns
+		s.sub.Unsubscribe()
+	}
+	s.unsubscribeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
 // subscribe until it succeeds or reached timeout.
 func (s *Subscriber) subscribe(retryTimeout time.Duration) error {
+	if s.pollingInterval != 0 {
+		return s.subscribePolling(retryTimeout)
+	}
+
 	var sub ethereum.Subscription
 	operation := func() (err error) {
 		cctx, cancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
@@ -196,16 +262,137 @@ func (s *Subscriber) subscribe(retryTimeout time.Duration) error {
 	return nil
 }
 
+// subscribePolling fetches the latest block number until it succeeds or reached timeout, and starts polling
+// for new blocks from there.
+func (s *Subscriber) subscribePolling(retryTimeout time.Duration) error {
+	var latestHeight uint64
+	operation := func() (err error) {
+		cctx, cancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+		defer cancel()
+		latestHeight, err = s.rpcClient.BlockNumber(cctx)
+		return err
+	}
+	notify := func(err error, duration time.Duration) {
+		s.logger.Info(
+			"get block number failed, retrying...",
+			zap.Duration("retryIn", duration),
+			zap.Error(err),
+		)
+	}
+
+	err := utils.WithRetriesTimeout(operation, notify, retryTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block number: %w", err)
+	}
+
+	sub := &pollingSubscription{
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+		err:  make(chan error, 1),
+	}
+	go s.pollBlocks(sub, latestHeight)
+	s.sub = sub
+
+	return nil
+}
+
+// pollBlocks writes the [relayerTypes.WarpBlockInfo] of each new block to the blocks channel consumed by the
+// listener, until the subscription is unsubscribed or an RPC call fails. The latest block at the time of
+// subscribing is written first, in the same way as the first header received from a WS subscription.
+func (s *Subscriber) pollBlocks(sub *pollingSubscription, latestHeight uint64) {
+	defer close(sub.done)
+
+	ticker := time.NewTicker(s.pollingInterval)
+	defer ticker.Stop()
+
+	var lastPolledHeight uint64
+	if latestHeight > 0 {
+		lastPolledHeight = latestHeight - 1
+	}
+	for {
+		for fromBlock := lastPolledHeight + 1; fromBlock <= latestHeight; fromBlock += MaxBlocksPerRequest {
+			toBlock := min(fromBlock+MaxBlocksPerRequest-1, latestHeight)
+			blocks, err := s.getBlockRange(fromBlock, toBlock, false)
+			if err != nil {
+				sub.err <- fmt.Errorf("failed to poll block range: %w", err)
+				return
+			}
+			for _, block := range blocks {
+				select {
+				case s.icmBlocks <- block:
+				case <-sub.quit:
+					return
+				}
+			}
+			lastPolledHeight = toBlock
+		}
+
+		select {
+		case <-ticker.C:
+		case <-sub.quit:
+			return
+		}
+
+		cctx, cancel := context.WithTimeout(context.Background(), utils.DefaultRPCTimeout)
+		height, err := s.rpcClient.BlockNumber(cctx)
+		cancel()
+		if err != nil {
+			sub.err <- fmt.Errorf("failed to poll latest block number: %w", err)
+			return
+		}
+		latestHeight = max(latestHeight, height)
+	}
+}
+
+// pollingSubscription is the [ethereum.Subscription] of a polling Subscriber
+type pollingSubscription struct {
+	quit            chan struct{}
+	done            chan struct{}
+	err             chan error
+	unsubscribeOnce sync.Once
+}
+
+// Unsubscribe stops polling. No further blocks are written once Unsubscribe returns.
+func (p *pollingSubscription) Unsubscribe() {
+	p.unsubscribeOnce.Do(func() {
+		close(p.quit)
+		<-p.done
+	})
+}
+
+func (p *pollingSubscription) Err() <-chan error {
+	return p.err
+}
+
 // blocksInfoFromHeaders listens to the header channel and converts the headers to [relayerTypes.WarpBlockInfo]
-// and writes them to the blocks channel consumed by the listener
+// and writes them to the blocks channel consumed by the listener, until the Subscriber is unsubscribed
 func (s *Subscriber) blocksInfoFromHeaders() {
-	for header := range s.headers {
+	for {
+		var header *types.Header
+		select {
+		case header = <-s.headers:
+		case <-s.done:
+			return
+		}
 		block, err := relayerTypes.NewWarpBlockInfo(s.logger, header, s.rpcClient)
 		if err != nil {
-			s.errChan <- fmt.Errorf("creating warp block info: %w", err)
+			s.sendErr(fmt.Errorf("creating warp block info: %w", err))
 			return
 		}
-		s.icmBlocks <- block
+		select {
+		case s.icmBlocks <- block:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// sendErr writes [err] to the error channel, unless the Subscriber is unsubscribed, in which case the
+// listener no longer reads it
+func (s *Subscriber) sendErr(err error) {
+	select {
+	case s.errChan <- err:
+	case <-s.done:
 	}
 }
 
@@ -5,7 +5,9 @@ package evm
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ryt-io/ryt-v2/ids"
 	"github.com/ryt-io/ryt-v2/utils/logging"
@@ -120,3 +122,77 @@ func TestProcessFromHeight(t *testing.T) {
 		})
 	}
 }
+
+type pollingClientStub struct {
+	lock        sync.Mutex
+	blockNumber uint64
+}
+
+func (c *pollingClientStub) setBlockNumber(blockNumber uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.blockNumber = blockNumber
+}
+
+func (c *pollingClientStub) BlockNumber(ctx context.Context) (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.blockNumber, nil
+}
+
+func (c *pollingClientStub) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return []types.Log{}, nil
+}
+
+func TestPollingSubscriber(t *testing.T) {
+	stubRPCClient := &pollingClientStub{blockNumber: 10}
+	errChan := make(chan error, 1)
+	subscriber := NewPollingSubscriber(
+		logging.NoLog{},
+		ids.GenerateTestID(),
+		stubRPCClient,
+		10*time.Millisecond,
+		errChan,
+	)
+	require.NoError(t, subscriber.Subscribe(time.Second))
+
+	// The latest block is received first, in the same way as the first header of a WS subscription
+	block := <-subscriber.ICMBlocks()
+	require.Equal(t, uint64(10), block.BlockNumber)
+	require.False(t, block.IsCatchup)
+
+	// Each new block is then received in order
+	stubRPCClient.setBlockNumber(13)
+	for i := uint64(11); i <= 13; i++ {
+		block := <-subscriber.ICMBlocks()
+		require.Equal(t, i, block.BlockNumber)
+		require.Empty(t, block.Messages)
+		require.False(t, block.IsCatchup)
+	}
+
+	// No further blocks are received once unsubscribed
+	subscriber.Unsubscribe()
+	stubRPCClient.setBlockNumber(20)
+	time.Sleep(50 * time.Millisecond)
+	require.Empty(t, subscriber.ICMBlocks())
+	require.Empty(t, errChan)
+}
+
+func TestUnsubscribeStopsProcessFromHeight(t *testing.T) {
+	errChan := make(chan error)
+	subscriber, _ := makeSubscriberWithMockEthClient(t, errChan)
+	subscriber.Unsubscribe()
+
+	// The blocks are not consumed, so processing them would block once the blocks channel is full
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		subscriber.ProcessFromHeight(0, 2*maxClientSubscriptionBuffer)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "ProcessFromHeight did not return after unsubscribing")
+	}
+	require.Empty(t, errChan)
+}
@@ -0,0 +1,322 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/relayer/config"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// Source chain label value of spend limits that apply to all messages sent to a destination blockchain
+const allSourceChainsLabel = "all"
+
+// SpendLimitExhaustedError is returned when sending a transaction if one of the destination's spend limits can
+// not cover its maximum fee in the current period. The transaction can be sent once the limit resets.
+type SpendLimitExhaustedError struct {
+	Period   config.SpendLimitPeriod
+	MaxSpend *big.Int
+	ResetAt  time.Time
+}
+
+func (e *SpendLimitExhaustedError) Error() string {
+	return fmt.Sprintf(
+		"%s spend limit of %s exhausted until %s",
+		e.Period,
+		e.MaxSpend,
+		e.ResetAt.Format(time.RFC3339),
+	)
+}
+
+// spendBudget tracks the fees spent against a spend limit in the current period
+type spendBudget struct {
+	sourceBlockchainID ids.ID
+	period             config.SpendLimitPeriod
+	periodDuration     time.Duration
+	maxSpend           *big.Int
+	periodStart        time.Time
+	spent              *big.Int
+}
+
+func (b *spendBudget) appliesTo(sourceBlockchainID ids.ID) bool {
+	return b.sourceBlockchainID == ids.Empty || b.sourceBlockchainID == sourceBlockchainID
+}
+
+// reset starts a new period if the current period has ended. Returns true if a new period was started.
+func (b *spendBudget) reset(now time.Time) bool {
+	periodStart := now.UTC().Truncate(b.periodDuration)
+	if !periodStart.After(b.periodStart) {
+		return false
+	}
+	b.periodStart = periodStart
+	b.spent = big.NewInt(0)
+	return true
+}
+
+func (b *spendBudget) periodEnd() time.Time {
+	return b.periodStart.Add(b.periodDuration)
+}
+
+func (b *spendBudget) remaining() *big.Int {
+	remaining := new(big.Int).Sub(b.maxSpend, b.spent)
+	if remaining.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return remaining
+}
+
+func (b *spendBudget) labelValues(destinationBlockchainID ids.ID) []string {
+	sourceChainLabel := allSourceChainsLabel
+	if b.sourceBlockchainID != ids.Empty {
+		sourceChainLabel = b.sourceBlockchainID.String()
+	}
+	return []string{destinationBlockchainID.String(), sourceChainLabel, b.period.String()}
+}
+
+// storedSpendBudget is the state of a spend budget that is stored in the database
+type storedSpendBudget struct {
+	SourceBlockchainID ids.ID    `json:"sourceBlockchainID"`
+	Period             string    `json:"period"`
+	PeriodStart        time.Time `json:"periodStart"`
+	Spent              string    `json:"spent"`
+}
+
+// spendReservation is the maximum fee of a transaction, reserved against each budget that applies to it
+// until the actual fee is known.
+type spendReservation struct {
+	limiter      *txLimiter
+	amount       *big.Int
+	budgets      []*spendBudget
+	periodStarts []time.Time
+}
+
+// settle replaces the reserved fee with the [actual] fee spent by the transaction. The reservation is
+// kept in full if settle is not called, such as when it is unknown whether the transaction was included.
+func (r *spendReservation) settle(actual *big.Int) {
+	if r == nil || len(r.budgets) == 0 {
+		return
+	}
+	r.limiter.lock.Lock()
+	defer r.limiter.lock.Unlock()
+
+	refund := new(big.Int).Sub(r.amount, actual)
+	for i, budget := range r.budgets {
+		// Fees spent in a period that has since ended no longer count against the budget
+		if !budget.periodStart.Equal(r.periodStarts[i]) {
+			continue
+		}
+		budget.spent.Sub(budget.spent, refund)
+		if budget.spent.Sign() < 0 {
+			budget.spent.SetInt64(0)
+		}
+		r.limiter.updateRemaining(budget)
+		r.limiter.dirty = true
+	}
+}
+
+// txLimiter enforces a destination blockchain's maximum transaction rate and spend limits. The spend in the
+// current period of each limit is stored in the database, so that it is kept across restarts and reloads.
+type txLimiter struct {
+	logger                  logging.Logger
+	destinationBlockchainID ids.ID
+	// nil if the transaction rate is not limited
+	rateLimiter *rate.Limiter
+	metrics     *DestinationClientMetrics
+	state       *destinationState
+	// Cancelled by close, to stop waiting for the transaction rate
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock    sync.Mutex
+	budgets []*spendBudget
+	// Whether the budgets changed since they were last stored
+	dirty bool
+	now   func() time.Time
+}
+
+// newTxLimiter creates the limiter of a destination blockchain, and loads the spend of its limits in the current
+// period from [state]. Stored spend of limits that are no longer configured is discarded.
+func newTxLimiter(
+	logger logging.Logger,
+	destinationBlockchain *config.DestinationBlockchain,
+	metrics *DestinationClientMetrics,
+	state *destinationState,
+) (*txLimiter, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &txLimiter{
+		logger:                  logger,
+		destinationBlockchainID: destinationBlockchain.GetBlockchainID(),
+		metrics:                 metrics,
+		state:                   state,
+		ctx:                     ctx,
+		cancel:                  cancel,
+		budgets:                 make([]*spendBudget, len(destinationBlockchain.SpendLimits)),
+		now:                     time.Now,
+	}
+	if destinationBlockchain.MaxTransactionsPerMinute > 0 {
+		interval := time.Minute / time.Duration(destinationBlockchain.MaxTransactionsPerMinute)
+		l.rateLimiter = rate.NewLimiter(rate.Every(interval), 1)
+	}
+	for i, limit := range destinationBlockchain.SpendLimits {
+		l.budgets[i] = &spendBudget{
+			sourceBlockchainID: limit.GetSourceBlockchainID(),
+			period:             limit.GetPeriod(),
+			periodDuration:     limit.GetPeriodDuration(),
+			maxSpend:           limit.GetMaxSpend(),
+			spent:              big.NewInt(0),
+		}
+	}
+	if err := l.loadBudgets(); err != nil {
+		cancel()
+		return nil, err
+	}
+	for _, budget := range l.budgets {
+		l.updateRemaining(budget)
+		l.setPaused(budget, false)
+	}
+	return l, nil
+}
+
+// loadBudgets sets the spend of the budgets to the stored spend of the limits with the same source blockchain
+// and period
+func (l *txLimiter) loadBudgets() error {
+	var stored []storedSpendBudget
+	ok, err := l.state.get(database.SpendBudgetsKey, &stored)
+	if err != nil || !ok {
+		return err
+	}
+	for _, storedBudget := range stored {
+		for _, budget := range l.budgets {
+			if budget.sourceBlockchainID != storedBudget.SourceBlockchainID ||
+				budget.period.String() != storedBudget.Period {
+				continue
+			}
+			spent, ok := new(big.Int).SetString(storedBudget.Spent, 10)
+			if !ok {
+				return fmt.Errorf("invalid stored spend %q", storedBudget.Spent)
+			}
+			budget.periodStart = storedBudget.PeriodStart
+			budget.spent = spent
+		}
+	}
+	return nil
+}
+
+// writeState stores the budgets if they changed since they were last stored
+func (l *txLimiter) writeState() {
+	l.lock.Lock()
+	if !l.dirty {
+		l.lock.Unlock()
+		return
+	}
+	stored := make([]storedSpendBudget, len(l.budgets))
+	for i, budget := range l.budgets {
+		stored[i] = storedSpendBudget{
+			SourceBlockchainID: budget.sourceBlockchainID,
+			Period:             budget.period.String(),
+			PeriodStart:        budget.periodStart,
+			Spent:              budget.spent.String(),
+		}
+	}
+	l.dirty = false
+	l.lock.Unlock()
+
+	if err := l.state.put(database.SpendBudgetsKey, stored); err != nil {
+		l.logger.Error("Failed to store spend budgets", zap.Error(err))
+		l.lock.Lock()
+		l.dirty = true
+		l.lock.Unlock()
+	}
+}
+
+// close stops waiting for the transaction rate
+func (l *txLimiter) close() {
+	l.cancel()
+}
+
+// waitForRate blocks until a transaction may be sent without exceeding the maximum transaction rate.
+// Returns an error if the limiter is closed while waiting.
+func (l *txLimiter) waitForRate() error {
+	if l.rateLimiter == nil {
+		return nil
+	}
+	return l.rateLimiter.Wait(l.ctx)
+}
+
+// reserve reserves [amount] against each budget that applies to messages from [sourceBlockchainID].
+// If any of the budgets can not cover [amount] in the current period, nothing is reserved and a
+// SpendLimitExhaustedError is returned.
+func (l *txLimiter) reserve(sourceBlockchainID ids.ID, amount *big.Int) (*spendReservation, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	reservation := &spendReservation{
+		limiter: l,
+		amount:  amount,
+	}
+	for _, budget := range l.budgets {
+		if !budget.appliesTo(sourceBlockchainID) {
+			continue
+		}
+		if amount.Cmp(budget.maxSpend) > 0 {
+			return nil, fmt.Errorf(
+				"transaction fee %s exceeds the %s spend limit of %s",
+				amount,
+				budget.period,
+				budget.maxSpend,
+			)
+		}
+		if budget.reset(now) {
+			l.setPaused(budget, false)
+			l.dirty = true
+		}
+		if amount.Cmp(budget.remaining()) > 0 {
+			l.setPaused(budget, true)
+			return nil, &SpendLimitExhaustedError{
+				Period:   budget.period,
+				MaxSpend: budget.maxSpend,
+				ResetAt:  budget.periodEnd(),
+			}
+		}
+		reservation.budgets = append(reservation.budgets, budget)
+		reservation.periodStarts = append(reservation.periodStarts, budget.periodStart)
+	}
+	for _, budget := range reservation.budgets {
+		budget.spent.Add(budget.spent, amount)
+		l.updateRemaining(budget)
+	}
+	if len(reservation.budgets) > 0 {
+		l.dirty = true
+	}
+	return reservation, nil
+}
+
+func (l *txLimiter) updateRemaining(budget *spendBudget) {
+	if l.metrics == nil {
+		return
+	}
+	remaining, _ := new(big.Float).SetInt(budget.remaining()).Float64()
+	l.metrics.spendBudgetRemaining.WithLabelValues(budget.labelValues(l.destinationBlockchainID)...).Set(remaining)
+}
+
+func (l *txLimiter) setPaused(budget *spendBudget, paused bool) {
+	if l.metrics == nil {
+		return
+	}
+	value := 0.0
+	if paused {
+		value = 1.0
+	}
+	l.metrics.spendBudgetPaused.WithLabelValues(budget.labelValues(l.destinationBlockchainID)...).Set(value)
+}
@@ -0,0 +1,210 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ryt-io/ryt-v2/ids"
+	"github.com/ryt-io/ryt-v2/utils/logging"
+	"github.com/ryt-io/ryt-v2/utils/set"
+	"github.com/ryt-io/icm-services/database"
+	"github.com/ryt-io/icm-services/relayer/config"
+	mock_ethclient "github.com/ryt-io/icm-services/vms/evm/mocks"
+	"github.com/ryt-io/libevm/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/time/rate"
+)
+
+func newTestTxLimiter(
+	t *testing.T,
+	sourceBlockchainID ids.ID,
+	now *time.Time,
+	state *destinationState,
+) *txLimiter {
+	destinationBlockchain := config.TestValidDestinationBlockchainConfig
+	destinationBlockchain.SpendLimits = []*config.SpendLimit{
+		{MaxSpend: "1000", Period: "day"},
+		{SourceBlockchainID: sourceBlockchainID.String(), MaxSpend: "300", Period: "hour"},
+	}
+	require.NoError(t, destinationBlockchain.Validate())
+	limiter, err := newTxLimiter(logging.NoLog{}, &destinationBlockchain, nil, state)
+	require.NoError(t, err)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestTxLimiterReserve(t *testing.T) {
+	routeSourceID := ids.GenerateTestID()
+	otherSourceID := ids.GenerateTestID()
+	testCases := []struct {
+		name               string
+		reserve            func(t *testing.T, limiter *txLimiter, now *time.Time)
+		sourceBlockchainID ids.ID
+		amount             int64
+		expectedExhausted  config.SpendLimitPeriod
+		expectedResetAt    time.Time
+		expectedErr        bool
+	}{
+		{
+			name:               "within limits",
+			reserve:            func(*testing.T, *txLimiter, *time.Time) {},
+			sourceBlockchainID: routeSourceID,
+			amount:             300,
+		},
+		{
+			name:               "exceeds limit",
+			reserve:            func(*testing.T, *txLimiter, *time.Time) {},
+			sourceBlockchainID: routeSourceID,
+			amount:             301,
+			expectedErr:        true,
+		},
+		{
+			name: "route limit exhausted",
+			reserve: func(t *testing.T, limiter *txLimiter, _ *time.Time) {
+				reserveSpend(t, limiter, routeSourceID, 200)
+			},
+			sourceBlockchainID: routeSourceID,
+			amount:             200,
+			expectedExhausted:  config.HOURLY_SPEND_LIMIT_PERIOD,
+			expectedResetAt:    time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "route limit does not apply to other routes",
+			reserve: func(t *testing.T, limiter *txLimiter, _ *time.Time) {
+				reserveSpend(t, limiter, routeSourceID, 200)
+			},
+			sourceBlockchainID: otherSourceID,
+			amount:             800,
+		},
+		{
+			name: "destination limit exhausted",
+			reserve: func(t *testing.T, limiter *txLimiter, _ *time.Time) {
+				reserveSpend(t, limiter, otherSourceID, 900)
+			},
+			sourceBlockchainID: routeSourceID,
+			amount:             200,
+			expectedExhausted:  config.DAILY_SPEND_LIMIT_PERIOD,
+			expectedResetAt:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "route limit reset",
+			reserve: func(t *testing.T, limiter *txLimiter, now *time.Time) {
+				reserveSpend(t, limiter, routeSourceID, 300)
+				*now = now.Add(time.Hour)
+			},
+			sourceBlockchainID: routeSourceID,
+			amount:             300,
+		},
+		{
+			name: "settled below reservation",
+			reserve: func(t *testing.T, limiter *txLimiter, _ *time.Time) {
+				reserveSpend(t, limiter, routeSourceID, 300).settle(big.NewInt(100))
+			},
+			sourceBlockchainID: routeSourceID,
+			amount:             200,
+		},
+		{
+			name: "not settled",
+			reserve: func(t *testing.T, limiter *txLimiter, _ *time.Time) {
+				reserveSpend(t, limiter, routeSourceID, 300)
+			},
+			sourceBlockchainID: routeSourceID,
+			amount:             1,
+			expectedExhausted:  config.HOURLY_SPEND_LIMIT_PERIOD,
+			expectedResetAt:    time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)
+			limiter := newTestTxLimiter(t, routeSourceID, &now, nil)
+			testCase.reserve(t, limiter, &now)
+
+			reservation, err := limiter.reserve(
+				testCase.sourceBlockchainID,
+				big.NewInt(testCase.amount),
+			)
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			if testCase.expectedExhausted != config.UNKNOWN_SPEND_LIMIT_PERIOD {
+				var exhausted *SpendLimitExhaustedError
+				require.True(t, errors.As(err, &exhausted))
+				require.Nil(t, reservation)
+				require.Equal(t, testCase.expectedExhausted, exhausted.Period)
+				require.Equal(t, testCase.expectedResetAt, exhausted.ResetAt)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, reservation)
+		})
+	}
+}
+
+func reserveSpend(t *testing.T, limiter *txLimiter, sourceBlockchainID ids.ID, amount int64) *spendReservation {
+	reservation, err := limiter.reserve(sourceBlockchainID, big.NewInt(amount))
+	require.NoError(t, err)
+	require.NotNil(t, reservation)
+	return reservation
+}
+
+func TestTxLimiterStoredSpend(t *testing.T) {
+	routeSourceID := ids.GenerateTestID()
+	destinationBlockchainID, err := ids.FromString(config.TestValidDestinationBlockchainConfig.BlockchainID)
+	require.NoError(t, err)
+	db, err := database.NewJSONFileStorage(
+		logging.NoLog{},
+		t.TempDir(),
+		[]database.RelayerID{database.NewDestinationStateID(destinationBlockchainID)},
+	)
+	require.NoError(t, err)
+	state := newDestinationState(db, destinationBlockchainID)
+
+	now := time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)
+	limiter := newTestTxLimiter(t, routeSourceID, &now, state)
+	reserveSpend(t, limiter, routeSourceID, 200)
+	limiter.writeState()
+
+	// The spend is carried over to a new limiter in the same period
+	reloaded := newTestTxLimiter(t, routeSourceID, &now, state)
+	_, err = reloaded.reserve(routeSourceID, big.NewInt(200))
+	var exhausted *SpendLimitExhaustedError
+	require.True(t, errors.As(err, &exhausted))
+	require.Equal(t, config.HOURLY_SPEND_LIMIT_PERIOD, exhausted.Period)
+
+	// and discarded once the period ends
+	now = now.Add(time.Hour)
+	reloaded = newTestTxLimiter(t, routeSourceID, &now, state)
+	reserveSpend(t, reloaded, routeSourceID, 300)
+}
+
+func TestSendTxOverSpendLimitKeepsRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_ethclient.NewMockDestinationRPCClient(ctrl)
+	now := time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)
+	limiter := newTestTxLimiter(t, ids.GenerateTestID(), &now, nil)
+	limiter.rateLimiter = rate.NewLimiter(rate.Every(time.Hour), 1)
+	destClient := &destinationClient{
+		logger:       logging.NoLog{},
+		avaRPCClient: mockClient,
+		gasFeeConfig: &GasFeeConfig{
+			maxBaseFee:                 big.NewInt(100),
+			suggestedPriorityFeeBuffer: big.NewInt(0),
+			maxPriorityFeePerGas:       big.NewInt(10),
+		},
+		txLimiter: limiter,
+	}
+	mockClient.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(1), nil)
+
+	// The transaction's fee exceeds the spend limits, so it does not use up the transaction rate
+	_, err := SendTx(destClient, nil, set.Set[common.Address]{}, common.Address{}.Hex(), 100_000, nil, time.Minute, nil)
+	require.Error(t, err)
+	require.Equal(t, float64(1), limiter.rateLimiter.Tokens())
+}
@@ -74,6 +74,18 @@ func (mr *MockDestinationClientMockRecorder) Client() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Client", reflect.TypeOf((*MockDestinationClient)(nil).Client))
 }
 
+// Close mocks base method.
+func (m *MockDestinationClient) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockDestinationClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDestinationClient)(nil).Close))
+}
+
 // DestinationBlockchainID mocks base method.
 func (m *MockDestinationClient) DestinationBlockchainID() ids.ID {
 	m.ctrl.T.Helper()
@@ -117,19 +129,47 @@ func (mr *MockDestinationClientMockRecorder) GetRPCEndpointURL() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRPCEndpointURL", reflect.TypeOf((*MockDestinationClient)(nil).GetRPCEndpointURL))
 }
 
+// Health mocks base method.
+func (m *MockDestinationClient) Health() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockDestinationClientMockRecorder) Health() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockDestinationClient)(nil).Health))
+}
+
 // SendTx mocks base method.
-func (m *MockDestinationClient) SendTx(signedMessage *warp.Message, deliverers set.Set[common.Address], toAddress string, gasLimit uint64, callData []byte) (*types.Receipt, error) {
+func (m *MockDestinationClient) SendTx(signedMessage *warp.Message, deliverers set.Set[common.Address], toAddress string, gasLimit uint64, callData []byte, onSent func(common.Hash)) (*types.Receipt, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendTx", signedMessage, deliverers, toAddress, gasLimit, callData)
+	ret := m.ctrl.Call(m, "SendTx", signedMessage, deliverers, toAddress, gasLimit, callData, onSent)
 	ret0, _ := ret[0].(*types.Receipt)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SendTx indicates an expected call of SendTx.
-func (mr *MockDestinationClientMockRecorder) SendTx(signedMessage, deliverers, toAddress, gasLimit, callData any) *gomock.Call {
+func (mr *MockDestinationClientMockRecorder) SendTx(signedMessage, deliverers, toAddress, gasLimit, callData, onSent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTx", reflect.TypeOf((*MockDestinationClient)(nil).SendTx), signedMessage, deliverers, toAddress, gasLimit, callData, onSent)
+}
+
+// SimulateTx mocks base method.
+func (m *MockDestinationClient) SimulateTx(signedMessage *warp.Message, deliverers set.Set[common.Address], toAddress string, gasLimit uint64, callData []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimulateTx", signedMessage, deliverers, toAddress, gasLimit, callData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SimulateTx indicates an expected call of SimulateTx.
+func (mr *MockDestinationClientMockRecorder) SimulateTx(signedMessage, deliverers, toAddress, gasLimit, callData any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTx", reflect.TypeOf((*MockDestinationClient)(nil).SendTx), signedMessage, deliverers, toAddress, gasLimit, callData)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateTx", reflect.TypeOf((*MockDestinationClient)(nil).SimulateTx), signedMessage, deliverers, toAddress, gasLimit, callData)
 }
 
 // SenderAddresses mocks base method.
@@ -145,3 +185,43 @@ func (mr *MockDestinationClientMockRecorder) SenderAddresses() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SenderAddresses", reflect.TypeOf((*MockDestinationClient)(nil).SenderAddresses))
 }
+
+// MockDryRunRecorder is a mock of DryRunRecorder interface.
+type MockDryRunRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockDryRunRecorderMockRecorder
+	isgomock struct{}
+}
+
+// MockDryRunRecorderMockRecorder is the mock recorder for MockDryRunRecorder.
+type MockDryRunRecorderMockRecorder struct {
+	mock *MockDryRunRecorder
+}
+
+// NewMockDryRunRecorder creates a new mock instance.
+func NewMockDryRunRecorder(ctrl *gomock.Controller) *MockDryRunRecorder {
+	mock := &MockDryRunRecorder{ctrl: ctrl}
+	mock.recorder = &MockDryRunRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDryRunRecorder) EXPECT() *MockDryRunRecorderMockRecorder {
+	return m.recorder
+}
+
+// DryRunTransaction mocks base method.
+func (m *MockDryRunRecorder) DryRunTransaction(txHash common.Hash) (*types.Transaction, common.Address, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunTransaction", txHash)
+	ret0, _ := ret[0].(*types.Transaction)
+	ret1, _ := ret[1].(common.Address)
+	ret2, _ := ret[2].(bool)
+	return ret0, ret1, ret2
+}
+
+// DryRunTransaction indicates an expected call of DryRunTransaction.
+func (mr *MockDryRunRecorderMockRecorder) DryRunTransaction(txHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunTransaction", reflect.TypeOf((*MockDryRunRecorder)(nil).DryRunTransaction), txHash)
+}